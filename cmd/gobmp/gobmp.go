@@ -1,34 +1,80 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"net/http"
 	_ "net/http/pprof"
 
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
 	"github.com/sbezverk/gobmp/pkg/dumper"
 	"github.com/sbezverk/gobmp/pkg/filer"
 	"github.com/sbezverk/gobmp/pkg/gobmpsrv"
+	"github.com/sbezverk/gobmp/pkg/grpc"
 	"github.com/sbezverk/gobmp/pkg/kafka"
+	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/nats"
 	"github.com/sbezverk/gobmp/pkg/pub"
+	"github.com/sbezverk/gobmp/pkg/redis"
 	"github.com/sbezverk/tools"
 )
 
 var (
 	dstPort   int
+	dstAddr   string
 	srcPort   int
+	srcAddr   string
+	maxConns  int
+	passive   string
+	tlsCert   string
+	tlsKey    string
+	readTO    time.Duration
+	kaIdle    time.Duration
+	kaIntvl   time.Duration
+	kaCount   int
+	publishTO time.Duration
+	queueCap  int
+	parseConc int
+	queueDrop string
+	resync    int
+	tapDir    string
+	tapAddrs  string
+	tapSize   int64
+	addPath   string
+	rawMsg    bool
+	msgID     bool
+	attrFlags bool
+	unkAttrs  bool
+	parseErrs bool
+	endOfRIB  bool
+	peerEvent bool
 	perfPort  int
 	kafkaSrv  string
+	kafkaKey  string
+	kafkaComp string
+	kafkaAcks string
 	natsSrv   string
+	redisSrv  string
+	redisKey  string
+	redisLen  int64
+	grpcSrv   string
 	intercept string
 	splitAF   string
+	splitAFs  string
+	msgTypes  string
 	dump      string
 	file      string
 )
@@ -36,26 +82,67 @@ var (
 func init() {
 	runtime.GOMAXPROCS(1)
 	flag.IntVar(&srcPort, "source-port", 5000, "port exposed to outside")
+	flag.StringVar(&srcAddr, "source-address", "", "local address to listen on, when not set all interfaces are used")
+	flag.IntVar(&maxConns, "max-connections", 0, "maximum number of concurrent BMP sessions, 0 means unlimited")
+	flag.StringVar(&passive, "passive-routers", "", "comma separated list of host:port of routers expecting gobmp to connect to them")
+	flag.StringVar(&tlsCert, "tls-cert", "", "PEM encoded certificate file, when set together with tls-key incoming BMP sessions use TLS")
+	flag.StringVar(&tlsKey, "tls-key", "", "PEM encoded private key file of the tls-cert certificate")
+	flag.DurationVar(&readTO, "read-timeout", 0, "close BMP session when no message is received for the duration, 0 means no timeout")
+	flag.DurationVar(&kaIdle, "tcp-keepalive", 0, "enable TCP keepalive on BMP sessions, probes are sent after the session is idle for the duration, 0 means keepalive settings are not changed")
+	flag.DurationVar(&kaIntvl, "tcp-keepalive-interval", 0, "interval between TCP keepalive probes when \"tcp-keepalive\" is set, 0 means the system default")
+	flag.IntVar(&kaCount, "tcp-keepalive-count", 0, "number of unanswered TCP keepalive probes closing BMP session when \"tcp-keepalive\" is set, 0 means the system default")
+	flag.IntVar(&resync, "resync-max-skip", 0, "skip up to the number of bytes looking for a valid BMP message after receiving an invalid Common Header instead of closing the session, 0 means the session is closed")
+	flag.StringVar(&tapDir, "tap-directory", "", "directory to write BMP messages received from routers to, when not set sessions are not tapped")
+	flag.StringVar(&tapAddrs, "tap-routers", "", "comma separated list of addresses of routers tapped when \"tap-directory\" is set, when not set all routers are tapped")
+	flag.Int64Var(&tapSize, "tap-max-size", 100<<20, "maximum size in bytes of a tap file, a full file is kept with .1 suffix and a new one is started")
+	flag.StringVar(&addPath, "add-path", "", "comma separated list of afi/safi, for example 1/1,2/1, for which routers send NLRI with Add-Path Path Identifier")
+	flag.BoolVar(&rawMsg, "raw-bmp-message", false, "when set true, the original BMP message is attached to every published message as base64 encoded raw_bmp_message")
+	flag.BoolVar(&msgID, "message-id", false, "when set true, every published message carries message_id derived from its content to drop duplicates")
+	flag.BoolVar(&unkAttrs, "unknown-path-attributes", false, "when set true, path attributes not decoded by gobmp are attached to base_attrs of published messages as unknown_attrs")
+	flag.BoolVar(&attrFlags, "path-attribute-flags", false, "when set true, Attribute Flags of path attributes are attached to base_attrs of published messages as attr_flags")
+	flag.DurationVar(&publishTO, "publish-timeout", 0, "fail publishing a message not completed within the duration, 0 means no timeout")
+	flag.IntVar(&parseConc, "parse-concurrency", 0, "number of workers parsing messages of a BMP session, messages are published in the order they are received, 0 means every message is parsed by its own worker and published once parsed")
+	flag.IntVar(&queueCap, "producer-queue", 0, "number of BMP messages of a session waiting to be published, 0 means messages are published right away without a queue")
+	flag.StringVar(&queueDrop, "producer-drop-policy", "block", "what to do when the producer queue is full, \"block\" reading from the session, \"drop-oldest\" or \"drop-newest\" message")
+	flag.BoolVar(&endOfRIB, "publish-end-of-rib", false, "when set true, End-of-RIB markers received from peers are published to end_of_rib topic with the peer and afi/safi")
+	flag.BoolVar(&peerEvent, "publish-peer-events", false, "when set true, a peer event with action up or down is published to peer_event topic when a peer goes up or down, along with peer topic messages")
+	flag.BoolVar(&parseErrs, "publish-parse-errors", false, "when set true, BMP messages failed to be parsed are published to parse_error topic with the error and the message")
 	flag.IntVar(&dstPort, "destination-port", 5050, "port openBMP is listening")
+	flag.StringVar(&dstAddr, "destination-address", "", "address or host:port of the collector BMP messages are copied to when \"intercept=true\", when not set the local host and destination-port are used")
 	flag.StringVar(&kafkaSrv, "kafka-server", "", "URL to access Kafka server")
+	flag.StringVar(&kafkaKey, "kafka-partition-key", "router", "key of Kafka records, \"router\" keeps messages of a router in one partition, \"peer\" keeps messages of a peer in one partition")
+	flag.StringVar(&kafkaComp, "kafka-compression", "none", "compression codec of Kafka records, \"none\", \"gzip\", \"snappy\", \"lz4\" or \"zstd\"")
+	flag.StringVar(&kafkaAcks, "kafka-acks", "leader", "acknowledgement of produced Kafka records, \"none\", \"leader\" or \"all\"")
 	flag.StringVar(&natsSrv, "nats-server", "", "URL to access NATS server")
+	flag.StringVar(&redisSrv, "redis-server", "", "host:port or redis:// URL of Redis server when \"dump=redis\"")
+	flag.StringVar(&redisKey, "redis-stream", "gobmp.parsed", "key of Redis stream messages are added to when \"dump=redis\"")
+	flag.Int64Var(&redisLen, "redis-maxlen", 0, "approximate maximum number of entries of Redis stream, older entries are trimmed, 0 means the stream is not trimmed")
+	flag.StringVar(&grpcSrv, "grpc-server", ":50051", "address gRPC server listens on for subscribers when \"dump=grpc\"")
 	flag.StringVar(&intercept, "intercept", "false", "When intercept set \"true\", all incomming BMP messges will be copied to TCP port specified by destination-port, otherwise received BMP messages will be published to Kafka.")
 	flag.StringVar(&splitAF, "split-af", "true", "When set \"true\" (default) ipv4 and ipv6 will be published in separate topics. if set \"false\" the same topic will be used for both address families.")
-	flag.IntVar(&perfPort, "performance-port", 56767, "port used for performance debugging")
-	flag.StringVar(&dump, "dump", "", "Dump resulting messages to file when \"dump=file\", to standard output when \"dump=console\" or to NATS when \"dump=nats\"")
+	flag.IntVar(&perfPort, "performance-port", 56767, "port used for performance debugging and metrics")
+	flag.StringVar(&splitAFs, "split-afi-safi", "", "comma separated list of afi/safi, for example 1/1,2/1, split into ipv4 and ipv6 topics when \"split-af=true\", when not set all afi/safi are split")
+	flag.StringVar(&msgTypes, "bmp-message-types", "", "comma separated list of BMP message types, for example 0,2,3, published messages are limited to, when not set messages of all types are published")
+	flag.StringVar(&dump, "dump", "", "Dump resulting messages to file when \"dump=file\", to standard output when \"dump=console\", to NATS when \"dump=nats\", to Redis stream when \"dump=redis\" or to gRPC subscribers when \"dump=grpc\"")
 	flag.StringVar(&file, "msg-file", "/tmp/messages.json", "Full path anf file name to store messages when \"dump=file\"")
 }
 
 func main() {
 	flag.Parse()
 	_ = flag.Set("logtostderr", "true")
+	// Initializing metrics exposed on the performance port
+	m, err := metrics.NewMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		glog.Errorf("failed to initialize metrics with error: %+v", err)
+		os.Exit(1)
+	}
+	http.Handle("/metrics", promhttp.Handler())
 	// Starting performance collecting http server
 	go func() {
 		glog.Info(http.ListenAndServe(fmt.Sprintf(":%d", perfPort), nil))
 	}()
 	// Initializing publisher
 	var publisher pub.Publisher
-	var err error
 	switch strings.ToLower(dump) {
 	case "file":
 		publisher, err = filer.NewFiler(file)
@@ -78,8 +165,47 @@ func main() {
 			os.Exit(1)
 		}
 		glog.V(5).Infof("NATS publisher has been successfully initialized.")
+	case "redis":
+		redisOpts := []redis.Option{redis.WithStream(redisKey)}
+		if redisLen > 0 {
+			redisOpts = append(redisOpts, redis.WithMaxLen(redisLen, true))
+		}
+		publisher, err = redis.NewPublisher(redisSrv, redisOpts...)
+		if err != nil {
+			glog.Errorf("failed to initialize Redis publisher with error: %+v", err)
+			os.Exit(1)
+		}
+		glog.V(5).Infof("Redis publisher has been successfully initialized.")
+	case "grpc":
+		publisher, err = grpc.NewPublisher(grpcSrv)
+		if err != nil {
+			glog.Errorf("failed to initialize gRPC publisher with error: %+v", err)
+			os.Exit(1)
+		}
+		glog.V(5).Infof("gRPC publisher has been successfully initialized.")
 	default:
-		publisher, err = kafka.NewKafkaPublisher(kafkaSrv)
+		var keyFunc kafka.KeyFunc
+		switch kafkaKey {
+		case "router":
+			keyFunc = kafka.RouterKey
+		case "peer":
+			keyFunc = kafka.PeerKey
+		default:
+			glog.Errorf("invalid kafka-partition-key %q, supported values are \"router\" and \"peer\"", kafkaKey)
+			os.Exit(1)
+		}
+		codec, err := kafka.ParseCompression(kafkaComp)
+		if err != nil {
+			glog.Errorf("invalid kafka-compression with error: %+v", err)
+			os.Exit(1)
+		}
+		acks, err := kafka.ParseRequiredAcks(kafkaAcks)
+		if err != nil {
+			glog.Errorf("invalid kafka-acks with error: %+v", err)
+			os.Exit(1)
+		}
+		publisher, err = kafka.NewKafkaPublisher(kafkaSrv, kafka.WithKeyFunc(keyFunc), kafka.WithCompression(codec),
+			kafka.WithRequiredAcks(acks))
 		if err != nil {
 			glog.Errorf("failed to initialize Kafka publisher with error: %+v", err)
 			os.Exit(1)
@@ -98,13 +224,106 @@ func main() {
 		glog.Errorf("failed to parse to bool the value of the intercept flag with error: %+v", err)
 		os.Exit(1)
 	}
-	bmpSrv, err := gobmpsrv.NewBMPServer(srcPort, dstPort, interceptFlag, publisher, splitAFFlag)
+	opts := []gobmpsrv.Option{
+		gobmpsrv.WithBindAddress(srcAddr),
+		gobmpsrv.WithDestinationAddress(dstAddr),
+		gobmpsrv.WithMaxConnections(maxConns),
+		gobmpsrv.WithMetrics(m),
+		gobmpsrv.WithReadTimeout(readTO, false),
+		gobmpsrv.WithPublishTimeout(publishTO),
+		gobmpsrv.WithResync(resync),
+	}
+	if kaIdle > 0 {
+		opts = append(opts, gobmpsrv.WithTCPKeepAlive(kaIdle, kaIntvl, kaCount))
+	}
+	if tlsCert != "" || tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if err != nil {
+			glog.Errorf("failed to load TLS certificate with error: %+v", err)
+			os.Exit(1)
+		}
+		opts = append(opts, gobmpsrv.WithTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	}
+	if addPath != "" {
+		nlriTypes, err := parseAFISAFI(addPath)
+		if err != nil {
+			glog.Errorf("failed to parse add-path flag with error: %+v", err)
+			os.Exit(1)
+		}
+		opts = append(opts, gobmpsrv.WithAddPath(nlriTypes...))
+	}
+	if msgTypes != "" {
+		types, err := parseMessageTypes(msgTypes)
+		if err != nil {
+			glog.Errorf("failed to parse bmp-message-types flag with error: %+v", err)
+			os.Exit(1)
+		}
+		opts = append(opts, gobmpsrv.WithMessageTypes(types...))
+	}
+	if splitAFs != "" {
+		nlriTypes, err := parseAFISAFI(splitAFs)
+		if err != nil {
+			glog.Errorf("failed to parse split-afi-safi flag with error: %+v", err)
+			os.Exit(1)
+		}
+		opts = append(opts, gobmpsrv.WithSplitAF(nlriTypes...))
+	}
+	if rawMsg {
+		opts = append(opts, gobmpsrv.WithRawMessage())
+	}
+	if msgID {
+		opts = append(opts, gobmpsrv.WithMessageID())
+	}
+	if attrFlags {
+		opts = append(opts, gobmpsrv.WithAttributeFlags())
+	}
+	if unkAttrs {
+		opts = append(opts, gobmpsrv.WithUnknownAttributes())
+	}
+	if endOfRIB {
+		opts = append(opts, gobmpsrv.WithEndOfRIB())
+	}
+	if peerEvent {
+		opts = append(opts, gobmpsrv.WithPeerEvents())
+	}
+	if parseErrs {
+		opts = append(opts, gobmpsrv.WithParseErrors())
+	}
+	if parseConc > 0 {
+		opts = append(opts, gobmpsrv.WithParseConcurrency(parseConc))
+	}
+	if queueCap > 0 {
+		policy, err := parseDropPolicy(queueDrop)
+		if err != nil {
+			glog.Errorf("failed to parse producer-drop-policy flag with error: %+v", err)
+			os.Exit(1)
+		}
+		opts = append(opts, gobmpsrv.WithProducerQueue(queueCap, policy))
+	}
+	if tapDir != "" {
+		opts = append(opts, gobmpsrv.WithTap(tapDir, tapSize, tapMatch(tapAddrs)))
+	}
+	if passive != "" {
+		opts = append(opts, gobmpsrv.WithPassiveRouters(strings.Split(passive, ",")...))
+	}
+	bmpSrv, err := gobmpsrv.NewBMPServer(srcPort, dstPort, interceptFlag, publisher, splitAFFlag, opts...)
 	if err != nil {
 		glog.Errorf("failed to setup new gobmp server with error: %+v", err)
 		os.Exit(1)
 	}
 	// Starting Interceptor server
 	bmpSrv.Start()
+	// Exposing the server's health on the performance port
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		h := bmpSrv.Health()
+		if h.State != gobmpsrv.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprintf(w, "%s, sessions: %d, established sessions: %d\n", h.State, h.Sessions, h.EstablishedSessions)
+		if h.LastPublishError != nil {
+			fmt.Fprintf(w, "last publish error at %s: %v\n", h.LastPublishErrorTime.Format(time.RFC3339), h.LastPublishError)
+		}
+	})
 
 	stopCh := tools.SetupSignalHandler()
 	<-stopCh
@@ -112,3 +331,73 @@ func main() {
 	bmpSrv.Stop()
 	os.Exit(0)
 }
+
+// parseAFISAFI converts comma separated list of afi/safi into the list of NLRI message types
+func parseAFISAFI(s string) ([]int, error) {
+	nlriTypes := make([]int, 0)
+	for _, afiSAFI := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(afiSAFI), "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid afi/safi %q", afiSAFI)
+		}
+		afi, err := strconv.ParseUint(parts[0], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid afi in %q", afiSAFI)
+		}
+		safi, err := strconv.ParseUint(parts[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid safi in %q", afiSAFI)
+		}
+		t := bgp.NLRIMessageType(uint16(afi), uint8(safi))
+		if t == 0 {
+			return nil, fmt.Errorf("unsupported afi/safi %q", afiSAFI)
+		}
+		nlriTypes = append(nlriTypes, t)
+	}
+
+	return nlriTypes, nil
+}
+
+func parseMessageTypes(s string) ([]byte, error) {
+	types := make([]byte, 0)
+	for _, t := range strings.Split(s, ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(t), 10, 8)
+		if err != nil || v > bmp.RouteMirrorMsg {
+			return nil, fmt.Errorf("invalid bmp message type %q", t)
+		}
+		types = append(types, byte(v))
+	}
+
+	return types, nil
+}
+
+func parseDropPolicy(s string) (message.DropPolicy, error) {
+	for _, policy := range []message.DropPolicy{message.QueueBlock, message.QueueDropOldest, message.QueueDropNewest} {
+		if s == policy.String() {
+			return policy, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid drop policy %q, supported values are \"block\", \"drop-oldest\" and \"drop-newest\"", s)
+}
+
+// tapMatch returns a function matching addresses of the comma separated list, nil is returned for
+// an empty list to match all addresses
+func tapMatch(s string) func(remoteAddr string) bool {
+	if s == "" {
+		return nil
+	}
+	addrs := make(map[string]bool)
+	for _, addr := range strings.Split(s, ",") {
+		addr = strings.Trim(strings.TrimSpace(addr), "[]")
+		// Addresses of clients are matched in their canonical form
+		if ip := net.ParseIP(addr); ip != nil {
+			addr = ip.String()
+		}
+		addrs[addr] = true
+	}
+
+	return func(remoteAddr string) bool {
+		return addrs[remoteAddr]
+	}
+}
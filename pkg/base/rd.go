@@ -9,6 +9,15 @@ import (
 	"github.com/sbezverk/tools"
 )
 
+const (
+	// RDType0 defines Route Distinguisher with 2 bytes ASN administrator and 4 bytes assigned number, rfc4364
+	RDType0 = 0
+	// RDType1 defines Route Distinguisher with IPv4 address administrator and 2 bytes assigned number, rfc4364
+	RDType1 = 1
+	// RDType2 defines Route Distinguisher with 4 bytes ASN administrator and 2 bytes assigned number, rfc4364
+	RDType2 = 2
+)
+
 // RD defines a structure of VPN prefixe's Route Distinguisher
 type RD struct {
 	Type  uint16
@@ -23,7 +32,7 @@ func MakeRD(b []byte) (*RD, error) {
 		return nil, fmt.Errorf("invalid length expected 8 got %d", len(b))
 	}
 	rd.Type = binary.BigEndian.Uint16(b[0:2])
-	if rd.Type > 2 {
+	if rd.Type > RDType2 {
 		glog.Errorf("MakeRD: invalid rd type detected in %s", tools.MessageHex(b))
 		return nil, fmt.Errorf("invalid rd type %d", rd.Type)
 	}
@@ -33,15 +42,18 @@ func MakeRD(b []byte) (*RD, error) {
 	return &rd, nil
 }
 
-// GetRD returns a string representation of RD (one of three types)
+// String returns a string representation of RD, asn:number for types 0 and 2, ip:number for type 1
 func (rd *RD) String() string {
 	var s string
+	if len(rd.Value) != 6 {
+		return s
+	}
 	switch rd.Type {
-	case 0:
+	case RDType0:
 		s += fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(rd.Value[0:2]), binary.BigEndian.Uint32(rd.Value[2:]))
-	case 1:
+	case RDType1:
 		s += fmt.Sprintf("%s:%d", net.IP(rd.Value[0:4]).To4().String(), binary.BigEndian.Uint16(rd.Value[4:]))
-	case 2:
+	case RDType2:
 		s += fmt.Sprintf("%d:%d", binary.BigEndian.Uint32(rd.Value[0:4]), binary.BigEndian.Uint16(rd.Value[4:]))
 	}
 
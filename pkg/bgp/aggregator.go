@@ -0,0 +1,46 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Aggregator defines the value of AGGREGATOR and AS4_AGGREGATOR attributes,
+// rfc4271 and rfc6793
+type Aggregator struct {
+	AS       uint32 `json:"as"`
+	RouterID net.IP `json:"router_id"`
+}
+
+// UnmarshalAggregator builds Aggregator object from AGGREGATOR or AS4_AGGREGATOR attribute,
+// both legacy 2 bytes AS form (6 bytes) and 4 bytes AS form (8 bytes) are accepted.
+func UnmarshalAggregator(b []byte) (*Aggregator, error) {
+	agg := &Aggregator{}
+	switch len(b) {
+	case 6:
+		agg.AS = uint32(binary.BigEndian.Uint16(b[:2]))
+	case 8:
+		agg.AS = binary.BigEndian.Uint32(b[:4])
+	default:
+		return nil, fmt.Errorf("invalid length of Aggregator attribute %d, expected 6 or 8", len(b))
+	}
+	agg.RouterID = make(net.IP, 4)
+	copy(agg.RouterID, b[len(b)-4:])
+
+	return agg, nil
+}
+
+// UnmarshalJSON keeps Router ID in 4 bytes form, the form UnmarshalAggregator builds it in
+func (a *Aggregator) UnmarshalJSON(b []byte) error {
+	type aggregator Aggregator
+	if err := json.Unmarshal(b, (*aggregator)(a)); err != nil {
+		return err
+	}
+	if ip := a.RouterID.To4(); ip != nil {
+		a.RouterID = ip
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package bgp
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalAggregator(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect *Aggregator
+	}{
+		{
+			name:   "2 bytes as",
+			input:  []byte{0xfd, 0xe8, 0x0a, 0x00, 0x00, 0x01},
+			expect: &Aggregator{AS: 65000, RouterID: net.IP{10, 0, 0, 1}},
+		},
+		{
+			name:   "4 bytes as",
+			input:  []byte{0x00, 0x03, 0x0d, 0x40, 0xc0, 0xa8, 0x00, 0x01},
+			expect: &Aggregator{AS: 200000, RouterID: net.IP{192, 168, 0, 1}},
+		},
+		{
+			name:  "invalid length",
+			input: []byte{0x00, 0x03, 0x0d, 0x40, 0xc0, 0xa8, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalAggregator(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected aggregator %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBaseAttributesAggregator(t *testing.T) {
+	// ORIGIN, AGGREGATOR with AS_TRANS and AS4_AGGREGATOR
+	input := []byte{
+		0x40, 0x01, 0x01, 0x00,
+		0xc0, 0x07, 0x06, 0x5b, 0xa0, 0x0a, 0x00, 0x00, 0x01,
+		0xc0, 0x12, 0x08, 0x00, 0x03, 0x0d, 0x40, 0x0a, 0x00, 0x00, 0x01,
+	}
+	got, err := UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if expect := (&Aggregator{AS: 23456, RouterID: net.IP{10, 0, 0, 1}}); !reflect.DeepEqual(got.Aggregator, expect) {
+		t.Fatalf("expected aggregator %+v, got %+v", expect, got.Aggregator)
+	}
+	if expect := (&Aggregator{AS: 200000, RouterID: net.IP{10, 0, 0, 1}}); !reflect.DeepEqual(got.AS4Aggregator, expect) {
+		t.Fatalf("expected as4 aggregator %+v, got %+v", expect, got.AS4Aggregator)
+	}
+}
+
+func TestAggregatorJSON(t *testing.T) {
+	agg := &Aggregator{AS: 200000, RouterID: net.IP{192, 168, 0, 1}}
+	b, err := json.Marshal(agg)
+	if err != nil {
+		t.Fatalf("failed to marshal aggregator with error: %+v", err)
+	}
+	if string(b) != `{"as":200000,"router_id":"192.168.0.1"}` {
+		t.Fatalf("unexpected json %s", b)
+	}
+	got := &Aggregator{}
+	if err := json.Unmarshal(b, got); err != nil {
+		t.Fatalf("failed to unmarshal aggregator with error: %+v", err)
+	}
+	if !reflect.DeepEqual(got, agg) {
+		t.Fatalf("expected aggregator %+v, got %+v", agg, got)
+	}
+}
@@ -0,0 +1,38 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// AIGPMetricTLV defines AIGP TLV carrying Accumulated IGP Metric, rfc7311
+	AIGPMetricTLV = 1
+)
+
+// UnmarshalAIGPMetric returns the value of Accumulated IGP Metric from AIGP attribute TLVs,
+// TLVs of unknown types are ignored. If the attribute does not carry AIGP Metric TLV, 0 is returned.
+func UnmarshalAIGPMetric(b []byte) (uint64, error) {
+	var metric uint64
+	for p := 0; p < len(b); {
+		// Type 1 byte and Length 2 bytes, Length includes Type and Length fields
+		if p+3 > len(b) {
+			return 0, fmt.Errorf("not enough bytes to unmarshal AIGP tlv")
+		}
+		t := b[p]
+		l := int(binary.BigEndian.Uint16(b[p+1 : p+3]))
+		if l < 3 || p+l > len(b) {
+			return 0, fmt.Errorf("invalid AIGP tlv length %d", l)
+		}
+		switch t {
+		case AIGPMetricTLV:
+			if l != 11 {
+				return 0, fmt.Errorf("invalid AIGP Metric tlv length %d, expected 11", l)
+			}
+			metric = binary.BigEndian.Uint64(b[p+3 : p+l])
+		}
+		p += l
+	}
+
+	return metric, nil
+}
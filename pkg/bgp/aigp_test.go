@@ -0,0 +1,61 @@
+package bgp
+
+import (
+	"testing"
+)
+
+func TestUnmarshalAIGPMetric(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect uint64
+	}{
+		{
+			name:   "single aigp tlv",
+			input:  []byte{0x01, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xe8},
+			expect: 1000,
+		},
+		{
+			name:   "unknown tlv is ignored",
+			input:  []byte{0x02, 0x00, 0x05, 0xaa, 0xbb, 0x01, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00},
+			expect: 1 << 32,
+		},
+		{
+			name:  "invalid aigp tlv length",
+			input: []byte{0x01, 0x00, 0x07, 0x00, 0x00, 0x03, 0xe8},
+			fail:  true,
+		},
+		{
+			name:  "truncated tlv",
+			input: []byte{0x01, 0x00, 0x0b, 0x00, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalAIGPMetric(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if got != tt.expect {
+				t.Fatalf("expected AIGP metric %d, got %d", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBaseAttributesAIGP(t *testing.T) {
+	// ORIGIN and optional non-transitive AIGP attribute with a single AIGP Metric TLV
+	input := []byte{0x40, 0x01, 0x01, 0x00, 0x80, 0x1a, 0x0b, 0x01, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64}
+	got, err := UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if got.AIGP != 100 {
+		t.Fatalf("expected AIGP metric 100, got %d", got.AIGP)
+	}
+}
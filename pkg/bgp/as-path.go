@@ -0,0 +1,138 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/golang/glog"
+)
+
+// AS_PATH segment types [RFC4271], [RFC5065]
+const (
+	ASSet            = 1
+	ASSequence       = 2
+	ASConfedSequence = 3
+	ASConfedSet      = 4
+)
+
+var asPathSegmentTypes = map[uint8]string{
+	ASSet:            "as_set",
+	ASSequence:       "as_sequence",
+	ASConfedSequence: "as_confed_sequence",
+	ASConfedSet:      "as_confed_set",
+}
+
+// ASPathSegment defines a segment of AS_PATH attribute
+type ASPathSegment struct {
+	Type string   `json:"type"`
+	ASNs []uint32 `json:"asns"`
+}
+
+// IsConfed returns true for AS_CONFED_SEQUENCE and AS_CONFED_SET segments
+func (s ASPathSegment) IsConfed() bool {
+	return s.Type == asPathSegmentTypes[ASConfedSequence] || s.Type == asPathSegmentTypes[ASConfedSet]
+}
+
+// UnmarshalASPathSegments decodes segments of AS_PATH attribute carrying ASNs of asnLength bytes, 2 or 4
+func UnmarshalASPathSegments(b []byte, asnLength int) ([]ASPathSegment, error) {
+	segments := make([]ASPathSegment, 0)
+	for p := 0; p < len(b); {
+		if p+2 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal AS_PATH segment")
+		}
+		t, ok := asPathSegmentTypes[b[p]]
+		if !ok {
+			return nil, fmt.Errorf("invalid AS_PATH segment type %d", b[p])
+		}
+		l := int(b[p+1])
+		p += 2
+		if l == 0 {
+			return nil, fmt.Errorf("empty AS_PATH segment")
+		}
+		if p+l*asnLength > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal AS_PATH segment of %d ASNs of %d bytes", l, asnLength)
+		}
+		segment := ASPathSegment{Type: t, ASNs: make([]uint32, 0, l)}
+		for n := 0; n < l; n++ {
+			if asnLength == 4 {
+				segment.ASNs = append(segment.ASNs, binary.BigEndian.Uint32(b[p:p+4]))
+			} else {
+				segment.ASNs = append(segment.ASNs, uint32(binary.BigEndian.Uint16(b[p:p+2])))
+			}
+			p += asnLength
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// unmarshalAttrASPathSegments returns segments of AS_PATH attribute carrying ASNs of asnLength bytes,
+// malformed attribute is skipped.
+func unmarshalAttrASPathSegments(b []byte, asnLength int) []ASPathSegment {
+	if len(b) == 0 {
+		return nil
+	}
+	segments, err := UnmarshalASPathSegments(b, asnLength)
+	if err != nil {
+		glog.Warningf("skipping AS_PATH attribute: %+v", err)
+		return nil
+	}
+
+	return segments
+}
+
+// ASPathASNs returns ASNs of all segments in the order they appear in the path
+func ASPathASNs(segments []ASPathSegment) []uint32 {
+	if len(segments) == 0 {
+		return nil
+	}
+	asns := make([]uint32, 0)
+	for _, s := range segments {
+		asns = append(asns, s.ASNs...)
+	}
+
+	return asns
+}
+
+// ASPathLength returns the length of the path used in route selection, AS_SET counts as 1 and
+// confederation segments are not counted [RFC4271 9.1.2.2], [RFC5065 5.3].
+func ASPathLength(segments []ASPathSegment) int32 {
+	var length int32
+	for _, s := range segments {
+		switch {
+		case s.IsConfed():
+		case s.Type == asPathSegmentTypes[ASSet]:
+			length++
+		default:
+			length += int32(len(s.ASNs))
+		}
+	}
+
+	return length
+}
+
+// ASPathOriginAS returns the origin AS, the last ASN of the last segment which is not a confederation
+// segment, 0 is returned when there is no such segment.
+func ASPathOriginAS(segments []ASPathSegment) uint32 {
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i].IsConfed() || len(segments[i].ASNs) == 0 {
+			continue
+		}
+		return segments[i].ASNs[len(segments[i].ASNs)-1]
+	}
+
+	return 0
+}
+
+// OriginAS returns the origin AS of AS_PATH attribute, 0 is returned when it is unknown
+func (ba *BaseAttributes) OriginAS() uint32 {
+	if len(ba.ASPathSegments) != 0 {
+		return ASPathOriginAS(ba.ASPathSegments)
+	}
+	if len(ba.ASPath) != 0 {
+		return ba.ASPath[len(ba.ASPath)-1]
+	}
+
+	return 0
+}
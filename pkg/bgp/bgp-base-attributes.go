@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net"
 	"strconv"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/tools"
@@ -16,60 +17,67 @@ import (
 // codes for each can be found:
 // https://www.iana.org/assignments/bgp-parameters/bgp-parameters.xhtml#bgp-parameters-2
 type BaseAttributes struct {
-	BaseAttrHash     string   `json:"base_attr_hash,omitempty"`
-	Origin           string   `json:"origin,omitempty"`
-	ASPath           []uint32 `json:"as_path,omitempty"`
-	ASPathCount      int32    `json:"as_path_count,omitempty"`
-	Nexthop          string   `json:"nexthop,omitempty"`
-	MED              uint32   `json:"med,omitempty"`
-	LocalPref        uint32   `json:"local_pref,omitempty"`
-	IsAtomicAgg      bool     `json:"is_atomic_agg"`
-	Aggregator       []byte   `json:"aggregator,omitempty"`
-	CommunityList    []string `json:"community_list,omitempty"`
-	OriginatorID     string   `json:"originator_id,omitempty"`
-	ClusterList      string   `json:"cluster_list,omitempty"`
-	ExtCommunityList []string `json:"ext_community_list,omitempty"`
-	AS4Path          []uint32 `json:"as4_path,omitempty"`
-	AS4PathCount     int32    `json:"as4_path_count,omitempty"`
-	AS4Aggregator    []byte   `json:"as4_aggregator,omitempty"`
+	BaseAttrHash     string              `json:"base_attr_hash,omitempty"`
+	Origin           string              `json:"origin,omitempty"`
+	ASPath           []uint32            `json:"as_path,omitempty"`
+	ASPathCount      int32               `json:"as_path_count,omitempty"`
+	ASPathSegments   []ASPathSegment     `json:"as_path_segments,omitempty"`
+	Nexthop          string              `json:"nexthop,omitempty"`
+	MED              *uint32             `json:"med,omitempty"`
+	LocalPref        *uint32             `json:"local_pref,omitempty"`
+	IsAtomicAgg      bool                `json:"is_atomic_agg"`
+	Aggregator       *Aggregator         `json:"aggregator,omitempty"`
+	CommunityList    []string            `json:"community_list,omitempty"`
+	OriginatorID     string              `json:"originator_id,omitempty"`
+	ClusterList      string              `json:"cluster_list,omitempty"`
+	ClusterIDs       []string            `json:"cluster_ids,omitempty"`
+	ExtCommunityList []string            `json:"ext_community_list,omitempty"`
+	ExtCommunities   []TypedExtCommunity `json:"ext_communities,omitempty"`
+	AS4Path          []uint32            `json:"as4_path,omitempty"`
+	AS4PathCount     int32               `json:"as4_path_count,omitempty"`
+	AS4Aggregator    *Aggregator         `json:"as4_aggregator,omitempty"`
 	// PMSITunnel
-	TunnelEncapAttr []byte `json:"-"`
+	PMSITunnel *PMSITunnel `json:"pmsi_tunnel,omitempty"`
+	// TunnelEncap
+	TunnelEncapAttr []byte    `json:"-"`
+	TunnelEncap     []*Tunnel `json:"tunnel_encap,omitempty"`
 	// TraficEng
-	// IPv6SpecExtCommunity
-	// AIGP
+	IPv6ExtCommunities []IPv6ExtCommunity `json:"ipv6_ext_communities,omitempty"`
+	AIGP               uint64             `json:"aigp,omitempty"`
 	// PEDistinguisherLable
-	LgCommunityList []string `json:"large_community_list,omitempty"`
+	LgCommunityList []string      `json:"large_community_list,omitempty"`
+	LgCommunities   []LgCommunity `json:"large_communities,omitempty"`
 	// SecPath
 	// AttrSet
+
+	// AttrFlags carries Attribute Flags of all path attributes of the update by attribute type code,
+	// it is set only when requested and it is not covered by BaseAttrHash.
+	AttrFlags map[uint8]PathAttributeFlags `json:"attr_flags,omitempty"`
+	// UnknownAttrs carries path attributes of the update not decoded by gobmp, it is set only when
+	// requested and it is not covered by BaseAttrHash.
+	UnknownAttrs []UnknownAttribute `json:"unknown_attrs,omitempty"`
 }
 
 // UnmarshalBGPBaseAttributes discovers all present Base Attributes in BGP Update
-// and instantiates BaseAttributes object
-func UnmarshalBGPBaseAttributes(b []byte) (*BaseAttributes, error) {
+// and instantiates BaseAttributes object, AS_PATH attribute carries ASNs of asnLength bytes, 2 or 4
+func UnmarshalBGPBaseAttributes(b []byte, asnLength int) (*BaseAttributes, error) {
 	if glog.V(6) {
 		glog.Infof("UnmarshalBGPBaseAttributes RAW: %+v", tools.MessageHex(b))
 	}
 	baseAttr := BaseAttributes{}
 	for p := 0; p < len(b); {
-		flag := b[p]
-		p++
-		t := b[p]
-		p++
-		var l uint16
-		// Checking for Extened
-		if flag&0x10 == 0x10 {
-			l = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-		} else {
-			l = uint16(b[p])
-			p++
+		_, t, l, v, err := unmarshalAttributeHeader(b, p)
+		if err != nil {
+			return nil, err
 		}
+		p = v
 		switch t {
 		case 1:
 			baseAttr.Origin = unmarshalAttrOrigin(b[p : p+int(l)])
 		case 2:
-			baseAttr.ASPath = unmarshalAttrASPath(b[p : p+int(l)])
-			baseAttr.ASPathCount = int32(len(baseAttr.ASPath))
+			baseAttr.ASPathSegments = unmarshalAttrASPathSegments(b[p:p+int(l)], asnLength)
+			baseAttr.ASPath = ASPathASNs(baseAttr.ASPathSegments)
+			baseAttr.ASPathCount = ASPathLength(baseAttr.ASPathSegments)
 		case 3:
 			baseAttr.Nexthop = unmarshalAttrNextHop(b[p : p+int(l)])
 		case 4:
@@ -85,26 +93,33 @@ func UnmarshalBGPBaseAttributes(b []byte) (*BaseAttributes, error) {
 		case 9:
 			baseAttr.OriginatorID = unmarshalAttrOriginatorID(b[p : p+int(l)])
 		case 10:
-			baseAttr.ClusterList = unmarshalAttrClusterList(b[p : p+int(l)])
+			baseAttr.ClusterIDs = unmarshalAttrClusterList(b[p : p+int(l)])
+			baseAttr.ClusterList = strings.Join(baseAttr.ClusterIDs, ", ")
 		case 16:
 			baseAttr.ExtCommunityList = unmarshalAttrExtCommunity(b[p : p+int(l)])
+			baseAttr.ExtCommunities = unmarshalAttrTypedExtCommunities(b[p : p+int(l)])
 		case 17:
 			baseAttr.AS4Path = unmarshalAttrAS4Path(b[p : p+int(l)])
 			baseAttr.AS4PathCount = int32(len(baseAttr.AS4Path))
 		case 18:
 			baseAttr.AS4Aggregator = unmarshalAttrAS4Aggregator(b[p : p+int(l)])
 		case 22:
+			baseAttr.PMSITunnel = unmarshalAttrPMSITunnel(b[p : p+int(l)])
 		case 23:
 			baseAttr.TunnelEncapAttr = make([]byte, l)
 			copy(baseAttr.TunnelEncapAttr, b[p:p+int(l)])
+			baseAttr.TunnelEncap = unmarshalAttrTunnelEncap(b[p : p+int(l)])
 		case 24:
 		case 25:
+			baseAttr.IPv6ExtCommunities = unmarshalAttrIPv6ExtCommunities(b[p : p+int(l)])
 		case 26:
+			baseAttr.AIGP = unmarshalAttrAIGP(b[p : p+int(l)])
 		case 27:
 		case 28:
 		case 29:
 		case 32:
-			baseAttr.LgCommunityList = unmarshalAttrLgCommunity(b[p : p+int(l)])
+			baseAttr.LgCommunities = unmarshalAttrLgCommunities(b[p : p+int(l)])
+			baseAttr.LgCommunityList = lgCommunityList(baseAttr.LgCommunities)
 		case 33:
 		case 128:
 		}
@@ -121,8 +136,11 @@ func UnmarshalBGPBaseAttributes(b []byte) (*BaseAttributes, error) {
 	return &baseAttr, nil
 }
 
-// unmarshalAttrOrigin returns the value of Origin attribute
+// unmarshalAttrOrigin returns the value of Origin attribute, malformed attribute is skipped
 func unmarshalAttrOrigin(b []byte) string {
+	if len(b) != 1 {
+		return ""
+	}
 	switch b[0] {
 	case 0:
 		return "igp"
@@ -135,71 +153,6 @@ func unmarshalAttrOrigin(b []byte) string {
 	}
 }
 
-// unmarshalAttrASPath returns a slice with a list of ASes
-func unmarshalAttrASPath(b []byte) []uint32 {
-	if len(b) == 0 {
-		return nil
-	}
-	path := make([]uint32, 0)
-	as4 := isASPath4(b)
-	for p := 0; p < len(b); {
-		// Skipping type
-		p++
-		// Length of path segment of type
-		l := b[p]
-		p++
-		// Attempting to detect if 2 or 4 bytes AS is used
-		for n := 0; n < int(l); n++ {
-			if as4 {
-				as := binary.BigEndian.Uint32(b[p : p+4])
-				p += 4
-				path = append(path, as)
-			} else {
-				as := binary.BigEndian.Uint16(b[p : p+2])
-				p += 2
-				path = append(path, uint32(as))
-			}
-		}
-	}
-
-	return path
-}
-
-func isASPath4(b []byte) bool {
-	p := 0
-	// Skipping type
-	p++
-	// Length of path segment in 2 or 4 bytes depending if AS2 or AS4 is used.
-	l := int(b[p])
-	p++
-	// Check if next segment can be found with AS4
-	if l*4 == len(b[p:]) {
-		// Found last AS4 segment, confirmed AS4
-		return true
-	}
-	// Check if next segment can be found with AS4
-	if l*2 == len(b[p:]) {
-		// Found last AS2 segment, confirmed AS2
-		return false
-	}
-	// Check if next segment can be found with AS4
-	if p+l*4 < len(b) {
-		if b[p+l*4] == 0x1 || b[p+l*4] == 0x2 {
-			// Found next AS4 segment, confirmed AS4
-			return true
-		}
-	}
-	// Check if next segment can be found with AS2
-	if p+l*2 < len(b) {
-		if b[p+l*2] == 0x1 || b[p+l*2] == 0x2 {
-			// Found next AS2 segment, confirmed AS2
-			return false
-		}
-	}
-	// Should never reach here
-	return false
-}
-
 // unmarshalAttrNextHop returns the value of Next Hop attribute
 func unmarshalAttrNextHop(b []byte) string {
 	if len(b) == 4 {
@@ -208,37 +161,48 @@ func unmarshalAttrNextHop(b []byte) string {
 	return net.IP(b).To16().String()
 }
 
-// unmarshalAttrMED returns the value of MED attribute
-func unmarshalAttrMED(b []byte) uint32 {
+// unmarshalAttrMED returns the value of MED attribute, nil is returned for malformed attribute
+// so MED of 0 is distinguished from missing MED.
+func unmarshalAttrMED(b []byte) *uint32 {
 	if len(b) != 4 {
-		return 0
+		return nil
 	}
-	return binary.BigEndian.Uint32(b)
+	med := binary.BigEndian.Uint32(b)
+
+	return &med
 }
 
-// unmarshalAttrLocalPref returns the value of LOCAL_PREF attribute
-func unmarshalAttrLocalPref(b []byte) uint32 {
+// unmarshalAttrLocalPref returns the value of LOCAL_PREF attribute, nil is returned for malformed attribute
+// so LOCAL_PREF of 0 is distinguished from missing LOCAL_PREF.
+func unmarshalAttrLocalPref(b []byte) *uint32 {
 	if len(b) != 4 {
-		return 0
+		return nil
 	}
-	return binary.BigEndian.Uint32(b)
+	lp := binary.BigEndian.Uint32(b)
+
+	return &lp
 }
 
-// unmarshalAttrAggregator returns the value of AGGREGATOR attribute
-func unmarshalAttrAggregator(b []byte) []byte {
-	agg := make([]byte, len(b))
-	copy(agg, b)
+// unmarshalAttrAggregator returns the value of AGGREGATOR attribute, malformed attribute is skipped
+func unmarshalAttrAggregator(b []byte) *Aggregator {
+	agg, err := UnmarshalAggregator(b)
+	if err != nil {
+		glog.Warningf("skipping Aggregator attribute: %+v", err)
+		return nil
+	}
 
 	return agg
 }
 
-// getCommunity returns a slice of communities
+// getCommunity returns a slice of communities, malformed attribute is skipped
 func getCommunity(b []byte) []uint32 {
-	comm := make([]uint32, 0)
-	for p := 0; p < len(b); {
-		c := binary.BigEndian.Uint32(b[p : p+4])
-		p += 4
-		comm = append(comm, c)
+	if len(b)%4 != 0 {
+		glog.Warningf("skipping COMMUNITIES attribute: invalid length %d, expected a multiple of 4", len(b))
+		return nil
+	}
+	comm := make([]uint32, 0, len(b)/4)
+	for p := 0; p < len(b); p += 4 {
+		comm = append(comm, binary.BigEndian.Uint32(b[p:p+4]))
 	}
 
 	return comm
@@ -247,6 +211,9 @@ func getCommunity(b []byte) []uint32 {
 // unmarshalAttrCommunity returns the string with comma separated communities.
 func unmarshalAttrCommunity(b []byte) []string {
 	cs := getCommunity(b)
+	if cs == nil {
+		return nil
+	}
 	s := make([]string, len(cs))
 	for i, c := range cs {
 		s[i] += strconv.Itoa(int((0xffff0000&c)>>16)) + ":" + strconv.Itoa(int(0xffff&c))
@@ -255,42 +222,28 @@ func unmarshalAttrCommunity(b []byte) []string {
 	return s
 }
 
-// unmarshalAttrOriginatorID returns the value of ORIGINATOR_ID attribute
+// unmarshalAttrOriginatorID returns the value of ORIGINATOR_ID attribute, malformed attribute is skipped
 func unmarshalAttrOriginatorID(b []byte) string {
-	if len(b) == 4 {
-		return net.IP(b).To4().String()
+	if len(b) != 4 {
+		glog.Warningf("skipping ORIGINATOR_ID attribute: invalid length %d, expected 4", len(b))
+		return ""
 	}
 
-	return "invalid length"
+	return net.IP(b).To4().String()
 }
 
-// getClusterID returns a slice of Cluster IDs from Cluster List attribute
-func getClusterID(b []byte) [][]byte {
-	cl := make([][]byte, 0)
-	i := 0
-	for p := 0; p < len(b); {
-		c := make([]byte, 4)
-		copy(c, b[p:p+4])
-		p += 4
-		i++
-		cl = append(cl, c)
+// unmarshalAttrClusterList returns Cluster IDs of CLUSTER_LIST attribute, malformed attribute is skipped
+func unmarshalAttrClusterList(b []byte) []string {
+	if len(b)%4 != 0 {
+		glog.Warningf("skipping CLUSTER_LIST attribute: invalid length %d, expected a multiple of 4", len(b))
+		return nil
 	}
-
-	return cl
-}
-
-// unmarshalAttrClusterList returns the string with comma separated communities.
-func unmarshalAttrClusterList(b []byte) string {
-	var clist string
-	cl := getClusterID(b)
-	for i, c := range cl {
-		clist += net.IP(c).To4().String()
-		if i < len(cl)-1 {
-			clist += ", "
-		}
+	cl := make([]string, 0, len(b)/4)
+	for p := 0; p < len(b); p += 4 {
+		cl = append(cl, net.IP(b[p:p+4]).To4().String())
 	}
 
-	return clist
+	return cl
 }
 
 //  unmarshalAttrExtCommunity returns a slice with all extended communities found in bgp update
@@ -307,10 +260,54 @@ func unmarshalAttrExtCommunity(b []byte) []string {
 	return s
 }
 
-// unmarshalAttrLgCommunity returns a slice with all large communities found in bgp update
-func unmarshalAttrLgCommunity(b []byte) []string {
+// unmarshalAttrTypedExtCommunities returns a slice with all extended communities found in bgp update
+// decoded by their type and sub-type, malformed attribute is skipped.
+func unmarshalAttrTypedExtCommunities(b []byte) []TypedExtCommunity {
+	if len(b) == 0 {
+		return nil
+	}
+	exts, err := UnmarshalTypedExtCommunities(b)
+	if err != nil {
+		glog.Warningf("skipping Extended Communities attribute: %+v", err)
+		return nil
+	}
+
+	return exts
+}
+
+// unmarshalAttrIPv6ExtCommunities returns a slice with all IPv6 address specific extended communities
+// found in bgp update, malformed attribute is skipped.
+func unmarshalAttrIPv6ExtCommunities(b []byte) []IPv6ExtCommunity {
+	if len(b) == 0 {
+		return nil
+	}
+	exts, err := UnmarshalBGPIPv6ExtCommunity(b)
+	if err != nil {
+		glog.Warningf("skipping IPv6 Address Specific Extended Communities attribute: %+v", err)
+		return nil
+	}
+
+	return exts
+}
+
+// unmarshalAttrLgCommunities returns a slice with all large communities found in bgp update,
+// malformed attribute is skipped.
+func unmarshalAttrLgCommunities(b []byte) []LgCommunity {
+	if len(b) == 0 {
+		return nil
+	}
 	lg, err := UnmarshalBGPLgCommunity(b)
 	if err != nil {
+		glog.Warningf("skipping Large Communities attribute: %+v", err)
+		return nil
+	}
+
+	return lg
+}
+
+// lgCommunityList returns a slice with string representation of large communities
+func lgCommunityList(lg []LgCommunity) []string {
+	if len(lg) == 0 {
 		return nil
 	}
 	s := make([]string, len(lg))
@@ -321,29 +318,58 @@ func unmarshalAttrLgCommunity(b []byte) []string {
 	return s
 }
 
-// unmarshalAttrAS4Path returns a sequence of AS4 path segments
+// unmarshalAttrPMSITunnel returns the value of PMSI Tunnel attribute, malformed attribute is skipped.
+func unmarshalAttrPMSITunnel(b []byte) *PMSITunnel {
+	pt, err := UnmarshalPMSITunnel(b)
+	if err != nil {
+		glog.Warningf("skipping PMSI Tunnel attribute: %+v", err)
+		return nil
+	}
+
+	return pt
+}
+
+// unmarshalAttrAIGP returns the value of AIGP Metric, malformed attribute is skipped.
+func unmarshalAttrAIGP(b []byte) uint64 {
+	metric, err := UnmarshalAIGPMetric(b)
+	if err != nil {
+		glog.Warningf("skipping AIGP attribute: %+v", err)
+		return 0
+	}
+
+	return metric
+}
+
+// unmarshalAttrAS4Path returns ASNs of all AS4_PATH segments, malformed attribute is skipped
 func unmarshalAttrAS4Path(b []byte) []uint32 {
-	path := make([]uint32, 0)
-	for p := 0; p < len(b); {
-		// Skipping type
-		p++
-		// Length of path segment in 4 bytes
-		l := b[p]
-		p++
-		for n := 0; n < int(l); n++ {
-			as := binary.BigEndian.Uint32(b[p : p+4])
-			p += 4
-			path = append(path, as)
-		}
+	segments, err := UnmarshalASPathSegments(b, 4)
+	if err != nil {
+		glog.Warningf("skipping AS4_PATH attribute: %+v", err)
+		return nil
+	}
+
+	return ASPathASNs(segments)
+}
+
+// unmarshalAttrTunnelEncap returns the list of tunnels of Tunnel Encapsulation attribute,
+// malformed attribute is skipped
+func unmarshalAttrTunnelEncap(b []byte) []*Tunnel {
+	tunnels, err := UnmarshalTunnelEncapsulation(b)
+	if err != nil {
+		glog.Warningf("skipping Tunnel Encapsulation attribute: %+v", err)
+		return nil
 	}
 
-	return path
+	return tunnels
 }
 
-// getAttrAS4Aggregator returns the value of AS4 AGGREGATOR attribute
-func unmarshalAttrAS4Aggregator(b []byte) []byte {
-	agg := make([]byte, len(b))
-	copy(agg, b)
+// unmarshalAttrAS4Aggregator returns the value of AS4 AGGREGATOR attribute, malformed attribute is skipped
+func unmarshalAttrAS4Aggregator(b []byte) *Aggregator {
+	if len(b) != 8 {
+		glog.Warningf("skipping AS4 Aggregator attribute: invalid length %d, expected 8", len(b))
+		return nil
+	}
+	agg, _ := UnmarshalAggregator(b)
 
 	return agg
 }
@@ -1,6 +1,7 @@
 package bgp
 
 import (
+	"net"
 	"reflect"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 )
 
 func TestUnmarshaBaseAttributes(t *testing.T) {
+	uint32Ptr := func(v uint32) *uint32 { return &v }
 	tests := []struct {
 		name   string
 		input  []byte
@@ -17,20 +19,134 @@ func TestUnmarshaBaseAttributes(t *testing.T) {
 			name:  "panic 1",
 			input: []byte{0x40, 0x01, 0x01, 0x00, 0x40, 0x02, 0x20, 0x02, 0x06, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x9a, 0x6d, 0x00, 0x00, 0x19, 0x35, 0x00, 0x00, 0x0a, 0x7f, 0x00, 0x00, 0x65, 0x20, 0x00, 0x00, 0x53, 0x4e, 0x01, 0x01, 0x00, 0x00, 0x12, 0xc9, 0x40, 0x03, 0x04, 0xc2, 0x1c, 0x62, 0x25, 0x80, 0x04, 0x04, 0x00, 0x00, 0x00, 0x00, 0xc0, 0x07, 0x08, 0x00, 0x00, 0x65, 0x20, 0xc0, 0x78, 0x51, 0x88, 0xc0, 0x08, 0x18, 0x00, 0x00, 0x9a, 0x6d, 0x19, 0x35, 0x00, 0x56, 0x19, 0x35, 0x0b, 0xb8, 0x19, 0x35, 0x0c, 0x1c, 0x19, 0x35, 0x0c, 0x1e, 0x9a, 0x6d, 0xc2, 0x02, 0xc0, 0x20, 0x30, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0xd3, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x64, 0x00, 0x00, 0x00, 0x31, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x7a, 0x00, 0x00, 0x00, 0x01},
 			expect: &BaseAttributes{
-				BaseAttrHash:    "adfbe0fd919abd7a940eb75ed99af1e5",
-				Origin:          "igp",
-				ASPath:          []uint32{34872, 39533, 6453, 2687, 25888, 21326, 4809},
-				ASPathCount:     7,
+				BaseAttrHash: "5155aa475e62f810df1d28aa76c85e92",
+				Origin:       "igp",
+				ASPath:       []uint32{34872, 39533, 6453, 2687, 25888, 21326, 4809},
+				ASPathCount:  7,
+				ASPathSegments: []ASPathSegment{
+					{Type: "as_sequence", ASNs: []uint32{34872, 39533, 6453, 2687, 25888, 21326}},
+					{Type: "as_set", ASNs: []uint32{4809}},
+				},
 				Nexthop:         "194.28.98.37",
-				Aggregator:      []byte{0, 0, 101, 32, 192, 120, 81, 136},
+				MED:             uint32Ptr(0),
+				Aggregator:      &Aggregator{AS: 25888, RouterID: net.IP{192, 120, 81, 136}},
 				CommunityList:   []string{"0:39533", "6453:86", "6453:3000", "6453:3100", "6453:3102", "39533:49666"},
 				LgCommunityList: []string{"34872:10:211", "34872:11:1", "34872:100:49", "34872:122:1"},
+				LgCommunities: []LgCommunity{
+					{GlobalAdmin: 34872, LocalData1: 10, LocalData2: 211},
+					{GlobalAdmin: 34872, LocalData1: 11, LocalData2: 1},
+					{GlobalAdmin: 34872, LocalData1: 100, LocalData2: 49},
+					{GlobalAdmin: 34872, LocalData1: 122, LocalData2: 1},
+				},
+			},
+		},
+		{
+			name: "origin, med, local pref and atomic aggregate",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x02, // ORIGIN INCOMPLETE
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0x80, 0x04, 0x04, 0x00, 0x00, 0x00, 0x64, // MULTI_EXIT_DISC 100
+				0x40, 0x05, 0x04, 0x00, 0x00, 0x00, 0x00, // LOCAL_PREF 0
+				0x40, 0x06, 0x00, // ATOMIC_AGGREGATE
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash: "a9d06209a2fddf9bdec57b3ea67ade28",
+				Origin:       "incomplete",
+				Nexthop:      "10.0.0.1",
+				MED:          uint32Ptr(100),
+				LocalPref:    uint32Ptr(0),
+				IsAtomicAgg:  true,
+			},
+		},
+		{
+			name: "originator id and cluster list of two cluster ids",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0x80, 0x09, 0x04, 0x0A, 0x00, 0x00, 0x03, // ORIGINATOR_ID 10.0.0.3
+				0x80, 0x0A, 0x08, 0x0A, 0x00, 0x00, 0x64, 0x0A, 0x00, 0x00, 0xC8, // CLUSTER_LIST 10.0.0.100 10.0.0.200
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash: "7f12a1faf4a757f542e93a4fd4b7af2a",
+				Origin:       "igp",
+				Nexthop:      "10.0.0.1",
+				OriginatorID: "10.0.0.3",
+				ClusterList:  "10.0.0.100, 10.0.0.200",
+				ClusterIDs:   []string{"10.0.0.100", "10.0.0.200"},
+			},
+		},
+		{
+			name: "cluster list of invalid length",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0x80, 0x0A, 0x06, 0x0A, 0x00, 0x00, 0x64, 0x0A, 0x00, // CLUSTER_LIST of 6 bytes
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash: "0d7460fb42108e2cbc439483236833a6",
+				Origin:       "igp",
+				Nexthop:      "10.0.0.1",
+			},
+		},
+		{
+			name: "communities and as4 path",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0xC0, 0x08, 0x08, 0xFD, 0xE8, 0x00, 0x64, 0xFD, 0xE8, 0x00, 0xC8, // COMMUNITIES 65000:100 65000:200
+				0xC0, 0x11, 0x06, 0x02, 0x01, 0x00, 0x01, 0x11, 0x70, // AS4_PATH 70000
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash:  "3b3489a79ba36a42f34b58d34e71a05d",
+				Origin:        "igp",
+				Nexthop:       "10.0.0.1",
+				CommunityList: []string{"65000:100", "65000:200"},
+				AS4Path:       []uint32{70000},
+				AS4PathCount:  1,
+			},
+		},
+		{
+			name: "communities of invalid length",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0xC0, 0x08, 0x03, 0xFD, 0xE8, 0x00, // COMMUNITIES of 3 bytes
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash: "0d7460fb42108e2cbc439483236833a6",
+				Origin:       "igp",
+				Nexthop:      "10.0.0.1",
+			},
+		},
+		{
+			name: "as4 path of invalid length",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0xC0, 0x11, 0x05, 0x02, 0x01, 0x00, 0x01, 0x11, // AS4_PATH segment of 1 ASN carrying 3 bytes
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash: "0d7460fb42108e2cbc439483236833a6",
+				Origin:       "igp",
+				Nexthop:      "10.0.0.1",
+			},
+		},
+		{
+			name: "without med, local pref and atomic aggregate",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+			},
+			expect: &BaseAttributes{
+				BaseAttrHash: "0d7460fb42108e2cbc439483236833a6",
+				Origin:       "igp",
+				Nexthop:      "10.0.0.1",
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := UnmarshalBGPBaseAttributes(tt.input)
+			got, err := UnmarshalBGPBaseAttributes(tt.input, 4)
 			if err != nil {
 				t.Fatalf("expected to succeed but failed with error: %+v", err)
 			}
@@ -46,60 +162,171 @@ func TestUnmarshaBaseAttributes(t *testing.T) {
 
 func TestUnmarshalASPath(t *testing.T) {
 	tests := []struct {
-		name   string
-		input  []byte
-		asPath []uint32
+		name      string
+		input     []byte
+		asnLength int
+		asPath    []uint32
 	}{
 		{
-			name:   "panic #1",
-			input:  []byte{0x02, 0x08, 0x00, 0x00, 0x24, 0x58, 0x00, 0x00, 0x92, 0x5c, 0x00, 0x00, 0xf1, 0x88, 0x00, 0x04, 0x03, 0xb8, 0x00, 0x00, 0x6e, 0xd0, 0x00, 0x04, 0x03, 0xb8, 0x00, 0x00, 0x6e, 0xd0, 0x00, 0x04, 0x03, 0xb8},
-			asPath: []uint32{9304, 37468, 61832, 263096, 28368, 263096, 28368, 263096},
+			name:      "panic #1",
+			asnLength: 4,
+			input:     []byte{0x02, 0x08, 0x00, 0x00, 0x24, 0x58, 0x00, 0x00, 0x92, 0x5c, 0x00, 0x00, 0xf1, 0x88, 0x00, 0x04, 0x03, 0xb8, 0x00, 0x00, 0x6e, 0xd0, 0x00, 0x04, 0x03, 0xb8, 0x00, 0x00, 0x6e, 0xd0, 0x00, 0x04, 0x03, 0xb8},
+			asPath:    []uint32{9304, 37468, 61832, 263096, 28368, 263096, 28368, 263096},
+		},
+		{
+			name:      "panic #2",
+			asnLength: 4,
+			input:     []byte{0x02, 0x48, 0x00, 0x00, 0xce, 0x89, 0x00, 0x00, 0x32, 0x9c, 0x00, 0x00, 0xf0, 0x1c, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0x14},
+			asPath:    []uint32{52873, 12956, 61468, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 269844},
+		},
+		{
+			name:      "panic #3",
+			asnLength: 4,
+			input:     []byte{0x02, 0xa2, 0x00, 0x00, 0xbe, 0xb5, 0x00, 0x03, 0x21, 0x38, 0x00, 0x00, 0xc5, 0xc5, 0x00, 0x00, 0x00, 0xae, 0x00, 0x00, 0x6c, 0x66, 0x00, 0x00, 0xf0, 0x1c, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0x14},
+			asPath:    []uint32{48821, 205112, 50629, 174, 27750, 61468, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 269844},
 		},
 		{
-			name:   "panic #2",
-			input:  []byte{0x02, 0x48, 0x00, 0x00, 0xce, 0x89, 0x00, 0x00, 0x32, 0x9c, 0x00, 0x00, 0xf0, 0x1c, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0x14},
-			asPath: []uint32{52873, 12956, 61468, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 269844},
+			name:      "panic #4",
+			asnLength: 4,
+			input:     []byte{0x02, 0x06, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x9a, 0x6d, 0x00, 0x00, 0x19, 0x35, 0x00, 0x00, 0x0a, 0x7f, 0x00, 0x00, 0x65, 0x20, 0x00, 0x00, 0x53, 0x4e, 0x01, 0x01, 0x00, 0x00, 0x12, 0xc9},
+			asPath:    []uint32{34872, 39533, 6453, 2687, 25888, 21326, 4809},
 		},
 		{
-			name:   "panic #3",
-			input:  []byte{0x02, 0xa2, 0x00, 0x00, 0xbe, 0xb5, 0x00, 0x03, 0x21, 0x38, 0x00, 0x00, 0xc5, 0xc5, 0x00, 0x00, 0x00, 0xae, 0x00, 0x00, 0x6c, 0x66, 0x00, 0x00, 0xf0, 0x1c, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0xfa, 0x00, 0x04, 0x1e, 0x14},
-			asPath: []uint32{48821, 205112, 50629, 174, 27750, 61468, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 270074, 269844},
+			name:      "1 AS4 segment",
+			asnLength: 4,
+			input:     []byte{0x02, 0x01, 0x00, 0x00, 0x88, 0x38},
+			asPath:    []uint32{34872},
 		},
 		{
-			name:   "panic #4",
-			input:  []byte{0x02, 0x06, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x9a, 0x6d, 0x00, 0x00, 0x19, 0x35, 0x00, 0x00, 0x0a, 0x7f, 0x00, 0x00, 0x65, 0x20, 0x00, 0x00, 0x53, 0x4e, 0x01, 0x01, 0x00, 0x00, 0x12, 0xc9},
-			asPath: []uint32{34872, 39533, 6453, 2687, 25888, 21326, 4809},
+			name:      "1 AS2 segment",
+			asnLength: 2,
+			input:     []byte{0x02, 0x01, 0x88, 0x38},
+			asPath:    []uint32{34872},
 		},
 		{
-			name:   "1 AS4 segment",
-			input:  []byte{0x02, 0x01, 0x00, 0x00, 0x88, 0x38},
-			asPath: []uint32{34872},
+			name:      "2 AS4 segments",
+			asnLength: 4,
+			input:     []byte{0x02, 0x01, 0x00, 0x00, 0x88, 0x38, 0x01, 0x01, 0x00, 0x00, 0x88, 0x38},
+			asPath:    []uint32{34872, 34872},
 		},
 		{
-			name:   "1 AS2 segment",
-			input:  []byte{0x02, 0x01, 0x88, 0x38},
-			asPath: []uint32{34872},
+			name:      "2 AS2 segments",
+			asnLength: 2,
+			input:     []byte{0x02, 0x01, 0x88, 0x38, 0x01, 0x01, 0x88, 0x38},
+			asPath:    []uint32{34872, 34872},
 		},
 		{
-			name:   "2 AS4 segments",
-			input:  []byte{0x02, 0x01, 0x00, 0x00, 0x88, 0x38, 0x01, 0x01, 0x00, 0x00, 0x88, 0x38},
-			asPath: []uint32{34872, 34872},
+			name:      "2 AS2 segments decoded as 4 bytes asn",
+			asnLength: 4,
+			input:     []byte{0x02, 0x01, 0xFD, 0xE8, 0x02, 0x01, 0x02, 0x01, 0x02, 0x01, 0xFD, 0xE9},
+			asPath:    []uint32{4259840513, 33684969},
 		},
 		{
-			name:   "2 AS2 segments",
-			input:  []byte{0x02, 0x01, 0x88, 0x38, 0x01, 0x01, 0x88, 0x38},
-			asPath: []uint32{34872, 34872},
+			name:      "3 AS2 segments",
+			asnLength: 2,
+			input:     []byte{0x02, 0x01, 0xFD, 0xE8, 0x02, 0x01, 0x02, 0x01, 0x02, 0x01, 0xFD, 0xE9},
+			asPath:    []uint32{65000, 513, 65001},
 		},
 		{
-			name:   "Panic #5",
-			input:  []byte{0x02, 0x05, 0xDC, 0x6E, 0x30, 0x16, 0x00, 0xAE, 0x04, 0xF9, 0x15, 0x02, 0x01, 0x01, 0x62, 0x2F},
-			asPath: []uint32{56430, 12310, 174, 1273, 5378, 25135},
+			name:      "Panic #5",
+			asnLength: 2,
+			input:     []byte{0x02, 0x05, 0xDC, 0x6E, 0x30, 0x16, 0x00, 0xAE, 0x04, 0xF9, 0x15, 0x02, 0x01, 0x01, 0x62, 0x2F},
+			asPath:    []uint32{56430, 12310, 174, 1273, 5378, 25135},
 		},
 	}
 	for _, tt := range tests {
-		r := unmarshalAttrASPath(tt.input)
+		r := ASPathASNs(unmarshalAttrASPathSegments(tt.input, tt.asnLength))
 		if !reflect.DeepEqual(tt.asPath, r) {
 			t.Fatalf("expected %+v and result %+v as path do not match", tt.asPath, r)
 		}
 	}
 }
+
+func TestUnmarshalASPathSegments(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     []byte
+		asnLength int
+		segments  []ASPathSegment
+		length    int32
+		originAS  uint32
+	}{
+		{
+			name:      "confed sequence and 4 bytes asn",
+			asnLength: 4,
+			input: []byte{
+				0x03, 0x02, 0x00, 0x00, 0xFD, 0xE8, 0x00, 0x00, 0xFD, 0xE9, // AS_CONFED_SEQUENCE 65000 65001
+				0x02, 0x02, 0x00, 0x00, 0x1B, 0x1B, 0x00, 0x03, 0x0D, 0x40, // AS_SEQUENCE 6939 200000
+			},
+			segments: []ASPathSegment{
+				{Type: "as_confed_sequence", ASNs: []uint32{65000, 65001}},
+				{Type: "as_sequence", ASNs: []uint32{6939, 200000}},
+			},
+			length:   2,
+			originAS: 200000,
+		},
+		{
+			name:      "confed set and as set",
+			asnLength: 4,
+			input: []byte{
+				0x04, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_CONFED_SET 65000
+				0x02, 0x01, 0x00, 0x00, 0x1B, 0x1B, // AS_SEQUENCE 6939
+				0x01, 0x02, 0x00, 0x01, 0x11, 0x70, 0x00, 0x01, 0x11, 0x71, // AS_SET 70000 70001
+			},
+			segments: []ASPathSegment{
+				{Type: "as_confed_set", ASNs: []uint32{65000}},
+				{Type: "as_sequence", ASNs: []uint32{6939}},
+				{Type: "as_set", ASNs: []uint32{70000, 70001}},
+			},
+			length:   2,
+			originAS: 70001,
+		},
+		{
+			name:      "2 bytes asn with confed sequence",
+			asnLength: 2,
+			input: []byte{
+				0x03, 0x01, 0xFD, 0xE8, // AS_CONFED_SEQUENCE 65000
+				0x02, 0x02, 0x1B, 0x1B, 0x0D, 0x1C, // AS_SEQUENCE 6939 3356
+			},
+			segments: []ASPathSegment{
+				{Type: "as_confed_sequence", ASNs: []uint32{65000}},
+				{Type: "as_sequence", ASNs: []uint32{6939, 3356}},
+			},
+			length:   2,
+			originAS: 3356,
+		},
+		{
+			name:      "only confed sequence",
+			asnLength: 4,
+			input:     []byte{0x03, 0x01, 0x00, 0x00, 0xFD, 0xE8},
+			segments: []ASPathSegment{
+				{Type: "as_confed_sequence", ASNs: []uint32{65000}},
+			},
+		},
+		{
+			name:      "4 bytes asn of 2 bytes asn as path",
+			asnLength: 4,
+			input:     []byte{0x03, 0x01, 0xFD, 0xE8, 0x02, 0x02, 0x1B, 0x1B, 0x0D, 0x1C},
+		},
+		{
+			name:      "invalid segment type",
+			asnLength: 4,
+			input:     []byte{0x05, 0x01, 0x00, 0x00, 0xFD, 0xE8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := unmarshalAttrASPathSegments(tt.input, tt.asnLength)
+			if diff := deep.Equal(tt.segments, segments); diff != nil {
+				t.Fatalf("Diffs: %+v", diff)
+			}
+			if length := ASPathLength(segments); length != tt.length {
+				t.Errorf("expected path length %d, got %d", tt.length, length)
+			}
+			ba := &BaseAttributes{ASPath: ASPathASNs(segments), ASPathSegments: segments}
+			if originAS := ba.OriginAS(); originAS != tt.originAS {
+				t.Errorf("expected origin as %d, got %d", tt.originAS, originAS)
+			}
+		})
+	}
+}
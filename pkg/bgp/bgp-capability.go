@@ -2,6 +2,7 @@ package bgp
 
 import (
 	"encoding/binary"
+	"sort"
 	"strconv"
 
 	"github.com/golang/glog"
@@ -115,3 +116,161 @@ func UnmarshalBGPCapability(b []byte) (Capability, error) {
 
 	return caps, nil
 }
+
+// AFISAFI defines a pair of AFI and SAFI carried by BGP Capabilities
+type AFISAFI struct {
+	AFI  uint16 `json:"afi"`
+	SAFI uint8  `json:"safi"`
+}
+
+// ExtendedNextHop defines NLRI AFI/SAFI which can be advertised with Next Hop of NextHopAFI, RFC 8950
+type ExtendedNextHop struct {
+	AFISAFI
+	NextHopAFI uint16 `json:"nexthop_afi"`
+}
+
+// GracefulRestartAFISAFI defines AFI/SAFI preserved during Graceful Restart, ForwardingState is set
+// when the forwarding state of AFI/SAFI has been preserved during the previous restart.
+type GracefulRestartAFISAFI struct {
+	AFISAFI
+	ForwardingState bool `json:"forwarding_state"`
+}
+
+// GracefulRestart defines Graceful Restart Capability, RFC 4724 and RFC 8538
+type GracefulRestart struct {
+	Restarted    bool                     `json:"restarted"`
+	Notification bool                     `json:"notification"`
+	RestartTime  uint16                   `json:"restart_time"`
+	AFISAFI      []GracefulRestartAFISAFI `json:"afi_safi,omitempty"`
+}
+
+// AddPath defines AFI/SAFI for which the speaker is able to send and or receive multiple paths, RFC 7911
+type AddPath struct {
+	AFISAFI
+	Send    bool `json:"send"`
+	Receive bool `json:"receive"`
+}
+
+// FQDN defines FQDN Capability carrying host and domain names of the speaker
+type FQDN struct {
+	HostName   string `json:"host_name"`
+	DomainName string `json:"domain_name,omitempty"`
+}
+
+// RawCapability defines a capability which is not decoded, its value is preserved as received
+type RawCapability struct {
+	Code  uint8  `json:"code"`
+	Value []byte `json:"value,omitempty"`
+}
+
+// OpenCapabilities defines BGP Capabilities of Open Message decoded into structured fields,
+// capabilities with unknown codes or malformed values are preserved in Unknown.
+type OpenCapabilities struct {
+	MultiProtocol        []AFISAFI         `json:"multiprotocol,omitempty"`
+	RouteRefresh         bool              `json:"route_refresh,omitempty"`
+	ExtendedNextHop      []ExtendedNextHop `json:"extended_nexthop,omitempty"`
+	ExtendedMessage      bool              `json:"extended_message,omitempty"`
+	GracefulRestart      *GracefulRestart  `json:"graceful_restart,omitempty"`
+	FourOctetAS          uint32            `json:"four_octet_as,omitempty"`
+	AddPath              []AddPath         `json:"add_path,omitempty"`
+	EnhancedRouteRefresh bool              `json:"enhanced_route_refresh,omitempty"`
+	FQDN                 *FQDN             `json:"fqdn,omitempty"`
+	Unknown              []RawCapability   `json:"unknown,omitempty"`
+}
+
+// DecodeCapabilities decodes known capabilities of c into OpenCapabilities, it returns nil when c is empty
+func DecodeCapabilities(c Capability) *OpenCapabilities {
+	if len(c) == 0 {
+		return nil
+	}
+	codes := make([]int, 0, len(c))
+	for code := range c {
+		codes = append(codes, int(code))
+	}
+	// Capability is a map, sorting codes keeps unknown capabilities in a stable order
+	sort.Ints(codes)
+	oc := &OpenCapabilities{}
+	for _, code := range codes {
+		for _, d := range c[uint8(code)] {
+			if !oc.decode(uint8(code), d.Value) {
+				oc.Unknown = append(oc.Unknown, RawCapability{Code: uint8(code), Value: d.Value})
+			}
+		}
+	}
+
+	return oc
+}
+
+// decode sets the field of capability code from its value, it returns false when the code is unknown
+// or the value is malformed.
+func (oc *OpenCapabilities) decode(code uint8, v []byte) bool {
+	switch code {
+	case 1:
+		if len(v) != 4 {
+			return false
+		}
+		oc.MultiProtocol = append(oc.MultiProtocol, AFISAFI{AFI: binary.BigEndian.Uint16(v[0:2]), SAFI: v[3]})
+	case 2:
+		oc.RouteRefresh = true
+	case 5:
+		if len(v) == 0 || len(v)%6 != 0 {
+			return false
+		}
+		for p := 0; p < len(v); p += 6 {
+			// NLRI SAFI is encoded in 2 bytes
+			oc.ExtendedNextHop = append(oc.ExtendedNextHop, ExtendedNextHop{
+				AFISAFI:    AFISAFI{AFI: binary.BigEndian.Uint16(v[p : p+2]), SAFI: v[p+3]},
+				NextHopAFI: binary.BigEndian.Uint16(v[p+4 : p+6]),
+			})
+		}
+	case 6:
+		oc.ExtendedMessage = true
+	case 64:
+		if len(v) < 2 || (len(v)-2)%4 != 0 || oc.GracefulRestart != nil {
+			return false
+		}
+		gr := &GracefulRestart{
+			Restarted:    v[0]&0x80 != 0,
+			Notification: v[0]&0x40 != 0,
+			RestartTime:  binary.BigEndian.Uint16(v[0:2]) & 0x0fff,
+		}
+		for p := 2; p < len(v); p += 4 {
+			gr.AFISAFI = append(gr.AFISAFI, GracefulRestartAFISAFI{
+				AFISAFI:         AFISAFI{AFI: binary.BigEndian.Uint16(v[p : p+2]), SAFI: v[p+2]},
+				ForwardingState: v[p+3]&0x80 != 0,
+			})
+		}
+		oc.GracefulRestart = gr
+	case 65:
+		if len(v) != 4 || oc.FourOctetAS != 0 {
+			return false
+		}
+		oc.FourOctetAS = binary.BigEndian.Uint32(v)
+	case 69:
+		if len(v) == 0 || len(v)%4 != 0 {
+			return false
+		}
+		for p := 0; p < len(v); p += 4 {
+			oc.AddPath = append(oc.AddPath, AddPath{
+				AFISAFI: AFISAFI{AFI: binary.BigEndian.Uint16(v[p : p+2]), SAFI: v[p+2]},
+				Receive: v[p+3]&0x1 != 0,
+				Send:    v[p+3]&0x2 != 0,
+			})
+		}
+	case 70:
+		oc.EnhancedRouteRefresh = true
+	case 73:
+		if len(v) < 1 || len(v) < 2+int(v[0]) || len(v) != 2+int(v[0])+int(v[1+int(v[0])]) || oc.FQDN != nil {
+			return false
+		}
+		hl := int(v[0])
+		oc.FQDN = &FQDN{
+			HostName:   string(v[1 : 1+hl]),
+			DomainName: string(v[2+hl:]),
+		}
+	default:
+		return false
+	}
+
+	return true
+}
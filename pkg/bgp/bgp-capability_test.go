@@ -0,0 +1,67 @@
+package bgp
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDecodeCapabilities(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect *OpenCapabilities
+	}{
+		{
+			name: "multiprotocol and 4-octet as",
+			input: []byte{
+				0, 67, 1, 4, 0x5B, 0xA0, 0, 90, 192, 168, 8, 8, 38,
+				2, 6, 1, 4, 0, 1, 0, 1, // IPv4 Unicast
+				2, 6, 1, 4, 0, 2, 0, 1, // IPv6 Unicast
+				2, 6, 65, 4, 0xFA, 0x56, 0xEA, 0x00, // AS 4200000000
+				2, 6, 69, 4, 0, 1, 1, 3, // ADD-PATH Send/Receive IPv4 Unicast
+				2, 4, 200, 2, 1, 2, // Unknown capability 200
+			},
+			expect: &OpenCapabilities{
+				MultiProtocol: []AFISAFI{{AFI: 1, SAFI: 1}, {AFI: 2, SAFI: 1}},
+				FourOctetAS:   4200000000,
+				AddPath:       []AddPath{{AFISAFI: AFISAFI{AFI: 1, SAFI: 1}, Send: true, Receive: true}},
+				Unknown:       []RawCapability{{Code: 200, Value: []byte{1, 2}}},
+			},
+		},
+		{
+			name: "graceful restart, fqdn and malformed 4-octet as",
+			input: []byte{
+				0, 70, 1, 4, 0x5B, 0xA0, 0, 90, 192, 168, 8, 8, 41,
+				2, 2, 2, 0, // Route Refresh
+				2, 12, 64, 10, 0x80, 0x78, 0, 1, 1, 0x80, 0, 2, 1, 0, // Graceful Restart 120s
+				2, 15, 73, 13, 4, 'r', 't', 'r', '1', 7, 'e', 'x', 'a', 'm', 'p', 'l', 'e', // FQDN rtr1 example
+				2, 4, 65, 2, 0x5B, 0xA0, // Malformed 4-octet AS
+			},
+			expect: &OpenCapabilities{
+				RouteRefresh: true,
+				GracefulRestart: &GracefulRestart{
+					Restarted:   true,
+					RestartTime: 120,
+					AFISAFI: []GracefulRestartAFISAFI{
+						{AFISAFI: AFISAFI{AFI: 1, SAFI: 1}, ForwardingState: true},
+						{AFISAFI: AFISAFI{AFI: 2, SAFI: 1}},
+					},
+				},
+				FQDN:    &FQDN{HostName: "rtr1", DomainName: "example"},
+				Unknown: []RawCapability{{Code: 65, Value: []byte{0x5B, 0xA0}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, err := UnmarshalBGPOpenMessage(tt.input)
+			if err != nil {
+				t.Fatalf("failed to unmarshal BGP Open message with error: %+v", err)
+			}
+			if diff := deep.Equal(tt.expect, DecodeCapabilities(open.GetCapabilities())); diff != nil {
+				t.Errorf("Diffs: %+v", diff)
+			}
+		})
+	}
+}
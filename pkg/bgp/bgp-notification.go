@@ -0,0 +1,135 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/tools"
+)
+
+const (
+	// BGPNotificationMessageType defines BGP Notification Message type
+	BGPNotificationMessageType = 3
+	// BGPMinNotificationMessageLength defines a minimum length of BGP Notification Message
+	BGPMinNotificationMessageLength = 21
+)
+
+// notificationErrors defines descriptions of BGP Notification error codes and subcodes,
+// subcode 0 describes the error code itself.
+var notificationErrors = map[uint8]map[uint8]string{
+	1: {
+		0: "Message Header Error",
+		1: "Connection Not Synchronized",
+		2: "Bad Message Length",
+		3: "Bad Message Type",
+	},
+	2: {
+		0:  "OPEN Message Error",
+		1:  "Unsupported Version Number",
+		2:  "Bad Peer AS",
+		3:  "Bad BGP Identifier",
+		4:  "Unsupported Optional Parameter",
+		6:  "Unacceptable Hold Time",
+		7:  "Unsupported Capability",
+		11: "Role Mismatch",
+	},
+	3: {
+		0:  "UPDATE Message Error",
+		1:  "Malformed Attribute List",
+		2:  "Unrecognized Well-known Attribute",
+		3:  "Missing Well-known Attribute",
+		4:  "Attribute Flags Error",
+		5:  "Attribute Length Error",
+		6:  "Invalid ORIGIN Attribute",
+		8:  "Invalid NEXT_HOP Attribute",
+		9:  "Optional Attribute Error",
+		10: "Invalid Network Field",
+		11: "Malformed AS_PATH",
+	},
+	4: {
+		0: "Hold Timer Expired",
+	},
+	5: {
+		0: "Finite State Machine Error",
+		1: "Receive Unexpected Message in OpenSent State",
+		2: "Receive Unexpected Message in OpenConfirm State",
+		3: "Receive Unexpected Message in Established State",
+	},
+	6: {
+		0:  "Cease",
+		1:  "Maximum Number of Prefixes Reached",
+		2:  "Administrative Shutdown",
+		3:  "Peer De-configured",
+		4:  "Administrative Reset",
+		5:  "Connection Rejected",
+		6:  "Other Configuration Change",
+		7:  "Connection Collision Resolution",
+		8:  "Out of Resources",
+		9:  "Hard Reset",
+		10: "BFD Down",
+	},
+	7: {
+		0: "ROUTE-REFRESH Message Error",
+		1: "Invalid Message Length",
+	},
+}
+
+// NotificationMessage defines BGP Notification Message structure
+type NotificationMessage struct {
+	Code    uint8 `json:"code"`
+	Subcode uint8 `json:"subcode"`
+	// Error describes the error code and the subcode, for example "Cease: Administrative Reset"
+	Error string `json:"error,omitempty"`
+	Data  []byte `json:"data,omitempty"`
+}
+
+// NotificationError returns description of BGP Notification error code and subcode, unknown codes and subcodes
+// are described by their numbers.
+func NotificationError(code, subcode uint8) string {
+	subcodes, ok := notificationErrors[code]
+	if !ok {
+		return fmt.Sprintf("Unknown Error Code %d Subcode %d", code, subcode)
+	}
+	if subcode == 0 {
+		return subcodes[0]
+	}
+	if s, ok := subcodes[subcode]; ok {
+		return subcodes[0] + ": " + s
+	}
+
+	return fmt.Sprintf("%s: Unknown Subcode %d", subcodes[0], subcode)
+}
+
+// UnmarshalBGPNotificationMessage validates information passed in byte slice and returns NotificationMessage object,
+// the slice starts with the length field of BGP message header following the marker.
+func UnmarshalBGPNotificationMessage(b []byte) (*NotificationMessage, error) {
+	if glog.V(6) {
+		glog.Infof("BGPNotificationMessage Raw: %s", tools.MessageHex(b))
+	}
+	if len(b) < BGPMinNotificationMessageLength-16 {
+		return nil, fmt.Errorf("BGP Notification Message length %d is invalid", len(b))
+	}
+	p := 0
+	l := int(binary.BigEndian.Uint16(b[p : p+2]))
+	if l < BGPMinNotificationMessageLength || l-16 > len(b) {
+		return nil, fmt.Errorf("invalid length %d of BGP Notification Message, remaining data length %d", l, len(b))
+	}
+	p += 2
+	if b[p] != BGPNotificationMessageType {
+		return nil, fmt.Errorf("invalid message type %d for BGP Notification Message", b[p])
+	}
+	p++
+	m := &NotificationMessage{
+		Code:    b[p],
+		Subcode: b[p+1],
+	}
+	p += 2
+	m.Error = NotificationError(m.Code, m.Subcode)
+	if p < l-16 {
+		m.Data = make([]byte, l-16-p)
+		copy(m.Data, b[p:l-16])
+	}
+
+	return m, nil
+}
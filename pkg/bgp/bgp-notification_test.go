@@ -0,0 +1,85 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalBGPNotificationMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect *NotificationMessage
+		fail   bool
+	}{
+		{
+			name:  "hold timer expired",
+			input: []byte{0x00, 0x15, 0x03, 0x04, 0x00},
+			expect: &NotificationMessage{
+				Code:    4,
+				Subcode: 0,
+				Error:   "Hold Timer Expired",
+			},
+		},
+		{
+			name:  "cease administrative shutdown with communication",
+			input: []byte{0x00, 0x1A, 0x03, 0x06, 0x02, 0x04, 'm', 'a', 'i', 'n'},
+			expect: &NotificationMessage{
+				Code:    6,
+				Subcode: 2,
+				Error:   "Cease: Administrative Shutdown",
+				Data:    []byte{0x04, 'm', 'a', 'i', 'n'},
+			},
+		},
+		{
+			name:  "unknown subcode",
+			input: []byte{0x00, 0x15, 0x03, 0x02, 0x05},
+			expect: &NotificationMessage{
+				Code:    2,
+				Subcode: 5,
+				Error:   "OPEN Message Error: Unknown Subcode 5",
+			},
+		},
+		{
+			name:  "unknown code",
+			input: []byte{0x00, 0x15, 0x03, 0x09, 0x01},
+			expect: &NotificationMessage{
+				Code:    9,
+				Subcode: 1,
+				Error:   "Unknown Error Code 9 Subcode 1",
+			},
+		},
+		{
+			name:  "not a notification",
+			input: []byte{0x00, 0x15, 0x02, 0x04, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "truncated message",
+			input: []byte{0x00, 0x15, 0x03, 0x04},
+			fail:  true,
+		},
+		{
+			name:  "length exceeds data",
+			input: []byte{0x00, 0x18, 0x03, 0x04, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalBGPNotificationMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected Notification %+v, got %+v", *tt.expect, *got)
+			}
+		})
+	}
+}
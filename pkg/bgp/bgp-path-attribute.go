@@ -2,6 +2,7 @@ package bgp
 
 import (
 	"encoding/binary"
+	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/tools"
@@ -23,18 +24,11 @@ func UnmarshalBGPPathAttributes(b []byte) ([]PathAttribute, error) {
 	attrs := make([]PathAttribute, 0)
 
 	for p := 0; p < len(b); {
-		f := b[p]
-		t := b[p+1]
-		p += 2
-		var l uint16
-		// Checking for Extened
-		if f&0x10 == 0x10 {
-			l = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-		} else {
-			l = uint16(b[p])
-			p++
+		f, t, l, v, err := unmarshalAttributeHeader(b, p)
+		if err != nil {
+			return nil, err
 		}
+		p = v
 		pa := PathAttribute{
 			AttributeTypeFlags: f,
 			AttributeType:      t,
@@ -49,3 +43,115 @@ func UnmarshalBGPPathAttributes(b []byte) ([]PathAttribute, error) {
 
 	return attrs, nil
 }
+
+// unmarshalAttributeHeader decodes the header of the path attribute starting at b[p] and returns its Attribute Flags,
+// Attribute Type Code, Attribute Length and the offset of the attribute value. Attribute Length is 2 bytes when
+// Extended Length bit is set regardless of the attribute type and the length of the value, rfc4271 section 4.3.
+func unmarshalAttributeHeader(b []byte, p int) (uint8, uint8, uint16, int, error) {
+	if p+3 > len(b) {
+		return 0, 0, 0, 0, fmt.Errorf("not enough bytes to unmarshal path attribute header at offset %d", p)
+	}
+	f := b[p]
+	t := b[p+1]
+	p += 2
+	var l uint16
+	if f&0x10 == 0x10 {
+		if p+2 > len(b) {
+			return 0, 0, 0, 0, fmt.Errorf("not enough bytes to unmarshal extended length of path attribute type %d", t)
+		}
+		l = binary.BigEndian.Uint16(b[p : p+2])
+		p += 2
+	} else {
+		l = uint16(b[p])
+		p++
+	}
+	if p+int(l) > len(b) {
+		return 0, 0, 0, 0, fmt.Errorf("path attribute type %d length %d exceeds remaining %d bytes", t, l, len(b)-p)
+	}
+
+	return f, t, l, p, nil
+}
+
+// decodedAttributes defines type codes of path attributes decoded by gobmp
+var decodedAttributes = map[uint8]bool{
+	1:  true, // ORIGIN
+	2:  true, // AS_PATH
+	3:  true, // NEXT_HOP
+	4:  true, // MULTI_EXIT_DISC
+	5:  true, // LOCAL_PREF
+	6:  true, // ATOMIC_AGGREGATE
+	7:  true, // AGGREGATOR
+	8:  true, // COMMUNITIES
+	9:  true, // ORIGINATOR_ID
+	10: true, // CLUSTER_LIST
+	14: true, // MP_REACH_NLRI
+	15: true, // MP_UNREACH_NLRI
+	16: true, // EXTENDED COMMUNITIES
+	17: true, // AS4_PATH
+	18: true, // AS4_AGGREGATOR
+	22: true, // PMSI_TUNNEL
+	23: true, // Tunnel Encapsulation
+	25: true, // IPv6 Address Specific Extended Community
+	26: true, // AIGP
+	29: true, // BGP-LS Attribute
+	32: true, // LARGE_COMMUNITY
+	40: true, // BGP Prefix-SID
+}
+
+// UnknownAttribute defines a path attribute not decoded by gobmp, Value is the raw attribute value
+type UnknownAttribute struct {
+	Type  uint8  `json:"type"`
+	Flags uint8  `json:"flags"`
+	Value []byte `json:"value"`
+}
+
+// UnknownAttributes returns path attributes of types not decoded by gobmp, nil is returned when
+// all attributes are decoded
+func UnknownAttributes(attrs []PathAttribute) []UnknownAttribute {
+	var unknown []UnknownAttribute
+	for _, attr := range attrs {
+		if decodedAttributes[attr.AttributeType] {
+			continue
+		}
+		unknown = append(unknown, UnknownAttribute{
+			Type:  attr.AttributeType,
+			Flags: attr.AttributeTypeFlags,
+			Value: attr.Attribute,
+		})
+	}
+
+	return unknown
+}
+
+// PathAttributeFlags defines Attribute Flags of a path attribute, rfc4271, Flags carries the raw octet
+type PathAttributeFlags struct {
+	Flags          uint8 `json:"flags"`
+	Optional       bool  `json:"optional"`
+	Transitive     bool  `json:"transitive"`
+	Partial        bool  `json:"partial"`
+	ExtendedLength bool  `json:"extended_length"`
+}
+
+// NewPathAttributeFlags decodes Attribute Flags octet of a path attribute
+func NewPathAttributeFlags(f uint8) PathAttributeFlags {
+	return PathAttributeFlags{
+		Flags:          f,
+		Optional:       f&0x80 == 0x80,
+		Transitive:     f&0x40 == 0x40,
+		Partial:        f&0x20 == 0x20,
+		ExtendedLength: f&0x10 == 0x10,
+	}
+}
+
+// AttributeFlags returns Attribute Flags of path attributes by attribute type code
+func AttributeFlags(attrs []PathAttribute) map[uint8]PathAttributeFlags {
+	if len(attrs) == 0 {
+		return nil
+	}
+	flags := make(map[uint8]PathAttributeFlags, len(attrs))
+	for _, attr := range attrs {
+		flags[attr.AttributeType] = NewPathAttributeFlags(attr.AttributeTypeFlags)
+	}
+
+	return flags
+}
@@ -0,0 +1,158 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAttributeFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect map[uint8]PathAttributeFlags
+	}{
+		{
+			name: "well-known and optional attributes",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x80, 0x04, 0x04, 0x00, 0x00, 0x00, 0x64, // MULTI_EXIT_DISC 100
+				0xe0, 0x08, 0x04, 0xfd, 0xe8, 0x00, 0x64, // COMMUNITIES 65000:100 with Partial bit
+				0xd0, 0x20, 0x00, 0x0c, 0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, // LARGE_COMMUNITY
+			},
+			expect: map[uint8]PathAttributeFlags{
+				1:  {Flags: 0x40, Transitive: true},
+				4:  {Flags: 0x80, Optional: true},
+				8:  {Flags: 0xe0, Optional: true, Transitive: true, Partial: true},
+				32: {Flags: 0xd0, Optional: true, Transitive: true, ExtendedLength: true},
+			},
+		},
+		{
+			name:  "no attributes",
+			input: []byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs, err := UnmarshalBGPPathAttributes(tt.input)
+			if err != nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if got := AttributeFlags(attrs); !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected attribute flags %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBGPPathAttributesExtendedLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect []PathAttribute
+		origin string
+		fail   bool
+	}{
+		{
+			name: "extended length set on short attribute",
+			input: []byte{
+				0x50, 0x02, 0x00, 0x0a, 0x02, 0x02, 0x00, 0x00, 0xfd, 0xe9, 0x00, 0x00, 0xfd, 0xeb, // AS_PATH 65001 65003, extended length
+				0x40, 0x01, 0x01, 0x02, // ORIGIN INCOMPLETE
+				0x40, 0x03, 0x04, 0x0a, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0x90, 0x04, 0x00, 0x04, 0x00, 0x00, 0x00, 0x64, // MULTI_EXIT_DISC 100, extended length
+			},
+			expect: []PathAttribute{
+				{AttributeTypeFlags: 0x50, AttributeType: 2, AttributeLength: 10, Attribute: []byte{0x02, 0x02, 0x00, 0x00, 0xfd, 0xe9, 0x00, 0x00, 0xfd, 0xeb}},
+				{AttributeTypeFlags: 0x40, AttributeType: 1, AttributeLength: 1, Attribute: []byte{0x02}},
+				{AttributeTypeFlags: 0x40, AttributeType: 3, AttributeLength: 4, Attribute: []byte{0x0a, 0x00, 0x00, 0x01}},
+				{AttributeTypeFlags: 0x90, AttributeType: 4, AttributeLength: 4, Attribute: []byte{0x00, 0x00, 0x00, 0x64}},
+			},
+			origin: "incomplete",
+		},
+		{
+			name:   "extended length of zero",
+			input:  []byte{0x50, 0x06, 0x00, 0x00, 0x40, 0x01, 0x01, 0x00},
+			expect: []PathAttribute{{AttributeTypeFlags: 0x50, AttributeType: 6, Attribute: []byte{}}, {AttributeTypeFlags: 0x40, AttributeType: 1, AttributeLength: 1, Attribute: []byte{0x00}}},
+			origin: "igp",
+		},
+		{
+			name:  "truncated header",
+			input: []byte{0x40, 0x01, 0x01, 0x00, 0x40, 0x03},
+			fail:  true,
+		},
+		{
+			name:  "truncated extended length",
+			input: []byte{0x40, 0x01, 0x01, 0x00, 0x50, 0x02, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "length exceeds attributes",
+			input: []byte{0x50, 0x02, 0x00, 0x0a, 0x02, 0x01, 0x00, 0x00, 0xfd, 0xe9},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs, err := UnmarshalBGPPathAttributes(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if !reflect.DeepEqual(attrs, tt.expect) {
+				t.Fatalf("expected path attributes %+v, got %+v", tt.expect, attrs)
+			}
+			// Base attributes following an attribute with unnecessary extended length must be recovered
+			baseAttrs, err := UnmarshalBGPBaseAttributes(tt.input, 4)
+			if tt.fail {
+				if err == nil {
+					t.Fatalf("supposed to fail but succeeded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if baseAttrs.Origin != tt.origin {
+				t.Fatalf("expected origin %q, got %q", tt.origin, baseAttrs.Origin)
+			}
+		})
+	}
+}
+
+func TestUnknownAttributes(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect []UnknownAttribute
+	}{
+		{
+			name: "made-up attribute",
+			input: []byte{
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0xc0, 0xc7, 0x02, 0xbe, 0xef, // Attribute 199
+				0x80, 0x04, 0x04, 0x00, 0x00, 0x00, 0x64, // MULTI_EXIT_DISC 100
+				0xd0, 0xc8, 0x00, 0x00, // Attribute 200 with extended length
+			},
+			expect: []UnknownAttribute{
+				{Type: 199, Flags: 0xc0, Value: []byte{0xbe, 0xef}},
+				{Type: 200, Flags: 0xd0, Value: []byte{}},
+			},
+		},
+		{
+			name:  "all attributes decoded",
+			input: []byte{0x40, 0x01, 0x01, 0x00, 0x80, 0x04, 0x04, 0x00, 0x00, 0x00, 0x64},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs, err := UnmarshalBGPPathAttributes(tt.input)
+			if err != nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if got := UnknownAttributes(attrs); !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected unknown attributes %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
@@ -107,8 +107,30 @@ func (up *Update) GetNLRIType() (uint8, int) {
 	return BGP4_NLRI, 0
 }
 
-// UnmarshalBGPUpdate build BGP Update object from the byte slice provided
-func UnmarshalBGPUpdate(b []byte) (*Update, error) {
+// EndOfRIB returns AFI and SAFI of End-of-RIB marker carried by the update, rfc4724. End-of-RIB of IPv4
+// unicast is an update without withdrawn routes, path attributes and NLRI, End-of-RIB of other AFI/SAFI
+// is an update carrying only MP_UNREACH_NLRI without withdrawn routes, ok is false for other updates.
+func (up *Update) EndOfRIB() (afi uint16, safi uint8, ok bool) {
+	if up.WithdrawnRoutesLength != 0 || len(up.NLRI) != 0 {
+		return 0, 0, false
+	}
+	switch len(up.PathAttributes) {
+	case 0:
+		return 1, 1, true
+	case 1:
+		attr := up.PathAttributes[0]
+		if attr.AttributeType != MP_UNREACH_NLRI || len(attr.Attribute) != 3 {
+			return 0, 0, false
+		}
+		return binary.BigEndian.Uint16(attr.Attribute[:2]), attr.Attribute[2], true
+	}
+
+	return 0, 0, false
+}
+
+// UnmarshalBGPUpdate build BGP Update object from the byte slice provided, AS_PATH attribute carries ASNs
+// of asnLength bytes, 2 or 4
+func UnmarshalBGPUpdate(b []byte, asnLength int) (*Update, error) {
 	if glog.V(6) {
 		glog.Infof("BGPUpdate Raw: %s", tools.MessageHex(b))
 	}
@@ -126,7 +148,7 @@ func UnmarshalBGPUpdate(b []byte) (*Update, error) {
 		return nil, err
 	}
 	// Building BGP's update Base attributes struct which is common to all messages
-	baseAttrs, err := UnmarshalBGPBaseAttributes(b[p : p+int(u.TotalPathAttributeLength)])
+	baseAttrs, err := UnmarshalBGPBaseAttributes(b[p:p+int(u.TotalPathAttributeLength)], asnLength)
 	if err != nil {
 		return nil, err
 	}
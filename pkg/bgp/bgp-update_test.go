@@ -21,10 +21,11 @@ func TestUnmarshalBGPUpdate(t *testing.T) {
 				NLRI:                     make([]byte, 0),
 				TotalPathAttributeLength: 44,
 				BaseAttributes: &BaseAttributes{
-					BaseAttrHash: "3b87061fdf773278959113c6f010f24c",
-					ASPath:       []uint32{65001, 65003},
-					ASPathCount:  2,
-					Origin:       "incomplete",
+					BaseAttrHash:   "38305c4b6672eea470d596d7ac820ddc",
+					ASPath:         []uint32{65001, 65003},
+					ASPathCount:    2,
+					ASPathSegments: []ASPathSegment{{Type: "as_sequence", ASNs: []uint32{65001, 65003}}},
+					Origin:         "incomplete",
 				},
 				PathAttributes: []PathAttribute{
 					{
@@ -51,7 +52,7 @@ func TestUnmarshalBGPUpdate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			actual, err := UnmarshalBGPUpdate(tt.input)
+			actual, err := UnmarshalBGPUpdate(tt.input, 4)
 			if err != nil {
 				t.Fatalf("failed to unmarshal BGP Update with error: %+v", err)
 			}
@@ -70,3 +71,52 @@ func TestUnmarshalBGPUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateEndOfRIB(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		afi   uint16
+		safi  uint8
+		eor   bool
+	}{
+		{
+			name:  "ipv4 unicast",
+			input: []byte{0x00, 0x00, 0x00, 0x00},
+			afi:   1,
+			safi:  1,
+			eor:   true,
+		},
+		{
+			name:  "ipv6 unicast",
+			input: []byte{0x00, 0x00, 0x00, 0x06, 0x80, 0x0F, 0x03, 0x00, 0x02, 0x01},
+			afi:   2,
+			safi:  1,
+			eor:   true,
+		},
+		{
+			name:  "ipv6 unicast withdraw",
+			input: []byte{0x00, 0x00, 0x00, 0x09, 0x80, 0x0F, 0x06, 0x00, 0x02, 0x01, 0x10, 0x20, 0x01},
+		},
+		{
+			name:  "ipv4 unicast advertise",
+			input: []byte{0x00, 0x00, 0x00, 0x04, 0x40, 0x01, 0x01, 0x00, 0x08, 0x0a},
+		},
+		{
+			name:  "ipv4 unicast withdraw",
+			input: []byte{0x00, 0x02, 0x08, 0x0a, 0x00, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, err := UnmarshalBGPUpdate(tt.input, 4)
+			if err != nil {
+				t.Fatalf("failed to unmarshal BGP Update with error: %+v", err)
+			}
+			afi, safi, ok := up.EndOfRIB()
+			if ok != tt.eor || afi != tt.afi || safi != tt.safi {
+				t.Fatalf("expected end-of-rib %t afi %d safi %d, got %t afi %d safi %d", tt.eor, tt.afi, tt.safi, ok, afi, safi)
+			}
+		})
+	}
+}
@@ -120,4 +120,6 @@ const (
 	CPFlowspecRedirect = "flowspec-redirect="
 	// CPFlowspecTrafficRemarking defines Flowspec Traffic Remarking Sub type
 	CPFlowspecTrafficRemarking = "flowspec-traffic-remarking="
+	// CPFlowspecTrafficRatePackets defines Flowspec Traffic rate in packets Sub type
+	CPFlowspecTrafficRatePackets = "flowspec-traffic-rate-packets="
 )
@@ -0,0 +1,179 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// ExtCommunityKind identifies a recognized pair of Extended Community type and sub-type,
+// the value is the type in the high-order octet and the sub-type in the low-order octet.
+type ExtCommunityKind uint16
+
+const (
+	// ExtCommunityAS2RouteTarget is Route Target of Transitive Two-Octet AS-Specific type [RFC4360]
+	ExtCommunityAS2RouteTarget ExtCommunityKind = 0x0002
+	// ExtCommunityIPv4RouteTarget is Route Target of Transitive IPv4-Address-Specific type [RFC4360]
+	ExtCommunityIPv4RouteTarget ExtCommunityKind = 0x0102
+	// ExtCommunityAS4RouteTarget is Route Target of Transitive Four-Octet AS-Specific type [RFC5668]
+	ExtCommunityAS4RouteTarget ExtCommunityKind = 0x0202
+	// ExtCommunityOSPFRouteType is OSPF Route Type of Transitive Opaque type [RFC4577]
+	ExtCommunityOSPFRouteType ExtCommunityKind = 0x0306
+	// ExtCommunityColor is Color of Transitive Opaque type [RFC9012]
+	ExtCommunityColor ExtCommunityKind = 0x030b
+	// ExtCommunityEncapsulation is Encapsulation of Transitive Opaque type [RFC9012]
+	ExtCommunityEncapsulation ExtCommunityKind = 0x030c
+	// ExtCommunityDefaultGateway is Default Gateway of Transitive Opaque type [RFC7432]
+	ExtCommunityDefaultGateway ExtCommunityKind = 0x030d
+	// ExtCommunityMACMobility is MAC Mobility of EVPN type [RFC7432]
+	ExtCommunityMACMobility ExtCommunityKind = 0x0600
+	// ExtCommunityESILabel is ESI Label of EVPN type [RFC7432]
+	ExtCommunityESILabel ExtCommunityKind = 0x0601
+	// ExtCommunityESImportRouteTarget is ES-Import Route Target of EVPN type [RFC7432]
+	ExtCommunityESImportRouteTarget ExtCommunityKind = 0x0602
+	// ExtCommunityRouterMAC is EVPN Router's MAC of EVPN type [RFC9135]
+	ExtCommunityRouterMAC ExtCommunityKind = 0x0603
+)
+
+// extCommunityKinds defines names of recognized Extended Community kinds, only these kinds are decoded
+var extCommunityKinds = map[ExtCommunityKind]string{
+	ExtCommunityAS2RouteTarget:      "route_target",
+	ExtCommunityIPv4RouteTarget:     "route_target",
+	ExtCommunityAS4RouteTarget:      "route_target",
+	ExtCommunityOSPFRouteType:       "ospf_route_type",
+	ExtCommunityColor:               "color",
+	ExtCommunityEncapsulation:       "encapsulation",
+	ExtCommunityDefaultGateway:      "default_gateway",
+	ExtCommunityMACMobility:         "mac_mobility",
+	ExtCommunityESILabel:            "esi_label",
+	ExtCommunityESImportRouteTarget: "es_import_route_target",
+	ExtCommunityRouterMAC:           "router_mac",
+}
+
+func (k ExtCommunityKind) String() string {
+	if s, ok := extCommunityKinds[k]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("unknown type %d sub-type %d", uint8(k>>8), uint8(k))
+}
+
+// OSPFRouteTypeExtCommunity defines the value of OSPF Route Type Extended Community
+type OSPFRouteTypeExtCommunity struct {
+	AreaID    string `json:"area_id"`
+	RouteType uint8  `json:"route_type"`
+	Options   uint8  `json:"options"`
+}
+
+// ColorExtCommunity defines the value of Color Extended Community, Flags carry Color-Only bits [RFC9256]
+type ColorExtCommunity struct {
+	Flags uint16 `json:"flags,omitempty"`
+	Color uint32 `json:"color"`
+}
+
+// MACMobilityExtCommunity defines the value of MAC Mobility Extended Community
+type MACMobilityExtCommunity struct {
+	Sticky         bool   `json:"sticky"`
+	SequenceNumber uint32 `json:"sequence_number"`
+}
+
+// ESILabelExtCommunity defines the value of ESI Label Extended Community, Label is the 3 octets field
+// as carried, with MPLS encapsulation the label value is in its high-order 20 bits.
+type ESILabelExtCommunity struct {
+	SingleActive bool   `json:"single_active"`
+	Label        uint32 `json:"label"`
+}
+
+// TypedExtCommunity defines an Extended Community decoded according to its type and sub-type, Kind is set
+// only for recognized kinds and exactly one of the kind specific fields is set. Unrecognized Extended
+// Communities are preserved as raw 8 octets in Value.
+type TypedExtCommunity struct {
+	Type                uint8                      `json:"type"`
+	SubType             uint8                      `json:"sub_type"`
+	Kind                string                     `json:"kind,omitempty"`
+	RouteTarget         string                     `json:"route_target,omitempty"`
+	OSPFRouteType       *OSPFRouteTypeExtCommunity `json:"ospf_route_type,omitempty"`
+	Color               *ColorExtCommunity         `json:"color,omitempty"`
+	TunnelType          *uint16                    `json:"tunnel_type,omitempty"`
+	DefaultGateway      bool                       `json:"default_gateway,omitempty"`
+	MACMobility         *MACMobilityExtCommunity   `json:"mac_mobility,omitempty"`
+	ESILabel            *ESILabelExtCommunity      `json:"esi_label,omitempty"`
+	ESImportRouteTarget string                     `json:"es_import_route_target,omitempty"`
+	RouterMAC           string                     `json:"router_mac,omitempty"`
+	Value               []byte                     `json:"value,omitempty"`
+}
+
+// UnmarshalTypedExtCommunity decodes a single 8 octets Extended Community
+func UnmarshalTypedExtCommunity(b []byte) (*TypedExtCommunity, error) {
+	if len(b) != 8 {
+		return nil, fmt.Errorf("invalid length of Extended Community, expected 8 got %d", len(b))
+	}
+	ext := &TypedExtCommunity{
+		Type:    b[0],
+		SubType: b[1],
+	}
+	kind := ExtCommunityKind(binary.BigEndian.Uint16(b[0:2]))
+	v := b[2:]
+	switch kind {
+	case ExtCommunityAS2RouteTarget:
+		ext.RouteTarget = fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(v[0:2]), binary.BigEndian.Uint32(v[2:6]))
+	case ExtCommunityIPv4RouteTarget:
+		ext.RouteTarget = fmt.Sprintf("%s:%d", net.IP(v[0:4]).To4().String(), binary.BigEndian.Uint16(v[4:6]))
+	case ExtCommunityAS4RouteTarget:
+		ext.RouteTarget = fmt.Sprintf("%d:%d", binary.BigEndian.Uint32(v[0:4]), binary.BigEndian.Uint16(v[4:6]))
+	case ExtCommunityOSPFRouteType:
+		ext.OSPFRouteType = &OSPFRouteTypeExtCommunity{
+			AreaID:    net.IP(v[0:4]).To4().String(),
+			RouteType: v[4],
+			Options:   v[5],
+		}
+	case ExtCommunityColor:
+		ext.Color = &ColorExtCommunity{
+			Flags: binary.BigEndian.Uint16(v[0:2]),
+			Color: binary.BigEndian.Uint32(v[2:6]),
+		}
+	case ExtCommunityEncapsulation:
+		tt := binary.BigEndian.Uint16(v[4:6])
+		ext.TunnelType = &tt
+	case ExtCommunityDefaultGateway:
+		ext.DefaultGateway = true
+	case ExtCommunityMACMobility:
+		ext.MACMobility = &MACMobilityExtCommunity{
+			Sticky:         v[0]&0x01 == 0x01,
+			SequenceNumber: binary.BigEndian.Uint32(v[2:6]),
+		}
+	case ExtCommunityESILabel:
+		ext.ESILabel = &ESILabelExtCommunity{
+			SingleActive: v[0]&0x01 == 0x01,
+			Label:        uint32(v[3])<<16 | uint32(v[4])<<8 | uint32(v[5]),
+		}
+	case ExtCommunityESImportRouteTarget:
+		ext.ESImportRouteTarget = net.HardwareAddr(v).String()
+	case ExtCommunityRouterMAC:
+		ext.RouterMAC = net.HardwareAddr(v).String()
+	default:
+		ext.Value = make([]byte, 8)
+		copy(ext.Value, b)
+		return ext, nil
+	}
+	ext.Kind = kind.String()
+
+	return ext, nil
+}
+
+// UnmarshalTypedExtCommunities decodes all Extended Communities of Extended Communities attribute
+func UnmarshalTypedExtCommunities(b []byte) ([]TypedExtCommunity, error) {
+	if len(b)%8 != 0 {
+		return nil, fmt.Errorf("invalid length of Extended Communities attribute %d, expected multiple of 8", len(b))
+	}
+	exts := make([]TypedExtCommunity, 0, len(b)/8)
+	for p := 0; p < len(b); p += 8 {
+		ext, err := UnmarshalTypedExtCommunity(b[p : p+8])
+		if err != nil {
+			return nil, err
+		}
+		exts = append(exts, *ext)
+	}
+
+	return exts, nil
+}
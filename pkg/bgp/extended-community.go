@@ -67,6 +67,9 @@ func makeExtCommunity(b []byte) (*ExtCommunity, error) {
 
 // UnmarshalBGPExtCommunity builds a slice of Extended Communities
 func UnmarshalBGPExtCommunity(b []byte) ([]ExtCommunity, error) {
+	if len(b)%8 != 0 {
+		return nil, fmt.Errorf("invalid length of Extended Communities attribute %d, expected multiple of 8", len(b))
+	}
 	exts := make([]ExtCommunity, 0)
 	for p := 0; p < len(b); {
 		if glog.V(6) {
@@ -232,11 +235,13 @@ var nonTransAS2SubTypes = map[uint8]string{
 // 0x07               Flow spec traffic-action (Use of the "Value" field is defined in the "Traffic Action Fields" registry)
 // 0x08               Flow spec redirect
 // 0x09               Flow spec traffic-remarking
+// 0x0c               Flow spec traffic-rate-packets
 var flowspecSubTypes = map[uint8]string{
 	0x6: CPFlowspecTrafficRate,
 	0x7: CPFlowspecTrafficAction,
 	0x8: CPFlowspecRedirect,
 	0x9: CPFlowspecTrafficRemarking,
+	0xc: CPFlowspecTrafficRatePackets,
 }
 
 func getSubType(m map[uint8]string, subType uint8) string {
@@ -333,13 +338,16 @@ func type80(subType uint8, value []byte) string {
 		switch subType {
 		case 0x06:
 			s = fmt.Sprintf("AS: %d Rate: %d bps", binary.BigEndian.Uint16(value[:2]), uint32(math.Float32frombits(binary.BigEndian.Uint32(value[2:])))*8)
+		case 0x07:
+			// The last bit is Terminal Action bit, the bit before it is Sample bit, rfc8955
+			s = fmt.Sprintf("Sample: %t Terminal: %t", value[5]&0x02 == 0x02, value[5]&0x01 == 0x01)
 		case 0x08:
 			s = fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(value[0:2]), binary.BigEndian.Uint32(value[2:]))
 		case 0x09:
-			fallthrough
-		case 0x07:
-			// TODO (sbezverk) add corresponding transformation actions
-			fallthrough
+			// DSCP value is carried in 6 least significant bits, rfc8955
+			s = fmt.Sprintf("DSCP: %d", value[5]&0x3f)
+		case 0x0c:
+			s = fmt.Sprintf("AS: %d Rate: %d pps", binary.BigEndian.Uint16(value[:2]), uint32(math.Float32frombits(binary.BigEndian.Uint32(value[2:]))))
 		default:
 			s = tools.MessageHex(value)
 		}
@@ -1,6 +1,7 @@
 package bgp
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -26,6 +27,36 @@ func TestExtendedCommunity(t *testing.T) {
 			input:  []byte{0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
 			expect: "flowspec=redirect_to_ip_next_hop",
 		},
+		{
+			name:   "flowspec traffic rate",
+			input:  []byte{0x80, 0x06, 0xfd, 0xe8, 0x47, 0x43, 0x50, 0x00},
+			expect: "flowspec-traffic-rate=AS: 65000 Rate: 400000 bps",
+		},
+		{
+			name:   "flowspec traffic action",
+			input:  []byte{0x80, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03},
+			expect: "flowspec-traffic-action=Sample: true Terminal: true",
+		},
+		{
+			name:   "flowspec redirect as2",
+			input:  []byte{0x80, 0x08, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64},
+			expect: "flowspec-redirect=65000:100",
+		},
+		{
+			name:   "flowspec redirect ipv4",
+			input:  []byte{0x81, 0x08, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x64},
+			expect: "flowspec-redirect=10.0.0.1:100",
+		},
+		{
+			name:   "flowspec traffic remarking",
+			input:  []byte{0x80, 0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2e},
+			expect: "flowspec-traffic-remarking=DSCP: 46",
+		},
+		{
+			name:   "flowspec traffic rate packets",
+			input:  []byte{0x80, 0x0c, 0x00, 0x00, 0x44, 0x7a, 0x00, 0x00},
+			expect: "flowspec-traffic-rate-packets=AS: 0 Rate: 1000 pps",
+		},
 		{
 			name:   "type 6 rmac",
 			input:  []byte{0x06, 0x03, 0x0c, 0x03, 0x00, 0x00, 0x1b, 0x08},
@@ -45,3 +76,149 @@ func TestExtendedCommunity(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalTypedExtCommunity(t *testing.T) {
+	vxlan := uint16(TunnelTypeVXLAN)
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect *TypedExtCommunity
+	}{
+		{
+			name:  "mac mobility sticky",
+			input: []byte{0x06, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x05},
+			expect: &TypedExtCommunity{
+				Type:        0x06,
+				SubType:     0x00,
+				Kind:        "mac_mobility",
+				MACMobility: &MACMobilityExtCommunity{Sticky: true, SequenceNumber: 5},
+			},
+		},
+		{
+			name:  "color",
+			input: []byte{0x03, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64},
+			expect: &TypedExtCommunity{
+				Type:    0x03,
+				SubType: 0x0b,
+				Kind:    "color",
+				Color:   &ColorExtCommunity{Color: 100},
+			},
+		},
+		{
+			name:  "color with color-only bits",
+			input: []byte{0x03, 0x0b, 0x40, 0x00, 0x00, 0x01, 0x00, 0x00},
+			expect: &TypedExtCommunity{
+				Type:    0x03,
+				SubType: 0x0b,
+				Kind:    "color",
+				Color:   &ColorExtCommunity{Flags: 0x4000, Color: 65536},
+			},
+		},
+		{
+			name:  "ipv4 route target",
+			input: []byte{0x01, 0x02, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x64},
+			expect: &TypedExtCommunity{
+				Type:        0x01,
+				SubType:     0x02,
+				Kind:        "route_target",
+				RouteTarget: "10.0.0.1:100",
+			},
+		},
+		{
+			name:  "encapsulation vxlan",
+			input: []byte{0x03, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08},
+			expect: &TypedExtCommunity{
+				Type:       0x03,
+				SubType:    0x0c,
+				Kind:       "encapsulation",
+				TunnelType: &vxlan,
+			},
+		},
+		{
+			name:  "esi label single-active",
+			input: []byte{0x06, 0x01, 0x01, 0x00, 0x00, 0x00, 0x3e, 0x80},
+			expect: &TypedExtCommunity{
+				Type:     0x06,
+				SubType:  0x01,
+				Kind:     "esi_label",
+				ESILabel: &ESILabelExtCommunity{SingleActive: true, Label: 16000},
+			},
+		},
+		{
+			name:  "es-import route target",
+			input: []byte{0x06, 0x02, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+			expect: &TypedExtCommunity{
+				Type:                0x06,
+				SubType:             0x02,
+				Kind:                "es_import_route_target",
+				ESImportRouteTarget: "00:11:22:33:44:55",
+			},
+		},
+		{
+			name:  "unrecognized is preserved",
+			input: []byte{0x03, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			expect: &TypedExtCommunity{
+				Type:    0x03,
+				SubType: 0x14,
+				Value:   []byte{0x03, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			},
+		},
+		{
+			name:  "invalid length",
+			input: []byte{0x06, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalTypedExtCommunity(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected extended community %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalAttrTypedExtCommunities(t *testing.T) {
+	// Base attributes with ORIGIN and EXTENDED COMMUNITIES attribute carrying MAC Mobility and Color
+	input := []byte{
+		0x40, 0x01, 0x01, 0x00,
+		0xc0, 0x10, 0x10,
+		0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+		0x03, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64,
+	}
+	got, err := UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	expect := []TypedExtCommunity{
+		{Type: 0x06, SubType: 0x00, Kind: "mac_mobility", MACMobility: &MACMobilityExtCommunity{SequenceNumber: 2}},
+		{Type: 0x03, SubType: 0x0b, Kind: "color", Color: &ColorExtCommunity{Color: 100}},
+	}
+	if !reflect.DeepEqual(got.ExtCommunities, expect) {
+		t.Fatalf("expected extended communities %+v, got %+v", expect, got.ExtCommunities)
+	}
+	if !reflect.DeepEqual(got.ExtCommunityList, []string{"macmob=0:2", "color=100"}) {
+		t.Fatalf("unexpected extended community list %+v", got.ExtCommunityList)
+	}
+
+	// Malformed EXTENDED COMMUNITIES attribute of length 7 is skipped
+	input = []byte{0x40, 0x01, 0x01, 0x00, 0xc0, 0x10, 0x07, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	got, err = UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if got.ExtCommunities != nil || got.ExtCommunityList != nil {
+		t.Fatalf("expected malformed extended communities to be skipped, got %+v %+v", got.ExtCommunities, got.ExtCommunityList)
+	}
+}
@@ -0,0 +1,48 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// IPv6ExtCommunity defines IPv6 Address Specific Extended Community https://tools.ietf.org/html/rfc5701,
+// Type is 0x00 for transitive and 0x40 for non-transitive communities.
+type IPv6ExtCommunity struct {
+	Type        uint8  `json:"type"`
+	SubType     uint8  `json:"sub_type"`
+	GlobalAdmin string `json:"global_admin"`
+	LocalAdmin  uint16 `json:"local_admin"`
+}
+
+func makeIPv6ExtCommunity(b []byte) (*IPv6ExtCommunity, error) {
+	if len(b) != 20 {
+		return nil, fmt.Errorf("invalid length expected 20 got %d", len(b))
+	}
+	ext := IPv6ExtCommunity{
+		Type:        b[0],
+		SubType:     b[1],
+		GlobalAdmin: net.IP(b[2:18]).To16().String(),
+		LocalAdmin:  binary.BigEndian.Uint16(b[18:20]),
+	}
+
+	return &ext, nil
+}
+
+// UnmarshalBGPIPv6ExtCommunity builds a slice of IPv6 Address Specific Extended Communities
+func UnmarshalBGPIPv6ExtCommunity(b []byte) ([]IPv6ExtCommunity, error) {
+	if len(b)%20 != 0 {
+		return nil, fmt.Errorf("invalid length of IPv6 Address Specific Extended Communities attribute %d, expected multiple of 20", len(b))
+	}
+	exts := make([]IPv6ExtCommunity, 0)
+	for p := 0; p < len(b); {
+		ext, err := makeIPv6ExtCommunity(b[p : p+20])
+		if err != nil {
+			return nil, err
+		}
+		p += 20
+		exts = append(exts, *ext)
+	}
+
+	return exts, nil
+}
@@ -0,0 +1,89 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalBGPIPv6ExtCommunity(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect []IPv6ExtCommunity
+	}{
+		{
+			name: "ipv6 route target",
+			input: []byte{
+				0x00, 0x02,
+				0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				0x00, 0x64,
+			},
+			expect: []IPv6ExtCommunity{
+				{Type: 0x00, SubType: 0x02, GlobalAdmin: "2001:db8::1", LocalAdmin: 100},
+			},
+		},
+		{
+			name:   "empty attribute",
+			input:  []byte{},
+			expect: []IPv6ExtCommunity{},
+		},
+		{
+			name: "length is not multiple of 20",
+			input: []byte{
+				0x00, 0x02,
+				0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			},
+			fail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalBGPIPv6ExtCommunity(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected ipv6 extended communities %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalAttrIPv6ExtCommunities(t *testing.T) {
+	// Base attributes with ORIGIN and IPV6_EXT_COMMUNITIES attribute carrying a single Route Target
+	input := []byte{
+		0x40, 0x01, 0x01, 0x00,
+		0xc0, 0x19, 0x14,
+		0x00, 0x02,
+		0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0xfd, 0xe8,
+	}
+	got, err := UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	expect := []IPv6ExtCommunity{{Type: 0x00, SubType: 0x02, GlobalAdmin: "2001:db8::1", LocalAdmin: 65000}}
+	if !reflect.DeepEqual(got.IPv6ExtCommunities, expect) {
+		t.Fatalf("expected ipv6 extended communities %+v, got %+v", expect, got.IPv6ExtCommunities)
+	}
+
+	// Malformed IPV6_EXT_COMMUNITIES attribute of length 8 is skipped
+	input = []byte{0x40, 0x01, 0x01, 0x00, 0xc0, 0x19, 0x08, 0x00, 0x02, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00}
+	got, err = UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if got.Origin != "igp" {
+		t.Fatalf("expected origin igp, got %s", got.Origin)
+	}
+	if got.IPv6ExtCommunities != nil {
+		t.Fatalf("expected malformed ipv6 extended communities to be skipped, got %+v", got.IPv6ExtCommunities)
+	}
+}
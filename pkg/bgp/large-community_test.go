@@ -0,0 +1,79 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalBGPLgCommunity(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect []LgCommunity
+		list   []string
+	}{
+		{
+			name: "two large communities",
+			input: []byte{
+				0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0xd3,
+				0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			},
+			expect: []LgCommunity{
+				{GlobalAdmin: 34872, LocalData1: 10, LocalData2: 211},
+				{GlobalAdmin: 4294967295, LocalData1: 0, LocalData2: 1},
+			},
+			list: []string{"34872:10:211", "4294967295:0:1"},
+		},
+		{
+			name:   "empty attribute",
+			input:  []byte{},
+			expect: []LgCommunity{},
+			list:   []string{},
+		},
+		{
+			name:  "length is not multiple of 12",
+			input: []byte{0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalBGPLgCommunity(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected large communities %+v, got %+v", tt.expect, got)
+			}
+			list := make([]string, len(got))
+			for i, lg := range got {
+				list[i] = lg.String()
+			}
+			if !reflect.DeepEqual(list, tt.list) {
+				t.Fatalf("expected large communities %+v, got %+v", tt.list, list)
+			}
+		})
+	}
+}
+
+func TestUnmarshalAttrLgCommunitiesMalformed(t *testing.T) {
+	// Base attributes with ORIGIN and malformed LARGE_COMMUNITY attribute of length 10
+	input := []byte{0x40, 0x01, 0x01, 0x00, 0xc0, 0x20, 0x0a, 0x00, 0x00, 0x88, 0x38, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00}
+	got, err := UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if got.Origin != "igp" {
+		t.Fatalf("expected origin igp, got %s", got.Origin)
+	}
+	if got.LgCommunities != nil || got.LgCommunityList != nil {
+		t.Fatalf("expected malformed large communities to be skipped, got %+v %+v", got.LgCommunities, got.LgCommunityList)
+	}
+}
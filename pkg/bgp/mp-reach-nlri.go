@@ -34,6 +34,11 @@ func (mp *MPReachNLRI) GetAFISAFIType() int {
 	return NLRIMessageType(mp.AddressFamilyID, mp.SubAddressFamilyID)
 }
 
+// GetAFISAFI returns AFI and SAFI of the NLRI
+func (mp *MPReachNLRI) GetAFISAFI() (uint16, uint8) {
+	return mp.AddressFamilyID, mp.SubAddressFamilyID
+}
+
 // IsIPv6NLRI return true if NLRI is for IPv6 address family
 func (mp *MPReachNLRI) IsIPv6NLRI() bool {
 	return mp.AddressFamilyID == 2
@@ -155,6 +160,22 @@ func (mp *MPReachNLRI) GetNLRIUnicast() (*base.MPNLRI, error) {
 	return nil, fmt.Errorf("not found")
 }
 
+// GetNLRIMulticast check for presense of NLRI AFI 1 or 2 and SAFI 2 in the NLRI 14 NLRI data and if exists, instantiate Multicast object,
+// multicast NLRI is encoded the same way as unicast NLRI.
+func (mp *MPReachNLRI) GetNLRIMulticast() (*base.MPNLRI, error) {
+	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 2 {
+		pathID := mp.addPath[NLRIMessageType(mp.AddressFamilyID, mp.SubAddressFamilyID)]
+		nlri, err := unicast.UnmarshalUnicastNLRI(mp.NLRI, pathID)
+		if err != nil {
+			return nil, err
+		}
+		return nlri, nil
+	}
+
+	// TODO return new type of errors to be able to check for the code
+	return nil, fmt.Errorf("not found")
+}
+
 // GetNLRILU check for presense of NLRI EVPN AFI 1 or 2  and SAFI 4 in the NLRI 14 NLRI data and if exists, instantiate Unicast object
 func (mp *MPReachNLRI) GetNLRILU() (*base.MPNLRI, error) {
 	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 4 {
@@ -170,9 +191,12 @@ func (mp *MPReachNLRI) GetNLRILU() (*base.MPNLRI, error) {
 	return nil, fmt.Errorf("not found")
 }
 
-// GetFlowspecNLRI checks for presense of NLRI 133 IPv4 Flowspec in the NLRI 14 NLRI data and if exists, instantiate NLRI object
+// GetFlowspecNLRI checks for presense of NLRI 133 IPv4 or IPv6 Flowspec in the NLRI 14 NLRI data and if exists, instantiate NLRI object
 func (mp *MPReachNLRI) GetFlowspecNLRI() (*flowspec.NLRI, error) {
 	if mp.SubAddressFamilyID == 133 {
+		if mp.AddressFamilyID == 2 {
+			return flowspec.UnmarshalFlowspecNLRIv6(mp.NLRI)
+		}
 		return flowspec.UnmarshalFlowspecNLRI(mp.NLRI)
 	}
 
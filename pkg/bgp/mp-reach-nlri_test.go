@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/base"
 )
 
 func TestUnmarshalMPReachNLRI(t *testing.T) {
@@ -57,3 +58,68 @@ func TestUnmarshalMPReachNLRI(t *testing.T) {
 		})
 	}
 }
+
+func TestMPReachNLRIMulticast(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		nlriType int
+		nextHop  string
+		expect   *base.MPNLRI
+	}{
+		{
+			name:     "ipv4 multicast",
+			input:    []byte{0x00, 0x01, 0x02, 0x04, 0x0A, 0x00, 0x00, 0x01, 0x00, 0x08, 0x0A, 0x10, 0xC0, 0xA8},
+			nlriType: 3,
+			nextHop:  "10.0.0.1",
+			expect: &base.MPNLRI{
+				NLRI: []base.Route{
+					{Length: 8, Prefix: []byte{0x0A}},
+					{Length: 16, Prefix: []byte{0xC0, 0xA8}},
+				},
+			},
+		},
+		{
+			name: "ipv6 multicast with link local next hop",
+			input: []byte{
+				0x00, 0x02, 0x02, 0x20,
+				0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				0xFE, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				0x00,
+				0x20, 0x20, 0x01, 0x0D, 0xB8,
+			},
+			nlriType: 4,
+			nextHop:  "2001:db8::1,fe80::1",
+			expect: &base.MPNLRI{
+				NLRI: []base.Route{
+					{Length: 32, Prefix: []byte{0x20, 0x01, 0x0D, 0xB8}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mp, err := UnmarshalMPReachNLRI(tt.input, false, map[int]bool{})
+			if err != nil {
+				t.Fatalf("failed to unmarshal MP Reach NLRI with error: %+v", err)
+			}
+			if mp.GetAFISAFIType() != tt.nlriType {
+				t.Errorf("expected NLRI type %d, got %d", tt.nlriType, mp.GetAFISAFIType())
+			}
+			if mp.GetNextHop() != tt.nextHop {
+				t.Errorf("expected next hop %s, got %s", tt.nextHop, mp.GetNextHop())
+			}
+			if _, err := mp.GetNLRIUnicast(); err == nil {
+				t.Errorf("multicast NLRI must not be decoded as unicast")
+			}
+			nlri, err := mp.GetNLRIMulticast()
+			if err != nil {
+				t.Fatalf("failed to get multicast NLRI with error: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.expect, nlri) {
+				t.Logf("differences: %+v", deep.Equal(tt.expect, nlri))
+				t.Fatal("the expected object does not match the actual")
+			}
+		})
+	}
+}
@@ -28,6 +28,11 @@ func (mp *MPUnReachNLRI) GetAFISAFIType() int {
 	return NLRIMessageType(mp.AddressFamilyID, mp.SubAddressFamilyID)
 }
 
+// GetAFISAFI returns AFI and SAFI of the NLRI
+func (mp *MPUnReachNLRI) GetAFISAFI() (uint16, uint8) {
+	return mp.AddressFamilyID, mp.SubAddressFamilyID
+}
+
 // IsIPv6NLRI return true if NLRI is for IPv6 address family
 func (mp *MPUnReachNLRI) IsIPv6NLRI() bool {
 	return mp.AddressFamilyID == 2
@@ -72,9 +77,9 @@ func (mp *MPUnReachNLRI) GetNLRI73() (*srpolicy.NLRI73, error) {
 	return nil, fmt.Errorf("not found")
 }
 
-// GetNLRIL3VPN check for presense of NLRI L3VPN AFI 1 and SAFI 128 in the NLRI 14 NLRI data and if exists, instantiate L3VPN object
+// GetNLRIL3VPN check for presense of NLRI L3VPN AFI 1 or 2 and SAFI 128 in the NLRI 15 NLRI data and if exists, instantiate L3VPN object
 func (mp *MPUnReachNLRI) GetNLRIL3VPN() (*base.MPNLRI, error) {
-	if mp.AddressFamilyID == 1 && mp.SubAddressFamilyID == 128 {
+	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 128 {
 		pathID := mp.addPath[NLRIMessageType(mp.AddressFamilyID, mp.SubAddressFamilyID)]
 		nlri, err := l3vpn.UnmarshalL3VPNNLRI(mp.WithdrawnRoutes, pathID)
 		if err != nil {
@@ -116,6 +121,22 @@ func (mp *MPUnReachNLRI) GetNLRIUnicast() (*base.MPNLRI, error) {
 	return nil, fmt.Errorf("not found")
 }
 
+// GetNLRIMulticast check for presense of NLRI AFI 1 or 2 and SAFI 2 in the NLRI 15 NLRI data and if exists, instantiate Multicast object,
+// multicast NLRI is encoded the same way as unicast NLRI.
+func (mp *MPUnReachNLRI) GetNLRIMulticast() (*base.MPNLRI, error) {
+	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 2 {
+		pathID := mp.addPath[NLRIMessageType(mp.AddressFamilyID, mp.SubAddressFamilyID)]
+		nlri, err := unicast.UnmarshalUnicastNLRI(mp.WithdrawnRoutes, pathID)
+		if err != nil {
+			return nil, err
+		}
+		return nlri, nil
+	}
+
+	// TODO return new type of errors to be able to check for the code
+	return nil, fmt.Errorf("not found")
+}
+
 // GetNLRILU check for presense of NLRI EVPN AFI 1 or 2  and SAFI 4 in the NLRI 14 NLRI data and if exists, instantiate Unicast object
 func (mp *MPUnReachNLRI) GetNLRILU() (*base.MPNLRI, error) {
 	if (mp.AddressFamilyID == 1 || mp.AddressFamilyID == 2) && mp.SubAddressFamilyID == 4 {
@@ -131,9 +152,12 @@ func (mp *MPUnReachNLRI) GetNLRILU() (*base.MPNLRI, error) {
 	return nil, fmt.Errorf("not found")
 }
 
-// GetFlowspecNLRI checks for presense of NLRI 133 IPv4 Flowspec in the NLRI 15 NLRI data and if exists, instantiate NLRI object
+// GetFlowspecNLRI checks for presense of NLRI 133 IPv4 or IPv6 Flowspec in the NLRI 15 NLRI data and if exists, instantiate NLRI object
 func (mp *MPUnReachNLRI) GetFlowspecNLRI() (*flowspec.NLRI, error) {
 	if mp.SubAddressFamilyID == 133 {
+		if mp.AddressFamilyID == 2 {
+			return flowspec.UnmarshalFlowspecNLRIv6(mp.WithdrawnRoutes)
+		}
 		return flowspec.UnmarshalFlowspecNLRI(mp.WithdrawnRoutes)
 	}
 
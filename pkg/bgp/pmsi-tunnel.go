@@ -0,0 +1,126 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+const (
+	// PMSITunnelNoInfo defines no tunnel information present tunnel type, rfc6514
+	PMSITunnelNoInfo = 0
+	// PMSITunnelRSVPTEP2MP defines RSVP-TE P2MP LSP tunnel type, rfc6514
+	PMSITunnelRSVPTEP2MP = 1
+	// PMSITunnelMLDPP2MP defines mLDP P2MP LSP tunnel type, rfc6514
+	PMSITunnelMLDPP2MP = 2
+	// PMSITunnelPIMSSM defines PIM-SSM Tree tunnel type, rfc6514
+	PMSITunnelPIMSSM = 3
+	// PMSITunnelPIMSM defines PIM-SM Tree tunnel type, rfc6514
+	PMSITunnelPIMSM = 4
+	// PMSITunnelBIDIRPIM defines BIDIR-PIM Tree tunnel type, rfc6514
+	PMSITunnelBIDIRPIM = 5
+	// PMSITunnelIngressReplication defines Ingress Replication tunnel type, rfc6514
+	PMSITunnelIngressReplication = 6
+	// PMSITunnelMLDPMP2MP defines mLDP MP2MP LSP tunnel type, rfc6514
+	PMSITunnelMLDPMP2MP = 7
+)
+
+// PMSITunnelLeafInfoRequired defines Leaf Information Required flag of PMSI Tunnel attribute
+const PMSITunnelLeafInfoRequired = 0x01
+
+// PMSITunnel defines the value of PMSI Tunnel attribute, rfc6514. Tunnel Identifier is decoded according
+// to the tunnel type, Tunnel Identifier of unknown tunnel types is carried as is. Label is the MPLS label
+// carried in the high-order 20 bits of the label field, VNI is the whole 24 bits label field carrying
+// VNI of VXLAN encapsulation, rfc8365.
+type PMSITunnel struct {
+	Flags            uint8  `json:"flags"`
+	LeafInfoRequired bool   `json:"leaf_info_required"`
+	TunnelType       uint8  `json:"tunnel_type"`
+	Label            uint32 `json:"label"`
+	VNI              uint32 `json:"vni"`
+	// Tunnel endpoint of Ingress Replication
+	TunnelEndpoint net.IP `json:"tunnel_endpoint,omitempty"`
+	// Sender Address and P-Multicast Group of PIM trees
+	SenderAddress net.IP `json:"sender_address,omitempty"`
+	GroupAddress  net.IP `json:"group_address,omitempty"`
+	// SESSION Object of RSVP-TE P2MP LSP, rfc4875
+	P2MPID           uint32 `json:"p2mp_id,omitempty"`
+	TunnelID         uint16 `json:"tunnel_id,omitempty"`
+	ExtendedTunnelID net.IP `json:"extended_tunnel_id,omitempty"`
+	// P2MP or MP2MP FEC Element of mLDP LSP, rfc6388
+	RootNodeAddress net.IP `json:"root_node_address,omitempty"`
+	OpaqueValue     []byte `json:"opaque_value,omitempty"`
+	// Tunnel Identifier of unknown tunnel types
+	TunnelIdentifier []byte `json:"tunnel_identifier,omitempty"`
+}
+
+// UnmarshalPMSITunnel builds PMSITunnel object from PMSI Tunnel attribute
+func UnmarshalPMSITunnel(b []byte) (*PMSITunnel, error) {
+	// Flags 1 byte, Tunnel Type 1 byte and MPLS Label 3 bytes
+	if len(b) < 5 {
+		return nil, fmt.Errorf("not enough bytes to unmarshal PMSI Tunnel attribute")
+	}
+	pt := &PMSITunnel{
+		Flags:      b[0],
+		TunnelType: b[1],
+		VNI:        uint32(b[2])<<16 | uint32(b[3])<<8 | uint32(b[4]),
+	}
+	pt.LeafInfoRequired = pt.Flags&PMSITunnelLeafInfoRequired != 0
+	pt.Label = pt.VNI >> 4
+	id := b[5:]
+	switch pt.TunnelType {
+	case PMSITunnelNoInfo:
+	case PMSITunnelIngressReplication:
+		if len(id) != 4 && len(id) != 16 {
+			return nil, fmt.Errorf("invalid length of Ingress Replication tunnel endpoint %d", len(id))
+		}
+		pt.TunnelEndpoint = makeIP(id)
+	case PMSITunnelPIMSSM, PMSITunnelPIMSM, PMSITunnelBIDIRPIM:
+		if len(id) != 8 && len(id) != 32 {
+			return nil, fmt.Errorf("invalid length of PIM tree tunnel identifier %d", len(id))
+		}
+		pt.SenderAddress = makeIP(id[:len(id)/2])
+		pt.GroupAddress = makeIP(id[len(id)/2:])
+	case PMSITunnelRSVPTEP2MP:
+		// P2MP ID 4 bytes, reserved 2 bytes, Tunnel ID 2 bytes and Extended Tunnel ID
+		if len(id) != 12 && len(id) != 24 {
+			return nil, fmt.Errorf("invalid length of RSVP-TE P2MP LSP tunnel identifier %d", len(id))
+		}
+		pt.P2MPID = binary.BigEndian.Uint32(id[:4])
+		pt.TunnelID = binary.BigEndian.Uint16(id[6:8])
+		pt.ExtendedTunnelID = makeIP(id[8:])
+	case PMSITunnelMLDPP2MP, PMSITunnelMLDPMP2MP:
+		// FEC Element Type 1 byte, Address Family 2 bytes, Address Length 1 byte, Root Node Address,
+		// Opaque Length 2 bytes and Opaque Value
+		if len(id) < 4 {
+			return nil, fmt.Errorf("not enough bytes to unmarshal mLDP FEC Element")
+		}
+		l := int(id[3])
+		if l != 4 && l != 16 {
+			return nil, fmt.Errorf("invalid length of mLDP Root Node Address %d", l)
+		}
+		if len(id) < 4+l+2 {
+			return nil, fmt.Errorf("not enough bytes to unmarshal mLDP FEC Element")
+		}
+		pt.RootNodeAddress = makeIP(id[4 : 4+l])
+		ol := int(binary.BigEndian.Uint16(id[4+l : 4+l+2]))
+		if len(id) != 4+l+2+ol {
+			return nil, fmt.Errorf("invalid length of mLDP Opaque Value %d", ol)
+		}
+		pt.OpaqueValue = make([]byte, ol)
+		copy(pt.OpaqueValue, id[4+l+2:])
+	default:
+		pt.TunnelIdentifier = make([]byte, len(id))
+		copy(pt.TunnelIdentifier, id)
+	}
+
+	return pt, nil
+}
+
+// makeIP returns a copy of IPv4 or IPv6 address b
+func makeIP(b []byte) net.IP {
+	ip := make(net.IP, len(b))
+	copy(ip, b)
+
+	return ip
+}
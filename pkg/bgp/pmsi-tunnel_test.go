@@ -0,0 +1,120 @@
+package bgp
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalPMSITunnel(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect *PMSITunnel
+	}{
+		{
+			name:  "ingress replication ipv4",
+			input: []byte{0x00, 0x06, 0x00, 0x27, 0x10, 0x0a, 0x00, 0x00, 0x01},
+			expect: &PMSITunnel{
+				TunnelType:     PMSITunnelIngressReplication,
+				Label:          625,
+				VNI:            10000,
+				TunnelEndpoint: net.IP{10, 0, 0, 1},
+			},
+		},
+		{
+			name: "ingress replication ipv6",
+			input: []byte{0x00, 0x06, 0x00, 0x00, 0x00,
+				0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			expect: &PMSITunnel{
+				TunnelType:     PMSITunnelIngressReplication,
+				TunnelEndpoint: net.ParseIP("2001:db8::1"),
+			},
+		},
+		{
+			name:  "no tunnel information with leaf information required",
+			input: []byte{0x01, 0x00, 0x00, 0x00, 0x00},
+			expect: &PMSITunnel{
+				Flags:            0x01,
+				LeafInfoRequired: true,
+				TunnelType:       PMSITunnelNoInfo,
+			},
+		},
+		{
+			name:  "pim-ssm tree",
+			input: []byte{0x00, 0x03, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x01, 0xe8, 0x01, 0x01, 0x01},
+			expect: &PMSITunnel{
+				TunnelType:    PMSITunnelPIMSSM,
+				SenderAddress: net.IP{10, 0, 0, 1},
+				GroupAddress:  net.IP{232, 1, 1, 1},
+			},
+		},
+		{
+			name:  "rsvp-te p2mp lsp",
+			input: []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, 0x00, 0x00, 0x00, 0x0a, 0x0a, 0x00, 0x00, 0x01},
+			expect: &PMSITunnel{
+				TunnelType:       PMSITunnelRSVPTEP2MP,
+				P2MPID:           100,
+				TunnelID:         10,
+				ExtendedTunnelID: net.IP{10, 0, 0, 1},
+			},
+		},
+		{
+			name:  "mldp p2mp lsp",
+			input: []byte{0x00, 0x02, 0x00, 0x00, 0x00, 0x06, 0x00, 0x01, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x02, 0xab, 0xcd},
+			expect: &PMSITunnel{
+				TunnelType:      PMSITunnelMLDPP2MP,
+				RootNodeAddress: net.IP{10, 0, 0, 1},
+				OpaqueValue:     []byte{0xab, 0xcd},
+			},
+		},
+		{
+			name:  "unknown tunnel type",
+			input: []byte{0x00, 0x0b, 0x00, 0x00, 0x00, 0x01, 0x02, 0x03},
+			expect: &PMSITunnel{
+				TunnelType:       11,
+				TunnelIdentifier: []byte{0x01, 0x02, 0x03},
+			},
+		},
+		{
+			name:  "ingress replication invalid endpoint",
+			input: []byte{0x00, 0x06, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "not enough bytes",
+			input: []byte{0x00, 0x06, 0x00, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalPMSITunnel(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected pmsi tunnel %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestUnmarshalBaseAttributesPMSITunnel(t *testing.T) {
+	// ORIGIN and PMSI Tunnel of Ingress Replication to 10.0.0.1 with VNI 10000
+	input := []byte{
+		0x40, 0x01, 0x01, 0x00,
+		0xc0, 0x16, 0x09, 0x00, 0x06, 0x00, 0x27, 0x10, 0x0a, 0x00, 0x00, 0x01,
+	}
+	got, err := UnmarshalBGPBaseAttributes(input, 4)
+	if err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	if got.PMSITunnel == nil || !got.PMSITunnel.TunnelEndpoint.Equal(net.IP{10, 0, 0, 1}) || got.PMSITunnel.VNI != 10000 {
+		t.Fatalf("expected ingress replication to 10.0.0.1 with vni 10000, got %+v", got.PMSITunnel)
+	}
+}
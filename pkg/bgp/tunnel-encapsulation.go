@@ -0,0 +1,209 @@
+package bgp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+const (
+	// TunnelTypeL2TPv3 defines L2TPv3 over IP tunnel type, rfc9012
+	TunnelTypeL2TPv3 = 1
+	// TunnelTypeGRE defines GRE tunnel type, rfc9012
+	TunnelTypeGRE = 2
+	// TunnelTypeIPinIP defines IP in IP tunnel type, rfc9012
+	TunnelTypeIPinIP = 7
+	// TunnelTypeVXLAN defines VXLAN tunnel type, rfc9012
+	TunnelTypeVXLAN = 8
+	// TunnelTypeNVGRE defines NVGRE tunnel type, rfc9012
+	TunnelTypeNVGRE = 9
+	// TunnelTypeMPLS defines MPLS tunnel type, rfc9012
+	TunnelTypeMPLS = 10
+	// TunnelTypeMPLSinGRE defines MPLS in GRE tunnel type, rfc9012
+	TunnelTypeMPLSinGRE = 11
+	// TunnelTypeVXLANGPE defines VXLAN GPE tunnel type, rfc9012
+	TunnelTypeVXLANGPE = 12
+	// TunnelTypeMPLSinUDP defines MPLS in UDP tunnel type, rfc9012
+	TunnelTypeMPLSinUDP = 13
+)
+
+const (
+	// EncapsulationSubTLV defines Encapsulation sub-TLV code, rfc9012
+	EncapsulationSubTLV = 1
+	// ProtocolTypeSubTLV defines Protocol Type sub-TLV code, rfc9012
+	ProtocolTypeSubTLV = 2
+	// ColorSubTLV defines Color sub-TLV code, rfc9012
+	ColorSubTLV = 4
+	// RemoteEndpointSubTLV defines Tunnel Egress Endpoint sub-TLV code, rfc9012
+	RemoteEndpointSubTLV = 6
+	// UDPDestinationPortSubTLV defines UDP Destination Port sub-TLV code, rfc9012
+	UDPDestinationPortSubTLV = 8
+)
+
+// TunnelEncapsulation defines the value of Encapsulation sub-TLV, VNID and MAC are recovered for VXLAN and NVGRE
+// tunnels, Key for GRE and MPLS in GRE tunnels. The value of Encapsulation sub-TLV of other tunnel types is
+// carried as is.
+type TunnelEncapsulation struct {
+	VNID  *uint32 `json:"vnid,omitempty"`
+	MAC   string  `json:"mac,omitempty"`
+	Key   *uint32 `json:"key,omitempty"`
+	Value []byte  `json:"value,omitempty"`
+}
+
+// TunnelRemoteEndpoint defines the value of Tunnel Egress Endpoint sub-TLV
+type TunnelRemoteEndpoint struct {
+	AFI     uint16 `json:"afi"`
+	Address string `json:"address,omitempty"`
+}
+
+// TunnelSubTLV defines a sub-TLV which is not decoded, its value is preserved as is
+type TunnelSubTLV struct {
+	Type  uint8  `json:"type"`
+	Value []byte `json:"value,omitempty"`
+}
+
+// Tunnel defines a Tunnel TLV of Tunnel Encapsulation attribute with its sub-TLVs
+type Tunnel struct {
+	Type               uint16                `json:"type"`
+	Encapsulation      *TunnelEncapsulation  `json:"encapsulation,omitempty"`
+	ProtocolType       uint16                `json:"protocol_type,omitempty"`
+	Color              []uint32              `json:"color,omitempty"`
+	RemoteEndpoint     *TunnelRemoteEndpoint `json:"remote_endpoint,omitempty"`
+	UDPDestinationPort uint16                `json:"udp_destination_port,omitempty"`
+	SubTLVs            []*TunnelSubTLV       `json:"sub_tlvs,omitempty"`
+}
+
+// UnmarshalTunnelEncapsulation builds a list of Tunnels from Tunnel Encapsulation attribute, rfc9012
+func UnmarshalTunnelEncapsulation(b []byte) ([]*Tunnel, error) {
+	tunnels := make([]*Tunnel, 0)
+	for p := 0; p < len(b); {
+		// Tunnel Type 2 bytes and Length 2 bytes
+		if p+4 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal Tunnel TLV")
+		}
+		t := binary.BigEndian.Uint16(b[p : p+2])
+		l := int(binary.BigEndian.Uint16(b[p+2 : p+4]))
+		p += 4
+		if p+l > len(b) {
+			return nil, fmt.Errorf("invalid Tunnel TLV length %d", l)
+		}
+		tunnel, err := unmarshalTunnel(t, b[p:p+l])
+		if err != nil {
+			return nil, err
+		}
+		tunnels = append(tunnels, tunnel)
+		p += l
+	}
+
+	return tunnels, nil
+}
+
+func unmarshalTunnel(t uint16, b []byte) (*Tunnel, error) {
+	tunnel := &Tunnel{
+		Type: t,
+	}
+	for p := 0; p < len(b); {
+		if p+2 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal Tunnel sub-TLV")
+		}
+		st := b[p]
+		p++
+		// Sub-TLVs of types 0-127 have 1 byte length, of types 128-255 2 bytes length
+		var sl int
+		if st < 128 {
+			sl = int(b[p])
+			p++
+		} else {
+			if p+2 > len(b) {
+				return nil, fmt.Errorf("not enough bytes to unmarshal Tunnel sub-TLV")
+			}
+			sl = int(binary.BigEndian.Uint16(b[p : p+2]))
+			p += 2
+		}
+		if p+sl > len(b) {
+			return nil, fmt.Errorf("invalid Tunnel sub-TLV %d length %d", st, sl)
+		}
+		v := b[p : p+sl]
+		switch st {
+		case EncapsulationSubTLV:
+			tunnel.Encapsulation = unmarshalTunnelEncapsulationSubTLV(t, v)
+		case ProtocolTypeSubTLV:
+			if sl != 2 {
+				return nil, fmt.Errorf("invalid Protocol Type sub-TLV length %d", sl)
+			}
+			tunnel.ProtocolType = binary.BigEndian.Uint16(v)
+		case ColorSubTLV:
+			// Color Extended Community, Type 0x03 Sub-Type 0x0b, 2 bytes of flags and 4 bytes of color value
+			if sl != 8 {
+				return nil, fmt.Errorf("invalid Color sub-TLV length %d", sl)
+			}
+			tunnel.Color = append(tunnel.Color, binary.BigEndian.Uint32(v[4:8]))
+		case RemoteEndpointSubTLV:
+			ep, err := unmarshalTunnelRemoteEndpoint(v)
+			if err != nil {
+				return nil, err
+			}
+			tunnel.RemoteEndpoint = ep
+		case UDPDestinationPortSubTLV:
+			if sl != 2 {
+				return nil, fmt.Errorf("invalid UDP Destination Port sub-TLV length %d", sl)
+			}
+			tunnel.UDPDestinationPort = binary.BigEndian.Uint16(v)
+		default:
+			stlv := &TunnelSubTLV{
+				Type:  st,
+				Value: make([]byte, sl),
+			}
+			copy(stlv.Value, v)
+			tunnel.SubTLVs = append(tunnel.SubTLVs, stlv)
+		}
+		p += sl
+	}
+
+	return tunnel, nil
+}
+
+func unmarshalTunnelEncapsulationSubTLV(t uint16, b []byte) *TunnelEncapsulation {
+	encap := &TunnelEncapsulation{}
+	switch {
+	case (t == TunnelTypeVXLAN || t == TunnelTypeNVGRE) && len(b) == 12:
+		// V flag indicates valid VN-ID, M flag indicates valid MAC Address
+		if b[0]&0x80 == 0x80 {
+			vnid := uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+			encap.VNID = &vnid
+		}
+		if b[0]&0x40 == 0x40 {
+			encap.MAC = net.HardwareAddr(b[4:10]).String()
+		}
+	case (t == TunnelTypeGRE || t == TunnelTypeMPLSinGRE) && len(b) == 4:
+		key := binary.BigEndian.Uint32(b)
+		encap.Key = &key
+	default:
+		encap.Value = make([]byte, len(b))
+		copy(encap.Value, b)
+	}
+
+	return encap
+}
+
+func unmarshalTunnelRemoteEndpoint(b []byte) (*TunnelRemoteEndpoint, error) {
+	// Reserved 4 bytes and Address Family 2 bytes
+	if len(b) < 6 {
+		return nil, fmt.Errorf("invalid Tunnel Egress Endpoint sub-TLV length %d", len(b))
+	}
+	ep := &TunnelRemoteEndpoint{
+		AFI: binary.BigEndian.Uint16(b[4:6]),
+	}
+	addr := b[6:]
+	switch {
+	case ep.AFI == 0 && len(addr) == 0:
+	case ep.AFI == 1 && len(addr) == 4:
+		ep.Address = net.IP(addr).To4().String()
+	case ep.AFI == 2 && len(addr) == 16:
+		ep.Address = net.IP(addr).To16().String()
+	default:
+		return nil, fmt.Errorf("invalid Tunnel Egress Endpoint address family %d and address length %d", ep.AFI, len(addr))
+	}
+
+	return ep, nil
+}
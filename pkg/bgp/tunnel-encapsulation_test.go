@@ -0,0 +1,90 @@
+package bgp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestUnmarshalTunnelEncapsulation(t *testing.T) {
+	vnid := uint32(10010)
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect []*Tunnel
+	}{
+		{
+			name: "vxlan with color",
+			input: []byte{
+				0x00, 0x08, 0x00, 0x28, // Tunnel Type VXLAN, Length 40
+				0x01, 0x0c, 0xc0, 0x00, 0x27, 0x1a, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x00, // Encapsulation
+				0x04, 0x08, 0x03, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, // Color 100
+				0x06, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x01, // Remote Endpoint 10.0.0.1
+				0x08, 0x02, 0x12, 0xb5, // UDP Destination Port 4789
+			},
+			expect: []*Tunnel{
+				{
+					Type: TunnelTypeVXLAN,
+					Encapsulation: &TunnelEncapsulation{
+						VNID: &vnid,
+						MAC:  "00:11:22:33:44:55",
+					},
+					Color:              []uint32{100},
+					RemoteEndpoint:     &TunnelRemoteEndpoint{AFI: 1, Address: "10.0.0.1"},
+					UDPDestinationPort: 4789,
+				},
+			},
+		},
+		{
+			name: "truncated sub tlv",
+			input: []byte{
+				0x00, 0x0d, 0x00, 0x0b, // Tunnel Type MPLS in UDP, Length 11
+				0x09, 0x01, 0x01, // Embedded Label Handling
+				0x80, 0x00, 0x03, 0xaa, 0xbb, 0xcc, // sub-TLV with 2 bytes length
+				0x04, 0x08, // Truncated Color
+			},
+			fail: true,
+		},
+		{
+			name: "unknown sub tlvs",
+			input: []byte{
+				0x00, 0x0d, 0x00, 0x09, // Tunnel Type MPLS in UDP, Length 9
+				0x09, 0x01, 0x01, // Embedded Label Handling
+				0x80, 0x00, 0x03, 0xaa, 0xbb, 0xcc, // sub-TLV with 2 bytes length
+			},
+			expect: []*Tunnel{
+				{
+					Type: TunnelTypeMPLSinUDP,
+					SubTLVs: []*TunnelSubTLV{
+						{Type: 9, Value: []byte{0x01}},
+						{Type: 128, Value: []byte{0xaa, 0xbb, 0xcc}},
+					},
+				},
+			},
+		},
+		{
+			name:  "invalid tunnel length",
+			input: []byte{0x00, 0x08, 0x00, 0x10, 0x04, 0x08},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalTunnelEncapsulation(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("tunnels do not match the expected, differences: %+v", deep.Equal(got, tt.expect))
+			}
+		})
+	}
+}
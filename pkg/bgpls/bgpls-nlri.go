@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"strings"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/base"
@@ -86,37 +87,30 @@ func (ls *NLRI) GetNodeName() string {
 	return ""
 }
 
-// GetISISAreaID returns a string IS-IS Area Identifier TLVs
+// GetISISAreaID returns a string of IS-IS Area Identifier TLVs, multiple area identifiers are separated by comma
 func (ls *NLRI) GetISISAreaID() string {
-	var s string
+	return strings.Join(ls.GetISISAreaIDs(), ",")
+}
+
+// GetISISAreaIDs returns IS-IS Area Identifiers of the node, every IS-IS Area Identifier TLV carries one area
+// identifier, it is formatted as the first byte followed by groups of two bytes in hex separated by dot,
+// for example 49.0001.0002.
+func (ls *NLRI) GetISISAreaIDs() []string {
+	var ids []string
 	for _, tlv := range ls.LS {
-		if tlv.Type != 1027 {
+		if tlv.Type != 1027 || len(tlv.Value) == 0 {
 			continue
 		}
-		for p := 0; p < len(tlv.Value); {
-			s += fmt.Sprintf("%02x", tlv.Value[p])
-			p++
-			if p >= len(tlv.Value) {
-				break
-			}
-			s += "."
-			s += fmt.Sprintf("%02x", tlv.Value[p])
-			p++
-			if p >= len(tlv.Value) {
-				break
-			}
-			s += fmt.Sprintf("%02x", tlv.Value[p])
-			p++
-			if p >= len(tlv.Value) {
-				break
-			}
-			if p < len(tlv.Value) {
-				s += ","
-			}
-		}
-	}
-
-	return s
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%02x", tlv.Value[0]))
+		for p := 1; p < len(tlv.Value); p += 2 {
+			sb.WriteString(".")
+			sb.WriteString(fmt.Sprintf("%x", tlv.Value[p:min(p+2, len(tlv.Value))]))
+		}
+		ids = append(ids, sb.String())
+	}
+
+	return ids
 }
 
 // GetLocalIPv4RouterID returns string with local Node IPv4 router ID
@@ -362,19 +356,47 @@ func (ls *NLRI) GetAdminGroup() uint32 {
 		if tlv.Type != 1088 {
 			continue
 		}
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1088 invalid length: %d", len(tlv.Value))
+			return 0
+		}
 		return binary.BigEndian.Uint32(tlv.Value)
 	}
 
 	return 0
 }
 
-// GetTEDefaultMetric returns value of TE Default Metric
+// GetAdminGroups returns administrative groups set in Administrative group bitmask, the least significant bit
+// is group 0, rfc5305 section 3.1
+func (ls *NLRI) GetAdminGroups() []uint32 {
+	mask := ls.GetAdminGroup()
+	if mask == 0 {
+		return nil
+	}
+	groups := make([]uint32, 0)
+	for g := uint32(0); g < 32; g++ {
+		if mask&(1<<g) != 0 {
+			groups = append(groups, g)
+		}
+	}
+
+	return groups
+}
+
+// GetTEDefaultMetric returns value of TE Default Metric, rfc7752 defines 4 bytes TLV, 3 bytes TLV sent
+// by some implementations, as IS-IS TE Default Metric, is accepted as well
 func (ls *NLRI) GetTEDefaultMetric() uint32 {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1092 {
 			continue
 		}
-		return binary.BigEndian.Uint32(tlv.Value)
+		if len(tlv.Value) != 3 && len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1092 invalid length: %d", len(tlv.Value))
+			return 0
+		}
+		m := make([]byte, 4)
+		copy(m[4-len(tlv.Value):], tlv.Value)
+		return binary.BigEndian.Uint32(m)
 	}
 
 	return 0
@@ -431,26 +453,12 @@ func (ls *NLRI) GetUnreservedLinkBandwidth() []uint32 {
 
 // GetMaxLinkBandwidthKbps returns value of Maximum Link Bandwidth in kbps
 func (ls *NLRI) GetMaxLinkBandwidthKbps() uint64 {
-	for _, tlv := range ls.LS {
-		if tlv.Type != 1089 {
-			continue
-		}
-		return uint64(math.Float32frombits(binary.BigEndian.Uint32(tlv.Value)) * 8 / 1000)
-	}
-
-	return 0
+	return ls.getBandwidthKbps(1089)
 }
 
 // GetMaxReservableLinkBandwidthKbps returns value of Maximum Reservable Link Bandwidth in kbps
 func (ls *NLRI) GetMaxReservableLinkBandwidthKbps() uint64 {
-	for _, tlv := range ls.LS {
-		if tlv.Type != 1090 {
-			continue
-		}
-		return uint64(math.Float32frombits(binary.BigEndian.Uint32(tlv.Value)) * 8 / 1000)
-	}
-
-	return 0
+	return ls.getBandwidthKbps(1090)
 }
 
 // GetUnreservedLinkBandwidthKbps returns eight 64-bit number in kbps
@@ -460,13 +468,13 @@ func (ls *NLRI) GetUnreservedLinkBandwidthKbps() []uint64 {
 		if tlv.Type != 1091 {
 			continue
 		}
-                tlvLen := len(tlv.Value)
-                if tlvLen != 32 {
-                        glog.Errorf("BGP-LS TLV 1091 invalid length: %d, returning default\n", tlvLen)
-                        return unResrved
-                }
+		tlvLen := len(tlv.Value)
+		if tlvLen != 32 {
+			glog.Errorf("BGP-LS TLV 1091 invalid length: %d, returning default\n", tlvLen)
+			return unResrved
+		}
 		for i, p := 0, 0; p < tlvLen; i, p = i+1, p+4 {
-			unResrved[i] = uint64(math.Float32frombits(binary.BigEndian.Uint32(tlv.Value[p:p+4])) * 8 / 1000)
+			unResrved[i] = bandwidthKbps(tlv.Value[p : p+4])
 		}
 		return unResrved
 	}
@@ -474,18 +482,57 @@ func (ls *NLRI) GetUnreservedLinkBandwidthKbps() []uint64 {
 	return nil
 }
 
-// GetLinkProtectionType returns value of Link Protection Type
+// GetLinkProtectionType returns value of Link Protection Type, Protection Cap flags are carried
+// in the most significant byte
 func (ls *NLRI) GetLinkProtectionType() uint16 {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1093 {
 			continue
 		}
+		if len(tlv.Value) != 2 {
+			glog.Errorf("BGP-LS TLV 1093 invalid length: %d", len(tlv.Value))
+			return 0
+		}
 		return binary.BigEndian.Uint16(tlv.Value)
 	}
 
 	return 0
 }
 
+// LinkProtectionType defines Protection Cap flags of Link Protection Type TLV, rfc5307 section 1.2
+type LinkProtectionType struct {
+	ExtraTraffic    bool `json:"extra_traffic"`
+	Unprotected     bool `json:"unprotected"`
+	Shared          bool `json:"shared"`
+	Dedicated1to1   bool `json:"dedicated_1to1"`
+	Dedicated1plus1 bool `json:"dedicated_1plus1"`
+	Enhanced        bool `json:"enhanced"`
+}
+
+// GetLinkProtection returns decoded Protection Cap flags of Link Protection Type, nil is returned
+// when the TLV is not present or it is malformed
+func (ls *NLRI) GetLinkProtection() *LinkProtectionType {
+	for _, tlv := range ls.LS {
+		if tlv.Type != 1093 {
+			continue
+		}
+		if len(tlv.Value) != 2 {
+			return nil
+		}
+		f := tlv.Value[0]
+		return &LinkProtectionType{
+			ExtraTraffic:    f&0x01 == 0x01,
+			Unprotected:     f&0x02 == 0x02,
+			Shared:          f&0x04 == 0x04,
+			Dedicated1to1:   f&0x08 == 0x08,
+			Dedicated1plus1: f&0x10 == 0x10,
+			Enhanced:        f&0x20 == 0x20,
+		}
+	}
+
+	return nil
+}
+
 // GetLinkMPLSProtocolMask returns value of MPLS Protocol Mask
 func (ls *NLRI) GetLinkMPLSProtocolMask() uint8 {
 	for _, tlv := range ls.LS {
@@ -506,6 +553,10 @@ func (ls *NLRI) GetSRLG() []uint32 {
 		if tlv.Type != 1096 {
 			continue
 		}
+		if len(tlv.Value)%4 != 0 {
+			glog.Errorf("BGP-LS TLV 1096 invalid length: %d, not a multiple of 4", len(tlv.Value))
+			return nil
+		}
 		for p := 0; p < len(tlv.Value); {
 			srlg = append(srlg, binary.BigEndian.Uint32(tlv.Value[p:p+4]))
 			p += 4
@@ -615,64 +666,89 @@ func (ls *NLRI) GetSRv6SIDStructure() *srv6.SIDStructure {
 	return nil
 }
 
-// GetUnidirLinkDelay returns value of Unidirectional Link Delay
-func (ls *NLRI) GetUnidirLinkDelay() uint32 {
+// GetUnidirLinkDelay returns value of Unidirectional Link Delay in microseconds and
+// the state of Anomalous (A) flag, rfc8571
+func (ls *NLRI) GetUnidirLinkDelay() (uint32, bool) {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1114 {
 			continue
 		}
-		return binary.BigEndian.Uint32(tlv.Value)
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1114 invalid length: %d", len(tlv.Value))
+			return 0, false
+		}
+		return binary.BigEndian.Uint32(tlv.Value) & 0x00ffffff, tlv.Value[0]&0x80 == 0x80
 	}
 
-	return 0
+	return 0, false
 }
 
 // GetUnidirLinkDelayMinMax returns minimum and maximum delay values between two
-//   directly connected IGP link-state neighbors of MUnidirectional Link Delay
-func (ls *NLRI) GetUnidirLinkDelayMinMax() []uint32 {
+// directly connected IGP link-state neighbors in microseconds and the state of Anomalous (A) flag, rfc8571
+func (ls *NLRI) GetUnidirLinkDelayMinMax() ([]uint32, bool) {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1115 {
 			continue
 		}
-		return []uint32{binary.BigEndian.Uint32(tlv.Value[:4]), binary.BigEndian.Uint32(tlv.Value[4:])}
+		if len(tlv.Value) != 8 {
+			glog.Errorf("BGP-LS TLV 1115 invalid length: %d", len(tlv.Value))
+			return nil, false
+		}
+		return []uint32{binary.BigEndian.Uint32(tlv.Value[:4]) & 0x00ffffff, binary.BigEndian.Uint32(tlv.Value[4:]) & 0x00ffffff},
+			tlv.Value[0]&0x80 == 0x80
 	}
 
-	return nil
+	return nil, false
 }
 
 // GetUnidirDelayVariation returns a value of the link delay variation between two
-// directly connected IGP link-state neighbor
+// directly connected IGP link-state neighbor in microseconds, rfc8571
 func (ls *NLRI) GetUnidirDelayVariation() uint32 {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1116 {
 			continue
 		}
-		return binary.BigEndian.Uint32(tlv.Value)
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1116 invalid length: %d", len(tlv.Value))
+			return 0
+		}
+		return binary.BigEndian.Uint32(tlv.Value) & 0x00ffffff
 	}
 
 	return 0
 }
 
 // GetUnidirLinkLoss returns a value of the the loss (as a packet percentage) between two
-// directly connected IGP link-state neighbor
-func (ls *NLRI) GetUnidirLinkLoss() uint32 {
+// directly connected IGP link-state neighbor in units of 0.000003% and the state of Anomalous (A) flag, rfc8571
+func (ls *NLRI) GetUnidirLinkLoss() (uint32, bool) {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1117 {
 			continue
 		}
-		return binary.BigEndian.Uint32(tlv.Value)
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1117 invalid length: %d", len(tlv.Value))
+			return 0, false
+		}
+		return binary.BigEndian.Uint32(tlv.Value) & 0x00ffffff, tlv.Value[0]&0x80 == 0x80
 	}
 
-	return 0
+	return 0, false
 }
 
 // GetUnidirResidualBandwidth returns a value of the the residual bandwidth between two
-// directly connected IGP link-state neighbor
+// directly connected IGP link-state neighbor, the value is raw bits of IEEE floating point
+// bandwidth in bytes per second.
+//
+// Deprecated: use GetUnidirResidualBandwidthKbps.
 func (ls *NLRI) GetUnidirResidualBandwidth() uint32 {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1118 {
 			continue
 		}
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1118 invalid length: %d", len(tlv.Value))
+			return 0
+		}
 		return binary.BigEndian.Uint32(tlv.Value)
 	}
 
@@ -680,12 +756,19 @@ func (ls *NLRI) GetUnidirResidualBandwidth() uint32 {
 }
 
 // GetUnidirAvailableBandwidth returns a value of the the available bandwidth between two
-// directly connected IGP link-state neighbor
+// directly connected IGP link-state neighbor, the value is raw bits of IEEE floating point
+// bandwidth in bytes per second.
+//
+// Deprecated: use GetUnidirAvailableBandwidthKbps.
 func (ls *NLRI) GetUnidirAvailableBandwidth() uint32 {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1119 {
 			continue
 		}
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1119 invalid length: %d", len(tlv.Value))
+			return 0
+		}
 		return binary.BigEndian.Uint32(tlv.Value)
 	}
 
@@ -693,18 +776,71 @@ func (ls *NLRI) GetUnidirAvailableBandwidth() uint32 {
 }
 
 // GetUnidirUtilizedBandwidth returns a value of the the utilized bandwidth between two
-// directly connected IGP link-state neighbor
+// directly connected IGP link-state neighbor, the value is raw bits of IEEE floating point
+// bandwidth in bytes per second.
+//
+// Deprecated: use GetUnidirUtilizedBandwidthKbps.
 func (ls *NLRI) GetUnidirUtilizedBandwidth() uint32 {
 	for _, tlv := range ls.LS {
 		if tlv.Type != 1120 {
 			continue
 		}
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV 1120 invalid length: %d", len(tlv.Value))
+			return 0
+		}
 		return binary.BigEndian.Uint32(tlv.Value)
 	}
 
 	return 0
 }
 
+// GetUnidirResidualBandwidthKbps returns a value of the the residual bandwidth between two
+// directly connected IGP link-state neighbor in kbps
+func (ls *NLRI) GetUnidirResidualBandwidthKbps() uint64 {
+	return ls.getBandwidthKbps(1118)
+}
+
+// GetUnidirAvailableBandwidthKbps returns a value of the the available bandwidth between two
+// directly connected IGP link-state neighbor in kbps
+func (ls *NLRI) GetUnidirAvailableBandwidthKbps() uint64 {
+	return ls.getBandwidthKbps(1119)
+}
+
+// GetUnidirUtilizedBandwidthKbps returns a value of the the utilized bandwidth between two
+// directly connected IGP link-state neighbor in kbps
+func (ls *NLRI) GetUnidirUtilizedBandwidthKbps() uint64 {
+	return ls.getBandwidthKbps(1120)
+}
+
+// getBandwidthKbps converts the bandwidth of TLV t, encoded as IEEE floating point in bytes per second, to kbps
+func (ls *NLRI) getBandwidthKbps(t uint16) uint64 {
+	for _, tlv := range ls.LS {
+		if tlv.Type != t {
+			continue
+		}
+		if len(tlv.Value) != 4 {
+			glog.Errorf("BGP-LS TLV %d invalid length: %d", t, len(tlv.Value))
+			return 0
+		}
+		return bandwidthKbps(tlv.Value)
+	}
+
+	return 0
+}
+
+// bandwidthKbps converts 4 bytes IEEE floating point bandwidth in bytes per second to kbps rounded to the nearest
+// integer, the conversion is done in double precision to keep the precision of the value. Negative, infinite and
+// NaN values are invalid, 0 is returned for them.
+func bandwidthKbps(b []byte) uint64 {
+	bw := float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+	if math.IsNaN(bw) || math.IsInf(bw, 0) || bw < 0 {
+		return 0
+	}
+
+	return uint64(math.Round(bw * 8 / 1000))
+}
+
 // GetAppSpecLinkAttr returns a slice of Application Specifc Link Attributes
 func (ls *NLRI) GetAppSpecLinkAttr() ([]*AppSpecLinkAttr, error) {
 	aslas := make([]*AppSpecLinkAttr, 0)
@@ -0,0 +1,327 @@
+package bgpls
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetUnidirLinkDelay(t *testing.T) {
+	tests := []struct {
+		name      string
+		ls        *NLRI
+		delay     uint32
+		anomalous bool
+	}{
+		{
+			name:  "normal delay",
+			ls:    &NLRI{LS: []TLV{{Type: 1114, Length: 4, Value: []byte{0x00, 0x00, 0x03, 0xe8}}}},
+			delay: 1000,
+		},
+		{
+			name:      "anomalous delay",
+			ls:        &NLRI{LS: []TLV{{Type: 1114, Length: 4, Value: []byte{0x80, 0x01, 0x86, 0xa0}}}},
+			delay:     100000,
+			anomalous: true,
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1114, Length: 2, Value: []byte{0x03, 0xe8}}}},
+		},
+		{
+			name: "no delay tlv",
+			ls:   &NLRI{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, anomalous := tt.ls.GetUnidirLinkDelay()
+			if delay != tt.delay || anomalous != tt.anomalous {
+				t.Fatalf("expected delay %d anomalous %t, got delay %d anomalous %t", tt.delay, tt.anomalous, delay, anomalous)
+			}
+		})
+	}
+}
+
+func TestGetUnidirLinkDelayMinMax(t *testing.T) {
+	tests := []struct {
+		name      string
+		ls        *NLRI
+		minMax    []uint32
+		anomalous bool
+	}{
+		{
+			name:   "normal min max delay",
+			ls:     &NLRI{LS: []TLV{{Type: 1115, Length: 8, Value: []byte{0x00, 0x00, 0x01, 0xf4, 0x00, 0x00, 0x07, 0xd0}}}},
+			minMax: []uint32{500, 2000},
+		},
+		{
+			name:      "anomalous min max delay",
+			ls:        &NLRI{LS: []TLV{{Type: 1115, Length: 8, Value: []byte{0x80, 0x00, 0x01, 0xf4, 0x00, 0x00, 0x07, 0xd0}}}},
+			minMax:    []uint32{500, 2000},
+			anomalous: true,
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1115, Length: 4, Value: []byte{0x00, 0x00, 0x01, 0xf4}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minMax, anomalous := tt.ls.GetUnidirLinkDelayMinMax()
+			if !reflect.DeepEqual(minMax, tt.minMax) || anomalous != tt.anomalous {
+				t.Fatalf("expected min max %v anomalous %t, got min max %v anomalous %t", tt.minMax, tt.anomalous, minMax, anomalous)
+			}
+		})
+	}
+}
+
+func TestGetUnidirPerformanceMetrics(t *testing.T) {
+	ls := &NLRI{
+		LS: []TLV{
+			{Type: 1116, Length: 4, Value: []byte{0x00, 0x00, 0x00, 0x64}},
+			{Type: 1117, Length: 4, Value: []byte{0x80, 0x00, 0x00, 0x0a}},
+			// 125000000 bytes per second, 1Gbps
+			{Type: 1118, Length: 4, Value: []byte{0x4c, 0xee, 0x6b, 0x28}},
+			{Type: 1119, Length: 4, Value: []byte{0x4c, 0xee, 0x6b, 0x28}},
+			{Type: 1120, Length: 4, Value: []byte{0x4c, 0xee, 0x6b, 0x28}},
+		},
+	}
+	if v := ls.GetUnidirDelayVariation(); v != 100 {
+		t.Fatalf("expected delay variation 100, got %d", v)
+	}
+	if loss, anomalous := ls.GetUnidirLinkLoss(); loss != 10 || !anomalous {
+		t.Fatalf("expected loss 10 anomalous true, got loss %d anomalous %t", loss, anomalous)
+	}
+	if bw := ls.GetUnidirResidualBandwidthKbps(); bw != 1000000 {
+		t.Fatalf("expected residual bandwidth 1000000 kbps, got %d", bw)
+	}
+	if bw := ls.GetUnidirAvailableBandwidthKbps(); bw != 1000000 {
+		t.Fatalf("expected available bandwidth 1000000 kbps, got %d", bw)
+	}
+	if bw := ls.GetUnidirUtilizedBandwidthKbps(); bw != 1000000 {
+		t.Fatalf("expected utilized bandwidth 1000000 kbps, got %d", bw)
+	}
+	// Deprecated getters keep returning raw IEEE floating point bits
+	if bw := ls.GetUnidirResidualBandwidth(); bw != 0x4cee6b28 {
+		t.Fatalf("expected residual bandwidth 0x4cee6b28, got %#x", bw)
+	}
+	if bw := ls.GetUnidirAvailableBandwidth(); bw != 0x4cee6b28 {
+		t.Fatalf("expected available bandwidth 0x4cee6b28, got %#x", bw)
+	}
+	if bw := ls.GetUnidirUtilizedBandwidth(); bw != 0x4cee6b28 {
+		t.Fatalf("expected utilized bandwidth 0x4cee6b28, got %#x", bw)
+	}
+}
+
+func TestGetSRLG(t *testing.T) {
+	tests := []struct {
+		name string
+		ls   *NLRI
+		srlg []uint32
+	}{
+		{
+			name: "three srlg values",
+			ls: &NLRI{LS: []TLV{{Type: 1096, Length: 12, Value: []byte{
+				0x00, 0x00, 0x00, 0x0a,
+				0x00, 0x00, 0x01, 0x00,
+				0xff, 0xff, 0xff, 0xff,
+			}}}},
+			srlg: []uint32{10, 256, 4294967295},
+		},
+		{
+			name: "empty list",
+			ls:   &NLRI{LS: []TLV{{Type: 1096, Length: 0, Value: []byte{}}}},
+			srlg: []uint32{},
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1096, Length: 6, Value: []byte{0x00, 0x00, 0x00, 0x0a, 0x00, 0x00}}}},
+		},
+		{
+			name: "no srlg",
+			ls:   &NLRI{LS: []TLV{{Type: 1098, Length: 3, Value: []byte("lnk")}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if srlg := tt.ls.GetSRLG(); !reflect.DeepEqual(srlg, tt.srlg) {
+				t.Fatalf("expected srlg %v, got %v", tt.srlg, srlg)
+			}
+		})
+	}
+}
+
+func TestGetISISAreaID(t *testing.T) {
+	tests := []struct {
+		name   string
+		ls     *NLRI
+		areaID string
+	}{
+		{
+			name:   "single area",
+			ls:     &NLRI{LS: []TLV{{Type: 1027, Length: 3, Value: []byte{0x49, 0x00, 0x01}}}},
+			areaID: "49.0001",
+		},
+		{
+			name:   "single byte area",
+			ls:     &NLRI{LS: []TLV{{Type: 1027, Length: 1, Value: []byte{0x49}}}},
+			areaID: "49",
+		},
+		{
+			name:   "long area",
+			ls:     &NLRI{LS: []TLV{{Type: 1027, Length: 6, Value: []byte{0x47, 0x00, 0x05, 0x80, 0xff, 0xf8}}}},
+			areaID: "47.0005.80ff.f8",
+		},
+		{
+			name: "multiple areas",
+			ls: &NLRI{LS: []TLV{
+				{Type: 1026, Length: 4, Value: []byte("rtr1")},
+				{Type: 1027, Length: 3, Value: []byte{0x49, 0x00, 0x01}},
+				{Type: 1027, Length: 5, Value: []byte{0x49, 0x00, 0x01, 0x00, 0x02}},
+			}},
+			areaID: "49.0001,49.0001.0002",
+		},
+		{
+			name: "no area",
+			ls:   &NLRI{LS: []TLV{{Type: 1026, Length: 4, Value: []byte("rtr1")}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if areaID := tt.ls.GetISISAreaID(); areaID != tt.areaID {
+				t.Fatalf("expected area id %q, got %q", tt.areaID, areaID)
+			}
+		})
+	}
+}
+
+func TestGetAdminGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		ls     *NLRI
+		groups []uint32
+	}{
+		{
+			name:   "groups 0, 3 and 31",
+			ls:     &NLRI{LS: []TLV{{Type: 1088, Length: 4, Value: []byte{0x80, 0x00, 0x00, 0x09}}}},
+			groups: []uint32{0, 3, 31},
+		},
+		{
+			name: "no groups",
+			ls:   &NLRI{LS: []TLV{{Type: 1088, Length: 4, Value: []byte{0x00, 0x00, 0x00, 0x00}}}},
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1088, Length: 2, Value: []byte{0x00, 0x09}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if groups := tt.ls.GetAdminGroups(); !reflect.DeepEqual(groups, tt.groups) {
+				t.Fatalf("expected groups %v, got %v", tt.groups, groups)
+			}
+		})
+	}
+}
+
+func TestGetBandwidthKbps(t *testing.T) {
+	tests := []struct {
+		name string
+		ls   *NLRI
+		kbps uint64
+	}{
+		{
+			name: "10 Gbps",
+			ls:   &NLRI{LS: []TLV{{Type: 1089, Length: 4, Value: []byte{0x4e, 0x95, 0x02, 0xf9}}}},
+			kbps: 10000000,
+		},
+		{
+			name: "1 Gbps",
+			ls:   &NLRI{LS: []TLV{{Type: 1089, Length: 4, Value: []byte{0x4c, 0xee, 0x6b, 0x28}}}},
+			kbps: 1000000,
+		},
+		{
+			name: "NaN",
+			ls:   &NLRI{LS: []TLV{{Type: 1089, Length: 4, Value: []byte{0x7f, 0xc0, 0x00, 0x00}}}},
+		},
+		{
+			name: "negative",
+			ls:   &NLRI{LS: []TLV{{Type: 1089, Length: 4, Value: []byte{0xbf, 0x80, 0x00, 0x00}}}},
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1089, Length: 2, Value: []byte{0x4e, 0x95}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kbps := tt.ls.GetMaxLinkBandwidthKbps(); kbps != tt.kbps {
+				t.Fatalf("expected %d kbps, got %d", tt.kbps, kbps)
+			}
+		})
+	}
+}
+
+func TestGetTEDefaultMetric(t *testing.T) {
+	tests := []struct {
+		name   string
+		ls     *NLRI
+		metric uint32
+	}{
+		{
+			name:   "4 bytes",
+			ls:     &NLRI{LS: []TLV{{Type: 1092, Length: 4, Value: []byte{0x00, 0x01, 0x00, 0x0a}}}},
+			metric: 65546,
+		},
+		{
+			name:   "3 bytes",
+			ls:     &NLRI{LS: []TLV{{Type: 1092, Length: 3, Value: []byte{0x01, 0x00, 0x0a}}}},
+			metric: 65546,
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1092, Length: 2, Value: []byte{0x00, 0x0a}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if metric := tt.ls.GetTEDefaultMetric(); metric != tt.metric {
+				t.Fatalf("expected metric %d, got %d", tt.metric, metric)
+			}
+		})
+	}
+}
+
+func TestGetLinkProtection(t *testing.T) {
+	tests := []struct {
+		name       string
+		ls         *NLRI
+		protection *LinkProtectionType
+	}{
+		{
+			name:       "shared and enhanced",
+			ls:         &NLRI{LS: []TLV{{Type: 1093, Length: 2, Value: []byte{0x24, 0x00}}}},
+			protection: &LinkProtectionType{Shared: true, Enhanced: true},
+		},
+		{
+			name:       "dedicated 1+1",
+			ls:         &NLRI{LS: []TLV{{Type: 1093, Length: 2, Value: []byte{0x10, 0x00}}}},
+			protection: &LinkProtectionType{Dedicated1plus1: true},
+		},
+		{
+			name: "invalid length",
+			ls:   &NLRI{LS: []TLV{{Type: 1093, Length: 1, Value: []byte{0x10}}}},
+		},
+		{
+			name: "not present",
+			ls:   &NLRI{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if protection := tt.ls.GetLinkProtection(); !reflect.DeepEqual(protection, tt.protection) {
+				t.Fatalf("expected protection %+v, got %+v", tt.protection, protection)
+			}
+		})
+	}
+}
@@ -9,6 +9,28 @@ import (
 	"github.com/sbezverk/tools"
 )
 
+const (
+	// FlexAlgoMetricIGP defines IGP Metric type of FlexAlgo Definition
+	FlexAlgoMetricIGP = 0
+	// FlexAlgoMetricMinUnidirectionalDelay defines Min Unidirectional Link Delay Metric type of FlexAlgo Definition
+	FlexAlgoMetricMinUnidirectionalDelay = 1
+	// FlexAlgoMetricTE defines Traffic Engineering Default Metric type of FlexAlgo Definition
+	FlexAlgoMetricTE = 2
+)
+
+const (
+	// FADExcludeAnyAffinitySubTLV defines Flexible Algorithm Exclude Any Affinity Sub-TLV code
+	FADExcludeAnyAffinitySubTLV = 1040
+	// FADIncludeAnyAffinitySubTLV defines Flexible Algorithm Include Any Affinity Sub-TLV code
+	FADIncludeAnyAffinitySubTLV = 1041
+	// FADIncludeAllAffinitySubTLV defines Flexible Algorithm Include All Affinity Sub-TLV code
+	FADIncludeAllAffinitySubTLV = 1042
+	// FADFlagsSubTLV defines Flexible Algorithm Definition Flags Sub-TLV code
+	FADFlagsSubTLV = 1043
+	// FADExcludeSRLGSubTLV defines Flexible Algorithm Exclude SRLG Sub-TLV code
+	FADExcludeSRLGSubTLV = 1045
+)
+
 // FlexAlgoDefinition defines an optional BGP-LS Attribute TLV associated
 // with the Node NLRI called the Flexible Algorithm Definition (FAD) TLV
 // https://tools.ietf.org/html/draft-ietf-idr-bgp-ls-flex-algo-02#section-3
@@ -70,27 +92,36 @@ func UnmarshalFlexAlgoDefinition(b []byte) (*FlexAlgoDefinition, error) {
 		}
 		fad.SubTLV = &FADSubTLV{}
 		for _, tlv := range sstlvs {
-			ints, err := getFADSubTLVValue(tlv)
-			if err != nil {
-				return nil, err
-			}
 			switch tlv.Type {
-			case 1040:
-				fad.SubTLV.ExcludeAny = ints
-			case 1041:
-				fad.SubTLV.IncludeAny = ints
-			case 1042:
-				fad.SubTLV.IncludeAll = ints
-			case 1043:
-				fad.SubTLV.Flags = &FADSubTLVFlags{}
+			case FADExcludeAnyAffinitySubTLV:
+				if fad.SubTLV.ExcludeAny, err = getFADSubTLVValue(tlv); err != nil {
+					return nil, err
+				}
+			case FADIncludeAnyAffinitySubTLV:
+				if fad.SubTLV.IncludeAny, err = getFADSubTLVValue(tlv); err != nil {
+					return nil, err
+				}
+			case FADIncludeAllAffinitySubTLV:
+				if fad.SubTLV.IncludeAll, err = getFADSubTLVValue(tlv); err != nil {
+					return nil, err
+				}
+			case FADExcludeSRLGSubTLV:
+				if fad.SubTLV.ExcludeSRLG, err = getFADSubTLVValue(tlv); err != nil {
+					return nil, err
+				}
+			case FADFlagsSubTLV:
+				// Flags Sub TLV is variable length, M flag is the most significant bit of the first byte
 				if tlv.Length < 1 {
 					return nil, fmt.Errorf("not enough bytes to decode FlexAlgo definition Sub TLV Flag")
 				}
-				fad.SubTLV.Flags.MFLag = tlv.Value[0]&0x80 == 0x80
-			case 1045: // the type is really TBD in the draft
-				fad.SubTLV.ExcludeSRLG = ints
+				fad.SubTLV.Flags = &FADSubTLVFlags{
+					MFLag: tlv.Value[0]&0x80 == 0x80,
+				}
 			default:
-				return nil, fmt.Errorf("unknown FlexAlgo definition subtlv type %d", tlv.Type)
+				// Unknown Sub TLVs are ignored, the rest of the definition is still valid
+				if glog.V(5) {
+					glog.Infof("skipping unknown FlexAlgo definition subtlv type %d", tlv.Type)
+				}
 			}
 		}
 	}
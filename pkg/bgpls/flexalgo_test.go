@@ -24,6 +24,25 @@ func TestUnmarshalFlexAlgoDefinition(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "delay metric with exclude admin group and flags",
+			input: []byte{
+				0x81, 0x01, 0x00, 0x64, // FlexAlgo 129, Min Unidirectional Delay, SPF, Priority 100
+				0x04, 0x10, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05, // Exclude Any 0x5
+				0x04, 0x13, 0x00, 0x01, 0x80, // Flags with M flag
+				0x04, 0x16, 0x00, 0x02, 0x00, 0x00, // Unknown Sub TLV
+			},
+			expect: &FlexAlgoDefinition{
+				FlexAlgorithm:   129,
+				MetricType:      FlexAlgoMetricMinUnidirectionalDelay,
+				CalculationType: 0,
+				Priority:        100,
+				SubTLV: &FADSubTLV{
+					ExcludeAny: []uint32{5},
+					Flags:      &FADSubTLVFlags{MFLag: true},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -37,3 +56,35 @@ func TestUnmarshalFlexAlgoDefinition(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFlexAlgoDefinition(t *testing.T) {
+	ls := &NLRI{
+		LS: []TLV{
+			{Type: 1039, Length: 4, Value: []byte{0x80, 0x00, 0x00, 0x80}},
+			{Type: 1026, Length: 2, Value: []byte{0x52, 0x31}},
+			{Type: 1039, Length: 12, Value: []byte{0x81, 0x01, 0x00, 0x64, 0x04, 0x10, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05}},
+		},
+	}
+	expect := []*FlexAlgoDefinition{
+		{
+			FlexAlgorithm: 128,
+			MetricType:    FlexAlgoMetricIGP,
+			Priority:      128,
+		},
+		{
+			FlexAlgorithm: 129,
+			MetricType:    FlexAlgoMetricMinUnidirectionalDelay,
+			Priority:      100,
+			SubTLV: &FADSubTLV{
+				ExcludeAny: []uint32{5},
+			},
+		},
+	}
+	fads, err := ls.GetFlexAlgoDefinition()
+	if err != nil {
+		t.Fatalf("failed to get flex algo definitions with error: %+v", err)
+	}
+	if !reflect.DeepEqual(fads, expect) {
+		t.Fatalf("expected %+v and resulted %+v flex algo definitions do not match", expect, fads)
+	}
+}
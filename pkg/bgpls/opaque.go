@@ -0,0 +1,72 @@
+package bgpls
+
+const (
+	// OpaqueNodeAttrTLV defines Opaque Node Attribute TLV, rfc7752
+	OpaqueNodeAttrTLV = 1025
+	// OpaqueLinkAttrTLV defines Opaque Link Attribute TLV, rfc7752
+	OpaqueLinkAttrTLV = 1097
+	// OpaquePrefixAttrTLV defines Opaque Prefix Attribute TLV, rfc7752
+	OpaquePrefixAttrTLV = 1157
+)
+
+// OpaqueTLV defines BGP-LS Attribute TLV which is not decoded, its value is preserved as is
+type OpaqueTLV struct {
+	Type  uint16 `json:"type"`
+	Value []byte `json:"value,omitempty"`
+}
+
+// nodeAttrTLVs defines BGP-LS Attribute TLVs decoded as attributes of Node NLRI
+var nodeAttrTLVs = map[uint16]bool{
+	263: true, 266: true, 1024: true, 1026: true, 1027: true, 1028: true, 1029: true,
+	1034: true, 1035: true, 1036: true, 1038: true, 1039: true,
+}
+
+// linkAttrTLVs defines BGP-LS Attribute TLVs decoded as attributes of Link NLRI
+var linkAttrTLVs = map[uint16]bool{
+	258: true, 267: true, 1028: true, 1029: true, 1030: true, 1031: true,
+	1088: true, 1089: true, 1090: true, 1091: true, 1092: true, 1093: true, 1094: true, 1095: true, 1096: true,
+	1098: true, 1099: true, 1101: true, 1102: true, 1103: true, 1106: true,
+	1114: true, 1115: true, 1116: true, 1117: true, 1118: true, 1119: true, 1120: true, 1122: true, 1251: true,
+}
+
+// prefixAttrTLVs defines BGP-LS Attribute TLVs decoded as attributes of Prefix NLRI
+var prefixAttrTLVs = map[uint16]bool{
+	1028: true, 1029: true, 1044: true, 1152: true, 1153: true, 1154: true, 1155: true, 1158: true, 1162: true,
+	1170: true, 1171: true,
+}
+
+// GetNodeOpaque returns Opaque Node Attribute TLVs and all other TLVs which are not decoded as
+// attributes of Node NLRI, so TLVs not known yet are not lost.
+func (ls *NLRI) GetNodeOpaque() []*OpaqueTLV {
+	return ls.getOpaque(nodeAttrTLVs)
+}
+
+// GetLinkOpaque returns Opaque Link Attribute TLVs and all other TLVs which are not decoded as
+// attributes of Link NLRI, so TLVs not known yet are not lost.
+func (ls *NLRI) GetLinkOpaque() []*OpaqueTLV {
+	return ls.getOpaque(linkAttrTLVs)
+}
+
+// GetPrefixOpaque returns Opaque Prefix Attribute TLVs and all other TLVs which are not decoded as
+// attributes of Prefix NLRI, so TLVs not known yet are not lost.
+func (ls *NLRI) GetPrefixOpaque() []*OpaqueTLV {
+	return ls.getOpaque(prefixAttrTLVs)
+}
+
+// getOpaque returns TLVs of types not found in decoded in the order they are carried
+func (ls *NLRI) getOpaque(decoded map[uint16]bool) []*OpaqueTLV {
+	var opaque []*OpaqueTLV
+	for _, tlv := range ls.LS {
+		if decoded[tlv.Type] {
+			continue
+		}
+		o := &OpaqueTLV{
+			Type:  tlv.Type,
+			Value: make([]byte, len(tlv.Value)),
+		}
+		copy(o.Value, tlv.Value)
+		opaque = append(opaque, o)
+	}
+
+	return opaque
+}
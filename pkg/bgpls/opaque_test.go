@@ -0,0 +1,66 @@
+package bgpls
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetOpaque(t *testing.T) {
+	ls := &NLRI{LS: []TLV{
+		{Type: 1026, Length: 2, Value: []byte{'r', '1'}},
+		{Type: OpaqueNodeAttrTLV, Length: 1, Value: []byte{0x01}},
+		{Type: 1095, Length: 3, Value: []byte{0x00, 0x00, 0x0a}},
+		{Type: OpaqueLinkAttrTLV, Length: 1, Value: []byte{0x02}},
+		{Type: 1155, Length: 4, Value: []byte{0x00, 0x00, 0x00, 0x0a}},
+		{Type: OpaquePrefixAttrTLV, Length: 1, Value: []byte{0x03}},
+	}}
+	tests := []struct {
+		name   string
+		get    func() []*OpaqueTLV
+		expect []*OpaqueTLV
+	}{
+		{
+			name: "node",
+			get:  ls.GetNodeOpaque,
+			expect: []*OpaqueTLV{
+				{Type: OpaqueNodeAttrTLV, Value: []byte{0x01}},
+				{Type: 1095, Value: []byte{0x00, 0x00, 0x0a}},
+				{Type: OpaqueLinkAttrTLV, Value: []byte{0x02}},
+				{Type: 1155, Value: []byte{0x00, 0x00, 0x00, 0x0a}},
+				{Type: OpaquePrefixAttrTLV, Value: []byte{0x03}},
+			},
+		},
+		{
+			name: "link",
+			get:  ls.GetLinkOpaque,
+			expect: []*OpaqueTLV{
+				{Type: 1026, Value: []byte{'r', '1'}},
+				{Type: OpaqueNodeAttrTLV, Value: []byte{0x01}},
+				{Type: OpaqueLinkAttrTLV, Value: []byte{0x02}},
+				{Type: 1155, Value: []byte{0x00, 0x00, 0x00, 0x0a}},
+				{Type: OpaquePrefixAttrTLV, Value: []byte{0x03}},
+			},
+		},
+		{
+			name: "prefix",
+			get:  ls.GetPrefixOpaque,
+			expect: []*OpaqueTLV{
+				{Type: 1026, Value: []byte{'r', '1'}},
+				{Type: OpaqueNodeAttrTLV, Value: []byte{0x01}},
+				{Type: 1095, Value: []byte{0x00, 0x00, 0x0a}},
+				{Type: OpaqueLinkAttrTLV, Value: []byte{0x02}},
+				{Type: OpaquePrefixAttrTLV, Value: []byte{0x03}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.get(); !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected opaque tlvs %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+	if got := (&NLRI{LS: []TLV{{Type: 1026, Length: 2, Value: []byte{'r', '1'}}}}).GetNodeOpaque(); got != nil {
+		t.Fatalf("expected no opaque tlvs, got %+v", got)
+	}
+}
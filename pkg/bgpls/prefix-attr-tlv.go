@@ -54,6 +54,17 @@ func (p *PrefixAttrTLVs) MarshalJSON() ([]byte, error) {
 			LSPrefixSID:    p.LSPrefixSID,
 			SourceRouterID: p.SourceRouterID,
 		})
+	case *OSPFv3Flags:
+		f := p.Flags.(*OSPFv3Flags)
+		return json.Marshal(struct {
+			LSPrefixSID    []*sr.PrefixSIDTLV `json:"ls_prefix_sid,omitempty"`
+			Flags          *OSPFv3Flags       `json:"flags,omitempty"`
+			SourceRouterID string             `json:"source_router_id,omitempty"`
+		}{
+			Flags:          f,
+			LSPrefixSID:    p.LSPrefixSID,
+			SourceRouterID: p.SourceRouterID,
+		})
 	case *UnknownProtoFlags:
 		f := p.Flags.(*UnknownProtoFlags)
 		return json.Marshal(struct {
@@ -139,6 +150,9 @@ func UnmarshalPrefixAttrFlags(b []byte, proto base.ProtoID) (PrefixAttrFlags, er
 	if glog.V(6) {
 		glog.Infof("Prefix Attr Flags Raw: %s for proto: %+v", tools.MessageHex(b), proto)
 	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("not enough bytes to unmarshal Prefix Attr Flags")
+	}
 	p := 0
 	switch proto {
 	case base.ISISL1:
@@ -249,7 +263,7 @@ func (f *OSPFFlags) GetPrefixAttrFlagsByte() byte {
 		b += 0x80
 	}
 	if f.NFlag {
-		b += 0x20
+		b += 0x40
 	}
 
 	return b
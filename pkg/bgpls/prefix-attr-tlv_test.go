@@ -0,0 +1,65 @@
+package bgpls
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/base"
+	"github.com/sbezverk/gobmp/pkg/sr"
+)
+
+func TestGetPrefixAttrTLVs(t *testing.T) {
+	index := uint32(100)
+	tests := []struct {
+		name   string
+		ls     *NLRI
+		proto  base.ProtoID
+		expect *PrefixAttrTLVs
+	}{
+		{
+			name: "isis prefix sid index and flags",
+			ls: &NLRI{LS: []TLV{
+				{Type: 1158, Length: 8, Value: []byte{0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64}},
+				{Type: 1170, Length: 1, Value: []byte{0x40}},
+			}},
+			proto: base.ISISL2,
+			expect: &PrefixAttrTLVs{
+				LSPrefixSID: []*sr.PrefixSIDTLV{{Flags: &sr.ISISFlags{NFlag: true}, SID: 100, Index: &index}},
+				Flags:       &ISISFlags{RFlag: true},
+			},
+		},
+		{
+			name:  "ospfv3 flags",
+			ls:    &NLRI{LS: []TLV{{Type: 1170, Length: 1, Value: []byte{0x22}}}},
+			proto: base.OSPFv3,
+			expect: &PrefixAttrTLVs{
+				LSPrefixSID: []*sr.PrefixSIDTLV{},
+				Flags:       &OSPFv3Flags{NFlag: true, LAFlag: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ls.GetPrefixAttrTLVs(tt.proto)
+			if err != nil {
+				t.Fatalf("failed with error: %+v", err)
+			}
+			if diff := deep.Equal(tt.expect, got); diff != nil {
+				t.Fatalf("Diffs: %+v", diff)
+			}
+			b, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("failed to marshal with error: %+v", err)
+			}
+			recovered := &PrefixAttrTLVs{}
+			if err := json.Unmarshal(b, recovered); err != nil {
+				t.Fatalf("failed to unmarshal with error: %+v", err)
+			}
+			if !reflect.DeepEqual(got.Flags, recovered.Flags) {
+				t.Fatalf("expected flags %+v, recovered %+v", got.Flags, recovered.Flags)
+			}
+		})
+	}
+}
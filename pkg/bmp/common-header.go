@@ -2,6 +2,7 @@ package bmp
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/golang/glog"
@@ -10,8 +11,14 @@ import (
 
 const (
 	BMP_HEADER_SIZE = 6
+	// BMPVersion is the only BMP version supported, rfc7854
+	BMPVersion = 3
 )
 
+// ErrUnsupportedVersion is returned when Common Header carries a version other than BMPVersion,
+// it usually means the stream is not BMP or it is misaligned, so the message length cannot be trusted.
+var ErrUnsupportedVersion = errors.New("unsupported BMP version")
+
 // CommonHeader defines BMP message Common Header per rfc7854
 type CommonHeader struct {
 	Version       byte
@@ -24,13 +31,28 @@ func UnmarshalCommonHeader(b []byte) (*CommonHeader, error) {
 	if glog.V(6) {
 		glog.Infof("BMP CommonHeader Raw: %s", tools.MessageHex(b))
 	}
+	if len(b) < CommonHeaderLength {
+		return nil, fmt.Errorf("invalid common header length %d, expected %d", len(b), CommonHeaderLength)
+	}
 	ch := &CommonHeader{}
-	if b[0] != 3 {
-		return nil, fmt.Errorf("invalid version in common header, expected 3 found %d", b[0])
+	if b[0] != BMPVersion {
+		return nil, fmt.Errorf("%w in common header, expected %d found %d", ErrUnsupportedVersion, BMPVersion, b[0])
 	}
 	ch.Version = b[0]
 	ch.MessageLength = int32(binary.BigEndian.Uint32(b[1:5]))
+	if ch.MessageLength < CommonHeaderLength {
+		return nil, fmt.Errorf("invalid message length in common header %d, expected at least %d", ch.MessageLength, CommonHeaderLength)
+	}
 	ch.MessageType = b[5]
+	if !validMessageType(ch.MessageType) {
+		return nil, fmt.Errorf("invalid message type in common header, expected between 0 and 6 found %d", b[5])
+	}
+
+	return ch, nil
+}
+
+// validMessageType returns true for message types defined by rfc7854
+func validMessageType(t byte) bool {
 	// *  Type = 0: Route Monitoring
 	// *  Type = 1: Statistics Report
 	// *  Type = 2: Peer Down Notification
@@ -38,7 +60,7 @@ func UnmarshalCommonHeader(b []byte) (*CommonHeader, error) {
 	// *  Type = 4: Initiation Message
 	// *  Type = 5: Termination Message
 	// *  Type = 6: Route Mirroring Message
-	switch ch.MessageType {
+	switch t {
 	case 0:
 	case 1:
 	case 2:
@@ -47,10 +69,26 @@ func UnmarshalCommonHeader(b []byte) (*CommonHeader, error) {
 	case 5:
 	case 6:
 	default:
-		return nil, fmt.Errorf("invalid message type in common header, expected between 0 and 6 found %d", b[5])
+		return false
 	}
 
-	return ch, nil
+	return true
+}
+
+// Marshal returns the wire form of CommonHeader, it fails for a header UnmarshalCommonHeader would reject.
+// MessageLength is the length of the whole BMP message including the Common Header.
+func (c *CommonHeader) Marshal() ([]byte, error) {
+	if c.Version != BMPVersion {
+		return nil, fmt.Errorf("%w in common header, expected %d found %d", ErrUnsupportedVersion, BMPVersion, c.Version)
+	}
+	if c.MessageLength < CommonHeaderLength {
+		return nil, fmt.Errorf("invalid message length in common header %d, expected at least %d", c.MessageLength, CommonHeaderLength)
+	}
+	if !validMessageType(c.MessageType) {
+		return nil, fmt.Errorf("invalid message type in common header, expected between 0 and 6 found %d", c.MessageType)
+	}
+
+	return c.Serialize()
 }
 
 // Serialize generates a slice of bytes from CommonHeader structure
@@ -1,6 +1,7 @@
 package bmp
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -51,3 +52,141 @@ func TestCommonHeaderRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalCommonHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect *CommonHeader
+		fail   bool
+	}{
+		{
+			name:  "valid",
+			input: []byte{3, 0, 0, 0, 32, 4},
+			expect: &CommonHeader{
+				Version:       3,
+				MessageLength: 32,
+				MessageType:   4,
+			},
+		},
+		{
+			name:  "message length shorter than common header",
+			input: []byte{3, 0, 0, 0, 3, 4},
+			fail:  true,
+		},
+		{
+			name:  "negative message length",
+			input: []byte{3, 0xff, 0xff, 0xff, 0xff, 4},
+			fail:  true,
+		},
+		{
+			name:  "truncated common header",
+			input: []byte{3, 0, 0, 0},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := UnmarshalCommonHeader(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but fail with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if !reflect.DeepEqual(tt.expect, result) {
+				t.Fatalf("Expected: %+v and Resulting: %+v Common Headers do not match.", tt.expect, result)
+			}
+		})
+	}
+}
+
+func TestCommonHeaderMarshal(t *testing.T) {
+	// Termination message body with Reason TLV
+	body := []byte{0, 1, 0, 2, 0, 1}
+	tests := []struct {
+		name   string
+		header *CommonHeader
+		fail   bool
+	}{
+		{
+			name: "termination message",
+			header: &CommonHeader{
+				Version:       3,
+				MessageLength: int32(CommonHeaderLength + len(body)),
+				MessageType:   TerminationMsg,
+			},
+		},
+		{
+			name: "invalid version",
+			header: &CommonHeader{
+				Version:       2,
+				MessageLength: int32(CommonHeaderLength + len(body)),
+				MessageType:   TerminationMsg,
+			},
+			fail: true,
+		},
+		{
+			name: "message length shorter than common header",
+			header: &CommonHeader{
+				Version:       3,
+				MessageLength: CommonHeaderLength - 1,
+				MessageType:   TerminationMsg,
+			},
+			fail: true,
+		},
+		{
+			name: "unknown message type",
+			header: &CommonHeader{
+				Version:       3,
+				MessageLength: int32(CommonHeaderLength + len(body)),
+				MessageType:   7,
+			},
+			fail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.header.Marshal()
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but fail with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if tt.fail {
+				return
+			}
+			if len(b) != CommonHeaderLength {
+				t.Fatalf("expected %d bytes of common header, got %d", CommonHeaderLength, len(b))
+			}
+			msg := append(b, body...)
+			result, err := UnmarshalCommonHeader(msg)
+			if err != nil {
+				t.Fatalf("failed to unmarshal marshaled common header with error: %+v", err)
+			}
+			if !reflect.DeepEqual(tt.header, result) {
+				t.Fatalf("Original: %+v and Resulting: %+v Common Headers do not match.", tt.header, result)
+			}
+			if int(result.MessageLength) != len(msg) {
+				t.Fatalf("expected message length %d, got %d", len(msg), result.MessageLength)
+			}
+		})
+	}
+}
+
+func TestUnmarshalCommonHeaderVersion(t *testing.T) {
+	_, err := UnmarshalCommonHeader([]byte{1, 0, 0, 0, 32, 4})
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected error %v, got %+v", ErrUnsupportedVersion, err)
+	}
+	// Other header errors are not reported as unsupported version
+	_, err = UnmarshalCommonHeader([]byte{3, 0, 0, 0, 32, 9})
+	if err == nil || errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected invalid message type error, got %+v", err)
+	}
+	_, err = (&CommonHeader{Version: 1, MessageLength: 32, MessageType: 4}).Marshal()
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected error %v, got %+v", ErrUnsupportedVersion, err)
+	}
+}
@@ -56,4 +56,16 @@ const (
 	FlowspecV4Msg = 164
 	// FlowspecV6Msg defines BMP Route Monitoring message carrying Flowspec NLRI
 	FlowspecV6Msg = 166
+	// ParseErrorMsg defines a message produced when a BMP message fails to be parsed
+	ParseErrorMsg = 17
+	// MulticastPrefixMsg defines a subtype of BMP Route Monitoring message for multicast NLRI
+	MulticastPrefixMsg = 18
+	// MulticastPrefixV4Msg defines a subtype of BMP Route Monitoring message for multicast NLRI AFI 1 SAFI 2
+	MulticastPrefixV4Msg = 184
+	// MulticastPrefixV6Msg defines a subtype of BMP Route Monitoring message for multicast NLRI AFI 2 SAFI 2
+	MulticastPrefixV6Msg = 186
+	// EndOfRIBMsg defines a message produced when BGP End-of-RIB marker is received from a peer
+	EndOfRIBMsg = 19
+	// PeerEventMsg defines a message produced when a peer goes up or down, see PeerStateChangeMsg
+	PeerEventMsg = 20
 )
@@ -0,0 +1,61 @@
+package bmp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalTLV(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect []InformationalTLV
+	}{
+		{
+			name:  "two tlvs",
+			input: []byte{0x00, 0x00, 0x00, 0x01, 0x61, 0x80, 0x01, 0x00, 0x00},
+			expect: []InformationalTLV{
+				{InformationType: 0, InformationLength: 1, Information: []byte{0x61}},
+				{InformationType: 0x8001, InformationLength: 0, Information: []byte{}},
+			},
+		},
+		{
+			name:  "truncated header",
+			input: []byte{0x00, 0x00, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "length exceeding remaining bytes",
+			input: []byte{0x00, 0x00, 0x00, 0x02, 0x61},
+			fail:  true,
+		},
+		{
+			name:  "length 0x8000",
+			input: []byte{0x00, 0x00, 0x80, 0x00, 0x61},
+			fail:  true,
+		},
+		{
+			name:  "length 0xffff",
+			input: []byte{0x00, 0x00, 0xff, 0xff, 0x61},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlvs, err := UnmarshalTLV(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tlvs, tt.expect) {
+				t.Fatalf("expected tlvs %+v, got %+v", tt.expect, tlvs)
+			}
+		})
+	}
+}
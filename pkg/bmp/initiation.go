@@ -1,49 +1,80 @@
 package bmp
 
 import (
-	"encoding/binary"
 	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/tools"
 )
 
+const (
+	// InitiationStringTLV defines Initiation TLV carrying free-form UTF-8 string
+	InitiationStringTLV = 0
+	// InitiationSysDescrTLV defines Initiation TLV carrying sysDescr of the monitored router
+	InitiationSysDescrTLV = 1
+	// InitiationSysNameTLV defines Initiation TLV carrying sysName of the monitored router
+	InitiationSysNameTLV = 2
+)
+
 // InitiationMessage defines BMP Initiation Message per rfc7854
 type InitiationMessage struct {
 	TLV []InformationalTLV
 }
 
+// SysDescr returns sysDescr of the monitored router, empty string if it is not present
+func (im *InitiationMessage) SysDescr() string {
+	for _, tlv := range im.TLV {
+		if tlv.InformationType == InitiationSysDescrTLV {
+			return string(tlv.Information)
+		}
+	}
+
+	return ""
+}
+
+// SysName returns sysName of the monitored router, empty string if it is not present
+func (im *InitiationMessage) SysName() string {
+	for _, tlv := range im.TLV {
+		if tlv.InformationType == InitiationSysNameTLV {
+			return string(tlv.Information)
+		}
+	}
+
+	return ""
+}
+
+// Strings returns all free-form strings in the order they are found in the message
+func (im *InitiationMessage) Strings() []string {
+	s := make([]string, 0)
+	for _, tlv := range im.TLV {
+		if tlv.InformationType == InitiationStringTLV {
+			s = append(s, string(tlv.Information))
+		}
+	}
+
+	return s
+}
+
 // UnmarshalInitiationMessage processes Initiation Message and returns BMPInitiationMessage object
 func UnmarshalInitiationMessage(b []byte) (*InitiationMessage, error) {
 	if glog.V(6) {
 		glog.Infof("BMP Initiation Message Raw: %s", tools.MessageHex(b))
 	}
-	im := &InitiationMessage{
-		TLV: make([]InformationalTLV, 0),
+	tlvs, err := UnmarshalTLV(b)
+	if err != nil {
+		return nil, err
 	}
-	for i := 0; i < len(b); {
-		// Extracting TLV type 2 bytes
-		t := int16(binary.BigEndian.Uint16(b[i : i+2]))
-		switch t {
-		case 0:
-		case 1:
-		case 2:
+	for _, tlv := range tlvs {
+		switch tlv.InformationType {
+		case InitiationStringTLV:
+		case InitiationSysDescrTLV:
+		case InitiationSysNameTLV:
 		default:
-			return nil, fmt.Errorf("invalid tlv type, expected between 0 and 2 found %d", t)
-		}
-		// Extracting TLV length
-		l := int16(binary.BigEndian.Uint16(b[i+2 : i+4]))
-		if l > int16(len(b)-(i+4)) {
-			return nil, fmt.Errorf("invalid tlv length %d", l)
+			return nil, fmt.Errorf("invalid tlv type, expected between 0 and 2 found %d", tlv.InformationType)
 		}
-		v := b[i+4 : i+4+int(l)]
-		im.TLV = append(im.TLV, InformationalTLV{
-			InformationType:   t,
-			InformationLength: l,
-			Information:       v,
-		})
-		i += 4 + int(l)
 	}
 
-	return im, nil
+	return &InitiationMessage{
+		TLV: tlvs,
+	}, nil
 }
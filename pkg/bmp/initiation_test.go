@@ -0,0 +1,73 @@
+package bmp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalInitiationMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		fail     bool
+		sysName  string
+		sysDescr string
+		strings  []string
+	}{
+		{
+			name:     "sysDescr and sysName",
+			input:    []byte{0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73, 0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49},
+			sysName:  "xrv9k-r1",
+			sysDescr: " 7.2.1.23I",
+			strings:  []string{},
+		},
+		{
+			name: "multiple strings",
+			input: []byte{
+				0, 0, 0, 3, 'o', 'n', 'e',
+				0, 2, 0, 2, 'r', '1',
+				0, 0, 0, 3, 't', 'w', 'o',
+			},
+			sysName: "r1",
+			strings: []string{"one", "two"},
+		},
+		{
+			name:  "invalid tlv type",
+			input: []byte{0, 3, 0, 1, 'a'},
+			fail:  true,
+		},
+		{
+			name:  "invalid tlv length",
+			input: []byte{0, 2, 0, 4, 'r', '1'},
+			fail:  true,
+		},
+		{
+			name:  "truncated tlv",
+			input: []byte{0, 2, 0},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im, err := UnmarshalInitiationMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if got := im.SysName(); got != tt.sysName {
+				t.Fatalf("expected sysName %q, got %q", tt.sysName, got)
+			}
+			if got := im.SysDescr(); got != tt.sysDescr {
+				t.Fatalf("expected sysDescr %q, got %q", tt.sysDescr, got)
+			}
+			if got := im.Strings(); !reflect.DeepEqual(got, tt.strings) {
+				t.Fatalf("expected strings %+v, got %+v", tt.strings, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package bmp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxMessageLength defines the default maximum length of a BMP message read by MessageReader
+const DefaultMaxMessageLength = 1 << 20
+
+var (
+	// ErrMessageTooLong is returned by MessageReader when Common Header carries a message length exceeding
+	// the maximum length of the reader
+	ErrMessageTooLong = errors.New("message length exceeds maximum")
+	// ErrResyncFailed is returned by MessageReader Resync when no plausible Common Header is found
+	ErrResyncFailed = errors.New("no plausible BMP message Common Header found")
+)
+
+// HeaderError is returned by MessageReader when Common Header of a message is invalid or the message is
+// longer than the maximum length. The length of the message cannot be trusted, so the following message
+// cannot be found in the stream and MessageReader returns the same error until it is resynchronized.
+type HeaderError struct {
+	// Header is the invalid Common Header, the framing header is not included
+	Header []byte
+	Err    error
+}
+
+func (e *HeaderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *HeaderError) Unwrap() error {
+	return e.Err
+}
+
+// MessageReaderOption defines a function which modifies optional parameters of MessageReader
+type MessageReaderOption func(*MessageReader)
+
+// WithMaxMessageLength sets the maximum length of a BMP message, by default DefaultMaxMessageLength
+func WithMaxMessageLength(length int) MessageReaderOption {
+	return func(mr *MessageReader) {
+		mr.maxMessageLength = length
+	}
+}
+
+// WithFraming makes MessageReader read length bytes of framing header preceding Common Header of every
+// BMP message and pass both to unframe, which returns Common Header of the message. A nil unframe strips
+// the framing header. The framing header is not included in returned messages.
+func WithFraming(length int, unframe func(b []byte) ([]byte, error)) MessageReaderOption {
+	return func(mr *MessageReader) {
+		mr.framingLength = length
+		mr.unframe = unframe
+	}
+}
+
+// WithAllocator sets the function allocating n bytes a BMP message of length n is read into,
+// by default every message is allocated with make.
+func WithAllocator(alloc func(n int) []byte) MessageReaderOption {
+	return func(mr *MessageReader) {
+		mr.alloc = alloc
+	}
+}
+
+// MessageReader reads BMP messages framed by their Common Header from a stream, for example a BMP session
+// or a file of concatenated BMP messages. MessageReader does not buffer reads, r is usually buffered.
+type MessageReader struct {
+	r                io.Reader
+	maxMessageLength int
+	framingLength    int
+	unframe          func(b []byte) ([]byte, error)
+	alloc            func(n int) []byte
+	// raw is the framing header followed by Common Header of the message being read, rawRead bytes
+	// of it are read. raw is reused for all messages.
+	raw     []byte
+	rawRead int
+	// header is Common Header of the message being read, it is set once raw is read
+	header []byte
+	// msg is the message being read, msgRead bytes of it are read
+	msg     []byte
+	msgRead int
+}
+
+// NewMessageReader returns MessageReader reading BMP messages from r
+func NewMessageReader(r io.Reader, opts ...MessageReaderOption) *MessageReader {
+	mr := &MessageReader{
+		r:                r,
+		maxMessageLength: DefaultMaxMessageLength,
+		alloc: func(n int) []byte {
+			return make([]byte, n)
+		},
+	}
+	for _, opt := range opts {
+		opt(mr)
+	}
+	mr.raw = make([]byte, mr.framingLength+CommonHeaderLength)
+
+	return mr
+}
+
+// Next returns the next BMP message including its Common Header. io.EOF is returned only when the stream
+// ends before the first byte of the message, io.ErrUnexpectedEOF is returned when it ends in the middle of
+// the message. When reading fails, for example a read deadline is exceeded, bytes of the message read so far
+// are kept and the following call of Next continues reading the message, see Pending. *HeaderError is returned
+// when Common Header is invalid.
+func (mr *MessageReader) Next() ([]byte, error) {
+	if mr.msg == nil {
+		if mr.header == nil {
+			if err := mr.fill(mr.raw, &mr.rawRead); err != nil {
+				return nil, err
+			}
+			header, err := mr.unframeHeader()
+			if err != nil {
+				mr.reset()
+				return nil, err
+			}
+			mr.header = header
+		}
+		header, err := UnmarshalCommonHeader(mr.header)
+		if err == nil && int(header.MessageLength) > mr.maxMessageLength {
+			err = fmt.Errorf("%w, length %d maximum %d", ErrMessageTooLong, header.MessageLength, mr.maxMessageLength)
+		}
+		if err != nil {
+			return nil, &HeaderError{Header: append([]byte{}, mr.header...), Err: err}
+		}
+		mr.msg = mr.alloc(int(header.MessageLength))
+		mr.msgRead = copy(mr.msg, mr.header)
+	}
+	if err := mr.fill(mr.msg, &mr.msgRead); err != nil {
+		return nil, err
+	}
+	msg := mr.msg
+	mr.reset()
+
+	return msg, nil
+}
+
+// Pending returns the number of bytes of the message being read, including the framing header, which are
+// read but not returned by Next yet. It is 0 when Next has failed before reading any byte of the message.
+func (mr *MessageReader) Pending() int {
+	if mr.msg != nil {
+		return mr.framingLength + mr.msgRead
+	}
+
+	return mr.rawRead
+}
+
+// Resync looks for a plausible Common Header in the stream following the invalid one returned by Next
+// in *HeaderError, the first byte of the invalid header is skipped and the search starts right after it.
+// Common Header of BMP version 3, a known message type and a length not exceeding the maximum is plausible.
+// When found, the following call of Next returns the message of the plausible Common Header. Resync returns
+// the number of skipped bytes, it gives up once maxSkip bytes are skipped.
+func (mr *MessageReader) Resync(maxSkip int) (int, error) {
+	if mr.header == nil || mr.msg != nil {
+		return 0, fmt.Errorf("no invalid Common Header to resynchronize from")
+	}
+	window := make([]byte, 0, CommonHeaderLength)
+	window = append(window, mr.header[1:]...)
+	skipped := 1
+	b := make([]byte, 1)
+	for {
+		for len(window) < CommonHeaderLength {
+			if _, err := io.ReadFull(mr.r, b); err != nil {
+				return skipped, err
+			}
+			window = append(window, b[0])
+		}
+		if mr.plausibleHeader(window) {
+			mr.header = window
+			return skipped, nil
+		}
+		if skipped >= maxSkip {
+			return skipped, ErrResyncFailed
+		}
+		window = append(window[:0], window[1:]...)
+		skipped++
+	}
+}
+
+// plausibleHeader returns true when b starts with Common Header of BMP version 3, a known message type
+// and a length not exceeding the maximum length.
+func (mr *MessageReader) plausibleHeader(b []byte) bool {
+	header, err := UnmarshalCommonHeader(b)
+	if err != nil {
+		return false
+	}
+
+	return int(header.MessageLength) <= mr.maxMessageLength
+}
+
+// unframeHeader returns Common Header of the message from raw
+func (mr *MessageReader) unframeHeader() ([]byte, error) {
+	if mr.unframe == nil {
+		return mr.raw[mr.framingLength:], nil
+	}
+	header, err := mr.unframe(mr.raw)
+	if err != nil {
+		return nil, fmt.Errorf("framing failed: %w", err)
+	}
+	if len(header) != CommonHeaderLength {
+		return nil, fmt.Errorf("framing returned %d bytes, expected Common Header of %d bytes", len(header), CommonHeaderLength)
+	}
+
+	return header, nil
+}
+
+// fill reads b from *n on, *n is advanced by the number of read bytes, so reading continues where it has
+// stopped when fill fails.
+func (mr *MessageReader) fill(b []byte, n *int) error {
+	started := mr.Pending() != 0
+	read, err := io.ReadFull(mr.r, b[*n:])
+	*n += read
+	if err == io.EOF && started {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
+
+// reset prepares the reader for the following message
+func (mr *MessageReader) reset() {
+	mr.rawRead = 0
+	mr.header = nil
+	mr.msg = nil
+	mr.msgRead = 0
+}
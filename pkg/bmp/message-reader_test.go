@@ -0,0 +1,227 @@
+package bmp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+// Initiation message with sysName TLV
+var testInitiation = []byte{3, 0, 0, 0, 13, InitiationMsg, 0, 2, 0, 3, 'l', 'a', 'b'}
+
+// Termination message with reason TLV
+var testTermination = []byte{3, 0, 0, 0, 12, TerminationMsg, 0, 1, 0, 2, 0, 1}
+
+// errTestFraming is returned by framing adapters failing to convert the framing header
+var errTestFraming = errors.New("invalid framing header")
+
+func TestMessageReader(t *testing.T) {
+	msgs := [][]byte{testInitiation, testTermination, testInitiation}
+	input := bytes.Join(msgs, nil)
+	tests := []struct {
+		name   string
+		r      io.Reader
+		opts   []MessageReaderOption
+		expect [][]byte
+		err    error
+	}{
+		{
+			name:   "concatenated messages",
+			r:      bytes.NewReader(input),
+			expect: msgs,
+			err:    io.EOF,
+		},
+		{
+			name:   "one byte reads",
+			r:      iotest.OneByteReader(bytes.NewReader(input)),
+			expect: msgs,
+			err:    io.EOF,
+		},
+		{
+			name:   "half reads",
+			r:      iotest.HalfReader(bytes.NewReader(input)),
+			expect: msgs,
+			err:    io.EOF,
+		},
+		{
+			name:   "stream ends in the middle of the header",
+			r:      bytes.NewReader(append(append([]byte{}, testInitiation...), 3, 0, 0)),
+			expect: [][]byte{testInitiation},
+			err:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:   "stream ends in the middle of the message",
+			r:      bytes.NewReader(append(append([]byte{}, testInitiation...), testTermination[:8]...)),
+			expect: [][]byte{testInitiation},
+			err:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:   "invalid version",
+			r:      bytes.NewReader(append(append([]byte{}, testInitiation...), 2, 0, 0, 0, 12, TerminationMsg)),
+			expect: [][]byte{testInitiation},
+			err:    ErrUnsupportedVersion,
+		},
+		{
+			name:   "message exceeds maximum",
+			r:      bytes.NewReader(input),
+			opts:   []MessageReaderOption{WithMaxMessageLength(12)},
+			expect: [][]byte{},
+			err:    ErrMessageTooLong,
+		},
+		{
+			name:   "framing header stripped",
+			r:      bytes.NewReader(append([]byte{0xfa, 0xce}, testInitiation...)),
+			opts:   []MessageReaderOption{WithFraming(2, nil)},
+			expect: [][]byte{testInitiation},
+			err:    io.EOF,
+		},
+		{
+			name:   "framing header converted",
+			r:      bytes.NewReader(append([]byte{0xfa, 0xce}, testInitiation...)),
+			opts:   []MessageReaderOption{WithFraming(2, func(b []byte) ([]byte, error) { return b[2:], nil })},
+			expect: [][]byte{testInitiation},
+			err:    io.EOF,
+		},
+		{
+			name:   "invalid framing header",
+			r:      bytes.NewReader(append([]byte{0xfa, 0xce}, testInitiation...)),
+			opts:   []MessageReaderOption{WithFraming(2, func(b []byte) ([]byte, error) { return nil, errTestFraming })},
+			expect: [][]byte{},
+			err:    errTestFraming,
+		},
+		{
+			name: "framing failed",
+			r:    bytes.NewReader(append([]byte{0xfa, 0xce}, testInitiation...)),
+			opts: []MessageReaderOption{WithFraming(2, func(b []byte) ([]byte, error) {
+				return b, nil
+			})},
+			expect: [][]byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewMessageReader(tt.r, tt.opts...)
+			for i, expect := range tt.expect {
+				got, err := reader.Next()
+				if err != nil {
+					t.Fatalf("failed to read message %d with error: %+v", i, err)
+				}
+				if !bytes.Equal(got, expect) {
+					t.Fatalf("expected message %d %v, got %v", i, expect, got)
+				}
+			}
+			_, err := reader.Next()
+			if err == nil {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestMessageReaderPartialRead(t *testing.T) {
+	// The second read fails with iotest.ErrTimeout, the message is read by the following call of Next
+	reader := NewMessageReader(iotest.TimeoutReader(iotest.OneByteReader(bytes.NewReader(testInitiation))))
+	if _, err := reader.Next(); err != iotest.ErrTimeout {
+		t.Fatalf("expected error %v, got %v", iotest.ErrTimeout, err)
+	}
+	if reader.Pending() != 1 {
+		t.Fatalf("expected 1 pending byte, got %d", reader.Pending())
+	}
+	got, err := reader.Next()
+	if err != nil {
+		t.Fatalf("failed to read message with error: %+v", err)
+	}
+	if !bytes.Equal(got, testInitiation) {
+		t.Fatalf("expected message %v, got %v", testInitiation, got)
+	}
+	if reader.Pending() != 0 {
+		t.Fatalf("expected no pending bytes, got %d", reader.Pending())
+	}
+}
+
+func TestMessageReaderAllocator(t *testing.T) {
+	allocated := 0
+	reader := NewMessageReader(bytes.NewReader(testInitiation), WithAllocator(func(n int) []byte {
+		allocated += n
+		return make([]byte, n)
+	}))
+	if _, err := reader.Next(); err != nil {
+		t.Fatalf("failed to read message with error: %+v", err)
+	}
+	if allocated != len(testInitiation) {
+		t.Fatalf("expected %d allocated bytes, got %d", len(testInitiation), allocated)
+	}
+}
+
+func TestMessageReaderResync(t *testing.T) {
+	tests := []struct {
+		name    string
+		stream  []byte
+		maxSkip int
+		skipped int
+		fail    bool
+	}{
+		{
+			name:    "junk before the message",
+			stream:  append([]byte{0xde, 0xad, 0xbe}, testInitiation...),
+			maxSkip: 16,
+			skipped: 3,
+		},
+		{
+			name:    "junk longer than common header",
+			stream:  append(bytes.Repeat([]byte{0xff}, 10), testInitiation...),
+			maxSkip: 16,
+			skipped: 10,
+		},
+		{
+			name:    "junk exceeds maximum skip",
+			stream:  append(bytes.Repeat([]byte{0xff}, 10), testInitiation...),
+			maxSkip: 4,
+			fail:    true,
+		},
+		{
+			name:    "stream ends",
+			stream:  []byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01, 0x02},
+			maxSkip: 16,
+			fail:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewMessageReader(bufio.NewReader(bytes.NewReader(tt.stream)))
+			var headerErr *HeaderError
+			if _, err := reader.Next(); !errors.As(err, &headerErr) {
+				t.Fatalf("expected header error, got %v", err)
+			}
+			if !bytes.Equal(headerErr.Header, tt.stream[:CommonHeaderLength]) {
+				t.Fatalf("expected invalid header %v, got %v", tt.stream[:CommonHeaderLength], headerErr.Header)
+			}
+			skipped, err := reader.Resync(tt.maxSkip)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if skipped != tt.skipped {
+				t.Fatalf("expected %d skipped bytes, got %d", tt.skipped, skipped)
+			}
+			got, err := reader.Next()
+			if err != nil {
+				t.Fatalf("failed to read message with error: %+v", err)
+			}
+			if !bytes.Equal(got, testInitiation) {
+				t.Fatalf("expected message %v, got %v", testInitiation, got)
+			}
+		})
+	}
+}
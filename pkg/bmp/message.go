@@ -1,8 +1,27 @@
 package bmp
 
+import "time"
+
 // Message defines a message used to transfer BMP messages for further processing
 // for BMP messages which do not carry PerPeerHeader, it will be set to nil.
+// RawMessage carries the original BMP message including the Common Header.
+// ReceivedAt is the time the collector received the BMP message, zero when it is unknown.
+// Sequence is the number of the BMP message in the order the messages of the session are received,
+// starting from 1, zero when it is unknown.
+// Metadata carries fields attached to every message produced from the BMP message, for example
+// by a transform of the producer.
 type Message struct {
 	PeerHeader *PerPeerHeader
 	Payload    interface{}
+	RawMessage []byte
+	ReceivedAt time.Time
+	Sequence   uint64
+	Metadata   map[string]string
+}
+
+// ParseError is the Payload of Message carrying a BMP message which failed to be parsed,
+// Message is the BMP message including the Common Header and Err is the parsing error.
+type ParseError struct {
+	Message []byte
+	Err     error
 }
@@ -1,33 +1,108 @@
 package bmp
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/tools"
 )
 
+// Peer Down reason codes per rfc7854 section 4.9 and rfc9069 section 5.6
+const (
+	// PeerDownLocalNotification defines the local system closed the session, BGP Notification PDU follows
+	PeerDownLocalNotification = 1
+	// PeerDownLocalNoNotification defines the local system closed the session without Notification,
+	// FSM Event code follows
+	PeerDownLocalNoNotification = 2
+	// PeerDownRemoteNotification defines the remote system closed the session, BGP Notification PDU follows
+	PeerDownRemoteNotification = 3
+	// PeerDownRemoteNoNotification defines the remote system closed the session without Notification
+	PeerDownRemoteNoNotification = 4
+	// PeerDownDeconfigured defines the peer is de-configured and no longer monitored
+	PeerDownDeconfigured = 5
+	// PeerDownLocalTLV defines the local system closed the session, Information TLVs follow
+	PeerDownLocalTLV = 6
+)
+
+// peerDownReasons defines descriptions of Peer Down reasons
+var peerDownReasons = map[uint8]string{
+	PeerDownLocalNotification:    "Local system closed session with notification",
+	PeerDownLocalNoNotification:  "Local system closed session without notification",
+	PeerDownRemoteNotification:   "Remote system closed session with notification",
+	PeerDownRemoteNoNotification: "Remote system closed session without notification",
+	PeerDownDeconfigured:         "Peer de-configured",
+	PeerDownLocalTLV:             "Local system closed session with TLV data",
+}
+
 // PeerDownMessage defines BMPPeerDownMessage per rfc7854
 type PeerDownMessage struct {
 	Reason uint8
 	Data   []byte
+	// Notification is BGP Notification carried by reasons 1 and 3
+	Notification *bgp.NotificationMessage
+	// FSMEvent is the code of FSM Event which closed the session, carried by reason 2
+	FSMEvent uint16
+	// Information is Information TLVs carried by reason 6
+	Information []InformationalTLV
+}
+
+// ReasonString returns the description of Peer Down reason
+func (pdw *PeerDownMessage) ReasonString() string {
+	if s, ok := peerDownReasons[pdw.Reason]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown reason %d", pdw.Reason)
 }
 
-// UnmarshalPeerDownMessage processes Peer Down message and returns BMPPeerDownMessage object
+// UnmarshalPeerDownMessage processes Peer Down message and returns BMPPeerDownMessage object,
+// malformed data of a valid reason is kept only as raw Data.
 func UnmarshalPeerDownMessage(b []byte) (*PeerDownMessage, error) {
 	if glog.V(6) {
 		glog.Infof("BMP Peer Down Message Raw: %s", tools.MessageHex(b))
 	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("not enough bytes to unmarshal Peer Down message")
+	}
 	pdw := &PeerDownMessage{
 		Data: make([]byte, len(b)-1),
 	}
 	p := 0
 	pdw.Reason = b[p]
 	p++
-	if pdw.Reason < 1 || pdw.Reason > 5 {
+	if pdw.Reason < PeerDownLocalNotification || pdw.Reason > PeerDownLocalTLV {
 		return nil, fmt.Errorf("invalid reason code %d in Peer Down message", pdw.Reason)
 	}
 	copy(pdw.Data, b[p:])
+	switch pdw.Reason {
+	case PeerDownLocalNotification, PeerDownRemoteNotification:
+		// BGP message header is 16 bytes marker, 2 bytes length and 1 byte type
+		if len(pdw.Data) < 19 {
+			glog.Warningf("Peer Down reason %d carries %d bytes, too short for BGP Notification", pdw.Reason, len(pdw.Data))
+			break
+		}
+		n, err := bgp.UnmarshalBGPNotificationMessage(pdw.Data[16:])
+		if err != nil {
+			glog.Warningf("failed to unmarshal BGP Notification of Peer Down reason %d with error: %+v", pdw.Reason, err)
+			break
+		}
+		pdw.Notification = n
+	case PeerDownLocalNoNotification:
+		if len(pdw.Data) != 2 {
+			glog.Warningf("Peer Down reason %d carries %d bytes, expected 2 bytes FSM Event", pdw.Reason, len(pdw.Data))
+			break
+		}
+		pdw.FSMEvent = binary.BigEndian.Uint16(pdw.Data)
+	case PeerDownLocalTLV:
+		tlvs, err := UnmarshalTLV(pdw.Data)
+		if err != nil {
+			glog.Warningf("failed to unmarshal Information TLVs of Peer Down reason %d with error: %+v", pdw.Reason, err)
+			break
+		}
+		pdw.Information = tlvs
+	}
 
 	return pdw, nil
 }
@@ -3,32 +3,113 @@ package bmp
 import (
 	"reflect"
 	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bgp"
 )
 
 func TestPeerDownMsg(t *testing.T) {
 	tests := []struct {
 		name   string
 		input  []byte
+		fail   bool
 		expect *PeerDownMessage
 	}{
 		{
 			name:  "real case 1",
 			input: []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x06, 0x04},
+			expect: &PeerDownMessage{
+				Reason:       1,
+				Data:         []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x06, 0x04},
+				Notification: &bgp.NotificationMessage{Code: 6, Subcode: 4, Error: "Cease: Administrative Reset"},
+			},
+		},
+		{
+			name:  "local close without notification",
+			input: []byte{0x02, 0x00, 0x0A},
+			expect: &PeerDownMessage{
+				Reason:   2,
+				Data:     []byte{0x00, 0x0A},
+				FSMEvent: 10,
+			},
+		},
+		{
+			name:  "remote notification hold timer expired",
+			input: []byte{0x03, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x04, 0x00},
+			expect: &PeerDownMessage{
+				Reason:       3,
+				Data:         []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x04, 0x00},
+				Notification: &bgp.NotificationMessage{Code: 4, Subcode: 0, Error: "Hold Timer Expired"},
+			},
+		},
+		{
+			name:  "remote close without notification",
+			input: []byte{0x04},
+			expect: &PeerDownMessage{
+				Reason: 4,
+				Data:   []byte{},
+			},
+		},
+		{
+			name:  "peer de-configured",
+			input: []byte{0x05},
+			expect: &PeerDownMessage{
+				Reason: 5,
+				Data:   []byte{},
+			},
+		},
+		{
+			name:  "local close with tlv data",
+			input: []byte{0x06, 0x00, 0x00, 0x00, 0x04, 'd', 'o', 'w', 'n'},
+			expect: &PeerDownMessage{
+				Reason:      6,
+				Data:        []byte{0x00, 0x00, 0x00, 0x04, 'd', 'o', 'w', 'n'},
+				Information: []InformationalTLV{{InformationType: 0, InformationLength: 4, Information: []byte{'d', 'o', 'w', 'n'}}},
+			},
+		},
+		{
+			name:  "malformed notification is kept as data",
+			input: []byte{0x01, 0xFF, 0xFF, 0x00},
 			expect: &PeerDownMessage{
 				Reason: 1,
-				Data:   []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x06, 0x04},
+				Data:   []byte{0xFF, 0xFF, 0x00},
 			},
 		},
+		{
+			name:  "invalid reason",
+			input: []byte{0x07},
+			fail:  true,
+		},
+		{
+			name:  "empty message",
+			input: []byte{},
+			fail:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			peerDown, err := UnmarshalPeerDownMessage(tt.input)
-			if err != nil {
+			if err != nil && !tt.fail {
 				t.Fatalf("failed but supposed to succeed with error: %+v", err)
 			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
 			if !reflect.DeepEqual(tt.expect, peerDown) {
 				t.Fatalf("expected %+v does not match unmarshaled %+v", tt.expect, peerDown)
 			}
 		})
 	}
 }
+
+func TestPeerDownReasonString(t *testing.T) {
+	for reason, expect := range map[uint8]string{
+		PeerDownLocalNotification:  "Local system closed session with notification",
+		PeerDownRemoteNotification: "Remote system closed session with notification",
+		PeerDownDeconfigured:       "Peer de-configured",
+		9:                          "Unknown reason 9",
+	} {
+		if s := (&PeerDownMessage{Reason: reason}).ReasonString(); s != expect {
+			t.Errorf("expected reason %d description %q, got %q", reason, expect, s)
+		}
+	}
+}
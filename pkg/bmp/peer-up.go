@@ -2,6 +2,7 @@ package bmp
 
 import (
 	"encoding/binary"
+	"fmt"
 	"net"
 
 	"github.com/golang/glog"
@@ -20,6 +21,8 @@ type PeerUpMessage struct {
 	isRemotePeerIPv6 bool
 }
 
+// GetLocalAddressString returns the local address of the monitored BGP session, the address is IPv6
+// when V flag of the Per-Peer Header is set, otherwise IPv4 address is carried in the last 4 bytes.
 func (pum *PeerUpMessage) GetLocalAddressString() string {
 	if pum.isRemotePeerIPv6 {
 		return net.IP(pum.LocalAddress).To16().String()
@@ -27,11 +30,27 @@ func (pum *PeerUpMessage) GetLocalAddressString() string {
 	return net.IP(pum.LocalAddress[12:]).To4().String()
 }
 
+// GetTableName returns VRF/Table Name carried in Information TLV type 3, Loc-RIB peer per RFC9069
+// must carry it, empty string is returned if the TLV is not found.
+func (pum *PeerUpMessage) GetTableName() string {
+	for _, tlv := range pum.Information {
+		if tlv.InformationType == 3 {
+			return string(tlv.Information)
+		}
+	}
+
+	return ""
+}
+
 // UnmarshalPeerUpMessage processes Peer Up message and returns BMPPeerUpMessage object
 func UnmarshalPeerUpMessage(b []byte, isIPv6 bool) (*PeerUpMessage, error) {
 	if glog.V(6) {
 		glog.Infof("BMP Peer Up Message Raw: %s", tools.MessageHex(b))
 	}
+	// Local Address, Local Port and Remote Port are followed by two BGP Open messages
+	if len(b) < 20+2*bgp.BGPMinOpenMessageLength {
+		return nil, fmt.Errorf("invalid Peer Up message length %d", len(b))
+	}
 	var err error
 	pu := &PeerUpMessage{
 		LocalAddress:     make([]byte, 16),
@@ -143,3 +143,51 @@ func TestPeerUpMsg(t *testing.T) {
 		})
 	}
 }
+
+func TestPeerUpLocalAddress(t *testing.T) {
+	// Open message of AS 65000 with BGP ID 10.0.0.2 without Optional Parameters
+	open := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+	}
+	tests := []struct {
+		name           string
+		localAddress   []byte
+		remotePeerIPv6 bool
+		expect         string
+	}{
+		{
+			name:         "ipv4",
+			localAddress: []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103},
+			expect:       "192.168.80.103",
+		},
+		{
+			name:           "ipv6",
+			localAddress:   []byte{0x20, 0x01, 0x0D, 0xB8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x02},
+			remotePeerIPv6: true,
+			expect:         "2001:db8::2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]byte{}, tt.localAddress...)
+			// Local Port 179 and Remote Port 50000
+			input = append(input, 0x00, 0xB3, 0xC3, 0x50)
+			input = append(input, open...)
+			input = append(input, open...)
+			peerUp, err := UnmarshalPeerUpMessage(input, tt.remotePeerIPv6)
+			if err != nil {
+				t.Fatalf("failed but supposed to succeed with error: %+v", err)
+			}
+			if got := peerUp.GetLocalAddressString(); got != tt.expect {
+				t.Errorf("expected local address %s, got %s", tt.expect, got)
+			}
+			if peerUp.LocalPort != 179 || peerUp.RemotePort != 50000 {
+				t.Errorf("expected local port 179 and remote port 50000, got %d and %d", peerUp.LocalPort, peerUp.RemotePort)
+			}
+			if _, err := UnmarshalPeerUpMessage(input[:len(input)-len(open)], tt.remotePeerIPv6); err == nil {
+				t.Errorf("supposed to fail on Peer Up message without received Open message")
+			}
+		})
+	}
+}
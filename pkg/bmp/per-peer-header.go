@@ -78,12 +78,29 @@ func peerType(b byte) (PeerType, error) {
 	}
 }
 
+// GetPeerTime returns the time of the event on the router carried by Timestamp seconds and microseconds,
+// zero time is returned when the router does not report the time and Timestamp is 0.
+func (p *PerPeerHeader) GetPeerTime() time.Time {
+	if len(p.PeerTimestamp) < 8 {
+		return time.Time{}
+	}
+	sec := binary.BigEndian.Uint32(p.PeerTimestamp[0:4])
+	usec := binary.BigEndian.Uint32(p.PeerTimestamp[4:8])
+	if sec == 0 && usec == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(sec), int64(usec)*int64(time.Microsecond)).UTC()
+}
+
+// GetPeerTimestamp returns the time of the event on the router in RFC3339 format,
+// empty string is returned when the router does not report the time.
 func (p *PerPeerHeader) GetPeerTimestamp() string {
-	t := time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
-	ts := time.Second * time.Duration(binary.BigEndian.Uint32(p.PeerTimestamp[0:4]))
-	tms := time.Duration(int(binary.BigEndian.Uint32(p.PeerTimestamp[4:8])))
-	t = t.Add(ts)
-	t = t.Add(tms)
+	t := p.GetPeerTime()
+	if t.IsZero() {
+		return ""
+	}
+
 	return t.Format(time.RFC3339Nano)
 }
 
@@ -113,8 +130,9 @@ func (p *PerPeerHeader) GetPeerAddrString() string {
 	return net.IP(p.PeerAddress[12:]).To4().String()
 }
 
-// IsAdjRIBOutPost returns true if PeerType is 0,1 or 2 and O flag is set, otherwise it returns error
-func (p *PerPeerHeader) IsAdjRIBOutPost() (bool, error) {
+// IsAdjRIBOut returns true if PeerType is 0,1 or 2 and O flag is set, the message carries Adj-RIB-Out
+// per RFC8671, otherwise it returns error
+func (p *PerPeerHeader) IsAdjRIBOut() (bool, error) {
 	if p.PeerType != PeerType3 {
 		return p.flagO, nil
 	}
@@ -122,10 +140,19 @@ func (p *PerPeerHeader) IsAdjRIBOutPost() (bool, error) {
 	return false, ErrInvFlagRequestForPeerType
 }
 
-// IsAdjRIBInPost returns true if PeerType is 0,1 or 2 and L flag is set, otherwise it returns error
+// IsAdjRIBOutPost returns true if PeerType is 0,1 or 2 and both O and L flags are set, otherwise it returns error
+func (p *PerPeerHeader) IsAdjRIBOutPost() (bool, error) {
+	if p.PeerType != PeerType3 {
+		return p.flagO && p.flagL, nil
+	}
+
+	return false, ErrInvFlagRequestForPeerType
+}
+
+// IsAdjRIBInPost returns true if PeerType is 0,1 or 2, L flag is set and O flag is not set, otherwise it returns error
 func (p *PerPeerHeader) IsAdjRIBInPost() (bool, error) {
 	if p.PeerType != PeerType3 {
-		return p.flagL, nil
+		return !p.flagO && p.flagL, nil
 	}
 
 	return false, ErrInvFlagRequestForPeerType
@@ -140,6 +167,11 @@ func (p *PerPeerHeader) IsLocRIBFiltered() (bool, error) {
 	return false, ErrInvFlagRequestForPeerType
 }
 
+// IsLocRIB returns true if PeerType is 3, the message carries routes of Loc-RIB per RFC9069
+func (p *PerPeerHeader) IsLocRIB() bool {
+	return p.PeerType == PeerType3
+}
+
 // IsRemotePeerIPv6 returns true if Remote Peer is IPv6 for PeerType is 0,1 or 2, for Peer Type 3 always returns false.
 func (p *PerPeerHeader) IsRemotePeerIPv6() bool {
 	if p.PeerType != PeerType3 {
@@ -149,6 +181,16 @@ func (p *PerPeerHeader) IsRemotePeerIPv6() bool {
 	return false
 }
 
+// ASNLength returns the length in bytes of ASNs carried in AS_PATH attribute of BGP messages of the peer,
+// 2 bytes if PeerType is 0, 1 or 2 and A flag is set, otherwise 4 bytes.
+func (p *PerPeerHeader) ASNLength() int {
+	if p.PeerType != PeerType3 && p.flagA {
+		return 2
+	}
+
+	return 4
+}
+
 // GetPeerDistinguisherString returns string representation of Peer's distinguisher
 // depending on the peer's type.
 func (p *PerPeerHeader) GetPeerDistinguisherString() string {
@@ -0,0 +1,229 @@
+package bmp
+
+import (
+	"testing"
+)
+
+func TestPerPeerHeaderRIBFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		peerType   byte
+		flags      byte
+		adjRIBOut  bool
+		inPost     bool
+		outPost    bool
+		locRIB     bool
+		locRIBFilt bool
+	}{
+		{
+			name:     "adj-rib-in pre-policy",
+			peerType: 0,
+			flags:    0x00,
+		},
+		{
+			name:     "adj-rib-in post-policy",
+			peerType: 0,
+			flags:    0x40,
+			inPost:   true,
+		},
+		{
+			name:      "adj-rib-out pre-policy",
+			peerType:  0,
+			flags:     0x10,
+			adjRIBOut: true,
+		},
+		{
+			name:      "adj-rib-out post-policy",
+			peerType:  1,
+			flags:     0x50,
+			adjRIBOut: true,
+			outPost:   true,
+		},
+		{
+			name:       "loc-rib filtered",
+			peerType:   3,
+			flags:      0x80,
+			locRIB:     true,
+			locRIBFilt: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := make([]byte, PerPeerHeaderLength)
+			b[0] = tt.peerType
+			b[1] = tt.flags
+			ph, err := UnmarshalPerPeerHeader(b)
+			if err != nil {
+				t.Fatalf("failed to unmarshal Per Peer Header with error: %+v", err)
+			}
+			if ph.IsLocRIB() != tt.locRIB {
+				t.Fatalf("expected Loc-RIB %t, got %t", tt.locRIB, ph.IsLocRIB())
+			}
+			if tt.locRIB {
+				if f, err := ph.IsLocRIBFiltered(); err != nil || f != tt.locRIBFilt {
+					t.Fatalf("expected Loc-RIB filtered %t, got %t error: %+v", tt.locRIBFilt, f, err)
+				}
+				if _, err := ph.IsAdjRIBOut(); err != ErrInvFlagRequestForPeerType {
+					t.Fatalf("expected error for O flag request of Loc-RIB peer, got %+v", err)
+				}
+				return
+			}
+			if f, err := ph.IsAdjRIBOut(); err != nil || f != tt.adjRIBOut {
+				t.Fatalf("expected Adj-RIB-Out %t, got %t error: %+v", tt.adjRIBOut, f, err)
+			}
+			if f, err := ph.IsAdjRIBInPost(); err != nil || f != tt.inPost {
+				t.Fatalf("expected Adj-RIB-In post-policy %t, got %t error: %+v", tt.inPost, f, err)
+			}
+			if f, err := ph.IsAdjRIBOutPost(); err != nil || f != tt.outPost {
+				t.Fatalf("expected Adj-RIB-Out post-policy %t, got %t error: %+v", tt.outPost, f, err)
+			}
+		})
+	}
+}
+
+func TestPerPeerHeaderASNLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		peerType  byte
+		flags     byte
+		asnLength int
+	}{
+		{
+			name:      "4 bytes asn",
+			peerType:  0,
+			flags:     0x00,
+			asnLength: 4,
+		},
+		{
+			name:      "2 bytes asn",
+			peerType:  1,
+			flags:     0x20,
+			asnLength: 2,
+		},
+		{
+			name:      "loc-rib",
+			peerType:  3,
+			flags:     0x20,
+			asnLength: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := make([]byte, PerPeerHeaderLength)
+			b[0] = tt.peerType
+			b[1] = tt.flags
+			ph, err := UnmarshalPerPeerHeader(b)
+			if err != nil {
+				t.Fatalf("failed to unmarshal Per Peer Header with error: %+v", err)
+			}
+			if l := ph.ASNLength(); l != tt.asnLength {
+				t.Fatalf("expected asn length %d, got %d", tt.asnLength, l)
+			}
+		})
+	}
+}
+
+func TestGetPeerDistinguisherString(t *testing.T) {
+	tests := []struct {
+		name     string
+		peerType byte
+		pd       []byte
+		expect   string
+	}{
+		{
+			name:     "global instance peer",
+			peerType: 0,
+			pd:       []byte{0, 0, 0, 0, 0, 0, 0, 0},
+			expect:   "0:0",
+		},
+		{
+			name:     "rd instance peer rd type 0",
+			peerType: 1,
+			pd:       []byte{0, 0, 0xfd, 0xe8, 0, 0, 0, 100},
+			expect:   "65000:100",
+		},
+		{
+			name:     "rd instance peer rd type 1",
+			peerType: 1,
+			pd:       []byte{0, 1, 192, 168, 1, 1, 0, 100},
+			expect:   "192.168.1.1:100",
+		},
+		{
+			name:     "rd instance peer rd type 2",
+			peerType: 1,
+			pd:       []byte{0, 2, 0, 0x01, 0x11, 0x70, 0, 100},
+			expect:   "70000:100",
+		},
+		{
+			name:     "rd instance peer invalid rd type",
+			peerType: 1,
+			pd:       []byte{0, 3, 0, 0, 0, 0, 0, 100},
+			expect:   "0:0",
+		},
+		{
+			name:     "local instance peer",
+			peerType: 2,
+			pd:       []byte{0, 0, 0, 0, 0, 0, 0x01, 0x00},
+			expect:   "256",
+		},
+		{
+			name:     "loc-rib instance peer vrf",
+			peerType: 3,
+			pd:       []byte{0, 0, 0xfd, 0xe8, 0, 0, 0, 1},
+			expect:   "65000:1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := make([]byte, PerPeerHeaderLength)
+			b[0] = tt.peerType
+			copy(b[2:10], tt.pd)
+			ph, err := UnmarshalPerPeerHeader(b)
+			if err != nil {
+				t.Fatalf("failed to unmarshal Per Peer Header with error: %+v", err)
+			}
+			if got := ph.GetPeerDistinguisherString(); got != tt.expect {
+				t.Errorf("expected peer distinguisher %s, got %s", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestGetPeerTimestamp(t *testing.T) {
+	tests := []struct {
+		name   string
+		ts     []byte
+		expect string
+	}{
+		{
+			name:   "seconds and microseconds",
+			ts:     []byte{0x65, 0x53, 0xf1, 0x00, 0x00, 0x07, 0xa1, 0x20},
+			expect: "2023-11-14T22:13:20.5Z",
+		},
+		{
+			name:   "seconds only",
+			ts:     []byte{0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x00, 0x00},
+			expect: "2023-11-14T22:13:20Z",
+		},
+		{
+			name: "not supported by router",
+			ts:   []byte{0, 0, 0, 0, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := make([]byte, PerPeerHeaderLength)
+			copy(b[34:42], tt.ts)
+			ph, err := UnmarshalPerPeerHeader(b)
+			if err != nil {
+				t.Fatalf("failed to unmarshal per peer header with error: %+v", err)
+			}
+			if got := ph.GetPeerTimestamp(); got != tt.expect {
+				t.Fatalf("expected timestamp %q, got %q", tt.expect, got)
+			}
+			if ph.GetPeerTime().IsZero() != (tt.expect == "") {
+				t.Fatalf("expected zero time only for timestamp not supported by router, got %v", ph.GetPeerTime())
+			}
+		})
+	}
+}
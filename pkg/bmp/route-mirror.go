@@ -0,0 +1,83 @@
+package bmp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/tools"
+)
+
+const (
+	// RouteMirrorBGPMessageTLV defines Route Mirroring TLV carrying BGP PDU
+	RouteMirrorBGPMessageTLV = 0
+	// RouteMirrorInformationTLV defines Route Mirroring TLV carrying Information code
+	RouteMirrorInformationTLV = 1
+	// RouteMirrorErroredPDU defines Information code signaling that the mirrored PDU could not be parsed
+	RouteMirrorErroredPDU = 0
+	// RouteMirrorMessagesLost defines Information code signaling that one or more messages were not mirrored
+	RouteMirrorMessagesLost = 1
+)
+
+// RouteMirrorMessage defines BMP Route Mirroring Message per rfc7854 section 4.7
+type RouteMirrorMessage struct {
+	TLV []InformationalTLV
+}
+
+// BGPMessages returns mirrored BGP PDUs
+func (rm *RouteMirrorMessage) BGPMessages() [][]byte {
+	msgs := make([][]byte, 0)
+	for _, tlv := range rm.TLV {
+		if tlv.InformationType == RouteMirrorBGPMessageTLV {
+			msgs = append(msgs, tlv.Information)
+		}
+	}
+
+	return msgs
+}
+
+// IsErroredPDU returns true if Route Mirroring message carries Errored PDU Information code
+func (rm *RouteMirrorMessage) IsErroredPDU() bool {
+	return rm.hasInformationCode(RouteMirrorErroredPDU)
+}
+
+// IsMessagesLost returns true if Route Mirroring message carries Messages Lost Information code
+func (rm *RouteMirrorMessage) IsMessagesLost() bool {
+	return rm.hasInformationCode(RouteMirrorMessagesLost)
+}
+
+func (rm *RouteMirrorMessage) hasInformationCode(code uint16) bool {
+	for _, tlv := range rm.TLV {
+		if tlv.InformationType == RouteMirrorInformationTLV && binary.BigEndian.Uint16(tlv.Information) == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnmarshalBMPRouteMirrorMessage builds BMP Route Mirroring object
+func UnmarshalBMPRouteMirrorMessage(b []byte) (*RouteMirrorMessage, error) {
+	if glog.V(6) {
+		glog.Infof("BMP Route Mirroring Message Raw: %s", tools.MessageHex(b))
+	}
+	tlvs, err := UnmarshalTLV(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, tlv := range tlvs {
+		switch tlv.InformationType {
+		case RouteMirrorBGPMessageTLV:
+		case RouteMirrorInformationTLV:
+			if tlv.InformationLength != 2 {
+				return nil, fmt.Errorf("invalid length of Route Mirroring Information tlv %d", tlv.InformationLength)
+			}
+		default:
+			return nil, fmt.Errorf("invalid Route Mirroring tlv type, expected 0 or 1 found %d", tlv.InformationType)
+		}
+	}
+
+	return &RouteMirrorMessage{
+		TLV: tlvs,
+	}, nil
+}
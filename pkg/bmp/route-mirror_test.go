@@ -0,0 +1,89 @@
+package bmp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalBMPRouteMirrorMessage(t *testing.T) {
+	update := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x17, 0x02, 0x00, 0x00, 0x00, 0x00,
+	}
+	tests := []struct {
+		name         string
+		input        []byte
+		fail         bool
+		bgpMessages  [][]byte
+		erroredPDU   bool
+		messagesLost bool
+	}{
+		{
+			name:        "mirrored bgp update",
+			input:       append([]byte{0x00, 0x00, 0x00, 0x17}, update...),
+			bgpMessages: [][]byte{update},
+		},
+		{
+			name: "errored pdu with bgp update",
+			input: append([]byte{
+				0x00, 0x01, 0x00, 0x02, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x17}, update...),
+			bgpMessages: [][]byte{update},
+			erroredPDU:  true,
+		},
+		{
+			name:         "messages lost",
+			input:        []byte{0x00, 0x01, 0x00, 0x02, 0x00, 0x01},
+			bgpMessages:  [][]byte{},
+			messagesLost: true,
+		},
+		{
+			name:  "invalid tlv type",
+			input: []byte{0x00, 0x02, 0x00, 0x02, 0x00, 0x01},
+			fail:  true,
+		},
+		{
+			name:  "invalid information tlv length",
+			input: []byte{0x00, 0x01, 0x00, 0x01, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "truncated tlv",
+			input: []byte{0x00, 0x00, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "tlv length exceeding 0x7fff",
+			input: []byte{0x00, 0x00, 0x80, 0x00, 0xFF, 0xFF},
+			fail:  true,
+		},
+		{
+			name:  "truncated bgp message",
+			input: []byte{0x00, 0x00, 0x00, 0x17, 0xFF, 0xFF},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm, err := UnmarshalBMPRouteMirrorMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if got := rm.BGPMessages(); !reflect.DeepEqual(got, tt.bgpMessages) {
+				t.Fatalf("expected bgp messages %+v, got %+v", tt.bgpMessages, got)
+			}
+			if got := rm.IsErroredPDU(); got != tt.erroredPDU {
+				t.Fatalf("expected errored pdu %t, got %t", tt.erroredPDU, got)
+			}
+			if got := rm.IsMessagesLost(); got != tt.messagesLost {
+				t.Fatalf("expected messages lost %t, got %t", tt.messagesLost, got)
+			}
+		})
+	}
+}
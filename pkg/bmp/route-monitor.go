@@ -13,8 +13,9 @@ type RouteMonitor struct {
 	Update *bgp.Update
 }
 
-// UnmarshalBMPRouteMonitorMessage builds BMP Route Monitor object
-func UnmarshalBMPRouteMonitorMessage(b []byte) (*RouteMonitor, error) {
+// UnmarshalBMPRouteMonitorMessage builds BMP Route Monitor object, AS_PATH attribute of the update carries
+// ASNs of asnLength bytes, see PerPeerHeader.ASNLength.
+func UnmarshalBMPRouteMonitorMessage(b []byte, asnLength int) (*RouteMonitor, error) {
 	if glog.V(6) {
 		glog.Infof("BMP Route Monitor Message Raw: %s length: %d", tools.MessageHex(b), len(b))
 	}
@@ -34,7 +35,7 @@ func UnmarshalBMPRouteMonitorMessage(b []byte) (*RouteMonitor, error) {
 	switch t {
 	case 2:
 		// Update type
-		u, err := bgp.UnmarshalBGPUpdate(b[p:])
+		u, err := bgp.UnmarshalBGPUpdate(b[p:], asnLength)
 		if err != nil {
 			return nil, err
 		}
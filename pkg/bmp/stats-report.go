@@ -8,10 +8,61 @@ import (
 	"github.com/sbezverk/tools"
 )
 
-// StatsReport defines BMP Stats message structure
+// Stat types per rfc7854 and rfc8671
+const (
+	StatPrefixesRejected           = 0
+	StatDuplicatePrefixes          = 1
+	StatDuplicateWithdraws         = 2
+	StatInvalidatedDueClusterList  = 3
+	StatInvalidatedDueASPath       = 4
+	StatInvalidatedDueOriginatorID = 5
+	StatInvalidatedDueASConfed     = 6
+	StatAdjRIBsIn                  = 7
+	StatLocRIB                     = 8
+	StatPerAFISAFIAdjRIBIn         = 9
+	StatPerAFISAFILocRIB           = 10
+	StatUpdatesAsWithdraw          = 11
+	StatPrefixesAsWithdraw         = 12
+	StatDuplicateUpdates           = 13
+	StatAdjRIBsOutPrePolicy        = 14
+	StatAdjRIBsOutPostPolicy       = 15
+	StatPerAFISAFIAdjRIBOutPre     = 16
+	StatPerAFISAFIAdjRIBOutPost    = 17
+)
+
+// AFISAFIGauge defines the value of a 64-bit gauge scoped to AFI/SAFI
+type AFISAFIGauge struct {
+	AFI   uint16
+	SAFI  uint8
+	Value uint64
+}
+
+// StatsReport defines BMP Stats message structure, all recovered stats are stored in StatsTLV,
+// known stat types are also decoded into the corresponding fields.
 type StatsReport struct {
 	StatsCount int32
 	StatsTLV   []InformationalTLV
+	// 32-bit counters
+	PrefixesRejected           uint32
+	DuplicatePrefixes          uint32
+	DuplicateWithdraws         uint32
+	InvalidatedDueClusterList  uint32
+	InvalidatedDueASPath       uint32
+	InvalidatedDueOriginatorID uint32
+	InvalidatedDueASConfed     uint32
+	UpdatesAsWithdraw          uint32
+	PrefixesAsWithdraw         uint32
+	DuplicateUpdates           uint32
+	// 64-bit gauges
+	AdjRIBsIn            uint64
+	LocRIB               uint64
+	AdjRIBsOutPrePolicy  uint64
+	AdjRIBsOutPostPolicy uint64
+	// 64-bit gauges scoped to AFI/SAFI
+	PerAFISAFIAdjRIBIn      []AFISAFIGauge
+	PerAFISAFILocRIB        []AFISAFIGauge
+	PerAFISAFIAdjRIBOutPre  []AFISAFIGauge
+	PerAFISAFIAdjRIBOutPost []AFISAFIGauge
 }
 
 // UnmarshalBMPStatsReportMessage builds BMP Stats Reports object
@@ -19,6 +70,9 @@ func UnmarshalBMPStatsReportMessage(b []byte) (*StatsReport, error) {
 	if glog.V(6) {
 		glog.Infof("BMP Stats Report Message Raw: %s", tools.MessageHex(b))
 	}
+	if len(b) < 4 {
+		return nil, fmt.Errorf("not enough bytes to unmarshal Stats Report")
+	}
 	sr := StatsReport{}
 	p := 0
 	l := int32(binary.BigEndian.Uint32(b[p : p+4]))
@@ -32,6 +86,88 @@ func UnmarshalBMPStatsReportMessage(b []byte) (*StatsReport, error) {
 		return nil, err
 	}
 	sr.StatsTLV = tlvs
+	for _, tlv := range tlvs {
+		if err := sr.decodeStat(tlv); err != nil {
+			return nil, err
+		}
+	}
 
 	return &sr, nil
 }
+
+func (sr *StatsReport) decodeStat(tlv InformationalTLV) error {
+	switch tlv.InformationType {
+	case StatPrefixesRejected:
+		return unmarshalCounter(tlv, &sr.PrefixesRejected)
+	case StatDuplicatePrefixes:
+		return unmarshalCounter(tlv, &sr.DuplicatePrefixes)
+	case StatDuplicateWithdraws:
+		return unmarshalCounter(tlv, &sr.DuplicateWithdraws)
+	case StatInvalidatedDueClusterList:
+		return unmarshalCounter(tlv, &sr.InvalidatedDueClusterList)
+	case StatInvalidatedDueASPath:
+		return unmarshalCounter(tlv, &sr.InvalidatedDueASPath)
+	case StatInvalidatedDueOriginatorID:
+		return unmarshalCounter(tlv, &sr.InvalidatedDueOriginatorID)
+	case StatInvalidatedDueASConfed:
+		return unmarshalCounter(tlv, &sr.InvalidatedDueASConfed)
+	case StatUpdatesAsWithdraw:
+		return unmarshalCounter(tlv, &sr.UpdatesAsWithdraw)
+	case StatPrefixesAsWithdraw:
+		return unmarshalCounter(tlv, &sr.PrefixesAsWithdraw)
+	case StatDuplicateUpdates:
+		return unmarshalCounter(tlv, &sr.DuplicateUpdates)
+	case StatAdjRIBsIn:
+		return unmarshalGauge(tlv, &sr.AdjRIBsIn)
+	case StatLocRIB:
+		return unmarshalGauge(tlv, &sr.LocRIB)
+	case StatAdjRIBsOutPrePolicy:
+		return unmarshalGauge(tlv, &sr.AdjRIBsOutPrePolicy)
+	case StatAdjRIBsOutPostPolicy:
+		return unmarshalGauge(tlv, &sr.AdjRIBsOutPostPolicy)
+	case StatPerAFISAFIAdjRIBIn:
+		return unmarshalAFISAFIGauge(tlv, &sr.PerAFISAFIAdjRIBIn)
+	case StatPerAFISAFILocRIB:
+		return unmarshalAFISAFIGauge(tlv, &sr.PerAFISAFILocRIB)
+	case StatPerAFISAFIAdjRIBOutPre:
+		return unmarshalAFISAFIGauge(tlv, &sr.PerAFISAFIAdjRIBOutPre)
+	case StatPerAFISAFIAdjRIBOutPost:
+		return unmarshalAFISAFIGauge(tlv, &sr.PerAFISAFIAdjRIBOutPost)
+	default:
+		glog.V(5).Infof("unknown stat type: %d", tlv.InformationType)
+	}
+
+	return nil
+}
+
+func unmarshalCounter(tlv InformationalTLV, v *uint32) error {
+	if len(tlv.Information) != 4 {
+		return fmt.Errorf("invalid length %d of 32-bit counter stat type %d", len(tlv.Information), tlv.InformationType)
+	}
+	*v = binary.BigEndian.Uint32(tlv.Information)
+
+	return nil
+}
+
+func unmarshalGauge(tlv InformationalTLV, v *uint64) error {
+	if len(tlv.Information) != 8 {
+		return fmt.Errorf("invalid length %d of 64-bit gauge stat type %d", len(tlv.Information), tlv.InformationType)
+	}
+	*v = binary.BigEndian.Uint64(tlv.Information)
+
+	return nil
+}
+
+func unmarshalAFISAFIGauge(tlv InformationalTLV, v *[]AFISAFIGauge) error {
+	// AFI 2 bytes, SAFI 1 byte and 64-bit gauge
+	if len(tlv.Information) != 11 {
+		return fmt.Errorf("invalid length %d of AFI/SAFI 64-bit gauge stat type %d", len(tlv.Information), tlv.InformationType)
+	}
+	*v = append(*v, AFISAFIGauge{
+		AFI:   binary.BigEndian.Uint16(tlv.Information[0:2]),
+		SAFI:  tlv.Information[2],
+		Value: binary.BigEndian.Uint64(tlv.Information[3:]),
+	})
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package bmp
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestUnmarshalBMPStatsReportMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect *StatsReport
+	}{
+		{
+			name: "32-bit counters",
+			input: []byte{
+				0x00, 0x00, 0x00, 0x03,
+				0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05,
+				0x00, 0x01, 0x00, 0x04, 0x00, 0x00, 0x01, 0x00,
+				0x00, 0x0D, 0x00, 0x04, 0x00, 0x00, 0x00, 0x07,
+			},
+			expect: &StatsReport{
+				StatsCount: 3,
+				StatsTLV: []InformationalTLV{
+					{InformationType: 0, InformationLength: 4, Information: []byte{0x00, 0x00, 0x00, 0x05}},
+					{InformationType: 1, InformationLength: 4, Information: []byte{0x00, 0x00, 0x01, 0x00}},
+					{InformationType: 13, InformationLength: 4, Information: []byte{0x00, 0x00, 0x00, 0x07}},
+				},
+				PrefixesRejected:  5,
+				DuplicatePrefixes: 256,
+				DuplicateUpdates:  7,
+			},
+		},
+		{
+			name: "64-bit gauges",
+			input: []byte{
+				0x00, 0x00, 0x00, 0x04,
+				0x00, 0x07, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x08, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x86, 0xA0,
+				0x00, 0x09, 0x00, 0x0B, 0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xE8,
+				0x00, 0x11, 0x00, 0x0B, 0x00, 0x02, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A,
+			},
+			expect: &StatsReport{
+				StatsCount: 4,
+				StatsTLV: []InformationalTLV{
+					{InformationType: 7, InformationLength: 8, Information: []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}},
+					{InformationType: 8, InformationLength: 8, Information: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x86, 0xA0}},
+					{InformationType: 9, InformationLength: 11, Information: []byte{0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xE8}},
+					{InformationType: 17, InformationLength: 11, Information: []byte{0x00, 0x02, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A}},
+				},
+				AdjRIBsIn: 1 << 32,
+				LocRIB:    100000,
+				PerAFISAFIAdjRIBIn: []AFISAFIGauge{
+					{AFI: 1, SAFI: 1, Value: 1000},
+				},
+				PerAFISAFIAdjRIBOutPost: []AFISAFIGauge{
+					{AFI: 2, SAFI: 1, Value: 10},
+				},
+			},
+		},
+		{
+			name: "invalid 32-bit counter length",
+			input: []byte{
+				0x00, 0x00, 0x00, 0x01,
+				0x00, 0x02, 0x00, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			},
+			fail: true,
+		},
+		{
+			name: "invalid 64-bit gauge length",
+			input: []byte{
+				0x00, 0x00, 0x00, 0x01,
+				0x00, 0x07, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01,
+			},
+			fail: true,
+		},
+		{
+			name:  "truncated stats report",
+			input: []byte{0x00, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalBMPStatsReportMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if diff := deep.Equal(tt.expect, got); diff != nil {
+				t.Errorf("Diffs: %+v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package bmp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/tools"
+)
+
+const (
+	// TerminationStringTLV defines Termination TLV carrying free-form UTF-8 string
+	TerminationStringTLV = 0
+	// TerminationReasonTLV defines Termination TLV carrying the reason of the session termination
+	TerminationReasonTLV = 1
+)
+
+// terminationReasons defines descriptions of termination reasons per rfc7854 section 4.5
+var terminationReasons = map[uint16]string{
+	0: "Session administratively closed",
+	1: "Unspecified reason",
+	2: "Out of resources",
+	3: "Redundant connection",
+	4: "Session permanently administratively closed",
+}
+
+// TerminationMessage defines BMP Termination Message per rfc7854
+type TerminationMessage struct {
+	TLV []InformationalTLV
+}
+
+// Reason returns the reason code of the termination, the second returned value is false
+// if the message does not carry the reason.
+func (tm *TerminationMessage) Reason() (uint16, bool) {
+	for _, tlv := range tm.TLV {
+		if tlv.InformationType == TerminationReasonTLV {
+			return binary.BigEndian.Uint16(tlv.Information), true
+		}
+	}
+
+	return 0, false
+}
+
+// ReasonString returns the description of the termination reason
+func (tm *TerminationMessage) ReasonString() string {
+	r, ok := tm.Reason()
+	if !ok {
+		return ""
+	}
+	if s, ok := terminationReasons[r]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("Unknown reason %d", r)
+}
+
+// Strings returns all free-form strings in the order they are found in the message
+func (tm *TerminationMessage) Strings() []string {
+	s := make([]string, 0)
+	for _, tlv := range tm.TLV {
+		if tlv.InformationType == TerminationStringTLV {
+			s = append(s, string(tlv.Information))
+		}
+	}
+
+	return s
+}
+
+// UnmarshalTerminationMessage processes Termination Message and returns TerminationMessage object
+func UnmarshalTerminationMessage(b []byte) (*TerminationMessage, error) {
+	if glog.V(6) {
+		glog.Infof("BMP Termination Message Raw: %s", tools.MessageHex(b))
+	}
+	tlvs, err := UnmarshalTLV(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, tlv := range tlvs {
+		switch tlv.InformationType {
+		case TerminationStringTLV:
+		case TerminationReasonTLV:
+			if tlv.InformationLength != 2 {
+				return nil, fmt.Errorf("invalid length of Termination reason tlv %d", tlv.InformationLength)
+			}
+		default:
+			return nil, fmt.Errorf("invalid tlv type, expected 0 or 1 found %d", tlv.InformationType)
+		}
+	}
+
+	return &TerminationMessage{
+		TLV: tlvs,
+	}, nil
+}
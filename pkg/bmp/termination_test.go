@@ -0,0 +1,77 @@
+package bmp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalTerminationMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        []byte
+		fail         bool
+		reason       uint16
+		hasReason    bool
+		reasonString string
+		strings      []string
+	}{
+		{
+			name:         "unspecified reason",
+			input:        []byte{0, 1, 0, 2, 0, 1},
+			reason:       1,
+			hasReason:    true,
+			reasonString: "Unspecified reason",
+			strings:      []string{},
+		},
+		{
+			name: "administratively closed with string",
+			input: []byte{
+				0, 0, 0, 11, 'm', 'a', 'i', 'n', 't', 'e', 'n', 'a', 'n', 'c', 'e',
+				0, 1, 0, 2, 0, 0,
+			},
+			reason:       0,
+			hasReason:    true,
+			reasonString: "Session administratively closed",
+			strings:      []string{"maintenance"},
+		},
+		{
+			name:    "string without reason",
+			input:   []byte{0, 0, 0, 3, 'b', 'y', 'e'},
+			strings: []string{"bye"},
+		},
+		{
+			name:  "invalid reason length",
+			input: []byte{0, 1, 0, 1, 0},
+			fail:  true,
+		},
+		{
+			name:  "invalid tlv type",
+			input: []byte{0, 2, 0, 2, 0, 1},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm, err := UnmarshalTerminationMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			reason, ok := tm.Reason()
+			if reason != tt.reason || ok != tt.hasReason {
+				t.Fatalf("expected reason %d present %t, got %d present %t", tt.reason, tt.hasReason, reason, ok)
+			}
+			if got := tm.ReasonString(); got != tt.reasonString {
+				t.Fatalf("expected reason string %q, got %q", tt.reasonString, got)
+			}
+			if got := tm.Strings(); !reflect.DeepEqual(got, tt.strings) {
+				t.Fatalf("expected strings %+v, got %+v", tt.strings, got)
+			}
+		})
+	}
+}
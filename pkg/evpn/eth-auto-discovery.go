@@ -1,6 +1,15 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/sbezverk/gobmp/pkg/base"
+)
+
+// MaxEthTag defines Ethernet Tag ID value MAX-ET, Ethernet Auto Discovery route with MAX-ET is advertised
+// per Ethernet Segment, otherwise per EVI.
+const MaxEthTag = 0xffffffff
 
 // EthAutoDiscovery defines a structure of Route type 1
 // (Ethernet Auto Discovery route type)
@@ -16,6 +25,12 @@ func (t *EthAutoDiscovery) GetRouteTypeSpec() interface{} {
 	return &t
 }
 
+// IsPerES returns true if the route is Ethernet A-D per Ethernet Segment route, false if the route is
+// Ethernet A-D per EVI route
+func (t *EthAutoDiscovery) IsPerES() bool {
+	return len(t.EthTag) == 4 && binary.BigEndian.Uint32(t.EthTag) == MaxEthTag
+}
+
 func (t *EthAutoDiscovery) getRD() string {
 	return t.RD.String()
 }
@@ -55,6 +70,10 @@ func (t *EthAutoDiscovery) getLabel() []*base.Label {
 // UnmarshalEVPNEthAutoDiscovery instantiates new instance of a Ethernet Auto Discovery route type object
 func UnmarshalEVPNEthAutoDiscovery(b []byte) (*EthAutoDiscovery, error) {
 	var err error
+	// RD 8 bytes, ESI 10 bytes, Ethernet Tag ID 4 bytes and MPLS Label 3 bytes
+	if len(b) < 25 {
+		return nil, fmt.Errorf("invalid Ethernet Auto Discovery route length %d", len(b))
+	}
 	t := EthAutoDiscovery{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -1,6 +1,10 @@
 package evpn
 
-import "github.com/sbezverk/gobmp/pkg/base"
+import (
+	"fmt"
+
+	"github.com/sbezverk/gobmp/pkg/base"
+)
 
 // EthernetSegment defines a structure of Route type 4
 // (Ethernet Segment Route)
@@ -52,9 +56,14 @@ func (t *EthernetSegment) getLabel() []*base.Label {
 	return nil
 }
 
-// UnmarshalEVPNEthernetSegment instantiates new instance of an Ethernet Segment Route object
+// UnmarshalEVPNEthernetSegment instantiates new instance of an Ethernet Segment Route object,
+// Originating Router's IP Address is either IPv4 or IPv6 address depending on its length.
 func UnmarshalEVPNEthernetSegment(b []byte) (*EthernetSegment, error) {
 	var err error
+	// RD 8 bytes, ESI 10 bytes and IP Address Length 1 byte
+	if len(b) < 19 {
+		return nil, fmt.Errorf("invalid Ethernet Segment route length %d", len(b))
+	}
 	t := EthernetSegment{}
 	p := 0
 	t.RD, err = base.MakeRD(b[p : p+8])
@@ -69,12 +78,15 @@ func UnmarshalEVPNEthernetSegment(b []byte) (*EthernetSegment, error) {
 	p += 10
 	t.IPAddrLength = b[p]
 	p++
+	if t.IPAddrLength != 32 && t.IPAddrLength != 128 {
+		return nil, fmt.Errorf("invalid Ethernet Segment route Originating Router's IP Address length %d", t.IPAddrLength)
+	}
 	l := int(t.IPAddrLength / 8)
-	if t.IPAddrLength != 0 {
-		t.IPAddr = make([]byte, l)
-		copy(t.IPAddr, b[p:p+l])
-		p += l
+	if p+l != len(b) {
+		return nil, fmt.Errorf("invalid Ethernet Segment route length %d for IP Address length %d", len(b), t.IPAddrLength)
 	}
+	t.IPAddr = make([]byte, l)
+	copy(t.IPAddr, b[p:p+l])
 
 	return &t, nil
 }
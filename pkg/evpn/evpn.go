@@ -99,11 +99,17 @@ func UnmarshalEVPNNLRI(b []byte) (*Route, error) {
 	for p := 0; p < len(b); {
 		var err error
 		n := &NLRI{}
+		if p+2 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal EVPN NLRI")
+		}
 		n.RouteType = b[p]
 		p++
 		n.Length = b[p]
 		p++
 		l := int(n.Length)
+		if p+l > len(b) {
+			return nil, fmt.Errorf("invalid EVPN NLRI length %d", l)
+		}
 		switch n.RouteType {
 		case 1:
 			n.RouteTypeSpec, err = UnmarshalEVPNEthAutoDiscovery(b[p : p+l])
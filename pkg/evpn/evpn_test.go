@@ -97,6 +97,33 @@ func TestUnmarshalEVPNNLRI(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "type 1 route per ethernet segment nlri",
+			input: []byte{0x01, 0x19, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x01},
+			expect: &Route{
+				Route: []*NLRI{
+					{
+						RouteType: 1,
+						Length:    0x19,
+						RouteTypeSpec: &EthAutoDiscovery{
+							RD: &base.RD{
+								Type:  0,
+								Value: []byte{0x00, 0xc8, 0x00, 0x00, 0x00, 0x32},
+							},
+							ESI:    esi3,
+							EthTag: []byte{0xff, 0xff, 0xff, 0xff},
+							Label: []*base.Label{
+								{
+									Value: 0,
+									Exp:   0,
+									BoS:   true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:  "real type 4 route nlri",
 			input: []byte{0x04, 0x17, 0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x20, 0xac, 0x1f, 0x65, 0x06},
@@ -268,3 +295,114 @@ func TestUnmarshalEVPNNLRI(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalEVPNEthAutoDiscovery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		perES bool
+		fail  bool
+	}{
+		{
+			name:  "per evi",
+			input: []byte{0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x00, 0x00, 0x00, 0x64, 0x18, 0xa9, 0xb1},
+			perES: false,
+		},
+		{
+			name:  "per ethernet segment",
+			input: []byte{0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x01},
+			perES: true,
+		},
+		{
+			name:  "missing label",
+			input: []byte{0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0xff, 0xff, 0xff, 0xff},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalEVPNEthAutoDiscovery(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("expected to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if got.IsPerES() != tt.perES {
+				t.Errorf("expected per ethernet segment %t, got %t", tt.perES, got.IsPerES())
+			}
+		})
+	}
+}
+
+func TestUnmarshalEVPNNLRITruncated(t *testing.T) {
+	// Route type 1 with length 25, but only 24 bytes of the route follow
+	input := []byte{0x01, 0x19, 0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x00, 0x00, 0x00, 0x00, 0x18, 0xa9}
+	if _, err := UnmarshalEVPNNLRI(input); err == nil {
+		t.Fatalf("expected to fail but succeeded")
+	}
+}
+
+func TestUnmarshalEVPNEthernetSegment(t *testing.T) {
+	rd, _ := base.MakeRD([]byte{0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00})
+	esi, _ := MakeESI([]byte{0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11})
+	tests := []struct {
+		name   string
+		input  []byte
+		expect *EthernetSegment
+		fail   bool
+	}{
+		{
+			name:  "ipv4 originating router",
+			input: []byte{0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x20, 0xac, 0x1f, 0x65, 0x06},
+			expect: &EthernetSegment{
+				RD:           rd,
+				ESI:          esi,
+				IPAddrLength: 32,
+				IPAddr:       []byte{0xac, 0x1f, 0x65, 0x06},
+			},
+		},
+		{
+			name:  "ipv6 originating router",
+			input: []byte{0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x80, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			expect: &EthernetSegment{
+				RD:           rd,
+				ESI:          esi,
+				IPAddrLength: 128,
+				IPAddr:       []byte{0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			},
+		},
+		{
+			name:  "invalid ip address length",
+			input: []byte{0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x18, 0xac, 0x1f, 0x65},
+			fail:  true,
+		},
+		{
+			name:  "truncated ip address",
+			input: []byte{0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x20, 0xac, 0x1f, 0x65},
+			fail:  true,
+		},
+		{
+			name:  "missing ip address length",
+			input: []byte{0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, 0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalEVPNEthernetSegment(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("expected to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if err == nil && !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("expected ethernet segment route %+v does not match actual %+v", tt.expect, got)
+			}
+		})
+	}
+}
@@ -57,10 +57,24 @@ const (
 	Type11 SpecType = 11
 	// Type12 defines Flowspec Specification type for Fragment
 	Type12 SpecType = 12
+	// Type13 defines Flowspec Specification type for IPv6 Flow Label
+	Type13 SpecType = 13
 )
 
-// UnmarshalFlowspecNLRI creates an instance of Flowspec NLRI from a slice of bytes
+// UnmarshalFlowspecNLRI creates an instance of IPv4 Flowspec NLRI from a slice of bytes
 func UnmarshalFlowspecNLRI(b []byte) (*NLRI, error) {
+	return unmarshalFlowspecNLRI(b, false)
+}
+
+// UnmarshalFlowspecNLRIv6 creates an instance of IPv6 Flowspec NLRI from a slice of bytes, prefix components
+// of AFI 2 Flowspec NLRI carry the offset of the prefix, rfc8956.
+func UnmarshalFlowspecNLRIv6(b []byte) (*NLRI, error) {
+	return unmarshalFlowspecNLRI(b, true)
+}
+
+// unmarshalFlowspecNLRI creates an instance of Flowspec NLRI from a slice of bytes, ipv6 indicates
+// AFI 2 Flowspec NLRI which prefix components carry the offset of the prefix, rfc8956.
+func unmarshalFlowspecNLRI(b []byte, ipv6 bool) (*NLRI, error) {
 	if glog.V(5) {
 		glog.Infof("Flowspec NLRI Raw: %s", tools.MessageHex(b))
 	}
@@ -70,8 +84,11 @@ func UnmarshalFlowspecNLRI(b []byte) (*NLRI, error) {
 	fs := &NLRI{}
 	p := 0
 	if b[p]&0xf0 == 0xf0 {
-		// NLRI length is encoded into 2 bytes
-		binary.BigEndian.PutUint16(b[p:p+2], fs.Length)
+		// NLRI length is encoded into 2 bytes, the first nibble is not a part of the length
+		if len(b) < 2 {
+			return nil, fmt.Errorf("not enough bytes to unmarshal Flowspec NLRI length")
+		}
+		fs.Length = binary.BigEndian.Uint16(b[p:p+2]) & 0x0fff
 		p += 2
 	} else {
 		// Otherwise it is encoded in the single byte
@@ -90,7 +107,7 @@ func UnmarshalFlowspecNLRI(b []byte) (*NLRI, error) {
 		case Type1:
 			fallthrough
 		case Type2:
-			spec, l, err = makePrefixSpec(b[p:])
+			spec, l, err = makePrefixSpec(b[p:], ipv6)
 			if err != nil {
 				return nil, err
 			}
@@ -109,14 +126,19 @@ func UnmarshalFlowspecNLRI(b []byte) (*NLRI, error) {
 		case Type10:
 			fallthrough
 		case Type11:
-			spec, l, err = makeGenericSpec(b[p:])
+			fallthrough
+		case Type13:
+			spec, l, err = makeGenericSpec(b[p:], false)
 			if err != nil {
 				return nil, err
 			}
 		case Type9:
 			fallthrough
 		case Type12:
-			return nil, fmt.Errorf("not implemented Flowspec type: %+v", t)
+			spec, l, err = makeGenericSpec(b[p:], true)
+			if err != nil {
+				return nil, err
+			}
 		default:
 			return nil, fmt.Errorf("unknown Flowspec type: %+v", t)
 		}
@@ -135,17 +157,41 @@ func UnmarshalFlowspecNLRI(b []byte) (*NLRI, error) {
 	return fs, nil
 }
 
-// Operator defines a data structure representing Flowspec operator byte
+// Operator defines a data structure representing Flowspec operator byte, LTBit, GTBit and EQBit are set by
+// numeric operators, NOTBit and MatchBit by bitmask operators of TCP Flags and Fragment types.
 type Operator struct {
-	EOLBit bool
-	ANDBit bool
-	Length uint8
-	LTBit  bool
-	GTBit  bool
-	EQBit  bool
+	EOLBit   bool
+	ANDBit   bool
+	Length   uint8
+	LTBit    bool
+	GTBit    bool
+	EQBit    bool
+	NOTBit   bool
+	MatchBit bool
+}
+
+// UnmarshalFlowspecBitmaskOperator creates an instance of Operator object from a bitmask operator byte
+func UnmarshalFlowspecBitmaskOperator(b byte) (*Operator, error) {
+	o := &Operator{}
+	if b&0x80 == 0x80 {
+		o.EOLBit = true
+	}
+	if b&0x40 == 0x40 {
+		o.ANDBit = true
+	}
+	l := (b & 0x30) >> 4
+	o.Length = 1 << l
+	if b&0x02 == 0x02 {
+		o.NOTBit = true
+	}
+	if b&0x01 == 0x01 {
+		o.MatchBit = true
+	}
+
+	return o, nil
 }
 
-// UnmarshalFlowspecOperator creates an instance of Operator object from a byte
+// UnmarshalFlowspecOperator creates an instance of Operator object from a numeric operator byte
 func UnmarshalFlowspecOperator(b byte) (*Operator, error) {
 	o := &Operator{}
 	if b&0x80 == 0x80 {
@@ -172,19 +218,23 @@ func UnmarshalFlowspecOperator(b byte) (*Operator, error) {
 // MarshalJSON returns a binary representation of Flowspec Operator structure
 func (o *Operator) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		EOLBit bool  `json:"end_of_list_bit,omitempty"`
-		ANDBit bool  `json:"and_bit,omitempty"`
-		Length uint8 `json:"value_length,omitempty"`
-		LTBit  bool  `json:"less_than,omitempty"`
-		GTBit  bool  `json:"greater_than,omitempty"`
-		EQBit  bool  `json:"equal,omitempty"`
+		EOLBit   bool  `json:"end_of_list_bit,omitempty"`
+		ANDBit   bool  `json:"and_bit,omitempty"`
+		Length   uint8 `json:"value_length,omitempty"`
+		LTBit    bool  `json:"less_than,omitempty"`
+		GTBit    bool  `json:"greater_than,omitempty"`
+		EQBit    bool  `json:"equal,omitempty"`
+		NOTBit   bool  `json:"not,omitempty"`
+		MatchBit bool  `json:"match,omitempty"`
 	}{
-		EOLBit: o.EOLBit,
-		ANDBit: o.ANDBit,
-		Length: o.Length,
-		LTBit:  o.LTBit,
-		GTBit:  o.GTBit,
-		EQBit:  o.EQBit,
+		EOLBit:   o.EOLBit,
+		ANDBit:   o.ANDBit,
+		Length:   o.Length,
+		LTBit:    o.LTBit,
+		GTBit:    o.GTBit,
+		EQBit:    o.EQBit,
+		NOTBit:   o.NOTBit,
+		MatchBit: o.MatchBit,
 	})
 
 }
@@ -201,26 +251,49 @@ func (o *Operator) UnmarshalJSON(b []byte) error {
 }
 
 // PrefixSpec defines a structure of Flowspec Type 1 and Type 2 (Destination/Source Prefix) spec.
+// Offset is carried only by IPv6 prefix specs, rfc8956.
 type PrefixSpec struct {
 	SpecType     uint8  `json:"type"`
 	PrefixLength uint8  `json:"prefix_len"`
+	Offset       uint8  `json:"offset,omitempty"`
 	Prefix       []byte `json:"prefix"`
 }
 
-func makePrefixSpec(b []byte) (Spec, int, error) {
+func makePrefixSpec(b []byte, ipv6 bool) (Spec, int, error) {
 	s := &PrefixSpec{}
 	p := 0
+	if len(b) < 2 {
+		return nil, 0, fmt.Errorf("not enough bytes to unmarshal Flowspec prefix spec")
+	}
 	s.SpecType = b[p]
 	p++
 	s.PrefixLength = b[p]
+	p++
 	l := int(s.PrefixLength / 8)
-	if b[p]%8 != 0 {
+	if s.PrefixLength%8 != 0 {
 		l++
 	}
-	p++
+	if ipv6 {
+		if p >= len(b) {
+			return nil, 0, fmt.Errorf("not enough bytes to unmarshal Flowspec prefix spec offset")
+		}
+		s.Offset = b[p]
+		p++
+		if s.Offset > s.PrefixLength {
+			return nil, 0, fmt.Errorf("invalid Flowspec prefix spec offset %d for prefix length %d", s.Offset, s.PrefixLength)
+		}
+		// Only the bits of the prefix after the offset are carried
+		l = int(s.PrefixLength-s.Offset) / 8
+		if (s.PrefixLength-s.Offset)%8 != 0 {
+			l++
+		}
+	}
+	if p+l > len(b) {
+		return nil, 0, fmt.Errorf("not enough bytes to unmarshal Flowspec prefix spec of length %d", s.PrefixLength)
+	}
 	s.Prefix = make([]byte, l)
 	copy(s.Prefix, b[p:p+l])
-	p += int(l)
+	p += l
 
 	return s, p, nil
 }
@@ -241,10 +314,12 @@ func (t *PrefixSpec) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		SpecType     uint8  `json:"type"`
 		PrefixLength uint8  `json:"prefix_len"`
+		Offset       uint8  `json:"offset,omitempty"`
 		Prefix       []byte `json:"prefix"`
 	}{
 		SpecType:     t.SpecType,
 		PrefixLength: t.PrefixLength,
+		Offset:       t.Offset,
 		Prefix:       t.Prefix,
 	})
 }
@@ -277,15 +352,30 @@ func (o *OpVal) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// UnmarshalOpVal creates a slice of Operator/Value pairs
+// UnmarshalOpVal creates a slice of Operator/Value pairs with numeric operators
 func UnmarshalOpVal(b []byte) ([]*OpVal, error) {
+	return unmarshalOpVal(b, false)
+}
+
+// UnmarshalBitmaskOpVal creates a slice of Operator/Value pairs with bitmask operators
+func UnmarshalBitmaskOpVal(b []byte) ([]*OpVal, error) {
+	return unmarshalOpVal(b, true)
+}
+
+func unmarshalOpVal(b []byte, bitmask bool) ([]*OpVal, error) {
 	opvals := make([]*OpVal, 0)
 	p := 0
 	// Skip type
 	p++
 	eol := false
 	for !eol && p < len(b) {
-		o, err := UnmarshalFlowspecOperator(b[p])
+		var o *Operator
+		var err error
+		if bitmask {
+			o, err = UnmarshalFlowspecBitmaskOperator(b[p])
+		} else {
+			o, err = UnmarshalFlowspecOperator(b[p])
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -308,22 +398,26 @@ func UnmarshalOpVal(b []byte) ([]*OpVal, error) {
 	return opvals, nil
 }
 
-// GenericSpec defines a structure of Flowspec Types (3,4,5,6,7,8,10,11) specs.
+// GenericSpec defines a structure of Flowspec Types (3,4,5,6,7,8,9,10,11,12,13) specs, Operators of
+// Types 9 (TCP Flags) and 12 (Fragment) are bitmask operators, of other types numeric operators.
 type GenericSpec struct {
 	SpecType uint8    `json:"type,omitempty"`
 	OpVal    []*OpVal `json:"op_val_pairs,omitempty"`
 }
 
-func makeGenericSpec(b []byte) (Spec, int, error) {
+func makeGenericSpec(b []byte, bitmask bool) (Spec, int, error) {
 	s := &GenericSpec{}
 	var err error
 	p := 0
 	s.SpecType = b[p]
 	p++
-	s.OpVal, err = UnmarshalOpVal(b)
+	s.OpVal, err = unmarshalOpVal(b, bitmask)
 	if err != nil {
 		return nil, 0, err
 	}
+	if len(s.OpVal) == 0 || !s.OpVal[len(s.OpVal)-1].Op.EOLBit {
+		return nil, 0, fmt.Errorf("Flowspec type %d Operator/Value list is not terminated", s.SpecType)
+	}
 	// Calculate total Spec length
 	for _, ov := range s.OpVal {
 		if ov == nil {
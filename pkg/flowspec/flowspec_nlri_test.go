@@ -54,6 +54,90 @@ func TestUnmarshalFlowspecNLRI(t *testing.T) {
 			},
 			fail: false,
 		},
+		{
+			name:  "Type 1 (Destination Prefix) and Type 5 (Destination Port)",
+			input: []byte{0x08, 0x01, 0x18, 0x0A, 0x00, 0x00, 0x05, 0x81, 0x50},
+			expect: &NLRI{
+				Length: 8,
+				Spec: []Spec{
+					&PrefixSpec{
+						SpecType:     1,
+						PrefixLength: 24,
+						Prefix:       []byte{0x0A, 0x00, 0x00},
+					},
+					&GenericSpec{
+						SpecType: 5,
+						OpVal: []*OpVal{
+							{
+								Op: &Operator{
+									EOLBit: true,
+									Length: 1,
+									EQBit:  true,
+								},
+								Val: []byte{0x50},
+							},
+						},
+					},
+				},
+				SpecHash: "bcc00529da530dd7874a33d6f6c0fd36",
+			},
+		},
+		{
+			name:  "Type 4 (Port) range and Type 9 (TCP Flags)",
+			input: []byte{0xF0, 0x0A, 0x04, 0x13, 0x04, 0x00, 0xD5, 0x04, 0x01, 0x09, 0x81, 0x02},
+			expect: &NLRI{
+				Length: 10,
+				Spec: []Spec{
+					&GenericSpec{
+						SpecType: 4,
+						OpVal: []*OpVal{
+							{
+								Op: &Operator{
+									Length: 2,
+									GTBit:  true,
+									EQBit:  true,
+								},
+								Val: []byte{0x04, 0x00},
+							},
+							{
+								Op: &Operator{
+									EOLBit: true,
+									ANDBit: true,
+									Length: 2,
+									LTBit:  true,
+									EQBit:  true,
+								},
+								Val: []byte{0x04, 0x01},
+							},
+						},
+					},
+					&GenericSpec{
+						SpecType: 9,
+						OpVal: []*OpVal{
+							{
+								Op: &Operator{
+									EOLBit:   true,
+									Length:   1,
+									MatchBit: true,
+								},
+								Val: []byte{0x02},
+							},
+						},
+					},
+				},
+				SpecHash: "003cb49342e6b8cc4d9bb3324fdd7c60",
+			},
+		},
+		{
+			name:  "Operator/Value list without end of list bit",
+			input: []byte{0x03, 0x03, 0x01, 0x2F},
+			fail:  true,
+		},
+		{
+			name:  "Truncated prefix",
+			input: []byte{0x03, 0x01, 0x18, 0x0A},
+			fail:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -61,6 +145,9 @@ func TestUnmarshalFlowspecNLRI(t *testing.T) {
 			if err != nil && !tt.fail {
 				t.Fatalf("failed with error: %+v", err)
 			}
+			if err == nil && tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
 			if !reflect.DeepEqual(tt.expect, got) {
 				t.Logf("Diffs: %+v", deep.Equal(tt.expect, got))
 				t.Fatalf("expected NLRI %+v does not match marshaled NLRI: %+v", tt.expect, got)
@@ -68,3 +155,61 @@ func TestUnmarshalFlowspecNLRI(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalFlowspecIPv6NLRI(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect []Spec
+		fail   bool
+	}{
+		{
+			name: "Destination Prefix with offset 0 and Source Prefix with offset 32",
+			input: []byte{
+				0x0E,
+				0x01, 0x20, 0x00, 0x20, 0x01, 0x0D, 0xB8, // 2001:db8::/32
+				0x02, 0x40, 0x20, 0x00, 0x00, 0x00, 0x01, // ::1 bits 32-63 of /64
+			},
+			expect: []Spec{
+				&PrefixSpec{
+					SpecType:     1,
+					PrefixLength: 32,
+					Prefix:       []byte{0x20, 0x01, 0x0D, 0xB8},
+				},
+				&PrefixSpec{
+					SpecType:     2,
+					PrefixLength: 64,
+					Offset:       32,
+					Prefix:       []byte{0x00, 0x00, 0x00, 0x01},
+				},
+			},
+		},
+		{
+			name:  "Offset exceeds prefix length",
+			input: []byte{0x04, 0x01, 0x08, 0x10, 0x00},
+			fail:  true,
+		},
+		{
+			name:  "Truncated prefix",
+			input: []byte{0x05, 0x01, 0x20, 0x00, 0x20, 0x01},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalFlowspecNLRIv6(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if diff := deep.Equal(tt.expect, got.Spec); diff != nil {
+				t.Errorf("Diffs: %+v", diff)
+			}
+		})
+	}
+}
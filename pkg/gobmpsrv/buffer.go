@@ -0,0 +1,33 @@
+package gobmpsrv
+
+const (
+	// messageBlockSize defines the size of blocks BMP messages of a session are read into
+	messageBlockSize = 64 << 10
+	// maxBlockMessageLength defines the longest BMP message read into a block, longer messages are
+	// allocated separately so a block is not wasted on a single message
+	maxBlockMessageLength = messageBlockSize / 4
+)
+
+// messageBuffer allocates buffers BMP messages of a session are read into. A message handed to the parser
+// is referenced by parsed messages, the producer, the tap and the interceptor, for as long as any of them
+// needs it, so buffers cannot be returned to a pool and reused. Instead, buffers are carved from blocks
+// allocated once for many messages, a block is never written again once a buffer is carved from it and
+// it is collected once all messages read into it are released.
+type messageBuffer struct {
+	block []byte
+}
+
+// get returns a buffer of n bytes, the capacity of the buffer is n, so appending to it does not
+// overwrite the following message.
+func (mb *messageBuffer) get(n int) []byte {
+	if n > maxBlockMessageLength {
+		return make([]byte, n)
+	}
+	if len(mb.block) < n {
+		mb.block = make([]byte, messageBlockSize)
+	}
+	b := mb.block[:n:n]
+	mb.block = mb.block[n:]
+
+	return b
+}
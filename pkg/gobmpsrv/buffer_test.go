@@ -0,0 +1,69 @@
+package gobmpsrv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestMessageBuffer(t *testing.T) {
+	var mb messageBuffer
+	msgs := make([][]byte, 0)
+	for n := 0; n < 2*messageBlockSize/len(peerUpMsg()); n++ {
+		b := mb.get(len(peerUpMsg()))
+		if len(b) != len(peerUpMsg()) || cap(b) != len(peerUpMsg()) {
+			t.Fatalf("expected buffer of length and capacity %d, got %d and %d", len(peerUpMsg()), len(b), cap(b))
+		}
+		copy(b, peerUpMsg())
+		msgs = append(msgs, b)
+	}
+	// Appending to a buffer must not overwrite the following one
+	_ = append(msgs[0], 0xFF)
+	for i, b := range msgs {
+		if !bytes.Equal(b, peerUpMsg()) {
+			t.Fatalf("message %d is overwritten, got %v", i, b)
+		}
+	}
+	if b := mb.get(maxBlockMessageLength + 1); len(b) != maxBlockMessageLength+1 {
+		t.Fatalf("expected buffer of length %d, got %d", maxBlockMessageLength+1, len(b))
+	}
+}
+
+func benchmarkMessageBuffer(b *testing.B, blocks bool) {
+	const count = 1000
+	input := bytes.Repeat(peerUpMsg(), count)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		opts := []bmp.MessageReaderOption{}
+		if blocks {
+			var buffers messageBuffer
+			opts = append(opts, bmp.WithAllocator(buffers.get))
+		}
+		reader := bmp.NewMessageReader(bufio.NewReaderSize(bytes.NewReader(input), readBufferSize), opts...)
+		n := 0
+		for ; ; n++ {
+			_, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatalf("failed to read message with error: %+v", err)
+			}
+		}
+		if n != count {
+			b.Fatalf("expected to read %d messages, read %d", count, n)
+		}
+	}
+}
+
+func BenchmarkMessageBufferMake(b *testing.B) {
+	benchmarkMessageBuffer(b, false)
+}
+
+func BenchmarkMessageBufferBlocks(b *testing.B) {
+	benchmarkMessageBuffer(b, true)
+}
@@ -0,0 +1,17 @@
+package gobmpsrv
+
+// FramingAdapter receives the framing header followed by Common Header of a BMP message as read from
+// the client and returns Common Header of the message, for example with the framing header stripped.
+// The returned error closes the session.
+type FramingAdapter func(b []byte) ([]byte, error)
+
+// WithFraming makes the server read length bytes of framing header preceding Common Header of every
+// BMP message received from clients and pass both to adapter, the message body is read right after
+// Common Header, see bmp.WithFraming. It allows receiving BMP messages wrapped in a proprietary framing
+// header. A nil adapter strips the framing header. By default BMP messages are not framed.
+func WithFraming(length int, adapter FramingAdapter) Option {
+	return func(srv *bmpServer) {
+		srv.framingLength = length
+		srv.framing = adapter
+	}
+}
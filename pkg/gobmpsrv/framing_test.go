@@ -0,0 +1,46 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// stripPrefix is a framing adapter stripping 4 bytes framing header
+func stripPrefix(b []byte) ([]byte, error) {
+	if !bytes.Equal(b[:4], []byte{0xca, 0xfe, 0x00, 0x01}) {
+		return nil, errors.New("invalid framing header")
+	}
+
+	return b[4:], nil
+}
+
+func TestServerFraming(t *testing.T) {
+	publisher := &recordingPublisher{msgs: make(chan int, 1)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"), WithFraming(4, stripPrefix))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(append([]byte{0xca, 0xfe, 0x00, 0x01}, peerUpMsg()...)); err != nil {
+		t.Fatalf("failed to send message with error: %+v", err)
+	}
+	select {
+	case msgType := <-publisher.msgs:
+		if msgType != bmp.PeerStateChangeMsg {
+			t.Fatalf("expected message type %d, got %d", bmp.PeerStateChangeMsg, msgType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("message has not been published")
+	}
+}
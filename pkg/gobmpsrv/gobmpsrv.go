@@ -1,137 +1,1002 @@
 package gobmpsrv
 
 import (
-	"fmt"
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
 	"io"
+	"log/slog"
+	"math"
+	"math/rand"
 	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/logging"
 	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/parser"
 	"github.com/sbezverk/gobmp/pkg/pub"
 )
 
+var (
+	errServerStopping     = errors.New("server is stopping")
+	errTooManyConnections = errors.New("maximum number of connections reached")
+)
+
+const (
+	// defaultMaxMessageLength defines the default maximum length of a BMP message accepted from a client
+	defaultMaxMessageLength = 1 << 20
+	// readBufferSize defines the size of the buffer used to read BMP messages from a client, multiple
+	// messages are read with a single read from the connection when the client sends them back to back
+	readBufferSize = 64 * 1024
+	// defaultMaxRetries defines the default number of consecutive failed attempts after which
+	// connecting to a passive router or accepting clients is abandoned
+	defaultMaxRetries = 10
+	// defaultRetryInterval defines the default base interval between retries
+	defaultRetryInterval = 30 * time.Second
+	// defaultMaxRetryInterval defines the default maximum interval between retries
+	defaultMaxRetryInterval = 5 * time.Minute
+)
+
+// Backoff defines how the interval between retries grows with the number of failed attempts
+type Backoff int
+
+const (
+	// LinearBackoff waits the base interval multiplied by the number of failed attempts
+	LinearBackoff Backoff = iota
+	// ExponentialBackoff doubles the interval with every failed attempt, the interval is randomized
+	// between a half and a full value to avoid reconnecting to many routers at the same time
+	ExponentialBackoff
+)
+
+var (
+	// stopTimeout defines how long Stop waits for active clients to drain already received messages
+	stopTimeout = 10 * time.Second
+	// passiveConnectTimeout defines how long to wait for a passive router to accept the connection
+	passiveConnectTimeout = 10 * time.Second
+)
+
 // BMPServer defines methods to manage BMP Server
 type BMPServer interface {
 	Start()
 	Stop()
+	StopWithContext(ctx context.Context)
+	Health() HealthStatus
 }
 
 type bmpServer struct {
-	splitAF         bool
-	intercept       bool
-	publisher       pub.Publisher
+	splitAF     bool
+	intercept   bool
+	publisher   pub.Publisher
+	health      *healthPublisher
+	bindAddress string
+	// tlsConfig when set makes the server negotiate TLS with incoming clients
+	tlsConfig *tls.Config
+	// listenConfig and reuseAddress when set configure the listener of incoming clients
+	listenConfig *net.ListenConfig
+	reuseAddress bool
+	// addPath is a list of NLRI types with Add-Path enabled for all clients
+	addPath []int
+	// splitNLRITypes is a list of NLRI types split into ipv4 and ipv6 topics when splitAF is set,
+	// an empty list splits all NLRI types
+	splitNLRITypes []int
+	// logger is used for records of the server and all its clients
+	logger *slog.Logger
+	// filters are evaluated by producers of all clients for every BMP message
+	filters []message.Filter
+	// messageTypes when not nil limits published BMP messages to these BMP message types
+	messageTypes map[byte]bool
+	// transforms are applied by producers of all clients to every BMP message accepted by filters
+	transforms []message.Transform
+	// rawMessage when set makes producers attach the original BMP message to every published message
+	rawMessage bool
+	// messageID when set makes producers attach an identifier derived from the content to every published message
+	messageID bool
+	// attrFlags when set makes producers attach Attribute Flags of path attributes to base attributes
+	attrFlags bool
+	// unknownAttrs when set makes producers attach path attributes not decoded by gobmp to base attributes
+	unknownAttrs bool
+	// endOfRIB when set makes producers publish End-of-RIB markers received from peers
+	endOfRIB bool
+	// peerEvents when set makes producers publish peer events along with PeerStateChange messages
+	peerEvents bool
+	// parseConcurrency when not 0 is the number of workers parsing messages of a session, parsed messages
+	// are published in the order they are received
+	parseConcurrency int
+	// parseErrors when set makes producers publish BMP messages failed to be parsed
+	parseErrors bool
+	// routerHashFunc when set derives RouterHash of messages published by producers of all clients
+	routerHashFunc message.RouterHashFunc
+	// publishTimeout when not 0 fails publishes of producers not completed within the timeout
+	publishTimeout time.Duration
+	// queueCapacity when not 0 makes producers queue received messages, dropPolicy defines what
+	// happens when the queue is full
+	queueCapacity int
+	dropPolicy    message.DropPolicy
+	// resyncMaxSkip when not 0 makes the server skip up to resyncMaxSkip bytes looking for a plausible
+	// Common Header after receiving an invalid one instead of closing the session
+	resyncMaxSkip int
+	// framingLength bytes of framing header precede every BMP message, framing when set converts
+	// the framing header and Common Header to Common Header
+	framingLength int
+	framing       FramingAdapter
+	// tapDir when set makes the server write messages of clients matching tapMatch to files
+	// of up to tapMaxSize bytes in the directory
+	tapDir     string
+	tapMaxSize int64
+	tapMatch   func(remoteAddr string) bool
+	// onConnect and onDisconnect when set are called when a BMP session starts and ends
+	onConnect    func(remoteAddr string)
+	onDisconnect func(remoteAddr string, err error)
+	// passiveRouters is a list of routers expecting the collector to connect to them
+	passiveRouters  []string
 	sourcePort      int
 	destinationPort int
-	incoming        net.Listener
-	stop            chan struct{}
+	// destinationAddress is the address of the collector messages are copied to in intercept mode,
+	// up to interceptBuffer messages wait to be copied while the collector is not reachable
+	destinationAddress string
+	interceptBuffer    int
+	// maxConnections limits the number of active BMP sessions, 0 means unlimited
+	maxConnections int
+	// maxMessageLength defines the maximum length of a BMP message, a client sending
+	// a longer message gets disconnected
+	maxMessageLength int
+	// readTimeout defines how long to wait for the next message from a client, 0 means forever,
+	// when idleKeepalive is set, the session of the idle client is kept and waiting is restarted
+	readTimeout   time.Duration
+	idleKeepalive bool
+	// keepAlive when set enables TCP keepalive on connections of clients, keepAliveIdle, keepAliveInterval
+	// and keepAliveCount when not 0 override system defaults
+	keepAlive         bool
+	keepAliveIdle     time.Duration
+	keepAliveInterval time.Duration
+	keepAliveCount    int
+	// maxRetries is the number of consecutive failed attempts after which connecting to
+	// a passive router or accepting clients is abandoned, 0 means retrying forever
+	maxRetries int
+	// retryInterval is the base interval between retries, it grows according to backoff
+	// with every failed attempt but never exceeds maxRetryInterval
+	retryInterval    time.Duration
+	maxRetryInterval time.Duration
+	backoff          Backoff
+	incoming         net.Listener
+	ctx              context.Context
+	stop             chan struct{}
+	stopOnce         sync.Once
+	metrics          *metrics.Metrics
+	// wg tracks active bmpWorkers, clients keeps their connections to be able to
+	// interrupt reads and to force close them when draining takes too long, the value
+	// is true once the client has sent Peer Up message.
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	clients map[net.Conn]bool
+	// listening is true while the server accepts clients
+	listening bool
 }
 
 func (srv *bmpServer) Start() {
 	// Starting bmp server server
-	glog.Infof("Starting gobmp server on %s, intercept mode: %t\n", srv.incoming.Addr().String(), srv.intercept)
+	srv.logger.Info("starting gobmp server", "address", srv.incoming.Addr().String(), "intercept", srv.intercept)
+	srv.mu.Lock()
+	srv.listening = !srv.stopping()
+	srv.mu.Unlock()
 	go srv.server()
+	for _, router := range srv.passiveRouters {
+		go srv.passiveConnect(router)
+	}
+	// Tearing down the server when its context gets cancelled
+	go func() {
+		select {
+		case <-srv.ctx.Done():
+			srv.logger.Info("gobmp server context is done", "error", srv.ctx.Err())
+			srv.Stop()
+		case <-srv.stop:
+		}
+	}()
 }
 
+// Stop stops the server giving active clients stopTimeout to drain already received messages.
 func (srv *bmpServer) Stop() {
-	glog.Infof("Stopping gobmp server\n")
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	srv.StopWithContext(ctx)
+}
+
+// StopWithContext stops accepting new clients and signals all active clients to stop reading
+// new BMP messages. Messages already received are parsed and published, StopWithContext waits
+// for it until ctx is done, then remaining client connections are force closed. The publisher
+// is stopped when all clients are done, messages being published are not lost.
+// Only the first call has effect.
+func (srv *bmpServer) StopWithContext(ctx context.Context) {
+	srv.stopOnce.Do(func() {
+		srv.stopWithContext(ctx)
+	})
+}
+
+func (srv *bmpServer) stopWithContext(ctx context.Context) {
+	srv.logger.Info("stopping gobmp server")
+	srv.mu.Lock()
+	close(srv.stop)
+	srv.incoming.Close()
+	for client := range srv.clients {
+		// Unblocking the worker waiting for the next message from the client
+		client.SetReadDeadline(time.Now())
+	}
+	srv.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		srv.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		srv.logger.Debug("all clients have been drained")
+	case <-ctx.Done():
+		srv.mu.Lock()
+		for client := range srv.clients {
+			srv.logger.Warn("client is still draining, closing connection", "client", client.RemoteAddr().String())
+			client.Close()
+		}
+		srv.mu.Unlock()
+		// Closed clients still publish messages handed to the producer, the publisher must outlive them
+		<-done
+	}
 	if srv.publisher != nil {
 		srv.publisher.Stop()
 	}
-	close(srv.stop)
 }
 
 func (srv *bmpServer) server() {
+	defer func() {
+		srv.mu.Lock()
+		srv.listening = false
+		srv.mu.Unlock()
+	}()
+	retryCount := 0
 	for {
 		client, err := srv.incoming.Accept()
 		if err != nil {
-			glog.Errorf("fail to accept client connection with error: %+v", err)
+			if srv.stopping() {
+				return
+			}
+			retryCount++
+			if srv.maxRetries > 0 && retryCount > srv.maxRetries {
+				srv.logger.Error("giving up accepting client connections", "attempts", srv.maxRetries, "error", err)
+				return
+			}
+			wait := srv.retryDelay(retryCount)
+			srv.logger.Error("fail to accept client connection, retrying", "error", err, "wait", wait)
+			select {
+			case <-time.After(wait):
+				continue
+			case <-srv.stop:
+				return
+			}
+		}
+		retryCount = 0
+		if err := srv.setKeepAlive(client); err != nil {
+			srv.logger.Warn("fail to set TCP keepalive on client connection", "client", client.RemoteAddr().String(), "error", err)
+		}
+		if err := srv.addClient(client); err != nil {
+			client.Close()
+			if err == errServerStopping {
+				return
+			}
+			srv.logger.Warn("refused client", "client", client.RemoteAddr().String(), "error", err)
+			srv.metrics.ConnectionRefused()
 			continue
 		}
-		glog.V(5).Infof("client %+v accepted, calling bmpWorker", client.RemoteAddr())
+		srv.logger.Debug("client accepted, calling bmpWorker", "client", client.RemoteAddr().String())
 		go srv.bmpWorker(client)
 	}
 }
 
-func (srv *bmpServer) bmpWorker(client net.Conn) {
-	defer client.Close()
-	var server net.Conn
-	var err error
-	if srv.intercept {
-		server, err = net.Dial("tcp", ":"+fmt.Sprintf("%d", srv.destinationPort))
+// passiveConnect connects to a passive router and runs bmpWorker for the established session,
+// when the session ends or the connection fails, passiveConnect attempts to reconnect.
+func (srv *bmpServer) passiveConnect(router string) {
+	dialer := &net.Dialer{Timeout: passiveConnectTimeout}
+	retryCount := 0
+	for {
+		client, err := dialer.DialContext(srv.ctx, "tcp", router)
 		if err != nil {
-			glog.Errorf("failed to connect to destination with error: %+v", err)
+			if srv.stopping() {
+				return
+			}
+			retryCount++
+			if srv.maxRetries > 0 && retryCount > srv.maxRetries {
+				srv.logger.Error("giving up connecting to passive router", "router", router, "attempts", srv.maxRetries)
+				return
+			}
+			wait := srv.retryDelay(retryCount)
+			srv.logger.Warn("fail to connect to passive router, retrying", "router", router, "error", err, "attempt", retryCount, "wait", wait)
+			select {
+			case <-time.After(wait):
+				continue
+			case <-srv.stop:
+				return
+			}
+		}
+		if err := srv.setKeepAlive(client); err != nil {
+			srv.logger.Warn("fail to set TCP keepalive on passive router connection", "router", router, "error", err)
+		}
+		if err := srv.addClient(client); err != nil {
+			client.Close()
+			if err == errServerStopping {
+				return
+			}
+			srv.metrics.ConnectionRefused()
+			// Refused connections count as failed attempts, so reconnecting backs off as well
+			retryCount++
+			wait := srv.retryDelay(retryCount)
+			srv.logger.Warn("refused passive router", "router", router, "error", err, "attempt", retryCount, "wait", wait)
+			select {
+			case <-time.After(wait):
+				continue
+			case <-srv.stop:
+				return
+			}
+		}
+		retryCount = 0
+		srv.logger.Info("connected to passive router", "router", router)
+		srv.bmpWorker(client)
+		if srv.stopping() {
 			return
 		}
-		defer server.Close()
-		glog.V(5).Infof("connection to destination server %v established, start intercepting", server.RemoteAddr())
+		srv.logger.Info("session with passive router is closed, reconnecting", "router", router)
 	}
-	var producerQueue chan bmp.Message
-	prod := message.NewProducer(srv.publisher, srv.splitAF)
-	prodStop := make(chan struct{})
-	producerQueue = make(chan bmp.Message)
-	// Starting messages producer per client with dedicated work queue
-	go prod.Producer(producerQueue, prodStop)
+}
 
-	parserQueue := make(chan []byte)
-	parsStop := make(chan struct{})
-	// Starting parser per client with dedicated work queue
-	go parser.Parser(parserQueue, producerQueue, parsStop)
+// retryDelay returns the interval to wait before the next attempt after retryCount consecutive failures
+func (srv *bmpServer) retryDelay(retryCount int) time.Duration {
+	var delay time.Duration
+	switch srv.backoff {
+	case ExponentialBackoff:
+		delay = srv.retryInterval
+		// 0 maximum interval means the delay is not capped, doubling stops before it overflows
+		for i := 1; i < retryCount && delay <= math.MaxInt64/2; i++ {
+			if srv.maxRetryInterval > 0 && delay >= srv.maxRetryInterval {
+				break
+			}
+			delay *= 2
+		}
+	default:
+		delay = time.Duration(retryCount) * srv.retryInterval
+	}
+	if srv.maxRetryInterval > 0 && delay > srv.maxRetryInterval {
+		delay = srv.maxRetryInterval
+	}
+	if srv.backoff == ExponentialBackoff && delay > 1 {
+		// Adding jitter, the actual delay is between a half and a full computed interval
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+
+	return delay
+}
+
+// stopping returns true if the server has been requested to stop
+func (srv *bmpServer) stopping() bool {
+	select {
+	case <-srv.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// addClient registers the client as an active one, if the server is stopping or the maximum
+// number of connections is reached, the client is not registered and the error is returned.
+func (srv *bmpServer) addClient(client net.Conn) error {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.stopping() {
+		return errServerStopping
+	}
+	if srv.maxConnections > 0 && len(srv.clients) >= srv.maxConnections {
+		return errTooManyConnections
+	}
+	srv.clients[client] = false
+	srv.wg.Add(1)
+	srv.metrics.SessionUp()
+
+	return nil
+}
+
+// sessionEstablished marks the client as the one which has sent Peer Up message
+func (srv *bmpServer) sessionEstablished(client net.Conn) {
+	srv.mu.Lock()
+	if _, ok := srv.clients[client]; ok {
+		srv.clients[client] = true
+	}
+	srv.mu.Unlock()
+}
+
+func (srv *bmpServer) removeClient(client net.Conn) {
+	srv.mu.Lock()
+	delete(srv.clients, client)
+	srv.mu.Unlock()
+	srv.metrics.SessionDown()
+	srv.wg.Done()
+}
+
+func (srv *bmpServer) bmpWorker(client net.Conn) {
+	defer srv.removeClient(client)
+	defer client.Close()
+	remoteAddr := client.RemoteAddr().String()
+	if srv.onConnect != nil {
+		srv.onConnect(remoteAddr)
+	}
+	err := srv.serveClient(client)
+	if srv.onDisconnect != nil {
+		srv.onDisconnect(remoteAddr, err)
+	}
+}
+
+// serveClient reads BMP messages from the client and hands them to the parser until the session ends,
+// it returns when all received messages are published. The returned error is the reason the session
+// has ended, nil when the client has closed the session between messages or the server is stopping.
+func (srv *bmpServer) serveClient(client net.Conn) error {
+	clientAddr := client.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(clientAddr); err == nil {
+		clientAddr = host
+	}
+	routerHash := pub.NewRouter(clientAddr).Hash
+	// Records of the session carry the client and the hash of its address, router_hash until Peer Up is received
+	logger := srv.logger.With("client", client.RemoteAddr().String(), "router_hash", routerHash)
+	var forward *interceptor
+	if srv.intercept {
+		forward = srv.newInterceptor(logger)
+		defer forward.close()
+	}
+	prodOpts := []message.Option{message.WithLogger(logger), message.WithMetrics(srv.metrics), message.WithRouterAddress(clientAddr), message.WithAddPath(srv.addPath...), message.WithFilter(srv.filters...), message.WithTransform(srv.transforms...)}
+	if len(srv.splitNLRITypes) != 0 {
+		prodOpts = append(prodOpts, message.WithSplitAF(srv.splitNLRITypes...))
+	}
+	if srv.rawMessage {
+		prodOpts = append(prodOpts, message.WithRawMessage())
+	}
+	if srv.messageID {
+		prodOpts = append(prodOpts, message.WithMessageID())
+	}
+	if srv.attrFlags {
+		prodOpts = append(prodOpts, message.WithAttributeFlags())
+	}
+	if srv.unknownAttrs {
+		prodOpts = append(prodOpts, message.WithUnknownAttributes())
+	}
+	if srv.endOfRIB {
+		prodOpts = append(prodOpts, message.WithEndOfRIB())
+	}
+	if srv.peerEvents {
+		prodOpts = append(prodOpts, message.WithPeerEvents())
+	}
+	if srv.parseErrors {
+		prodOpts = append(prodOpts, message.WithParseErrors())
+	}
+	if srv.routerHashFunc != nil {
+		prodOpts = append(prodOpts, message.WithRouterHashFunc(srv.routerHashFunc))
+	}
+	if srv.publishTimeout > 0 {
+		prodOpts = append(prodOpts, message.WithPublishTimeout(srv.publishTimeout))
+	}
+	if srv.queueCapacity > 0 {
+		prodOpts = append(prodOpts, message.WithQueue(srv.queueCapacity, srv.dropPolicy))
+	}
+	if srv.messageTypes != nil {
+		types := make([]byte, 0, len(srv.messageTypes))
+		for t := range srv.messageTypes {
+			types = append(types, t)
+		}
+		prodOpts = append(prodOpts, message.WithMessageTypes(types...))
+	}
+	parserQueue, stopPipeline := startPipeline(srv.publisher, srv.splitAF, srv.metrics, logger, srv.parseConcurrency, prodOpts...)
 	defer func() {
-		glog.V(5).Infof("all done with client %+v", client.RemoteAddr())
-		close(parsStop)
-		close(prodStop)
+		logger.Debug("all done with client")
+		stopPipeline()
 	}()
+	capture := srv.newTap(clientAddr, routerHash)
+	if capture != nil {
+		logger.Info("tapping session", "directory", srv.tapDir)
+		defer func() {
+			if capture != nil {
+				capture.close()
+			}
+		}()
+	}
+	var buffers messageBuffer
+	readerOpts := []bmp.MessageReaderOption{bmp.WithMaxMessageLength(srv.maxMessageLength), bmp.WithAllocator(buffers.get)}
+	if srv.framingLength > 0 || srv.framing != nil {
+		readerOpts = append(readerOpts, bmp.WithFraming(srv.framingLength, srv.framing))
+	}
+	reader := bmp.NewMessageReader(bufio.NewReaderSize(client, readBufferSize), readerOpts...)
 	for {
-		headerMsg := make([]byte, bmp.CommonHeaderLength)
-		if _, err := io.ReadAtLeast(client, headerMsg, bmp.CommonHeaderLength); err != nil {
-			glog.Errorf("fail to read from client %+v with error: %+v", client.RemoteAddr(), err)
-			return
+		if err := srv.setReadDeadline(client); err != nil {
+			logger.Debug("stop reading from client", "error", err)
+			if err == errServerStopping {
+				return nil
+			}
+			return err
+		}
+		fullMsg, err := reader.Next()
+		var headerErr *bmp.HeaderError
+		if errors.As(err, &headerErr) {
+			if srv.resyncMaxSkip > 0 {
+				logger.Warn("invalid BMP message Common Header from client, resynchronizing", "error", err)
+				if srv.parseErrors {
+					parserQueue <- headerErr.Header
+				}
+				skipped, rerr := reader.Resync(srv.resyncMaxSkip)
+				if rerr != nil {
+					logger.Error("fail to resynchronize with client, closing session", "skipped", skipped, "error", rerr)
+					return rerr
+				}
+				logger.Warn("resynchronized with client", "skipped", skipped)
+				continue
+			}
+			if errors.Is(err, bmp.ErrMessageTooLong) {
+				logger.Error("message length from client exceeds maximum", "error", err, "maximum", srv.maxMessageLength)
+				return err
+			}
+			// Once the header is invalid, for example of bmp.ErrUnsupportedVersion, the length cannot be trusted
+			// and the next message cannot be found in the stream, the session is closed unless resync is enabled
+			logger.Error("fail to recover BMP message Common Header from client", "error", err)
+			if srv.parseErrors {
+				// The parser fails on the same header and the failure is published before the session is closed
+				parserQueue <- headerErr.Header
+			}
+			return err
 		}
-		// Recovering common header first
-		header, err := bmp.UnmarshalCommonHeader(headerMsg[:bmp.CommonHeaderLength])
 		if err != nil {
-			glog.Errorf("fail to recover BMP message Common Header with error: %+v", err)
-			continue
+			if srv.stopping() {
+				logger.Debug("server is stopping, stop reading from client")
+				return nil
+			}
+			if isTimeout(err) {
+				if reader.Pending() != 0 {
+					logger.Error("timed out reading message from client, closing session")
+					return err
+				}
+				if srv.idleKeepalive {
+					logger.Warn("client has not sent any message, keep waiting", "timeout", srv.readTimeout)
+					continue
+				}
+				logger.Error("client has not sent any message, closing session", "timeout", srv.readTimeout)
+				return err
+			}
+			logger.Error("fail to read from client", "error", err)
+			if err == io.EOF {
+				// The client has closed the session between messages
+				return nil
+			}
+			return err
 		}
-		// Allocating space for the message body
-		msg := make([]byte, int(header.MessageLength)-bmp.CommonHeaderLength)
-		if _, err := io.ReadFull(client, msg); err != nil {
-			glog.Errorf("fail to read from client %+v with error: %+v", client.RemoteAddr(), err)
-			return
+		srv.metrics.MessageReceived(fullMsg[5])
+		if fullMsg[5] == bmp.PeerUpMsg {
+			srv.sessionEstablished(client)
+		}
+		srv.metrics.BytesRead(clientAddr, len(fullMsg))
+		if capture != nil {
+			if err := capture.write(fullMsg); err != nil {
+				// The session is processed as usual when the message cannot be written to the tap
+				logger.Warn("fail to write message to tap, stop tapping session", "error", err)
+				capture.close()
+				capture = nil
+			}
 		}
 
-		fullMsg := make([]byte, int(header.MessageLength))
-		copy(fullMsg, headerMsg)
-		copy(fullMsg[bmp.CommonHeaderLength:], msg)
 		// Sending information to the server only in intercept mode
-		if srv.intercept {
-			if _, err := server.Write(fullMsg); err != nil {
-				glog.Errorf("fail to write to server %+v with error: %+v", server.RemoteAddr(), err)
-				return
-			}
+		if forward != nil {
+			forward.send(fullMsg)
+		}
+		// Messages of types not allowed are dropped before they are parsed, except Peer Up and Initiation
+		// messages the session state is learned from, the producer does not publish them
+		if srv.messageTypes != nil && !srv.messageTypes[fullMsg[5]] && fullMsg[5] != bmp.PeerUpMsg && fullMsg[5] != bmp.InitiationMsg {
+			continue
 		}
 		parserQueue <- fullMsg
 	}
 }
 
+// startPipeline starts the parser and the producer publishing to publisher, BMP messages sent to the returned
+// queue are parsed and published. When parseConcurrency is not 0, messages are parsed by parseConcurrency
+// workers, see parser.WithConcurrency, and published one at a time, so they are published in the order they
+// are sent to the queue. Unless opts set the queue of the producer, the producer queues up to parseConcurrency
+// messages with QueueBlock policy. The returned function stops the pipeline, it returns when all messages sent
+// to the queue are published.
+func startPipeline(publisher pub.Publisher, splitAF bool, m *metrics.Metrics, logger *slog.Logger, parseConcurrency int, opts ...message.Option) (chan []byte, func()) {
+	if parseConcurrency > 0 {
+		// Options of opts are applied after, so the queue set by opts takes precedence
+		opts = append([]message.Option{message.WithQueue(parseConcurrency, message.QueueBlock)}, opts...)
+	}
+	prod := message.NewProducer(publisher, splitAF, opts...)
+	prodStop := make(chan struct{})
+	prodDone := make(chan struct{})
+	producerQueue := make(chan bmp.Message)
+	// Starting messages producer with dedicated work queue
+	go func() {
+		prod.Producer(producerQueue, prodStop)
+		close(prodDone)
+	}()
+
+	parserQueue := make(chan []byte)
+	parsStop := make(chan struct{})
+	parsDone := make(chan struct{})
+	// Starting parser with dedicated work queue
+	go func() {
+		parser.Parser(parserQueue, producerQueue, parsStop, parser.WithMetrics(m), parser.WithLogger(logger), parser.WithConcurrency(parseConcurrency))
+		close(parsDone)
+	}()
+
+	return parserQueue, func() {
+		// Parser is stopped first, it returns when all received messages are handed to the producer,
+		// then the producer is stopped, it returns when all these messages are published.
+		close(parsStop)
+		<-parsDone
+		close(prodStop)
+		<-prodDone
+	}
+}
+
+// setReadDeadline sets the deadline for reading the next message from the client when read timeout
+// is configured. The deadline set by the stopping server must not be overwritten, the error is returned
+// if the server is stopping.
+func (srv *bmpServer) setReadDeadline(client net.Conn) error {
+	if srv.readTimeout == 0 {
+		return nil
+	}
+	if err := client.SetReadDeadline(time.Now().Add(srv.readTimeout)); err != nil {
+		return err
+	}
+	// Stop closes stop channel before setting client's deadline, if the server is not stopping yet,
+	// the deadline set by Stop comes after and it wins.
+	if srv.stopping() {
+		return errServerStopping
+	}
+
+	return nil
+}
+
+// isTimeout returns true if err is caused by the read deadline being exceeded
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
+
+// Option defines a function which modifies optional parameters of BMP Server
+type Option func(*bmpServer)
+
+// WithBindAddress sets the local address the BMP Server listens on, the address can be either
+// an IP address or a host name, in this case the source port is used, or a full host:port string,
+// IPv6 literals are accepted with or without brackets. By default the server listens on all interfaces.
+func WithBindAddress(addr string) Option {
+	return func(srv *bmpServer) {
+		srv.bindAddress = addr
+	}
+}
+
+// WithDestinationAddress sets the address of the collector BMP messages are copied to in intercept mode,
+// the address can be either an IP address or a host name, in this case the destination port is used,
+// or a full host:port string. By default messages are copied to the destination port of the local host.
+func WithDestinationAddress(addr string) Option {
+	return func(srv *bmpServer) {
+		srv.destinationAddress = addr
+	}
+}
+
+// WithTLSConfig sets TLS configuration used to negotiate TLS with incoming BMP clients,
+// the configuration must carry at least one certificate. By default BMP is received over plain TCP.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(srv *bmpServer) {
+		srv.tlsConfig = config
+	}
+}
+
+// WithPassiveRouters sets a list of routers, in host:port form, the BMP Server connects to
+// instead of waiting for them to connect. Each router is connected and reconnected independently.
+func WithPassiveRouters(routers ...string) Option {
+	return func(srv *bmpServer) {
+		srv.passiveRouters = append(srv.passiveRouters, routers...)
+	}
+}
+
+// WithRetry sets how connecting to passive routers and accepting clients is retried after a failure,
+// maxRetries is the number of consecutive failed attempts after which retrying is abandoned, 0 means
+// retrying forever. The interval between retries starts at interval, grows according to backoff and
+// never exceeds maxInterval, 0 maxInterval means the interval is not capped. Refused connections to passive
+// routers count as failed attempts. By default 10 retries are made with 30 seconds linear backoff capped at
+// 5 minutes.
+func WithRetry(maxRetries int, interval, maxInterval time.Duration, backoff Backoff) Option {
+	return func(srv *bmpServer) {
+		srv.maxRetries = maxRetries
+		srv.retryInterval = interval
+		srv.maxRetryInterval = maxInterval
+		srv.backoff = backoff
+	}
+}
+
+// WithAddPath enables BGP Add-Path for the NLRI types, as returned by bgp.NLRIMessageType, for all
+// BMP sessions regardless of Add-Path capability advertised in Peer Up messages.
+func WithAddPath(nlriTypes ...int) Option {
+	return func(srv *bmpServer) {
+		srv.addPath = append(srv.addPath, nlriTypes...)
+	}
+}
+
+// WithSplitAF limits splitting ipv4 and ipv6 messages into separate topics to the NLRI types, as returned
+// by bgp.NLRIMessageType, when the server is instantiated with splitAF set to true, see message.WithSplitAF.
+func WithSplitAF(nlriTypes ...int) Option {
+	return func(srv *bmpServer) {
+		srv.splitNLRITypes = append(srv.splitNLRITypes, nlriTypes...)
+	}
+}
+
+// WithFilter sets filters evaluated for every BMP message of all BMP sessions, the message is published
+// only when all filters accept it.
+func WithFilter(filters ...message.Filter) Option {
+	return func(srv *bmpServer) {
+		srv.filters = append(srv.filters, filters...)
+	}
+}
+
+// WithMessageTypes limits published messages to BMP message types, for example bmp.RouteMonitorMsg and
+// bmp.PeerUpMsg. BMP messages of other types are dropped as soon as their Common Header is read, before they
+// are parsed, they are still tapped and copied in intercept mode. Peer Up and Initiation messages are always
+// parsed, the state of the session, like Add-Path capabilities of peers, is learned from them even when
+// they are not published. By default messages of all types are published.
+func WithMessageTypes(types ...byte) Option {
+	return func(srv *bmpServer) {
+		if srv.messageTypes == nil {
+			srv.messageTypes = make(map[byte]bool, len(types))
+		}
+		for _, t := range types {
+			srv.messageTypes[t] = true
+		}
+	}
+}
+
+// WithTransform sets transforms applied to every BMP message of all BMP sessions accepted by filters
+// before it is published, see message.WithTransform.
+func WithTransform(transforms ...message.Transform) Option {
+	return func(srv *bmpServer) {
+		srv.transforms = append(srv.transforms, transforms...)
+	}
+}
+
+// WithRawMessage makes producers of all BMP sessions attach the original BMP message to every published
+// message, see message.WithRawMessage.
+func WithRawMessage() Option {
+	return func(srv *bmpServer) {
+		srv.rawMessage = true
+	}
+}
+
+// WithMessageID makes producers of all BMP sessions attach message_id identifying the message for
+// deduplication to every published message, see message.WithMessageID.
+func WithMessageID() Option {
+	return func(srv *bmpServer) {
+		srv.messageID = true
+	}
+}
+
+// WithEndOfRIB makes producers of all BMP sessions publish a message when End-of-RIB marker is received
+// from a peer, see message.WithEndOfRIB.
+func WithEndOfRIB() Option {
+	return func(srv *bmpServer) {
+		srv.endOfRIB = true
+	}
+}
+
+// WithParseConcurrency makes every BMP session parse messages by a pool of n workers, messages are published
+// one at a time in the order they are received from the router, see parser.WithConcurrency. Unless
+// WithProducerQueue is set, the producer of the session queues up to n parsed messages and blocks parsing
+// when the queue is full. By default every message is parsed and published by a dedicated worker and
+// messages are published in the order parsing and publishing complete.
+func WithParseConcurrency(n int) Option {
+	return func(srv *bmpServer) {
+		srv.parseConcurrency = n
+	}
+}
+
+// WithPeerEvents makes producers of all BMP sessions publish a peer event when a peer goes up or down,
+// see message.WithPeerEvents.
+func WithPeerEvents() Option {
+	return func(srv *bmpServer) {
+		srv.peerEvents = true
+	}
+}
+
+// WithAttributeFlags makes producers of all BMP sessions attach Attribute Flags of path attributes
+// to base attributes of published messages, see message.WithAttributeFlags.
+func WithAttributeFlags() Option {
+	return func(srv *bmpServer) {
+		srv.attrFlags = true
+	}
+}
+
+// WithUnknownAttributes makes producers of all BMP sessions attach path attributes not decoded by gobmp
+// to base attributes of published messages, see message.WithUnknownAttributes.
+func WithUnknownAttributes() Option {
+	return func(srv *bmpServer) {
+		srv.unknownAttrs = true
+	}
+}
+
+// WithParseErrors makes producers of all BMP sessions publish BMP messages failed to be parsed,
+// including the Common Header closing the session, see message.WithParseErrors.
+func WithParseErrors() Option {
+	return func(srv *bmpServer) {
+		srv.parseErrors = true
+	}
+}
+
+// WithRouterHashFunc sets the function deriving RouterHash of messages published by producers of all
+// BMP sessions, see message.WithRouterHashFunc.
+func WithRouterHashFunc(f message.RouterHashFunc) Option {
+	return func(srv *bmpServer) {
+		srv.routerHashFunc = f
+	}
+}
+
+// WithPublishTimeout makes publishes of producers of all BMP sessions fail when they do not complete
+// within the timeout, see message.WithPublishTimeout. 0 means no timeout.
+func WithPublishTimeout(timeout time.Duration) Option {
+	return func(srv *bmpServer) {
+		srv.publishTimeout = timeout
+	}
+}
+
+// WithProducerQueue makes producers of all BMP sessions keep up to capacity messages waiting to be published,
+// when the queue of a session is full, the message is handled according to policy, see message.WithQueue.
+// QueueDropOldest and QueueDropNewest policies keep a slow publisher from blocking reading from the BMP
+// session at the cost of losing messages. 0 means no queue.
+func WithProducerQueue(capacity int, policy message.DropPolicy) Option {
+	return func(srv *bmpServer) {
+		srv.queueCapacity = capacity
+		srv.dropPolicy = policy
+	}
+}
+
+// WithResync makes the server resynchronize with a client sending an invalid Common Header, for example
+// after a message with a wrong length has thrown off framing. Instead of closing the session, the server
+// skips up to maxSkip bytes looking for a plausible Common Header, BMP version 3, a known message type and
+// a length within the maximum message length, and continues reading messages from there. Messages found
+// this way may still be garbage. 0 means the session is closed, it is the default.
+func WithResync(maxSkip int) Option {
+	return func(srv *bmpServer) {
+		srv.resyncMaxSkip = maxSkip
+	}
+}
+
+// WithOnConnect sets the function called with the remote address, in host:port form, when a BMP session
+// with an accepted client or a passive router starts. The function is called from the session's goroutine
+// before the first message is read, it must not block.
+func WithOnConnect(f func(remoteAddr string)) Option {
+	return func(srv *bmpServer) {
+		srv.onConnect = f
+	}
+}
+
+// WithOnDisconnect sets the function called with the remote address, in host:port form, when a BMP session
+// ends and all its messages are published. err is the reason the session has ended, it is nil when the client
+// has closed the session between messages or the server is stopping. The function is called from the session's
+// goroutine, it must not block, Stop waits for it.
+func WithOnDisconnect(f func(remoteAddr string, err error)) Option {
+	return func(srv *bmpServer) {
+		srv.onDisconnect = f
+	}
+}
+
+// WithMaxConnections sets the maximum number of active BMP sessions, connections exceeding
+// the limit are closed right after being accepted. 0 means unlimited.
+func WithMaxConnections(max int) Option {
+	return func(srv *bmpServer) {
+		srv.maxConnections = max
+	}
+}
+
+// WithMaxMessageLength sets the maximum length of a BMP message accepted from a client,
+// a client sending a longer message gets disconnected. By default it is 1MB.
+func WithMaxMessageLength(max int) Option {
+	return func(srv *bmpServer) {
+		srv.maxMessageLength = max
+	}
+}
+
+// WithReadTimeout sets how long to wait for the next message from a client. When timeout expires
+// the session is closed, passive routers are reconnected, unless keepalive is set, in this case an idle
+// client is reported and the session is kept. By default there is no timeout.
+func WithReadTimeout(timeout time.Duration, keepalive bool) Option {
+	return func(srv *bmpServer) {
+		srv.readTimeout = timeout
+		srv.idleKeepalive = keepalive
+	}
+}
+
+// WithLogger sets the logger of the server, records of a BMP session carry client and router_hash attributes.
+// By default and when logger is nil records are written to glog.
+func WithLogger(logger *slog.Logger) Option {
+	return func(srv *bmpServer) {
+		if logger != nil {
+			srv.logger = logger
+		}
+	}
+}
+
+// WithMetrics sets metrics collectors tracking received messages, active sessions,
+// parsing and publishing errors. By default metrics are not collected.
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(srv *bmpServer) {
+		srv.metrics = m
+	}
+}
+
+// listenAddress builds the address to listen on from the bind address and the source port
+func listenAddress(bindAddr string, port int) string {
+	return joinAddress(bindAddr, port)
+}
+
+// joinAddress builds host:port address from addr and port, port is used only when addr does not
+// carry one, empty addr results in the address of all interfaces or the local host when dialing.
+func joinAddress(addr string, port int) string {
+	if addr == "" {
+		return ":" + strconv.Itoa(port)
+	}
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		// Address already carries the port
+		return addr
+	}
+
+	return net.JoinHostPort(strings.Trim(addr, "[]"), strconv.Itoa(port))
+}
+
 // NewBMPServer instantiates a new instance of BMP Server
-func NewBMPServer(sPort, dPort int, intercept bool, p pub.Publisher, splitAF bool) (BMPServer, error) {
-	incoming, err := net.Listen("tcp", fmt.Sprintf(":%d", sPort))
+func NewBMPServer(sPort, dPort int, intercept bool, p pub.Publisher, splitAF bool, opts ...Option) (BMPServer, error) {
+	return NewBMPServerWithContext(context.Background(), sPort, dPort, intercept, p, splitAF, opts...)
+}
+
+// NewBMPServerWithContext instantiates a new instance of BMP Server, once started, the server
+// stops when ctx is cancelled, it closes the listener and tears down all active clients.
+func NewBMPServerWithContext(ctx context.Context, sPort, dPort int, intercept bool, p pub.Publisher, splitAF bool, opts ...Option) (BMPServer, error) {
+	bmp := bmpServer{
+		ctx:              ctx,
+		stop:             make(chan struct{}),
+		sourcePort:       sPort,
+		destinationPort:  dPort,
+		intercept:        intercept,
+		publisher:        p,
+		splitAF:          splitAF,
+		clients:          make(map[net.Conn]bool),
+		maxMessageLength: defaultMaxMessageLength,
+		maxRetries:       defaultMaxRetries,
+		retryInterval:    defaultRetryInterval,
+		maxRetryInterval: defaultMaxRetryInterval,
+		backoff:          LinearBackoff,
+		logger:           logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(&bmp)
+	}
+	if p != nil {
+		// Publishing errors are tracked to report the server's health
+		bmp.health = &healthPublisher{inner: p}
+		bmp.publisher = bmp.health
+	}
+	addr := listenAddress(bmp.bindAddress, sPort)
+	incoming, err := bmp.listen(addr)
 	if err != nil {
-		glog.Errorf("fail to setup listener on port %d with error: %+v", sPort, err)
+		bmp.logger.Error("fail to setup listener", "address", addr, "error", err)
 		return nil, err
 	}
-	bmp := bmpServer{
-		stop:            make(chan struct{}),
-		sourcePort:      sPort,
-		destinationPort: dPort,
-		intercept:       intercept,
-		publisher:       p,
-		incoming:        incoming,
-		splitAF:         splitAF,
+	if bmp.tlsConfig != nil {
+		// TLS handshake is performed on the first read from the accepted client
+		incoming = tls.NewListener(incoming, bmp.tlsConfig)
 	}
+	bmp.incoming = incoming
 
 	return &bmp, nil
 }
@@ -0,0 +1,871 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+func TestServerContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv, err := NewBMPServerWithContext(ctx, 0, 0, false, nil, false)
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	addr := srv.(*bmpServer).incoming.Addr().String()
+	client, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+
+	cancel()
+	// Client connection is expected to be closed by the server
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := client.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("expected client connection to be closed")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatalf("client connection has not been closed after context cancellation")
+	}
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Fatalf("expected listener to be closed after context cancellation")
+	}
+}
+
+func TestListenAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindAddr string
+		port     int
+		expect   string
+	}{
+		{
+			name:   "all interfaces",
+			port:   5000,
+			expect: ":5000",
+		},
+		{
+			name:     "ipv4 address",
+			bindAddr: "192.0.2.1",
+			port:     5000,
+			expect:   "192.0.2.1:5000",
+		},
+		{
+			name:     "ipv6 address",
+			bindAddr: "2001:db8::1",
+			port:     5000,
+			expect:   "[2001:db8::1]:5000",
+		},
+		{
+			name:     "ipv6 address in brackets",
+			bindAddr: "[2001:db8::1]",
+			port:     5000,
+			expect:   "[2001:db8::1]:5000",
+		},
+		{
+			name:     "ipv6 address with port",
+			bindAddr: "[2001:db8::1]:5001",
+			port:     5000,
+			expect:   "[2001:db8::1]:5001",
+		},
+		{
+			name:     "host name",
+			bindAddr: "localhost",
+			port:     5000,
+			expect:   "localhost:5000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := listenAddress(tt.bindAddr, tt.port); got != tt.expect {
+				t.Fatalf("expected listen address %s but got %s", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestServerBindIPv6(t *testing.T) {
+	srv, err := NewBMPServer(0, 0, false, nil, false, WithBindAddress("::1"))
+	if err != nil {
+		t.Skipf("IPv6 loopback is not available: %+v", err)
+	}
+	defer srv.Stop()
+	addr := srv.(*bmpServer).incoming.Addr().(*net.TCPAddr)
+	if !addr.IP.Equal(net.IPv6loopback) {
+		t.Fatalf("expected server to listen on %s but it listens on %s", net.IPv6loopback, addr.IP)
+	}
+}
+
+func TestServerInterceptDestination(t *testing.T) {
+	// The collector listens on an address other than the one the server binds to
+	collector, err := net.Listen("tcp", "127.0.0.2:0")
+	if err != nil {
+		t.Skipf("address 127.0.0.2 is not available: %+v", err)
+	}
+	defer collector.Close()
+	srv, err := NewBMPServer(0, 0, true, &recordingPublisher{msgs: make(chan int, 1)}, false,
+		WithBindAddress("127.0.0.1"), WithDestinationAddress(collector.Addr().String()))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send message with error: %+v", err)
+	}
+	collector.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+	forwarded, err := collector.Accept()
+	if err != nil {
+		t.Fatalf("server has not connected to the destination: %+v", err)
+	}
+	defer forwarded.Close()
+	forwarded.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b := make([]byte, len(peerUpMsg()))
+	if _, err := io.ReadFull(forwarded, b); err != nil {
+		t.Fatalf("failed to read forwarded message with error: %+v", err)
+	}
+	if !bytes.Equal(b, peerUpMsg()) {
+		t.Fatalf("expected forwarded message %v, got %v", peerUpMsg(), b)
+	}
+}
+
+func TestServerMaxConnections(t *testing.T) {
+	srv, err := NewBMPServer(0, 0, false, nil, false, WithMaxConnections(1))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	addr := srv.(*bmpServer).incoming.Addr().String()
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer first.Close()
+	// Waiting for the first client to become active
+	for i := 0; ; i++ {
+		srv.(*bmpServer).mu.Lock()
+		n := len(srv.(*bmpServer).clients)
+		srv.(*bmpServer).mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("first client has not been accepted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := second.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("expected second client connection to be closed")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatalf("second client connection has not been refused")
+	}
+	first.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, err := first.Read(make([]byte, 1)); err != nil {
+		if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+			t.Fatalf("first client connection is expected to stay open but got error: %+v", err)
+		}
+	}
+}
+
+func TestServerPassiveRouters(t *testing.T) {
+	router, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to setup passive router listener with error: %+v", err)
+	}
+	defer router.Close()
+	// Getting a port nobody listens on to simulate unreachable router
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to setup listener with error: %+v", err)
+	}
+	unreachable := l.Addr().String()
+	l.Close()
+
+	srv, err := NewBMPServer(0, 0, false, nil, false, WithPassiveRouters(unreachable, router.Addr().String()))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, err := router.Accept()
+		if err != nil {
+			return
+		}
+		accepted <- conn
+	}()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatalf("bmp server has not connected to the passive router")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		backoff     Backoff
+		interval    time.Duration
+		maxInterval time.Duration
+		retryCount  int
+		expect      time.Duration
+	}{
+		{
+			name:        "linear first attempt",
+			backoff:     LinearBackoff,
+			interval:    30 * time.Second,
+			maxInterval: 5 * time.Minute,
+			retryCount:  1,
+			expect:      30 * time.Second,
+		},
+		{
+			name:        "linear third attempt",
+			backoff:     LinearBackoff,
+			interval:    30 * time.Second,
+			maxInterval: 5 * time.Minute,
+			retryCount:  3,
+			expect:      90 * time.Second,
+		},
+		{
+			name:        "linear capped",
+			backoff:     LinearBackoff,
+			interval:    30 * time.Second,
+			maxInterval: 5 * time.Minute,
+			retryCount:  20,
+			expect:      5 * time.Minute,
+		},
+		{
+			name:        "exponential first attempt",
+			backoff:     ExponentialBackoff,
+			interval:    time.Second,
+			maxInterval: time.Minute,
+			retryCount:  1,
+			expect:      time.Second,
+		},
+		{
+			name:        "exponential fourth attempt",
+			backoff:     ExponentialBackoff,
+			interval:    time.Second,
+			maxInterval: time.Minute,
+			retryCount:  4,
+			expect:      8 * time.Second,
+		},
+		{
+			name:        "exponential capped",
+			backoff:     ExponentialBackoff,
+			interval:    time.Second,
+			maxInterval: time.Minute,
+			retryCount:  7,
+			expect:      time.Minute,
+		},
+		{
+			name:        "exponential capped with large retry count",
+			backoff:     ExponentialBackoff,
+			interval:    time.Second,
+			maxInterval: time.Minute,
+			retryCount:  1000,
+			expect:      time.Minute,
+		},
+		{
+			name:       "exponential without maximum interval",
+			backoff:    ExponentialBackoff,
+			interval:   time.Second,
+			retryCount: 10,
+			expect:     512 * time.Second,
+		},
+		{
+			name:       "exponential without maximum interval with large retry count",
+			backoff:    ExponentialBackoff,
+			interval:   time.Second,
+			retryCount: 1000,
+			expect:     time.Second << 33,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &bmpServer{
+				retryInterval:    tt.interval,
+				maxRetryInterval: tt.maxInterval,
+				backoff:          tt.backoff,
+			}
+			// Exponential backoff is randomized, checking the delay is within the jitter range
+			min := tt.expect
+			if tt.backoff == ExponentialBackoff {
+				min = tt.expect / 2
+			}
+			for i := 0; i < 100; i++ {
+				delay := srv.retryDelay(tt.retryCount)
+				if delay < min || delay > tt.expect {
+					t.Fatalf("expected delay between %s and %s, got %s", min, tt.expect, delay)
+				}
+			}
+		})
+	}
+}
+
+type recordingPublisher struct {
+	msgs chan int
+}
+
+func (p *recordingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	p.msgs <- msgType
+	return nil
+}
+
+func (p *recordingPublisher) Stop() {}
+
+// peerUpMsg returns BMP Peer Up message for IPv4 peer 10.0.0.2 AS 50123
+func peerUpMsg() []byte {
+	perPeerHeader := []byte{
+		0x00, 0x00, // Peer Type and Flags
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Peer Distinguisher
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x02, // Peer Address
+		0x00, 0x00, 0xC3, 0xCB, // Peer AS
+		0x0A, 0x00, 0x00, 0x02, // Peer BGP ID
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Timestamp
+	}
+	peerUp := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x01, // Local Address
+		0x00, 0xB3, 0xC0, 0x01, // Local and Remote ports
+		// Sent OPEN
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x25, 0x01, 0x04, 0xC3, 0xCB, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x01, 0x08,
+		0x02, 0x06, 0x01, 0x04, 0x00, 0x01, 0x00, 0x01,
+		// Received OPEN
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x25, 0x01, 0x04, 0xC3, 0xCB, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x08,
+		0x02, 0x06, 0x01, 0x04, 0x00, 0x01, 0x00, 0x01,
+	}
+	length := bmp.CommonHeaderLength + len(perPeerHeader) + len(peerUp)
+	msg := []byte{3, 0, 0, byte(length >> 8), byte(length), bmp.PeerUpMsg}
+	msg = append(msg, perPeerHeader...)
+
+	return append(msg, peerUp...)
+}
+
+// selfSignedCert returns a self-signed certificate for 127.0.0.1 and a pool trusting it
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key with error: %+v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gobmp"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate with error: %+v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate with error: %+v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
+
+func TestServerTLS(t *testing.T) {
+	cert, pool := selfSignedCert(t)
+	publisher := &recordingPublisher{msgs: make(chan int, 1)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false,
+		WithBindAddress("127.0.0.1"),
+		WithTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	addr := srv.(*bmpServer).incoming.Addr().String()
+	client, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: pool})
+	if err != nil {
+		t.Fatalf("failed to establish tls connection to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send Peer Up message with error: %+v", err)
+	}
+	select {
+	case msgType := <-publisher.msgs:
+		if msgType != bmp.PeerStateChangeMsg {
+			t.Fatalf("expected message type %d, got %d", bmp.PeerStateChangeMsg, msgType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Peer Up message has not been published")
+	}
+}
+
+func TestServerReadTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		keepalive bool
+		closed    bool
+	}{
+		{
+			name:   "idle client is disconnected",
+			closed: true,
+		},
+		{
+			name:      "idle client is kept with keepalive",
+			keepalive: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := NewBMPServer(0, 0, false, nil, false, WithReadTimeout(100*time.Millisecond, tt.keepalive))
+			if err != nil {
+				t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+			}
+			srv.Start()
+			defer srv.Stop()
+			client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+			if err != nil {
+				t.Fatalf("failed to connect to bmp server with error: %+v", err)
+			}
+			defer client.Close()
+			client.SetReadDeadline(time.Now().Add(time.Second))
+			_, err = client.Read(make([]byte, 1))
+			if err == nil {
+				t.Fatalf("unexpected data received from the server")
+			}
+			if tt.closed && isTimeout(err) {
+				t.Fatalf("expected idle client connection to be closed by the server")
+			}
+			if !tt.closed && !isTimeout(err) {
+				t.Fatalf("expected idle client connection to be kept, got error: %+v", err)
+			}
+		})
+	}
+}
+
+// syncBuffer is a buffer safe for concurrent writes of the logger and reads of the test
+type syncBuffer struct {
+	sync.Mutex
+	b bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.b.String()
+}
+
+func TestServerLogger(t *testing.T) {
+	out := &syncBuffer{}
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	srv, err := NewBMPServer(0, 0, false, nil, false, WithBindAddress("127.0.0.1"), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	// BMP version 1 is not supported, the session is closed
+	if _, err := client.Write([]byte{1, 0, 0, 0, 6, bmp.InitiationMsg}); err != nil {
+		t.Fatalf("failed to send message with error: %+v", err)
+	}
+	expect := fmt.Sprintf(`level=ERROR msg="fail to recover BMP message Common Header from client" client=%s router_hash=%s`,
+		client.LocalAddr().String(), pub.NewRouter("127.0.0.1").Hash)
+	for i := 0; !strings.Contains(out.String(), expect); i++ {
+		if i == 500 {
+			t.Fatalf("expected log record %s, got %s", expect, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerSessionCallbacks(t *testing.T) {
+	tests := []struct {
+		name    string
+		send    []byte
+		failing bool
+		err     error
+	}{
+		{
+			name: "client closes session",
+		},
+		{
+			name:    "client sends unsupported version",
+			send:    []byte{0x01, 0x00, 0x00, 0x00, 0x06, 0x04},
+			failing: true,
+			err:     bmp.ErrUnsupportedVersion,
+		},
+		{
+			name:    "client sends invalid message type",
+			send:    []byte{0x03, 0x00, 0x00, 0x00, 0x06, 0x09},
+			failing: true,
+		},
+	}
+	type disconnect struct {
+		addr string
+		err  error
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connected := make(chan string, 1)
+			disconnected := make(chan disconnect, 1)
+			srv, err := NewBMPServer(0, 0, false, nil, false, WithBindAddress("127.0.0.1"),
+				WithOnConnect(func(remoteAddr string) {
+					connected <- remoteAddr
+				}),
+				WithOnDisconnect(func(remoteAddr string, err error) {
+					disconnected <- disconnect{addr: remoteAddr, err: err}
+				}))
+			if err != nil {
+				t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+			}
+			srv.Start()
+			defer srv.Stop()
+			client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+			if err != nil {
+				t.Fatalf("failed to connect to bmp server with error: %+v", err)
+			}
+			defer client.Close()
+			select {
+			case addr := <-connected:
+				if addr != client.LocalAddr().String() {
+					t.Fatalf("expected connect of %s, got %s", client.LocalAddr().String(), addr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("connect callback has not been called")
+			}
+			if tt.send != nil {
+				if _, err := client.Write(tt.send); err != nil {
+					t.Fatalf("failed to write to bmp server with error: %+v", err)
+				}
+			} else {
+				client.Close()
+			}
+			select {
+			case d := <-disconnected:
+				if d.addr != client.LocalAddr().String() {
+					t.Fatalf("expected disconnect of %s, got %s", client.LocalAddr().String(), d.addr)
+				}
+				if tt.failing && d.err == nil {
+					t.Fatalf("expected disconnect with error")
+				}
+				if tt.err != nil && !errors.Is(d.err, tt.err) {
+					t.Fatalf("expected disconnect with error %v, got %+v", tt.err, d.err)
+				}
+				if !tt.failing && d.err != nil {
+					t.Fatalf("expected disconnect without error, got %+v", d.err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("disconnect callback has not been called")
+			}
+		})
+	}
+}
+
+// perPeerMsg returns BMP message of type msgType carrying Per-Peer Header of peerUpMsg followed by body
+func perPeerMsg(msgType byte, body []byte) []byte {
+	perPeerHeader := peerUpMsg()[bmp.CommonHeaderLength : bmp.CommonHeaderLength+bmp.PerPeerHeaderLength]
+	length := bmp.CommonHeaderLength + len(perPeerHeader) + len(body)
+	msg := []byte{3, 0, 0, byte(length >> 8), byte(length), msgType}
+	msg = append(msg, perPeerHeader...)
+
+	return append(msg, body...)
+}
+
+func TestServerMessageTypes(t *testing.T) {
+	publisher := &recordingPublisher{msgs: make(chan int, 2)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"), WithMessageTypes(bmp.RouteMonitorMsg))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	// Stats Report of 5 prefixes rejected by inbound policy
+	statsReport := perPeerMsg(bmp.StatsReportMsg, []byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05})
+	// Route Monitoring of 10.0.0.0/8
+	routeMonitor := perPeerMsg(bmp.RouteMonitorMsg, []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x2D, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x14, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+		0x08, 0x0A, // NLRI 10.0.0.0/8
+	})
+	if _, err := client.Write(append(statsReport, routeMonitor...)); err != nil {
+		t.Fatalf("failed to send messages with error: %+v", err)
+	}
+	// Messages are parsed concurrently, the Stats Report is expected neither before nor shortly after
+	// the Route Monitoring message
+	select {
+	case msgType := <-publisher.msgs:
+		if msgType != bmp.UnicastPrefixMsg {
+			t.Fatalf("expected message type %d, got %d", bmp.UnicastPrefixMsg, msgType)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("route monitoring message has not been published")
+	}
+	select {
+	case msgType := <-publisher.msgs:
+		t.Fatalf("expected no more published messages, got message type %d", msgType)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// prefixPublisher records prefixes of published unicast prefix messages in the order they are published
+type prefixPublisher struct {
+	sync.Mutex
+	prefixes []string
+}
+
+func (p *prefixPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	var prefix struct {
+		Prefix string `json:"prefix"`
+	}
+	if err := json.Unmarshal(msg, &prefix); err != nil {
+		return err
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.prefixes = append(p.prefixes, prefix.Prefix)
+	return nil
+}
+
+func (p *prefixPublisher) Stop() {}
+
+func TestPipelineParseConcurrencyOrder(t *testing.T) {
+	publisher := &prefixPublisher{}
+	queue, stop := startPipeline(publisher, false, nil, slog.Default(), 4)
+	expect := make([]string, 0)
+	for i := 0; i < 200; i++ {
+		// Route Monitoring of 10.1.i.0/24
+		queue <- perPeerMsg(bmp.RouteMonitorMsg, []byte{
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0x00, 0x2F, 0x02,
+			0x00, 0x00, // Withdrawn Routes Length
+			0x00, 0x14, // Total Path Attribute Length
+			0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+			0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+			0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+			0x18, 0x0A, 0x01, byte(i), // NLRI 10.1.i.0/24
+		})
+		expect = append(expect, fmt.Sprintf("10.1.%d.0", i))
+	}
+	stop()
+	if !reflect.DeepEqual(publisher.prefixes, expect) {
+		t.Fatalf("expected prefixes published in the order they are received %v, got %v", expect, publisher.prefixes)
+	}
+}
+
+// blockingPublisher blocks publishing until release is closed and records publishes completed after Stop
+type blockingPublisher struct {
+	sync.Mutex
+	started   chan struct{}
+	release   chan struct{}
+	published int
+	late      int
+	stopped   bool
+}
+
+func (p *blockingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	select {
+	case p.started <- struct{}{}:
+	default:
+	}
+	<-p.release
+	p.Lock()
+	defer p.Unlock()
+	p.published++
+	if p.stopped {
+		p.late++
+	}
+	return nil
+}
+
+func (p *blockingPublisher) Stop() {
+	p.Lock()
+	defer p.Unlock()
+	p.stopped = true
+}
+
+func TestServerStopWhilePublishing(t *testing.T) {
+	publisher := &blockingPublisher{started: make(chan struct{}, 1), release: make(chan struct{})}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send Peer Up message with error: %+v", err)
+	}
+	select {
+	case <-publisher.started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Peer Up message has not been published")
+	}
+	// The stop context is already done, the session publishing Peer Up is force closed right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		srv.StopWithContext(ctx)
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatalf("server stopped while the session is still publishing")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(publisher.release)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server has not stopped after the session is done publishing")
+	}
+	publisher.Lock()
+	defer publisher.Unlock()
+	if !publisher.stopped {
+		t.Fatalf("expected publisher to be stopped")
+	}
+	if publisher.published == 0 {
+		t.Fatalf("expected Peer Up message to be published")
+	}
+	if publisher.late != 0 {
+		t.Fatalf("expected no publish after publisher is stopped, got %d", publisher.late)
+	}
+}
+
+// messagePublisher sends published messages to msgs
+type messagePublisher struct {
+	msgs chan []byte
+}
+
+func (p *messagePublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	p.msgs <- msg
+	return nil
+}
+
+func (p *messagePublisher) Stop() {}
+
+func TestServerMessageTypesAddPath(t *testing.T) {
+	// Messages are parsed in order, so the Peer Up is handed to the producer before the Route Monitoring
+	publisher := &messagePublisher{msgs: make(chan []byte, 8)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"), WithMessageTypes(bmp.RouteMonitorMsg), WithParseConcurrency(2))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	open := func(bgpID byte) []byte {
+		return []byte{
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0x00, 0x25, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, bgpID,
+			0x08, 0x02, 0x06, 0x45, 0x04, 0x00, 0x01, 0x01, 0x03, // Add-Path AFI 1 SAFI 1 Send/Receive
+		}
+	}
+	// Peer Up of the session from local address 10.0.0.1 with Add-Path negotiated for IPv4 unicast
+	peerUp := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x01,
+		0x00, 0xB3, 0xC3, 0x50,
+	}
+	peerUp = append(append(peerUp, open(1)...), open(2)...)
+	// Route Monitoring of 10.0.0.0/8 with Path Identifier 16, without Add-Path NLRI decodes as 4 other prefixes
+	routeMonitor := perPeerMsg(bmp.RouteMonitorMsg, []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x31, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x14, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+		0x00, 0x00, 0x00, 0x10, 0x08, 0x0A, // NLRI Path ID 16 10.0.0.0/8
+	})
+	if _, err := client.Write(append(perPeerMsg(bmp.PeerUpMsg, peerUp), routeMonitor...)); err != nil {
+		t.Fatalf("failed to send messages with error: %+v", err)
+	}
+	select {
+	case msg := <-publisher.msgs:
+		var prefix struct {
+			Prefix   string `json:"prefix"`
+			PathID   int32  `json:"path_id"`
+			RouterIP string `json:"router_ip"`
+		}
+		if err := json.Unmarshal(msg, &prefix); err != nil {
+			t.Fatalf("failed to unmarshal published message with error: %+v", err)
+		}
+		if prefix.Prefix != "10.0.0.0" || prefix.PathID != 16 || prefix.RouterIP != "10.0.0.1" {
+			t.Fatalf("expected prefix 10.0.0.0 path id 16 router ip 10.0.0.1, got %s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("route monitoring message has not been published")
+	}
+	select {
+	case msg := <-publisher.msgs:
+		t.Fatalf("expected no more published messages, got %s", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
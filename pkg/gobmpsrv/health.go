@@ -0,0 +1,128 @@
+package gobmpsrv
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+// HealthState defines the overall health of BMP Server
+type HealthState int
+
+const (
+	// HealthNotListening means the server does not accept BMP clients, it is not started, stopped
+	// or has given up accepting clients after repeated failures.
+	HealthNotListening HealthState = iota
+	// HealthNoSessions means the server accepts BMP clients but no BMP session has sent Peer Up message yet
+	HealthNoSessions
+	// HealthDegraded means the server receives BMP messages but the last publish has failed
+	HealthDegraded
+	// Healthy means at least one BMP session has sent Peer Up message and messages are published
+	Healthy
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthNotListening:
+		return "not listening"
+	case HealthNoSessions:
+		return "no sessions"
+	case HealthDegraded:
+		return "degraded"
+	case Healthy:
+		return "healthy"
+	}
+
+	return "unknown"
+}
+
+// HealthStatus describes the health of BMP Server at the time Health was called
+type HealthStatus struct {
+	State HealthState
+	// Listening is true while the server accepts BMP clients
+	Listening bool
+	// Sessions is the number of active BMP sessions
+	Sessions int
+	// EstablishedSessions is the number of active BMP sessions which have sent at least one Peer Up message
+	EstablishedSessions int
+	// PublishFailing is true when the last publish has failed
+	PublishFailing bool
+	// LastPublishError is the error of the last failed publish and LastPublishErrorTime is when it failed,
+	// they are kept after publishing recovers.
+	LastPublishError     error
+	LastPublishErrorTime time.Time
+}
+
+// Health returns the health of the server, it is safe to call concurrently with the running server.
+func (srv *bmpServer) Health() HealthStatus {
+	h := HealthStatus{}
+	srv.mu.Lock()
+	h.Listening = srv.listening
+	h.Sessions = len(srv.clients)
+	for _, established := range srv.clients {
+		if established {
+			h.EstablishedSessions++
+		}
+	}
+	srv.mu.Unlock()
+	if srv.health != nil {
+		srv.health.status(&h)
+	}
+	switch {
+	case !h.Listening:
+		h.State = HealthNotListening
+	case h.EstablishedSessions == 0:
+		h.State = HealthNoSessions
+	case h.PublishFailing:
+		h.State = HealthDegraded
+	default:
+		h.State = Healthy
+	}
+
+	return h
+}
+
+// healthPublisher tracks errors of the wrapped publisher
+type healthPublisher struct {
+	inner pub.Publisher
+	sync.Mutex
+	failing     bool
+	lastErr     error
+	lastErrTime time.Time
+}
+
+func (p *healthPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishRouterMessage(pub.Router{}, msgType, msgHash, msg)
+}
+
+func (p *healthPublisher) PublishRouterMessage(router pub.Router, msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishMessageContext(context.Background(), router, msgType, msgHash, msg)
+}
+
+func (p *healthPublisher) PublishMessageContext(ctx context.Context, router pub.Router, msgType int, msgHash []byte, msg []byte) error {
+	err := pub.PublishMessageContext(ctx, p.inner, router, msgType, msgHash, msg)
+	p.Lock()
+	defer p.Unlock()
+	p.failing = err != nil
+	if err != nil {
+		p.lastErr = err
+		p.lastErrTime = time.Now()
+	}
+
+	return err
+}
+
+func (p *healthPublisher) Stop() {
+	p.inner.Stop()
+}
+
+// status sets publishing state of the health status h
+func (p *healthPublisher) status(h *HealthStatus) {
+	p.Lock()
+	defer p.Unlock()
+	h.PublishFailing = p.failing
+	h.LastPublishError = p.lastErr
+	h.LastPublishErrorTime = p.lastErrTime
+}
@@ -0,0 +1,92 @@
+package gobmpsrv
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// failingPublisher fails publishing messages while err is set
+type failingPublisher struct {
+	sync.Mutex
+	err       error
+	published chan struct{}
+}
+
+func (p *failingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	p.Lock()
+	defer p.Unlock()
+	p.published <- struct{}{}
+
+	return p.err
+}
+
+func (p *failingPublisher) Stop() {}
+
+func (p *failingPublisher) setError(err error) {
+	p.Lock()
+	defer p.Unlock()
+	p.err = err
+}
+
+// waitHealth waits for the server's health to reach the state and returns the health status
+func waitHealth(t *testing.T, srv BMPServer, state HealthState) HealthStatus {
+	t.Helper()
+	var h HealthStatus
+	for i := 0; i < 500; i++ {
+		if h = srv.Health(); h.State == state {
+			return h
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected health %s, got %s", state, h.State)
+
+	return h
+}
+
+func TestServerHealth(t *testing.T) {
+	publisher := &failingPublisher{published: make(chan struct{}, 10)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	waitHealth(t, srv, HealthNotListening)
+	srv.Start()
+	defer srv.Stop()
+	waitHealth(t, srv, HealthNoSessions)
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	// Connected client has not established BMP session until it sends Peer Up message
+	if h := waitHealth(t, srv, HealthNoSessions); h.Sessions != 1 || h.EstablishedSessions != 0 {
+		t.Fatalf("expected 1 session and no established sessions, got %+v", h)
+	}
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send Peer Up message with error: %+v", err)
+	}
+	if h := waitHealth(t, srv, Healthy); h.Sessions != 1 || h.EstablishedSessions != 1 || h.LastPublishError != nil {
+		t.Fatalf("expected 1 established session without publish errors, got %+v", h)
+	}
+	// Failing publisher degrades the health until a message is published
+	errPublish := errors.New("publish failure")
+	publisher.setError(errPublish)
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send Peer Up message with error: %+v", err)
+	}
+	if h := waitHealth(t, srv, HealthDegraded); h.LastPublishError != errPublish || h.LastPublishErrorTime.IsZero() {
+		t.Fatalf("expected last publish error %+v, got %+v", errPublish, h)
+	}
+	publisher.setError(nil)
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send Peer Up message with error: %+v", err)
+	}
+	if h := waitHealth(t, srv, Healthy); h.LastPublishError != errPublish {
+		t.Fatalf("expected last publish error to be kept, got %+v", h)
+	}
+	srv.Stop()
+	waitHealth(t, srv, HealthNotListening)
+}
@@ -0,0 +1,139 @@
+package gobmpsrv
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultInterceptBuffer defines how many BMP messages wait to be copied to the destination
+	defaultInterceptBuffer = 1024
+	// interceptWriteTimeout defines how long to wait for the destination to accept a message
+	interceptWriteTimeout = 10 * time.Second
+)
+
+// WithInterceptBuffer sets the number of BMP messages of a session waiting to be copied to the destination
+// in intercept mode, while the destination is not reachable, messages are buffered and the server keeps
+// reconnecting, once the buffer is full further messages are dropped. Reconnect attempts are delayed
+// according to WithRetry, but never abandoned. By default up to 1024 messages are buffered.
+func WithInterceptBuffer(size int) Option {
+	return func(srv *bmpServer) {
+		srv.interceptBuffer = size
+	}
+}
+
+// interceptor copies BMP messages of a session to the destination, a failure of the destination
+// does not affect the session, the connection is reestablished while messages are buffered.
+type interceptor struct {
+	addr       string
+	logger     *slog.Logger
+	retryDelay func(retryCount int) time.Duration
+	msgs       chan []byte
+	// dropped is the number of messages dropped since the last one copied to the destination
+	dropped atomic.Int64
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// newInterceptor starts copying messages passed to send to the destination of the server
+func (srv *bmpServer) newInterceptor(logger *slog.Logger) *interceptor {
+	size := srv.interceptBuffer
+	if size <= 0 {
+		size = defaultInterceptBuffer
+	}
+	addr := joinAddress(srv.destinationAddress, srv.destinationPort)
+	ctx, cancel := context.WithCancel(srv.ctx)
+	i := &interceptor{
+		addr:       addr,
+		logger:     logger.With("destination", addr),
+		retryDelay: srv.retryDelay,
+		msgs:       make(chan []byte, size),
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go i.run()
+
+	return i
+}
+
+// send queues msg to be copied to the destination, msg is dropped when the buffer is full
+func (i *interceptor) send(msg []byte) {
+	select {
+	case i.msgs <- msg:
+	default:
+		if i.dropped.Add(1) == 1 {
+			i.logger.Warn("destination buffer is full, dropping messages", "buffer", cap(i.msgs))
+		}
+	}
+}
+
+// close stops the interceptor, buffered messages are still copied when the destination is connected
+// and discarded otherwise.
+func (i *interceptor) close() {
+	close(i.msgs)
+	i.cancel()
+	<-i.done
+}
+
+func (i *interceptor) run() {
+	defer close(i.done)
+	var server net.Conn
+	defer func() {
+		if server != nil {
+			server.Close()
+		}
+	}()
+	for msg := range i.msgs {
+		for {
+			if server == nil {
+				if server = i.connect(); server == nil {
+					// The interceptor is stopped, remaining messages are discarded
+					for range i.msgs {
+					}
+					return
+				}
+			}
+			server.SetWriteDeadline(time.Now().Add(interceptWriteTimeout))
+			if _, err := server.Write(msg); err != nil {
+				i.logger.Error("fail to write to destination server, reconnecting", "error", err)
+				server.Close()
+				server = nil
+				continue
+			}
+			break
+		}
+		if n := i.dropped.Swap(0); n != 0 {
+			i.logger.Warn("messages were dropped while destination was not reachable", "dropped", n)
+		}
+	}
+}
+
+// connect connects to the destination retrying until it succeeds, nil is returned when the interceptor
+// is stopped before the connection is established.
+func (i *interceptor) connect() net.Conn {
+	dialer := &net.Dialer{Timeout: passiveConnectTimeout}
+	retryCount := 0
+	for {
+		server, err := dialer.DialContext(i.ctx, "tcp", i.addr)
+		if err == nil {
+			i.logger.Debug("connection to destination server established, start intercepting")
+			return server
+		}
+		if i.ctx.Err() != nil {
+			return nil
+		}
+		retryCount++
+		wait := i.retryDelay(retryCount)
+		i.logger.Warn("failed to connect to destination, retrying", "error", err, "attempt", retryCount, "wait", wait)
+		select {
+		case <-time.After(wait):
+		case <-i.ctx.Done():
+			return nil
+		}
+	}
+}
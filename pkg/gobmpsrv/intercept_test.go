@@ -0,0 +1,115 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestServerInterceptDestinationFailure(t *testing.T) {
+	collector, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen with error: %+v", err)
+	}
+	defer collector.Close()
+	publisher := &recordingPublisher{msgs: make(chan int, 100)}
+	srv, err := NewBMPServer(0, 0, true, publisher, false, WithBindAddress("127.0.0.1"),
+		WithDestinationAddress(collector.Addr().String()),
+		WithRetry(0, 10*time.Millisecond, 50*time.Millisecond, LinearBackoff))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	// send writes Peer Up message to the server and waits for it to be published
+	send := func() {
+		if _, err := client.Write(peerUpMsg()); err != nil {
+			t.Fatalf("failed to send message with error: %+v", err)
+		}
+		select {
+		case msgType := <-publisher.msgs:
+			if msgType != bmp.PeerStateChangeMsg {
+				t.Fatalf("expected published message type %d, got %d", bmp.PeerStateChangeMsg, msgType)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message has not been published")
+		}
+	}
+	// accept waits for the server to connect to the collector and reads a forwarded message
+	accept := func(timeout time.Duration) net.Conn {
+		collector.(*net.TCPListener).SetDeadline(time.Now().Add(timeout))
+		forwarded, err := collector.Accept()
+		if err != nil {
+			return nil
+		}
+		forwarded.SetReadDeadline(time.Now().Add(5 * time.Second))
+		b := make([]byte, len(peerUpMsg()))
+		if _, err := io.ReadFull(forwarded, b); err != nil {
+			t.Fatalf("failed to read forwarded message with error: %+v", err)
+		}
+		if !bytes.Equal(b, peerUpMsg()) {
+			t.Fatalf("expected forwarded message %v, got %v", peerUpMsg(), b)
+		}
+		return forwarded
+	}
+	send()
+	forwarded := accept(5 * time.Second)
+	if forwarded == nil {
+		t.Fatalf("server has not connected to the destination")
+	}
+	// The destination drops the connection, the session is kept and messages are still published
+	forwarded.Close()
+	for i := 0; ; i++ {
+		if i == 50 {
+			t.Fatalf("server has not reconnected to the destination")
+		}
+		send()
+		if forwarded = accept(100 * time.Millisecond); forwarded != nil {
+			break
+		}
+	}
+	forwarded.Close()
+}
+
+func TestInterceptorBuffer(t *testing.T) {
+	// Nothing listens on the address of the closed listener
+	collector, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen with error: %+v", err)
+	}
+	collector.Close()
+	srv, err := NewBMPServer(0, 0, true, nil, false, WithBindAddress("127.0.0.1"),
+		WithDestinationAddress(collector.Addr().String()), WithInterceptBuffer(2),
+		WithRetry(0, time.Hour, time.Hour, LinearBackoff))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	defer srv.Stop()
+	i := srv.(*bmpServer).newInterceptor(srv.(*bmpServer).logger)
+	// The first message is taken from the buffer while connecting, so up to 3 messages are kept
+	for n := 0; n < 5; n++ {
+		i.send(peerUpMsg())
+	}
+	if d := i.dropped.Load(); d < 2 {
+		t.Errorf("expected at least 2 dropped messages, got %d", d)
+	}
+	done := make(chan struct{})
+	go func() {
+		i.close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("interceptor has not been closed while destination is not reachable")
+	}
+}
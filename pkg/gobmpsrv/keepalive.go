@@ -0,0 +1,55 @@
+package gobmpsrv
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// WithTCPKeepAlive enables TCP keepalive on connections of accepted clients and passive routers, so the
+// operating system detects a dead peer, for example when a firewall silently drops a long-lived session,
+// and the session is closed and passive routers are reconnected. idle is how long the connection stays
+// idle before the first probe is sent, interval is the time between probes and count is the number of
+// unanswered probes after which the connection is closed. 0 interval or count keeps the system default,
+// interval and count are supported on Linux only. By default keepalive settings of net package are kept.
+func WithTCPKeepAlive(idle, interval time.Duration, count int) Option {
+	return func(srv *bmpServer) {
+		srv.keepAlive = true
+		srv.keepAliveIdle = idle
+		srv.keepAliveInterval = interval
+		srv.keepAliveCount = count
+	}
+}
+
+// setKeepAlive enables TCP keepalive on the connection of the client according to the server's keepalive
+// options, TLS connections are configured through the underlying TCP connection.
+func (srv *bmpServer) setKeepAlive(client net.Conn) error {
+	if !srv.keepAlive {
+		return nil
+	}
+	conn := client
+	if c, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		conn = c.NetConn()
+	}
+	tc, ok := conn.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("keepalive is not supported on connection of type %T", conn)
+	}
+	if err := tc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	if srv.keepAliveIdle > 0 {
+		if err := tc.SetKeepAlivePeriod(srv.keepAliveIdle); err != nil {
+			return err
+		}
+	}
+	if srv.keepAliveInterval == 0 && srv.keepAliveCount == 0 {
+		return nil
+	}
+	rc, err := tc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	return keepAliveProbes(rc, srv.keepAliveInterval, srv.keepAliveCount)
+}
@@ -0,0 +1,29 @@
+//go:build linux
+
+package gobmpsrv
+
+import (
+	"syscall"
+	"time"
+)
+
+// keepAliveProbes sets the interval between keepalive probes and the number of unanswered probes after
+// which the connection is closed, 0 keeps the current value. The interval is rounded up to seconds.
+func keepAliveProbes(c syscall.RawConn, interval time.Duration, count int) error {
+	var err error
+	if cerr := c.Control(func(fd uintptr) {
+		if interval > 0 {
+			secs := int((interval + time.Second - 1) / time.Second)
+			if err = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, secs); err != nil {
+				return
+			}
+		}
+		if count > 0 {
+			err = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT, count)
+		}
+	}); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
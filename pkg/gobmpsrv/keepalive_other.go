@@ -0,0 +1,15 @@
+//go:build !linux
+
+package gobmpsrv
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// keepAliveProbes fails as setting keepalive probes is not supported
+func keepAliveProbes(_ syscall.RawConn, _ time.Duration, _ int) error {
+	return fmt.Errorf("keepalive interval and count are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,102 @@
+//go:build linux
+
+package gobmpsrv
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// acceptedConn returns the connection of the client accepted by the server
+func acceptedConn(t *testing.T, srv *bmpServer) net.Conn {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		srv.mu.Lock()
+		for client := range srv.clients {
+			srv.mu.Unlock()
+			return client
+		}
+		srv.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("client has not been accepted")
+
+	return nil
+}
+
+func TestServerTCPKeepAlive(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		enabled  int
+		idle     int
+		interval int
+		count    int
+	}{
+		{
+			name:     "keepalive",
+			opts:     []Option{WithTCPKeepAlive(30*time.Second, 5*time.Second, 3)},
+			enabled:  1,
+			idle:     30,
+			interval: 5,
+			count:    3,
+		},
+		{
+			name:    "keepalive default interval and count",
+			opts:    []Option{WithTCPKeepAlive(45*time.Second, 0, 0)},
+			enabled: 1,
+			idle:    45,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := NewBMPServer(0, 0, false, nil, false, append([]Option{WithBindAddress("127.0.0.1")}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+			}
+			srv.Start()
+			defer srv.Stop()
+			client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+			if err != nil {
+				t.Fatalf("failed to connect to bmp server with error: %+v", err)
+			}
+			defer client.Close()
+			rc, err := acceptedConn(t, srv.(*bmpServer)).(*net.TCPConn).SyscallConn()
+			if err != nil {
+				t.Fatalf("failed to get raw connection with error: %+v", err)
+			}
+			got := map[string]int{}
+			opts := map[string][2]int{
+				"SO_KEEPALIVE":  {syscall.SOL_SOCKET, syscall.SO_KEEPALIVE},
+				"TCP_KEEPIDLE":  {syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE},
+				"TCP_KEEPINTVL": {syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL},
+				"TCP_KEEPCNT":   {syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT},
+			}
+			if err := rc.Control(func(fd uintptr) {
+				for name, opt := range opts {
+					v, err := syscall.GetsockoptInt(int(fd), opt[0], opt[1])
+					if err != nil {
+						t.Errorf("failed to get %s with error: %+v", name, err)
+					}
+					got[name] = v
+				}
+			}); err != nil {
+				t.Fatalf("failed to control raw connection with error: %+v", err)
+			}
+			if got["SO_KEEPALIVE"] != tt.enabled {
+				t.Errorf("expected SO_KEEPALIVE %d, got %d", tt.enabled, got["SO_KEEPALIVE"])
+			}
+			if got["TCP_KEEPIDLE"] != tt.idle {
+				t.Errorf("expected TCP_KEEPIDLE %d, got %d", tt.idle, got["TCP_KEEPIDLE"])
+			}
+			if tt.interval != 0 && got["TCP_KEEPINTVL"] != tt.interval {
+				t.Errorf("expected TCP_KEEPINTVL %d, got %d", tt.interval, got["TCP_KEEPINTVL"])
+			}
+			if tt.count != 0 && got["TCP_KEEPCNT"] != tt.count {
+				t.Errorf("expected TCP_KEEPCNT %d, got %d", tt.count, got["TCP_KEEPCNT"])
+			}
+		})
+	}
+}
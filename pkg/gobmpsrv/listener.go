@@ -0,0 +1,49 @@
+package gobmpsrv
+
+import (
+	"context"
+	"net"
+	"syscall"
+)
+
+// WithListenConfig sets the configuration used to create the listener of incoming BMP clients,
+// its Control hook can set socket options before the socket is bound. By default net.Listen is used.
+func WithListenConfig(lc net.ListenConfig) Option {
+	return func(srv *bmpServer) {
+		srv.listenConfig = &lc
+	}
+}
+
+// WithReuseAddress sets SO_REUSEADDR on the listener of incoming BMP clients, so a restarted server binds
+// its port while connections of the previous one are in TIME_WAIT state. The option is combined with
+// the Control hook of WithListenConfig. SO_REUSEADDR is supported on Unix systems only, where Go
+// sets it on listeners already, other systems fail to create the listener.
+func WithReuseAddress() Option {
+	return func(srv *bmpServer) {
+		srv.reuseAddress = true
+	}
+}
+
+// listen creates the listener of incoming BMP clients on addr according to the server's listener options
+func (srv *bmpServer) listen(addr string) (net.Listener, error) {
+	if srv.listenConfig == nil && !srv.reuseAddress {
+		return net.Listen("tcp", addr)
+	}
+	lc := net.ListenConfig{}
+	if srv.listenConfig != nil {
+		lc = *srv.listenConfig
+	}
+	if srv.reuseAddress {
+		control := lc.Control
+		lc.Control = func(network, address string, c syscall.RawConn) error {
+			if control != nil {
+				if err := control(network, address, c); err != nil {
+					return err
+				}
+			}
+			return reuseAddressControl(network, address, c)
+		}
+	}
+
+	return lc.Listen(context.Background(), "tcp", addr)
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package gobmpsrv
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+)
+
+// reuseAddressControl fails as SO_REUSEADDR is not supported
+func reuseAddressControl(_, _ string, _ syscall.RawConn) error {
+	return fmt.Errorf("SO_REUSEADDR is not supported on %s", runtime.GOOS)
+}
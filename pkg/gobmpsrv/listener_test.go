@@ -0,0 +1,52 @@
+//go:build unix
+
+package gobmpsrv
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServerReuseAddress(t *testing.T) {
+	srv, err := NewBMPServer(0, 0, false, nil, false, WithBindAddress("127.0.0.1"), WithReuseAddress())
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	port := srv.(*bmpServer).incoming.Addr().(*net.TCPAddr).Port
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	// The server closes the session of the client sending invalid Common Header first,
+	// so the connection stays in TIME_WAIT state on the server's port.
+	if _, err := client.Write([]byte{2, 0, 0, 0, 6, 0}); err != nil {
+		t.Fatalf("failed to write to bmp server with error: %+v", err)
+	}
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := client.Read(make([]byte, 1)); err == nil {
+		t.Fatalf("expected client connection to be closed")
+	} else if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		t.Fatalf("client connection has not been closed by the server")
+	}
+	client.Close()
+	srv.Stop()
+
+	controlled := false
+	lc := net.ListenConfig{
+		Control: func(_, _ string, _ syscall.RawConn) error {
+			controlled = true
+			return nil
+		},
+	}
+	restarted, err := NewBMPServer(port, 0, false, nil, false, WithBindAddress("127.0.0.1"), WithListenConfig(lc), WithReuseAddress())
+	if err != nil {
+		t.Fatalf("failed to bind port %d right after the first listener closed with error: %+v", port, err)
+	}
+	defer restarted.Stop()
+	if !controlled {
+		t.Errorf("expected Control hook of the listen configuration to be called")
+	}
+}
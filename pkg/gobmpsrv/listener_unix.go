@@ -0,0 +1,19 @@
+//go:build unix
+
+package gobmpsrv
+
+import (
+	"syscall"
+)
+
+// reuseAddressControl sets SO_REUSEADDR on the socket before it is bound
+func reuseAddressControl(_, _ string, c syscall.RawConn) error {
+	var err error
+	if cerr := c.Control(func(fd uintptr) {
+		err = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	}); cerr != nil {
+		return cerr
+	}
+
+	return err
+}
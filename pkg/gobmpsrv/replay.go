@@ -0,0 +1,89 @@
+package gobmpsrv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/logging"
+	"github.com/sbezverk/gobmp/pkg/message"
+	"github.com/sbezverk/gobmp/pkg/parser"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+// ParseResult describes the outcome of parsing a single BMP message read by ParseAll
+type ParseResult struct {
+	// Offset is the position of the message's Common Header in the reader
+	Offset int64
+	// MessageType is the message type of the Common Header
+	MessageType byte
+	// Length is the length of the message including the Common Header
+	Length int
+	// Err is the parsing error, it is nil when the message is parsed cleanly
+	Err error
+}
+
+// ReplayReader reads BMP messages framed by their Common Header from r, for example a file of concatenated
+// BMP messages captured from a BMP session, and publishes them through the same parser and producer pipeline
+// used for live BMP sessions. ReplayReader returns when r is exhausted and all read messages are published.
+// An error is returned when r ends in the middle of a message or a message's Common Header is invalid,
+// messages read before the error are published.
+func ReplayReader(r io.Reader, publisher pub.Publisher, splitAF bool, opts ...message.Option) error {
+	parserQueue, stopPipeline := startPipeline(publisher, splitAF, nil, logging.Default(), 0, opts...)
+	defer stopPipeline()
+	reader := bmp.NewMessageReader(bufio.NewReaderSize(r, readBufferSize))
+	for {
+		msg, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		parserQueue <- msg
+	}
+}
+
+// ParseAll reads BMP messages framed by their Common Header from r like ReplayReader and parses them, parsed messages
+// are produced to pub.NewNullPublisher, so a capture is validated without a publisher. A result is returned for every
+// message read, the parsing error is returned in the message's result instead of being only logged.
+// An error is returned when r ends in the middle of a message or a message's Common Header is invalid,
+// results of messages read before the error are returned with it.
+func ParseAll(r io.Reader, opts ...message.Option) ([]ParseResult, error) {
+	prod := message.NewProducer(pub.NewNullPublisher(), true, opts...)
+	producerQueue := make(chan bmp.Message)
+	prodStop := make(chan struct{})
+	prodDone := make(chan struct{})
+	go func() {
+		prod.Producer(producerQueue, prodStop)
+		close(prodDone)
+	}()
+	defer func() {
+		close(prodStop)
+		<-prodDone
+	}()
+	reader := bmp.NewMessageReader(bufio.NewReaderSize(r, readBufferSize))
+	results := make([]ParseResult, 0)
+	var offset int64
+	for {
+		msg, err := reader.Next()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to read message at offset %d with error: %w", offset, err)
+		}
+		msgs, err := parser.Parse(msg)
+		results = append(results, ParseResult{
+			Offset:      offset,
+			MessageType: msg[5],
+			Length:      len(msg),
+			Err:         err,
+		})
+		for _, m := range msgs {
+			producerQueue <- m
+		}
+		offset += int64(len(msg))
+	}
+}
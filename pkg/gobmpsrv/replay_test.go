@@ -0,0 +1,248 @@
+package gobmpsrv
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/iotest"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestReplayReader(t *testing.T) {
+	initiation := []byte{
+		3, 0, 0, 0, 18, bmp.InitiationMsg,
+		0, 2, 0, 8, 'x', 'r', 'v', '9', 'k', '-', 'r', '1', // sysName
+	}
+	termination := []byte{
+		3, 0, 0, 0, 12, bmp.TerminationMsg,
+		0, 1, 0, 2, 0, 1, // Reason Unspecified
+	}
+	dump := append(append(append([]byte{}, initiation...), peerUpMsg()...), termination...)
+	f := filepath.Join(t.TempDir(), "bmp.dump")
+	if err := ioutil.WriteFile(f, dump, 0644); err != nil {
+		t.Fatalf("failed to write BMP dump with error: %+v", err)
+	}
+	tests := []struct {
+		name   string
+		input  func(t *testing.T) io.Reader
+		expect []int
+		fail   bool
+	}{
+		{
+			name: "file of three messages",
+			input: func(t *testing.T) io.Reader {
+				r, err := os.Open(f)
+				if err != nil {
+					t.Fatalf("failed to open BMP dump with error: %+v", err)
+				}
+				t.Cleanup(func() { r.Close() })
+				return r
+			},
+			expect: []int{bmp.PeerStateChangeMsg, bmp.InitiationMsg, bmp.TerminationMsg},
+		},
+		{
+			name: "truncated last message",
+			input: func(t *testing.T) io.Reader {
+				return bytes.NewReader(dump[:len(dump)-1])
+			},
+			expect: []int{bmp.PeerStateChangeMsg, bmp.InitiationMsg},
+			fail:   true,
+		},
+		{
+			name: "empty",
+			input: func(t *testing.T) io.Reader {
+				return bytes.NewReader(nil)
+			},
+			expect: []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{msgs: make(chan int, len(tt.expect)+1)}
+			err := ReplayReader(tt.input(t), publisher, false)
+			if err != nil && !tt.fail {
+				t.Fatalf("expected to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			close(publisher.msgs)
+			// Messages are parsed and published concurrently, the order of published messages is not preserved
+			got := make([]int, 0)
+			for msgType := range publisher.msgs {
+				got = append(got, msgType)
+			}
+			sort.Ints(got)
+			sort.Ints(tt.expect)
+			if len(got) != len(tt.expect) {
+				t.Fatalf("expected published message types %v, got %v", tt.expect, got)
+			}
+			for i := range got {
+				if got[i] != tt.expect[i] {
+					t.Fatalf("expected published message types %v, got %v", tt.expect, got)
+				}
+			}
+		})
+	}
+}
+
+// countingReader counts reads from the underlying reader, each read from a connection is a syscall
+type countingReader struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	c.reads++
+	return c.r.Read(b)
+}
+
+func TestReadMessageBuffered(t *testing.T) {
+	msgs := [][]byte{peerUpMsg(), {3, 0, 0, 0, 12, bmp.TerminationMsg, 0, 1, 0, 2, 0, 1}, peerUpMsg()}
+	input := bytes.Join(msgs, nil)
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{
+			name: "one byte reads",
+			r:    bufio.NewReaderSize(iotest.OneByteReader(bytes.NewReader(input)), 16),
+		},
+		{
+			name: "half reads",
+			r:    bufio.NewReaderSize(iotest.HalfReader(bytes.NewReader(input)), 16),
+		},
+		{
+			// Messages are split at the buffer boundaries
+			name: "buffer smaller than message",
+			r:    bufio.NewReaderSize(bytes.NewReader(input), 50),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := bmp.NewMessageReader(tt.r)
+			for i, expect := range msgs {
+				got, err := reader.Next()
+				if err != nil {
+					t.Fatalf("failed to read message %d with error: %+v", i, err)
+				}
+				if !bytes.Equal(got, expect) {
+					t.Fatalf("expected message %d %v, got %v", i, expect, got)
+				}
+			}
+			if _, err := reader.Next(); err != io.EOF {
+				t.Fatalf("expected io.EOF, got %+v", err)
+			}
+		})
+	}
+}
+
+func benchmarkReadMessage(b *testing.B, buffered bool) {
+	const count = 1000
+	input := bytes.Repeat(peerUpMsg(), count)
+	b.ReportAllocs()
+	b.ResetTimer()
+	reads := 0
+	for i := 0; i < b.N; i++ {
+		cr := &countingReader{r: bytes.NewReader(input)}
+		var r io.Reader = cr
+		if buffered {
+			r = bufio.NewReaderSize(cr, readBufferSize)
+		}
+		reader := bmp.NewMessageReader(r)
+		for n := 0; n < count; n++ {
+			if _, err := reader.Next(); err != nil {
+				b.Fatalf("failed to read message with error: %+v", err)
+			}
+		}
+		reads += cr.reads
+	}
+	b.ReportMetric(float64(reads)/float64(b.N*count), "reads/msg")
+}
+
+func BenchmarkReadMessageUnbuffered(b *testing.B) {
+	benchmarkReadMessage(b, false)
+}
+
+func BenchmarkReadMessageBuffered(b *testing.B) {
+	benchmarkReadMessage(b, true)
+}
+
+func TestParseAll(t *testing.T) {
+	initiation := []byte{
+		3, 0, 0, 0, 18, bmp.InitiationMsg,
+		0, 2, 0, 8, 'x', 'r', 'v', '9', 'k', '-', 'r', '1', // sysName
+	}
+	// Peer Down of peer 10.0.0.2 with invalid reason 9
+	peerDown := []byte{
+		3, 0, 0, 0, 49, bmp.PeerDownMsg,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x02,
+		0x00, 0x00, 0xC3, 0xCB, 0x0A, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		9,
+	}
+	termination := []byte{
+		3, 0, 0, 0, 12, bmp.TerminationMsg,
+		0, 1, 0, 2, 0, 1, // Reason Unspecified
+	}
+	capture := bytes.Join([][]byte{initiation, peerUpMsg(), peerDown, termination}, nil)
+	tests := []struct {
+		name   string
+		input  []byte
+		expect []byte
+		failed []int
+		fail   bool
+	}{
+		{
+			name:   "capture with invalid peer down",
+			input:  capture,
+			expect: []byte{bmp.InitiationMsg, bmp.PeerUpMsg, bmp.PeerDownMsg, bmp.TerminationMsg},
+			failed: []int{2},
+		},
+		{
+			name:   "truncated capture",
+			input:  capture[:len(capture)-1],
+			expect: []byte{bmp.InitiationMsg, bmp.PeerUpMsg, bmp.PeerDownMsg},
+			failed: []int{2},
+			fail:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := ParseAll(bytes.NewReader(tt.input))
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but fail with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if len(results) != len(tt.expect) {
+				t.Fatalf("expected %d results, got %d", len(tt.expect), len(results))
+			}
+			var offset int64
+			failed := make([]int, 0)
+			for i, r := range results {
+				if r.MessageType != tt.expect[i] {
+					t.Errorf("expected message %d of type %d, got %d", i, tt.expect[i], r.MessageType)
+				}
+				if r.Offset != offset {
+					t.Errorf("expected message %d at offset %d, got %d", i, offset, r.Offset)
+				}
+				offset += int64(r.Length)
+				if r.Err != nil {
+					failed = append(failed, i)
+				}
+			}
+			if !reflect.DeepEqual(tt.failed, failed) {
+				t.Errorf("expected messages %v to fail, got %v", tt.failed, failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package gobmpsrv
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestServerResync(t *testing.T) {
+	publisher := &recordingPublisher{msgs: make(chan int, 2)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"), WithResync(64))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	defer client.Close()
+	// Junk bytes between two valid messages throw off framing, the second message is sent once the first
+	// one is published
+	for i, msg := range [][]byte{peerUpMsg(), append([]byte{0xde, 0xad, 0xbe, 0xef, 0x00}, peerUpMsg()...)} {
+		if _, err := client.Write(msg); err != nil {
+			t.Fatalf("failed to send message %d with error: %+v", i+1, err)
+		}
+		select {
+		case msgType := <-publisher.msgs:
+			if msgType != bmp.PeerStateChangeMsg {
+				t.Fatalf("expected message type %d, got %d", bmp.PeerStateChangeMsg, msgType)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %d has not been published", i+1)
+		}
+	}
+}
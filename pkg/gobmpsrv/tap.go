@@ -0,0 +1,83 @@
+package gobmpsrv
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WithTap makes the server write every BMP message received from a client matching match to a file in dir
+// while the message is processed as usual. match is called with the remote address of the client without
+// the port, nil match taps all clients. The file is named by the router hash of the client's address with
+// .bmp extension, once the file would exceed maxFileSize bytes, it is renamed with .1 suffix replacing
+// the previous one and a new file is started, so a client takes up to about twice maxFileSize bytes.
+// Files carry whole BMP messages and can be read with ReplayReader. By default sessions are not tapped.
+func WithTap(dir string, maxFileSize int64, match func(remoteAddr string) bool) Option {
+	return func(srv *bmpServer) {
+		srv.tapDir = dir
+		srv.tapMaxSize = maxFileSize
+		srv.tapMatch = match
+	}
+}
+
+// tap writes BMP messages to files opened by open, a file is closed and the next one is opened
+// when writing a message would make the file exceed maxSize bytes. A message larger than maxSize
+// is written to a file of its own.
+type tap struct {
+	open    func() (io.WriteCloser, error)
+	maxSize int64
+	w       io.WriteCloser
+	size    int64
+}
+
+// newTap returns the tap of the client or nil when the client's session is not tapped
+func (srv *bmpServer) newTap(clientAddr, routerHash string) *tap {
+	if srv.tapDir == "" || srv.tapMaxSize <= 0 {
+		return nil
+	}
+	if srv.tapMatch != nil && !srv.tapMatch(clientAddr) {
+		return nil
+	}
+
+	return &tap{open: openTapFile(filepath.Join(srv.tapDir, routerHash+".bmp")), maxSize: srv.tapMaxSize}
+}
+
+// openTapFile returns a function creating the file of path, the existing file is kept with .1 suffix
+func openTapFile(path string) func() (io.WriteCloser, error) {
+	return func() (io.WriteCloser, error) {
+		if err := os.Rename(path, path+".1"); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return os.Create(path)
+	}
+}
+
+func (t *tap) write(msg []byte) error {
+	if t.w != nil && t.size > 0 && t.size+int64(len(msg)) > t.maxSize {
+		if err := t.close(); err != nil {
+			return err
+		}
+	}
+	if t.w == nil {
+		w, err := t.open()
+		if err != nil {
+			return err
+		}
+		t.w = w
+		t.size = 0
+	}
+	n, err := t.w.Write(msg)
+	t.size += int64(n)
+
+	return err
+}
+
+func (t *tap) close() error {
+	if t.w == nil {
+		return nil
+	}
+	err := t.w.Close()
+	t.w = nil
+
+	return err
+}
@@ -0,0 +1,103 @@
+package gobmpsrv
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestTap(t *testing.T) {
+	initiation := []byte{3, 0, 0, 0, 13, bmp.InitiationMsg, 0, 2, 0, 3, 'l', 'a', 'b'}
+	peerUp := peerUpMsg()
+	tests := []struct {
+		name    string
+		maxSize int64
+		expect  [][]byte
+	}{
+		{
+			name:    "both messages in one file",
+			maxSize: 1024,
+			expect:  [][]byte{append(append([]byte{}, initiation...), peerUp...)},
+		},
+		{
+			name:    "file rotated",
+			maxSize: int64(len(initiation)),
+			expect:  [][]byte{initiation, peerUp},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make([]*bytes.Buffer, 0)
+			tp := &tap{
+				open: func() (io.WriteCloser, error) {
+					files = append(files, &bytes.Buffer{})
+					return nopCloser{files[len(files)-1]}, nil
+				},
+				maxSize: tt.maxSize,
+			}
+			for _, msg := range [][]byte{initiation, peerUp} {
+				if err := tp.write(msg); err != nil {
+					t.Fatalf("failed to write message with error: %+v", err)
+				}
+			}
+			if err := tp.close(); err != nil {
+				t.Fatalf("failed to close tap with error: %+v", err)
+			}
+			if len(files) != len(tt.expect) {
+				t.Fatalf("expected %d files, got %d", len(tt.expect), len(files))
+			}
+			for i, f := range files {
+				if !bytes.Equal(f.Bytes(), tt.expect[i]) {
+					t.Errorf("file %d: expected %v, got %v", i, tt.expect[i], f.Bytes())
+				}
+			}
+		})
+	}
+}
+
+func TestServerTap(t *testing.T) {
+	dir := t.TempDir()
+	publisher := &recordingPublisher{msgs: make(chan int, 1)}
+	srv, err := NewBMPServer(0, 0, false, publisher, false, WithBindAddress("127.0.0.1"),
+		WithTap(dir, 1<<20, func(remoteAddr string) bool { return remoteAddr == "127.0.0.1" }))
+	if err != nil {
+		t.Fatalf("failed to instantiate bmp server with error: %+v", err)
+	}
+	srv.Start()
+	defer srv.Stop()
+	client, err := net.Dial("tcp", srv.(*bmpServer).incoming.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to bmp server with error: %+v", err)
+	}
+	if _, err := client.Write(peerUpMsg()); err != nil {
+		t.Fatalf("failed to send message with error: %+v", err)
+	}
+	select {
+	case <-publisher.msgs:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("message has not been published")
+	}
+	client.Close()
+	path := filepath.Join(dir, pub.NewRouter("127.0.0.1").Hash+".bmp")
+	// The message is written to the tap before it is published
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read tap file with error: %+v", err)
+	}
+	if !bytes.Equal(b, peerUpMsg()) {
+		t.Fatalf("expected tap file %v, got %v", peerUpMsg(), b)
+	}
+}
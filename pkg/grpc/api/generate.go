@@ -0,0 +1,4 @@
+// Package api defines GoBMP gRPC service streaming messages produced by gobmp.
+package api
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative gobmp.proto
@@ -0,0 +1,140 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: gobmp.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	GoBMP_Subscribe_FullMethodName = "/gobmp.GoBMP/Subscribe"
+)
+
+// GoBMPClient is the client API for GoBMP service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GoBMPClient interface {
+	// Subscribe streams messages matching the filter until the subscriber disconnects
+	// or it is disconnected for not keeping up with published messages.
+	Subscribe(ctx context.Context, in *Filter, opts ...grpc.CallOption) (GoBMP_SubscribeClient, error)
+}
+
+type goBMPClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoBMPClient(cc grpc.ClientConnInterface) GoBMPClient {
+	return &goBMPClient{cc}
+}
+
+func (c *goBMPClient) Subscribe(ctx context.Context, in *Filter, opts ...grpc.CallOption) (GoBMP_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &GoBMP_ServiceDesc.Streams[0], GoBMP_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &goBMPSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type GoBMP_SubscribeClient interface {
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type goBMPSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *goBMPSubscribeClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GoBMPServer is the server API for GoBMP service.
+// All implementations must embed UnimplementedGoBMPServer
+// for forward compatibility
+type GoBMPServer interface {
+	// Subscribe streams messages matching the filter until the subscriber disconnects
+	// or it is disconnected for not keeping up with published messages.
+	Subscribe(*Filter, GoBMP_SubscribeServer) error
+	mustEmbedUnimplementedGoBMPServer()
+}
+
+// UnimplementedGoBMPServer must be embedded to have forward compatible implementations.
+type UnimplementedGoBMPServer struct {
+}
+
+func (UnimplementedGoBMPServer) Subscribe(*Filter, GoBMP_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedGoBMPServer) mustEmbedUnimplementedGoBMPServer() {}
+
+// UnsafeGoBMPServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoBMPServer will
+// result in compilation errors.
+type UnsafeGoBMPServer interface {
+	mustEmbedUnimplementedGoBMPServer()
+}
+
+func RegisterGoBMPServer(s grpc.ServiceRegistrar, srv GoBMPServer) {
+	s.RegisterService(&GoBMP_ServiceDesc, srv)
+}
+
+func _GoBMP_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Filter)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoBMPServer).Subscribe(m, &goBMPSubscribeServer{stream})
+}
+
+type GoBMP_SubscribeServer interface {
+	Send(*Message) error
+	grpc.ServerStream
+}
+
+type goBMPSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *goBMPSubscribeServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// GoBMP_ServiceDesc is the grpc.ServiceDesc for GoBMP service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoBMP_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gobmp.GoBMP",
+	HandlerType: (*GoBMPServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _GoBMP_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gobmp.proto",
+}
@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"net"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/grpc/api"
+	"github.com/sbezverk/gobmp/pkg/pub"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultBufferSize is the number of messages buffered for each subscriber
+	defaultBufferSize = 1024
+)
+
+// SlowConsumerPolicy defines what happens to a subscriber whose buffer is full when a message is published
+type SlowConsumerPolicy int
+
+const (
+	// DropMessages drops published messages until the subscriber frees its buffer
+	DropMessages SlowConsumerPolicy = iota
+	// Disconnect ends the subscriber's stream with ResourceExhausted status
+	Disconnect
+)
+
+// Option defines a function which modifies optional parameters of gRPC publisher
+type Option func(*publisher)
+
+// WithBufferSize sets the number of messages buffered for each subscriber, by default 1024 messages are buffered.
+func WithBufferSize(size int) Option {
+	return func(p *publisher) {
+		p.bufferSize = size
+	}
+}
+
+// WithSlowConsumerPolicy sets the policy applied to subscribers not keeping up with published messages,
+// by default messages are dropped.
+func WithSlowConsumerPolicy(policy SlowConsumerPolicy) Option {
+	return func(p *publisher) {
+		p.policy = policy
+	}
+}
+
+type subscriber struct {
+	types map[int32]bool
+	msgs  chan *api.Message
+	// slow is closed when the subscriber is disconnected by Disconnect policy
+	slow     chan struct{}
+	slowOnce sync.Once
+	dropped  uint64
+}
+
+func (s *subscriber) accepts(msgType int32) bool {
+	return len(s.types) == 0 || s.types[msgType]
+}
+
+type publisher struct {
+	api.UnimplementedGoBMPServer
+	server     *grpc.Server
+	bufferSize int
+	policy     SlowConsumerPolicy
+	sync.Mutex
+	subscribers map[*subscriber]struct{}
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+}
+
+func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
+	m := &api.Message{
+		Type:  int32(t),
+		Key:   key,
+		Value: msg,
+	}
+	p.Lock()
+	defer p.Unlock()
+	for s := range p.subscribers {
+		if !s.accepts(m.Type) {
+			continue
+		}
+		select {
+		case s.msgs <- m:
+			continue
+		default:
+		}
+		switch p.policy {
+		case Disconnect:
+			s.slowOnce.Do(func() { close(s.slow) })
+		default:
+			s.dropped++
+			if glog.V(5) {
+				glog.Infof("subscriber's buffer is full, dropped %d messages", s.dropped)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Subscribe streams published messages matching the filter to the subscriber
+func (p *publisher) Subscribe(filter *api.Filter, stream api.GoBMP_SubscribeServer) error {
+	s := &subscriber{
+		types: make(map[int32]bool, len(filter.GetTypes())),
+		msgs:  make(chan *api.Message, p.bufferSize),
+		slow:  make(chan struct{}),
+	}
+	for _, t := range filter.GetTypes() {
+		s.types[t] = true
+	}
+	p.Lock()
+	p.subscribers[s] = struct{}{}
+	p.Unlock()
+	defer func() {
+		p.Lock()
+		delete(p.subscribers, s)
+		p.Unlock()
+	}()
+	for {
+		select {
+		case m := <-s.msgs:
+			if err := stream.Send(m); err != nil {
+				return err
+			}
+		case <-s.slow:
+			return status.Error(codes.ResourceExhausted, "subscriber does not keep up with published messages")
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-p.stopCh:
+			return nil
+		}
+	}
+}
+
+// Stop disconnects subscribers and stops the gRPC server, calling Stop more than once is safe
+func (p *publisher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		p.server.Stop()
+	})
+}
+
+// NewPublisher instantiates a new instance of a gRPC publisher, the publisher serves GoBMP service on grpcSrv
+// address and streams published messages to its subscribers.
+func NewPublisher(grpcSrv string, opts ...Option) (pub.Publisher, error) {
+	lis, err := net.Listen("tcp", grpcSrv)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPublisher(lis, opts...), nil
+}
+
+func newPublisher(lis net.Listener, opts ...Option) *publisher {
+	p := &publisher{
+		server:      grpc.NewServer(),
+		bufferSize:  defaultBufferSize,
+		policy:      DropMessages,
+		subscribers: make(map[*subscriber]struct{}),
+		stopCh:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	api.RegisterGoBMPServer(p.server, p)
+	go func() {
+		if err := p.server.Serve(lis); err != nil {
+			glog.Errorf("gRPC server failed with error: %+v", err)
+		}
+	}()
+
+	return p
+}
@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/grpc/api"
+	"github.com/sbezverk/gobmp/pkg/message"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// subscribe starts in-process publisher and subscribes to it with the filter
+func subscribe(t *testing.T, filter *api.Filter, opts ...Option) (*publisher, api.GoBMP_SubscribeClient) {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	p := newPublisher(lis, opts...)
+	t.Cleanup(p.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial gRPC publisher with error: %+v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	stream, err := api.NewGoBMPClient(conn).Subscribe(ctx, filter)
+	if err != nil {
+		t.Fatalf("failed to subscribe with error: %+v", err)
+	}
+	// Messages published before the subscription is registered are not streamed
+	for {
+		p.Lock()
+		n := len(p.subscribers)
+		p.Unlock()
+		if n == 1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("subscriber is not registered")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	return p, stream
+}
+
+func TestSubscribePeerUp(t *testing.T) {
+	peerUp, err := json.Marshal(&message.PeerStateChange{
+		Action:     "add",
+		RouterHash: "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6",
+		RemoteIP:   "192.168.80.103",
+		RemoteASN:  5070,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal peer message with error: %+v", err)
+	}
+	p, stream := subscribe(t, &api.Filter{Types: []int32{bmp.PeerStateChangeMsg}})
+	// The filter drops statistics message
+	if err := p.PublishMessage(bmp.StatsReportMsg, []byte("4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6"), []byte(`{}`)); err != nil {
+		t.Fatalf("failed to publish statistics message with error: %+v", err)
+	}
+	if err := p.PublishMessage(bmp.PeerStateChangeMsg, []byte("4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6"), peerUp); err != nil {
+		t.Fatalf("failed to publish peer message with error: %+v", err)
+	}
+	m, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("failed to receive message with error: %+v", err)
+	}
+	if m.GetType() != bmp.PeerStateChangeMsg {
+		t.Fatalf("expected message type %d, got %d", bmp.PeerStateChangeMsg, m.GetType())
+	}
+	if string(m.GetKey()) != "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6" {
+		t.Errorf("expected key 4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6, got %s", string(m.GetKey()))
+	}
+	got := &message.PeerStateChange{}
+	if err := json.Unmarshal(m.GetValue(), got); err != nil {
+		t.Fatalf("failed to unmarshal peer message with error: %+v", err)
+	}
+	if got.Action != "add" || got.RemoteIP != "192.168.80.103" || got.RemoteASN != 5070 {
+		t.Errorf("expected Peer Up of 192.168.80.103 AS 5070, got %+v", got)
+	}
+}
+
+func TestSlowConsumerPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       SlowConsumerPolicy
+		dropped      uint64
+		disconnected bool
+	}{
+		{
+			name:    "drop messages",
+			policy:  DropMessages,
+			dropped: 2,
+		},
+		{
+			name:         "disconnect",
+			policy:       Disconnect,
+			disconnected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The subscriber does not drain its buffer of one message
+			s := &subscriber{
+				msgs: make(chan *api.Message, 1),
+				slow: make(chan struct{}),
+			}
+			p := &publisher{
+				policy:      tt.policy,
+				subscribers: map[*subscriber]struct{}{s: {}},
+			}
+			for i := 0; i < 3; i++ {
+				if err := p.PublishMessage(bmp.PeerStateChangeMsg, nil, []byte(`{}`)); err != nil {
+					t.Fatalf("failed to publish message with error: %+v", err)
+				}
+			}
+			if len(s.msgs) != 1 {
+				t.Errorf("expected 1 buffered message, got %d", len(s.msgs))
+			}
+			if s.dropped != tt.dropped {
+				t.Errorf("expected %d dropped messages, got %d", tt.dropped, s.dropped)
+			}
+			disconnected := false
+			select {
+			case <-s.slow:
+				disconnected = true
+			default:
+			}
+			if disconnected != tt.disconnected {
+				t.Errorf("expected subscriber disconnected %t, got %t", tt.disconnected, disconnected)
+			}
+		})
+	}
+}
+
+func TestSubscribeDisconnect(t *testing.T) {
+	p, stream := subscribe(t, &api.Filter{}, WithSlowConsumerPolicy(Disconnect))
+	p.Lock()
+	for s := range p.subscribers {
+		s.slowOnce.Do(func() { close(s.slow) })
+	}
+	p.Unlock()
+	if _, err := stream.Recv(); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected stream to end with %s, got %+v", codes.ResourceExhausted, err)
+	}
+}
+
+func TestStopTwice(t *testing.T) {
+	p := newPublisher(bufconn.Listen(1024 * 1024))
+	p.Stop()
+	p.Stop()
+}
@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/rand"
@@ -20,6 +21,9 @@ const (
 	unicastMessageTopic    = "gobmp.parsed.unicast_prefix"
 	unicastMessageV4Topic  = "gobmp.parsed.unicast_prefix_v4"
 	unicastMessageV6Topic  = "gobmp.parsed.unicast_prefix_v6"
+	multicastTopic         = "gobmp.parsed.multicast_prefix"
+	multicastV4Topic       = "gobmp.parsed.multicast_prefix_v4"
+	multicastV6Topic       = "gobmp.parsed.multicast_prefix_v6"
 	lsNodeMessageTopic     = "gobmp.parsed.ls_node"
 	lsLinkMessageTopic     = "gobmp.parsed.ls_link"
 	l3vpnMessageTopic      = "gobmp.parsed.l3vpn"
@@ -35,6 +39,12 @@ const (
 	flowspecMessageV4Topic = "gobmp.parsed.flowspec_v4"
 	flowspecMessageV6Topic = "gobmp.parsed.flowspec_v6"
 	statsMessageTopic      = "gobmp.parsed.statistics"
+	routeMirrorTopic       = "gobmp.parsed.route_mirror"
+	routerInfoTopic        = "gobmp.parsed.router_info"
+	terminationTopic       = "gobmp.parsed.termination"
+	parseErrorTopic        = "gobmp.parsed.parse_error"
+	endOfRIBTopic          = "gobmp.parsed.end_of_rib"
+	peerEventTopic         = "gobmp.parsed.peer_event"
 )
 
 var (
@@ -52,6 +62,9 @@ var (
 		unicastMessageTopic,
 		unicastMessageV4Topic,
 		unicastMessageV6Topic,
+		multicastTopic,
+		multicastV4Topic,
+		multicastV6Topic,
 		lsNodeMessageTopic,
 		lsLinkMessageTopic,
 		l3vpnMessageTopic,
@@ -67,17 +80,124 @@ var (
 		flowspecMessageV4Topic,
 		flowspecMessageV6Topic,
 		statsMessageTopic,
+		routeMirrorTopic,
+		routerInfoTopic,
+		terminationTopic,
+		parseErrorTopic,
+		endOfRIBTopic,
+		peerEventTopic,
 	}
 )
 
+// KeyFunc returns the key of Kafka record for a message of type msgType, key is the key computed by the producer,
+// RouterHash for most of message types. Records with the same key are stored in the same partition, so
+// the key controls which messages are consumed in order.
+type KeyFunc func(msgType int, key []byte, msg []byte) []byte
+
+// RouterKey is the default KeyFunc, it keeps the key computed by the producer, all messages of a router are
+// stored in the same partition.
+func RouterKey(msgType int, key []byte, msg []byte) []byte {
+	return key
+}
+
+// PeerKey is KeyFunc combining the router's key with the address of the peer the message was received from,
+// messages of different peers of the same router are spread across partitions while the order of messages
+// of each peer is preserved. Messages without peer address, like Initiation and Termination, keep the router's key.
+func PeerKey(msgType int, key []byte, msg []byte) []byte {
+	peer := struct {
+		PeerIP   string `json:"peer_ip"`
+		RemoteIP string `json:"remote_ip"`
+	}{}
+	if err := json.Unmarshal(msg, &peer); err != nil {
+		return key
+	}
+	addr := peer.PeerIP
+	if addr == "" {
+		addr = peer.RemoteIP
+	}
+	if addr == "" {
+		return key
+	}
+	k := make([]byte, 0, len(key)+1+len(addr))
+	k = append(k, key...)
+	k = append(k, '_')
+
+	return append(k, addr...)
+}
+
+// Option defines a function which modifies optional parameters of Kafka publisher
+type Option func(*publisher)
+
+// WithKeyFunc sets the function computing the key of Kafka records, by default RouterKey is used.
+func WithKeyFunc(f KeyFunc) Option {
+	return func(p *publisher) {
+		p.keyFunc = f
+	}
+}
+
+// WithCompression sets the codec compressing batches of records sent to the broker, by default records are
+// not compressed. Compression reduces the bandwidth and the storage taken by records at the cost of CPU
+// and of latency, as larger batches compress better. zstd requires Kafka 2.1.0 or later.
+func WithCompression(codec sarama.CompressionCodec) Option {
+	return func(p *publisher) {
+		p.config.Producer.Compression = codec
+		if codec == sarama.CompressionZSTD && !p.config.Version.IsAtLeast(sarama.V2_1_0_0) {
+			p.config.Version = sarama.V2_1_0_0
+		}
+	}
+}
+
+// WithRequiredAcks sets the acknowledgement the broker sends for produced records, by default the leader
+// acknowledges records once it has written them. sarama.NoResponse has the lowest latency, but records are
+// lost when the leader fails before writing them, sarama.WaitForAll has the highest latency and records
+// are kept as long as one in-sync replica survives.
+func WithRequiredAcks(acks sarama.RequiredAcks) Option {
+	return func(p *publisher) {
+		p.config.Producer.RequiredAcks = acks
+	}
+}
+
+// ParseCompression returns the compression codec of name, none, gzip, snappy, lz4 or zstd
+func ParseCompression(name string) (sarama.CompressionCodec, error) {
+	for _, codec := range []sarama.CompressionCodec{
+		sarama.CompressionNone,
+		sarama.CompressionGZIP,
+		sarama.CompressionSnappy,
+		sarama.CompressionLZ4,
+		sarama.CompressionZSTD,
+	} {
+		if codec.String() == name {
+			return codec, nil
+		}
+	}
+
+	return sarama.CompressionNone, fmt.Errorf("invalid compression codec %q", name)
+}
+
+// ParseRequiredAcks returns the required acks of name, none, leader or all
+func ParseRequiredAcks(name string) (sarama.RequiredAcks, error) {
+	switch name {
+	case "none":
+		return sarama.NoResponse, nil
+	case "leader":
+		return sarama.WaitForLocal, nil
+	case "all":
+		return sarama.WaitForAll, nil
+	}
+
+	return sarama.WaitForLocal, fmt.Errorf("invalid required acks %q", name)
+}
+
 type publisher struct {
 	broker   *sarama.Broker
 	config   *sarama.Config
 	producer sarama.AsyncProducer
 	stopCh   chan struct{}
+	keyFunc  KeyFunc
 }
 
 func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
+	key = p.keyFunc(t, key, msg)
 	switch t {
 	case bmp.PeerStateChangeMsg:
 		return p.produceMessage(peerTopic, key, msg)
@@ -87,6 +207,12 @@ func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
 		return p.produceMessage(unicastMessageV4Topic, key, msg)
 	case bmp.UnicastPrefixV6Msg:
 		return p.produceMessage(unicastMessageV6Topic, key, msg)
+	case bmp.MulticastPrefixMsg:
+		return p.produceMessage(multicastTopic, key, msg)
+	case bmp.MulticastPrefixV4Msg:
+		return p.produceMessage(multicastV4Topic, key, msg)
+	case bmp.MulticastPrefixV6Msg:
+		return p.produceMessage(multicastV6Topic, key, msg)
 	case bmp.LSNodeMsg:
 		return p.produceMessage(lsNodeMessageTopic, key, msg)
 	case bmp.LSLinkMsg:
@@ -117,6 +243,18 @@ func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
 		return p.produceMessage(flowspecMessageV6Topic, key, msg)
 	case bmp.StatsReportMsg:
 		return p.produceMessage(statsMessageTopic, key, msg)
+	case bmp.RouteMirrorMsg:
+		return p.produceMessage(routeMirrorTopic, key, msg)
+	case bmp.InitiationMsg:
+		return p.produceMessage(routerInfoTopic, key, msg)
+	case bmp.TerminationMsg:
+		return p.produceMessage(terminationTopic, key, msg)
+	case bmp.ParseErrorMsg:
+		return p.produceMessage(parseErrorTopic, key, msg)
+	case bmp.EndOfRIBMsg:
+		return p.produceMessage(endOfRIBTopic, key, msg)
+	case bmp.PeerEventMsg:
+		return p.produceMessage(peerEventTopic, key, msg)
 	}
 
 	return fmt.Errorf("not implemented")
@@ -142,16 +280,24 @@ func (p *publisher) Stop() {
 }
 
 // NewKafkaPublisher instantiates a new instance of a Kafka publisher
-func NewKafkaPublisher(kafkaSrv string) (pub.Publisher, error) {
+func NewKafkaPublisher(kafkaSrv string, opts ...Option) (pub.Publisher, error) {
 	glog.Infof("Initializing Kafka producer client")
 	if err := validator(kafkaSrv); err != nil {
 		glog.Errorf("Failed to validate Kafka server address %s with error: %+v", kafkaSrv, err)
 		return nil, err
 	}
-	config := sarama.NewConfig()
-	config.ClientID = "gobmp-producer" + "_" + strconv.Itoa(rand.Intn(1000))
-	config.Producer.Return.Successes = true
-	config.Version = sarama.V0_11_0_0
+	p := &publisher{
+		config:  newConfig(),
+		keyFunc: RouterKey,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	config := p.config
+	if err := config.Validate(); err != nil {
+		glog.Errorf("Invalid Kafka producer configuration with error: %+v", err)
+		return nil, err
+	}
 
 	br := sarama.NewBroker(kafkaSrv)
 	if err := br.Open(config); err != nil {
@@ -191,12 +337,21 @@ func NewKafkaPublisher(kafkaSrv string) (pub.Publisher, error) {
 		}
 	}(producer, stopCh)
 
-	return &publisher{
-		stopCh:   stopCh,
-		broker:   br,
-		config:   config,
-		producer: producer,
-	}, nil
+	p.stopCh = stopCh
+	p.broker = br
+	p.producer = producer
+
+	return p, nil
+}
+
+// newConfig returns the default configuration of Kafka producer
+func newConfig() *sarama.Config {
+	config := sarama.NewConfig()
+	config.ClientID = "gobmp-producer" + "_" + strconv.Itoa(rand.Intn(1000))
+	config.Producer.Return.Successes = true
+	config.Version = sarama.V0_11_0_0
+
+	return config
 }
 
 func validator(addr string) error {
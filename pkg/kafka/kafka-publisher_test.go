@@ -0,0 +1,172 @@
+package kafka
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestPublishMessageKey(t *testing.T) {
+	routerHash := []byte("4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6")
+	tests := []struct {
+		name    string
+		keyFunc KeyFunc
+		msgType int
+		msg     []byte
+		expect  []byte
+	}{
+		{
+			name:    "router key",
+			keyFunc: RouterKey,
+			msgType: bmp.UnicastPrefixV4Msg,
+			msg:     []byte(`{"router_hash":"4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6","peer_ip":"192.168.80.103"}`),
+			expect:  routerHash,
+		},
+		{
+			name:    "peer key of unicast prefix",
+			keyFunc: PeerKey,
+			msgType: bmp.UnicastPrefixV4Msg,
+			msg:     []byte(`{"router_hash":"4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6","peer_ip":"192.168.80.103"}`),
+			expect:  []byte("4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6_192.168.80.103"),
+		},
+		{
+			name:    "peer key of peer state change",
+			keyFunc: PeerKey,
+			msgType: bmp.PeerStateChangeMsg,
+			msg:     []byte(`{"router_hash":"4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6","remote_ip":"2001:db8::1"}`),
+			expect:  []byte("4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6_2001:db8::1"),
+		},
+		{
+			name:    "peer key of message without peer",
+			keyFunc: PeerKey,
+			msgType: bmp.InitiationMsg,
+			msg:     []byte(`{"router_hash":"4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6","sys_name":"xrv9k-r1"}`),
+			expect:  routerHash,
+		},
+		{
+			name: "custom key",
+			keyFunc: func(msgType int, key []byte, msg []byte) []byte {
+				return []byte("custom")
+			},
+			msgType: bmp.EVPNMsg,
+			msg:     []byte(`{}`),
+			expect:  []byte("custom"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := sarama.NewConfig()
+			config.Producer.Return.Successes = true
+			producer := mocks.NewAsyncProducer(t, config)
+			producer.ExpectInputAndSucceed()
+			p := &publisher{producer: producer, keyFunc: tt.keyFunc}
+			if err := p.PublishMessage(tt.msgType, routerHash, tt.msg); err != nil {
+				t.Fatalf("failed to publish message with error: %+v", err)
+			}
+			record := <-producer.Successes()
+			key, err := record.Key.Encode()
+			if err != nil {
+				t.Fatalf("failed to encode record key with error: %+v", err)
+			}
+			if !bytes.Equal(key, tt.expect) {
+				t.Errorf("expected record key %s, got %s", tt.expect, key)
+			}
+			if err := producer.Close(); err != nil {
+				t.Errorf("failed to close producer with error: %+v", err)
+			}
+		})
+	}
+}
+
+func TestPublisherConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		compression string
+		acks        string
+		expectCodec sarama.CompressionCodec
+		expectAcks  sarama.RequiredAcks
+		fail        bool
+	}{
+		{
+			name:        "default",
+			compression: "none",
+			acks:        "leader",
+			expectCodec: sarama.CompressionNone,
+			expectAcks:  sarama.WaitForLocal,
+		},
+		{
+			name:        "snappy and all",
+			compression: "snappy",
+			acks:        "all",
+			expectCodec: sarama.CompressionSnappy,
+			expectAcks:  sarama.WaitForAll,
+		},
+		{
+			name:        "zstd and none",
+			compression: "zstd",
+			acks:        "none",
+			expectCodec: sarama.CompressionZSTD,
+			expectAcks:  sarama.NoResponse,
+		},
+		{
+			name:        "invalid compression",
+			compression: "brotli",
+			acks:        "all",
+			fail:        true,
+		},
+		{
+			name:        "invalid acks",
+			compression: "gzip",
+			acks:        "2",
+			fail:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := ParseCompression(tt.compression)
+			if err != nil {
+				if !tt.fail {
+					t.Fatalf("failed to parse compression with error: %+v", err)
+				}
+				return
+			}
+			acks, err := ParseRequiredAcks(tt.acks)
+			if err != nil {
+				if !tt.fail {
+					t.Fatalf("failed to parse required acks with error: %+v", err)
+				}
+				return
+			}
+			if tt.fail {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			p := &publisher{config: newConfig()}
+			for _, opt := range []Option{WithCompression(codec), WithRequiredAcks(acks)} {
+				opt(p)
+			}
+			if err := p.config.Validate(); err != nil {
+				t.Fatalf("invalid producer configuration with error: %+v", err)
+			}
+			if p.config.Producer.Compression != tt.expectCodec {
+				t.Errorf("expected compression %s, got %s", tt.expectCodec, p.config.Producer.Compression)
+			}
+			if p.config.Producer.RequiredAcks != tt.expectAcks {
+				t.Errorf("expected required acks %d, got %d", tt.expectAcks, p.config.Producer.RequiredAcks)
+			}
+			producer := mocks.NewAsyncProducer(t, p.config)
+			producer.ExpectInputAndSucceed()
+			p.producer = producer
+			p.keyFunc = RouterKey
+			if err := p.PublishMessage(bmp.UnicastPrefixV4Msg, []byte("key"), []byte(`{}`)); err != nil {
+				t.Fatalf("failed to publish message with error: %+v", err)
+			}
+			<-producer.Successes()
+			if err := producer.Close(); err != nil {
+				t.Errorf("failed to close producer with error: %+v", err)
+			}
+		})
+	}
+}
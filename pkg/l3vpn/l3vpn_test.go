@@ -241,6 +241,68 @@ func TestUnmarshalL3VPNNLRI(t *testing.T) {
 			srv6:   false,
 			pathID: true,
 		},
+		{
+			name: "vpnv4 two labels stack",
+			input: []byte{
+				0x88,             // Length 136 bits
+				0x00, 0x06, 0x40, // Label 100
+				0x00, 0x0c, 0x81, // Label 200 with BoS
+				0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64, // RD 65000:100
+				0x0a, 0x01, 0x01, // Prefix 10.1.1.0/24
+			},
+			expect: &base.MPNLRI{
+				NLRI: []base.Route{
+					{
+						Length: 24,
+						Label: []*base.Label{
+							{
+								Value: 100,
+								Exp:   0,
+								BoS:   false,
+							},
+							{
+								Value: 200,
+								Exp:   0,
+								BoS:   true,
+							},
+						},
+						RD: &base.RD{
+							Type:  0,
+							Value: []byte{0xfd, 0xe8, 0x00, 0x00, 0x00, 0x64},
+						},
+						Prefix: []byte{0x0a, 0x01, 0x01},
+					},
+				},
+			},
+		},
+		{
+			name: "vpnv6 label with traffic class",
+			input: []byte{
+				0x88,             // Length 136 bits
+				0x00, 0x12, 0xc5, // Label 300 TC 2 with BoS
+				0x00, 0x01, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x05, // RD 10.0.0.1:5
+				0x20, 0x01, 0x0d, 0xb8, 0x00, 0x01, // Prefix 2001:db8:1::/48
+			},
+			expect: &base.MPNLRI{
+				NLRI: []base.Route{
+					{
+						Length: 48,
+						Label: []*base.Label{
+							{
+								Value: 300,
+								Exp:   2,
+								BoS:   true,
+							},
+						},
+						RD: &base.RD{
+							Type:  1,
+							Value: []byte{0x0a, 0x00, 0x00, 0x01, 0x00, 0x05},
+						},
+						Prefix: []byte{0x20, 0x01, 0x0d, 0xb8, 0x00, 0x01},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// LevelTrace is the level of detailed debugging records, they are logged by glog at verbosity 6
+	LevelTrace = slog.LevelDebug - 2
+)
+
+// glogHandler is slog.Handler writing records to glog, error and warning records are logged
+// as glog errors and warnings, debug records are logged at verbosity 5 and trace records at verbosity 6.
+type glogHandler struct {
+	// attrs are preformatted attributes added by WithAttrs
+	attrs  string
+	groups string
+}
+
+// NewGlogHandler returns slog.Handler writing records to glog, the records are subject to glog flags,
+// for example debug records are logged only with --v=5 or higher.
+func NewGlogHandler() slog.Handler {
+	return &glogHandler{}
+}
+
+// Default returns slog.Logger writing to glog, it keeps the logging behavior of glog based gobmp.
+func Default() *slog.Logger {
+	return slog.New(NewGlogHandler())
+}
+
+func (h *glogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	switch {
+	case level >= slog.LevelInfo:
+		return true
+	case level >= slog.LevelDebug:
+		return bool(glog.V(5))
+	default:
+		return bool(glog.V(6))
+	}
+}
+
+func (h *glogHandler) Handle(_ context.Context, r slog.Record) error {
+	// Depth skips Handle and slog.Logger frames, so glog reports the caller of slog.Logger
+	const depth = 3
+	switch {
+	case r.Level >= slog.LevelError:
+		glog.ErrorDepth(depth, h.format(r))
+	case r.Level >= slog.LevelWarn:
+		glog.WarningDepth(depth, h.format(r))
+	default:
+		glog.InfoDepth(depth, h.format(r))
+	}
+
+	return nil
+}
+
+// format returns the record's message followed by key=value attributes
+func (h *glogHandler) format(r slog.Record) string {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.groups, a)
+		return true
+	})
+
+	return b.String()
+}
+
+func (h *glogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		writeAttr(&b, h.groups, a)
+	}
+
+	return &glogHandler{attrs: b.String(), groups: h.groups}
+}
+
+func (h *glogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &glogHandler{attrs: h.attrs, groups: h.groups + name + "."}
+}
+
+// writeAttr appends the attribute as key=value to b, the key is qualified with groups
+func writeAttr(b *strings.Builder, groups string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			writeAttr(b, groups, ga)
+		}
+		return
+	}
+	fmt.Fprintf(b, " %s%s=%v", groups, a.Key, a.Value.Any())
+}
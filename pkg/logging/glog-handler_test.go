@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestGlogHandlerFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler slog.Handler
+		attrs   []slog.Attr
+		expect  string
+	}{
+		{
+			name:    "no attributes",
+			handler: NewGlogHandler(),
+			expect:  "client accepted",
+		},
+		{
+			name:    "record attributes",
+			handler: NewGlogHandler(),
+			attrs:   []slog.Attr{slog.String("client", "192.168.80.103:45000"), slog.Int("type", 3)},
+			expect:  "client accepted client=192.168.80.103:45000 type=3",
+		},
+		{
+			name:    "logger attributes first",
+			handler: NewGlogHandler().WithAttrs([]slog.Attr{slog.String("router_hash", "1f101e2bd3415b5b65f51fe19222e561")}),
+			attrs:   []slog.Attr{slog.Int("type", 3)},
+			expect:  "client accepted router_hash=1f101e2bd3415b5b65f51fe19222e561 type=3",
+		},
+		{
+			name:    "groups",
+			handler: NewGlogHandler().WithGroup("session"),
+			attrs:   []slog.Attr{slog.Group("peer", slog.String("ip", "10.0.0.2")), {}},
+			expect:  "client accepted session.peer.ip=10.0.0.2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := slog.NewRecord(time.Now(), slog.LevelInfo, "client accepted", 0)
+			r.AddAttrs(tt.attrs...)
+			if got := tt.handler.(*glogHandler).format(r); got != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestGlogHandlerEnabled(t *testing.T) {
+	h := NewGlogHandler()
+	for _, level := range []slog.Level{slog.LevelInfo, slog.LevelWarn, slog.LevelError} {
+		if !h.Enabled(context.Background(), level) {
+			t.Errorf("expected level %s to be enabled", level)
+		}
+	}
+	// Debug and trace records depend on glog verbosity, which is 0 by default
+	for _, level := range []slog.Level{slog.LevelDebug, LevelTrace} {
+		if h.Enabled(context.Background(), level) {
+			t.Errorf("expected level %s to be disabled", level)
+		}
+	}
+}
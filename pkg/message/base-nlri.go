@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/base"
 	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/prefixsid"
 )
 
 // nlri process base nlri information found and bgp update message and returns
@@ -34,24 +36,28 @@ func (p *producer) nlri(op int, ph *bmp.PerPeerHeader, update *bgp.Update) ([]Un
 	default:
 		return nil, fmt.Errorf("unknown operation %d", op)
 	}
+	psid := prefixSID(update)
 	prfxs := make([]UnicastPrefix, 0)
 	for _, pr := range routes {
 		prfx := UnicastPrefix{
 			Action:         operation,
+			IsWithdraw:     op == DelPrefix,
 			RouterHash:     p.speakerHash,
 			RouterIP:       p.speakerIP,
 			PeerHash:       ph.GetPeerHash(),
 			PeerASN:        ph.PeerAS,
+			PeerBGPID:      ph.GetPeerBGPIDString(),
 			Timestamp:      ph.GetPeerTimestamp(),
 			PeerType:       uint8(ph.PeerType),
+			PeerRD:         ph.GetPeerDistinguisherString(),
 			PrefixLen:      int32(pr.Length),
 			PathID:         int32(pr.PathID),
+			AFI:            1,
+			SAFI:           1,
 			BaseAttributes: update.BaseAttributes,
 		}
-		if ases := update.BaseAttributes.ASPath; len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = int32(ases[len(ases)-1])
-		}
+		// Last element in AS_PATH outside of confederation segments would be the AS of the origin
+		prfx.OriginAS = int32(update.BaseAttributes.OriginAS())
 		prfx.IsIPv4 = true
 		prfx.PeerIP = ph.GetPeerAddrString()
 		prfx.Nexthop = update.BaseAttributes.Nexthop
@@ -62,15 +68,36 @@ func (p *producer) nlri(op int, ph *bmp.PerPeerHeader, update *bgp.Update) ([]Un
 		if f, err := ph.IsAdjRIBInPost(); err == nil {
 			prfx.IsAdjRIBInPost = f
 		}
+		if f, err := ph.IsAdjRIBOut(); err == nil {
+			prfx.IsAdjRIBOut = f
+		}
 		if f, err := ph.IsAdjRIBOutPost(); err == nil {
 			prfx.IsAdjRIBOutPost = f
 		}
 		if f, err := ph.IsLocRIBFiltered(); err == nil {
 			prfx.IsLocRIBFiltered = f
 		}
+		prfx.IsLocRIB = ph.IsLocRIB()
+		prfx.TableName = p.tableName(ph)
+		prfx.PrefixSID = psid
 
 		prfxs = append(prfxs, prfx)
 	}
 
 	return prfxs, nil
 }
+
+// prefixSID returns Prefix SID attribute (40) carried by the update, Label Unicast and Unicast routes may carry
+// SR-MPLS Label Index or SRv6 Services TLVs in it. Malformed Prefix SID attribute is skipped.
+func prefixSID(update *bgp.Update) *prefixsid.PSid {
+	if !update.HasPrefixSID() {
+		return nil
+	}
+	psid, err := update.GetAttrPrefixSID()
+	if err != nil {
+		glog.Errorf("failed to unmarshal Prefix SID attribute with error: %+v", err)
+		return nil
+	}
+
+	return psid
+}
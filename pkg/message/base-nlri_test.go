@@ -0,0 +1,265 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/metrics"
+	"github.com/sbezverk/gobmp/pkg/srv6"
+)
+
+func TestProduceAdjRIBOutPostPolicy(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	prefix := &UnicastPrefix{}
+	// O and L flags are set
+	produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x50), Payload: routeMonitor(t)}, prefix)
+	if !prefix.IsAdjRIBOut || !prefix.IsAdjRIBOutPost || prefix.IsAdjRIBInPost || prefix.IsLocRIB {
+		t.Fatalf("expected Adj-RIB-Out post-policy prefix, got %+v", prefix)
+	}
+}
+
+func TestProduceAttributeFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		expect map[uint8]bgp.PathAttributeFlags
+	}{
+		{
+			name: "attribute flags are not attached by default",
+		},
+		{
+			name: "attribute flags are attached",
+			opts: []Option{WithAttributeFlags()},
+			expect: map[uint8]bgp.PathAttributeFlags{
+				1: {Flags: 0x40, Transitive: true},
+				2: {Flags: 0x40, Transitive: true},
+				3: {Flags: 0x40, Transitive: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, tt.opts...).(*producer)
+			prefix := &UnicastPrefix{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: routeMonitor(t)}, prefix)
+			if diff := deep.Equal(prefix.BaseAttributes.AttrFlags, tt.expect); diff != nil {
+				t.Fatalf("unexpected attribute flags: %+v", diff)
+			}
+		})
+	}
+}
+
+func TestProduceUnknownAttributes(t *testing.T) {
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x33, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x1A, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+		0xC0, 0xC7, 0x03, 0xDE, 0xAD, 0x01, // Made-up optional transitive attribute 199
+		0x08, 0x0A, // NLRI 10.0.0.0/8
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	tests := []struct {
+		name   string
+		opts   []Option
+		expect []bgp.UnknownAttribute
+	}{
+		{
+			name: "unknown attributes are counted",
+		},
+		{
+			name:   "unknown attributes are attached",
+			opts:   []Option{WithUnknownAttributes()},
+			expect: []bgp.UnknownAttribute{{Type: 199, Flags: 0xC0, Value: []byte{0xDE, 0xAD, 0x01}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			m, err := metrics.NewMetrics(reg)
+			if err != nil {
+				t.Fatalf("failed to instantiate metrics with error: %+v", err)
+			}
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, append(tt.opts, WithMetrics(m))...).(*producer)
+			prefix := &UnicastPrefix{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, prefix)
+			if v := metricValue(t, reg, "gobmp_unknown_path_attributes_total"); v != 1 {
+				t.Fatalf("expected 1 unknown path attribute, got %f", v)
+			}
+			if diff := deep.Equal(prefix.BaseAttributes.UnknownAttrs, tt.expect); diff != nil {
+				t.Fatalf("unexpected unknown attributes: %+v", diff)
+			}
+		})
+	}
+}
+
+func TestProduceAddPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		update    []byte
+		prefix    string
+		prefixLen int32
+		pathID    int32
+		isIPv4    bool
+	}{
+		{
+			name: "ipv4 unicast",
+			update: []byte{
+				0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				0x00, 0x31, 0x02,
+				0x00, 0x00, // Withdrawn Routes Length
+				0x00, 0x14, // Total Path Attribute Length
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0x00, 0x00, 0x00, 0x07, 0x08, 0x0A, // NLRI Path ID 7 10.0.0.0/8
+			},
+			prefix:    "10.0.0.0",
+			prefixLen: 8,
+			pathID:    7,
+			isIPv4:    true,
+		},
+		{
+			name: "ipv6 unicast",
+			update: []byte{
+				0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				0x00, 0x49, 0x02,
+				0x00, 0x00, // Withdrawn Routes Length
+				0x00, 0x32, // Total Path Attribute Length
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+				0x80, 0x0E, 0x22, // MP_REACH_NLRI
+				0x00, 0x02, 0x01, // AFI 2 SAFI 1
+				0x10, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Next Hop 2001:db8::1
+				0x00,                                                                         // Reserved
+				0x00, 0x00, 0x00, 0x03, 0x40, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x01, 0x00, 0x00, // NLRI Path ID 3 2001:db8:1::/64
+			},
+			prefix:    "2001:db8:1::",
+			prefixLen: 64,
+			pathID:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, WithAddPath(bgp.NLRIMessageType(1, 1), bgp.NLRIMessageType(2, 1))).(*producer)
+			rm, err := bmp.UnmarshalBMPRouteMonitorMessage(tt.update, 4)
+			if err != nil {
+				t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+			}
+			prefix := &UnicastPrefix{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, prefix)
+			if prefix.Prefix != tt.prefix || prefix.PrefixLen != tt.prefixLen || prefix.PathID != tt.pathID || prefix.IsIPv4 != tt.isIPv4 {
+				t.Fatalf("expected prefix %s/%d path id %d, got %s/%d path id %d", tt.prefix, tt.prefixLen, tt.pathID, prefix.Prefix, prefix.PrefixLen, prefix.PathID)
+			}
+		})
+	}
+}
+
+func TestProducePrefixSID(t *testing.T) {
+	tests := []struct {
+		name       string
+		update     []byte
+		labelIndex uint32
+		srv6SID    string
+	}{
+		{
+			name: "ipv4 unicast with label index",
+			update: []byte{
+				0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				0x00, 0x3a, 0x02,
+				0x00, 0x00, // Withdrawn Routes Length
+				0x00, 0x21, // Total Path Attribute Length
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+				0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+				0xC0, 0x28, 0x0A, 0x01, 0x00, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xA4, // Prefix SID Label Index 164
+				0x08, 0x0A, // NLRI 10.0.0.0/8
+			},
+			labelIndex: 164,
+		},
+		{
+			name: "ipv6 unicast with srv6 l3 service",
+			update: []byte{
+				0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				0x00, 0x6d, 0x02,
+				0x00, 0x00, // Withdrawn Routes Length
+				0x00, 0x56, // Total Path Attribute Length
+				0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+				0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+				0x80, 0x0E, 0x1E, // MP_REACH_NLRI
+				0x00, 0x02, 0x01, // AFI 2 SAFI 1
+				0x10, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Next Hop 2001:db8::1
+				0x00,                                                 // Reserved
+				0x40, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x01, 0x00, 0x00, // NLRI 2001:db8:1::/64
+				0xC0, 0x28, 0x25, // Prefix SID with SRv6 L3 Service TLV
+				0x05, 0x00, 0x22, 0x00, 0x01, 0x00, 0x1e, 0x00, 0x20, 0x01, 0x00, 0x00, 0x00, 0x05, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x11, 0x00, 0x01, 0x00, 0x06, 0x28, 0x18, 0x10, 0x00, 0x10, 0x40,
+			},
+			srv6SID: "2001:0:5:3::",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false).(*producer)
+			rm, err := bmp.UnmarshalBMPRouteMonitorMessage(tt.update, 4)
+			if err != nil {
+				t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+			}
+			prefix := &UnicastPrefix{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, prefix)
+			if prefix.PrefixSID == nil {
+				t.Fatalf("expected prefix sid in the published message")
+			}
+			if tt.labelIndex != 0 {
+				if prefix.PrefixSID.LabelIndex == nil || prefix.PrefixSID.LabelIndex.LabelIndex != tt.labelIndex {
+					t.Fatalf("expected label index %d, got %+v", tt.labelIndex, prefix.PrefixSID.LabelIndex)
+				}
+			}
+			if tt.srv6SID != "" {
+				if prefix.PrefixSID.SRv6L3Service == nil || len(prefix.PrefixSID.SRv6L3Service.SubTLVs[1]) != 1 {
+					t.Fatalf("expected srv6 l3 service with sid information sub tlv, got %+v", prefix.PrefixSID.SRv6L3Service)
+				}
+				info, ok := prefix.PrefixSID.SRv6L3Service.SubTLVs[1][0].(*srv6.InformationSubTLV)
+				if !ok {
+					t.Fatalf("expected sid information sub tlv, got %T", prefix.PrefixSID.SRv6L3Service.SubTLVs[1][0])
+				}
+				if info.SID != tt.srv6SID || info.EndpointBehavior != 17 {
+					t.Fatalf("expected sid %s behavior 17, got sid %s behavior %d", tt.srv6SID, info.SID, info.EndpointBehavior)
+				}
+			}
+		})
+	}
+}
+
+// addPathRouteMonitor returns Route Monitoring message advertising 10.0.0.0/8 with Path Identifier 16, NLRI
+// decoded without Add-Path is 0.0.0.0/0 three times and 8.10.0.0/16
+func addPathRouteMonitor(t *testing.T) *bmp.RouteMonitor {
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x31, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x14, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+		0x00, 0x00, 0x00, 0x10, 0x08, 0x0A, // NLRI Path ID 16 10.0.0.0/8
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+
+	return rm
+}
@@ -1,27 +1,25 @@
 package message
 
 import (
-	"encoding/binary"
 	"encoding/json"
 
-	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bmp"
 )
 
 // produceStatsMessage proceduces message from BMP Statistic Message
 func (p *producer) produceStatsMessage(msg bmp.Message) {
 	if msg.PeerHeader == nil {
-		glog.Errorf("perPeerHeader is missing, cannot construct Stats message")
+		p.logger.Error("perPeerHeader is missing, cannot construct Stats message")
 		return
 	}
 	StatsMsg, ok := msg.Payload.(*bmp.StatsReport)
 	if !ok {
-		glog.Errorf("got invalid Payload type in bmp.StatsReport %+v", msg.Payload)
+		p.logger.Error("got invalid Payload type in bmp.StatsReport", "payload", msg.Payload)
 		return
 	}
 	if len(StatsMsg.StatsTLV) == 0 {
 		b, _ := json.MarshalIndent(StatsMsg, "", "   ")
-		glog.Errorf("stats message does not contain any tlv(stat) %s", string(b))
+		p.logger.Error("stats message does not contain any tlv(stat)", "message", string(b))
 		return
 	}
 
@@ -35,34 +33,42 @@ func (p *producer) produceStatsMessage(msg bmp.Message) {
 	}
 	m.RemoteIP = msg.PeerHeader.GetPeerAddrString()
 	m.RemoteBGPID = msg.PeerHeader.GetPeerBGPIDString()
-	for _, tlv := range StatsMsg.StatsTLV {
-		switch tlv.InformationType {
-		case 1:
-			m.DuplicatePrefixs = binary.BigEndian.Uint32(tlv.Information)
-		case 2:
-			m.DuplicateWithDraws = binary.BigEndian.Uint32(tlv.Information)
-		case 3:
-			m.InvalidatedDueCluster = binary.BigEndian.Uint32(tlv.Information)
-		case 4:
-			m.InvalidatedDueAspath = binary.BigEndian.Uint32(tlv.Information)
-		case 5:
-			m.InvalidatedDueOriginatorId = binary.BigEndian.Uint32(tlv.Information)
-		case 6:
-			m.InvalidatedAsConfed = binary.BigEndian.Uint32(tlv.Information)
-		case 7:
-			m.AdjRIBsIn = binary.BigEndian.Uint64(tlv.Information)
-		case 8:
-			m.LocalRib = binary.BigEndian.Uint64(tlv.Information)
-		case 11:
-			m.UpdatesAsWithdraw = binary.BigEndian.Uint32(tlv.Information)
-		case 12:
-			m.PrefixesAsWithdraw = binary.BigEndian.Uint32(tlv.Information)
-		default:
-			glog.Warningf("unprocessed stats type:%v", tlv.InformationType)
-		}
-	}
-	if err := p.marshalAndPublish(&m, bmp.StatsReportMsg, []byte(m.RouterHash), false); err != nil {
-		glog.Errorf("failed to process peer Stats Report message with error: %+v", err)
+	m.DuplicatePrefixs = StatsMsg.DuplicatePrefixes
+	m.DuplicateWithDraws = StatsMsg.DuplicateWithdraws
+	m.InvalidatedDueCluster = StatsMsg.InvalidatedDueClusterList
+	m.InvalidatedDueAspath = StatsMsg.InvalidatedDueASPath
+	m.InvalidatedDueOriginatorId = StatsMsg.InvalidatedDueOriginatorID
+	m.InvalidatedAsConfed = StatsMsg.InvalidatedDueASConfed
+	m.AdjRIBsIn = StatsMsg.AdjRIBsIn
+	m.LocalRib = StatsMsg.LocRIB
+	m.UpdatesAsWithdraw = StatsMsg.UpdatesAsWithdraw
+	m.PrefixesAsWithdraw = StatsMsg.PrefixesAsWithdraw
+	m.PrefixesRejected = StatsMsg.PrefixesRejected
+	m.DuplicateUpdates = StatsMsg.DuplicateUpdates
+	m.AdjRIBsOutPrePolicy = StatsMsg.AdjRIBsOutPrePolicy
+	m.AdjRIBsOutPostPolicy = StatsMsg.AdjRIBsOutPostPolicy
+	m.PerAFISAFIAdjRIBIn = afiSAFIStats(StatsMsg.PerAFISAFIAdjRIBIn)
+	m.PerAFISAFILocRIB = afiSAFIStats(StatsMsg.PerAFISAFILocRIB)
+	m.PerAFISAFIAdjRIBOutPre = afiSAFIStats(StatsMsg.PerAFISAFIAdjRIBOutPre)
+	m.PerAFISAFIAdjRIBOutPost = afiSAFIStats(StatsMsg.PerAFISAFIAdjRIBOutPost)
+	if err := p.marshalAndPublish(&m, bmp.StatsReportMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process peer Stats Report message", "error", err)
 		return
 	}
 }
+
+func afiSAFIStats(gauges []bmp.AFISAFIGauge) []AFISAFIStat {
+	if len(gauges) == 0 {
+		return nil
+	}
+	stats := make([]AFISAFIStat, len(gauges))
+	for i, g := range gauges {
+		stats[i] = AFISAFIStat{
+			AFI:    g.AFI,
+			SAFI:   g.SAFI,
+			Routes: g.Value,
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,41 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// produceEndOfRIBMessage produces message from BGP End-of-RIB marker of AFI/SAFI received from the peer
+func (p *producer) produceEndOfRIBMessage(msg bmp.Message, afi uint16, safi uint8) {
+	ph := msg.PeerHeader
+	m := EndOfRIB{
+		RouterHash: p.speakerHash,
+		RouterIP:   p.speakerIP,
+		PeerHash:   ph.GetPeerHash(),
+		PeerIP:     ph.GetPeerAddrString(),
+		PeerBGPID:  ph.GetPeerBGPIDString(),
+		PeerType:   uint8(ph.PeerType),
+		PeerRD:     ph.GetPeerDistinguisherString(),
+		PeerASN:    ph.PeerAS,
+		Timestamp:  ph.GetPeerTimestamp(),
+		AFI:        afi,
+		SAFI:       safi,
+	}
+	if f, err := ph.IsAdjRIBInPost(); err == nil {
+		m.IsAdjRIBInPost = f
+	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		m.IsAdjRIBOut = f
+	}
+	if f, err := ph.IsAdjRIBOutPost(); err == nil {
+		m.IsAdjRIBOutPost = f
+	}
+	if f, err := ph.IsLocRIBFiltered(); err == nil {
+		m.IsLocRIBFiltered = f
+	}
+	m.IsLocRIB = ph.IsLocRIB()
+	m.TableName = p.tableName(ph)
+	if err := p.marshalAndPublish(&m, bmp.EndOfRIBMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process End-of-RIB message", "error", err)
+		return
+	}
+}
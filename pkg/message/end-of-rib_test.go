@@ -0,0 +1,83 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// endOfRIBRouteMonitor returns Route Monitoring message carrying BGP Update with path attributes attrs
+// and no withdrawn routes and NLRI
+func endOfRIBRouteMonitor(t *testing.T, attrs []byte) *bmp.RouteMonitor {
+	length := 19 + 2 + 2 + len(attrs)
+	update := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		byte(length >> 8), byte(length), 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		byte(len(attrs) >> 8), byte(len(attrs)), // Total Path Attribute Length
+	}
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage(append(update, attrs...), 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+
+	return rm
+}
+
+func TestProduceEndOfRIB(t *testing.T) {
+	tests := []struct {
+		name     string
+		rm       *bmp.RouteMonitor
+		endOfRIB bool
+		afi      uint16
+		safi     uint8
+	}{
+		{
+			name:     "ipv6 unicast",
+			rm:       endOfRIBRouteMonitor(t, mpAttribute(15, []byte{0x00, 0x02, 0x01})),
+			endOfRIB: true,
+			afi:      2,
+			safi:     1,
+		},
+		{
+			name:     "ipv4 unicast",
+			rm:       endOfRIBRouteMonitor(t, nil),
+			endOfRIB: true,
+			afi:      1,
+			safi:     1,
+		},
+		{
+			name: "ipv6 unicast not enabled",
+			rm:   endOfRIBRouteMonitor(t, mpAttribute(15, []byte{0x00, 0x02, 0x01})),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			opts := []Option{}
+			if tt.endOfRIB {
+				opts = append(opts, WithEndOfRIB())
+			}
+			p := NewProducer(publisher, false, opts...).(*producer)
+			msg := bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: tt.rm}
+			if !tt.endOfRIB {
+				p.producingWorker(msg)
+				if len(publisher.msgs) != 0 {
+					t.Fatalf("expected no published messages, got %d", len(publisher.msgs))
+				}
+				return
+			}
+			got := &EndOfRIB{}
+			published := produceOne(t, p, publisher, msg, got)
+			if published.msgType != bmp.EndOfRIBMsg {
+				t.Fatalf("expected message type %d, got %d", bmp.EndOfRIBMsg, published.msgType)
+			}
+			if got.AFI != tt.afi || got.SAFI != tt.safi {
+				t.Errorf("expected afi %d safi %d, got afi %d safi %d", tt.afi, tt.safi, got.AFI, got.SAFI)
+			}
+			if got.PeerIP != "10.0.0.2" || got.PeerASN != 65000 {
+				t.Errorf("expected peer 10.0.0.2 as 65000, got %s as %d", got.PeerIP, got.PeerASN)
+			}
+		})
+	}
+}
@@ -1,21 +1,23 @@
 package message
 
 import (
+	"context"
 	"fmt"
 	"net"
 
-	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/evpn"
+	"github.com/sbezverk/gobmp/pkg/logging"
 )
 
 // evpn process MP_REACH_NLRI AFI 25 SAFI 70 update message and returns
 // EVPN prefix object.
 func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *bgp.Update) ([]EVPNPrefix, error) {
-	if glog.V(6) {
-		glog.Infof("All attributes in evpn update: %+v", update.GetAllAttributeID())
+	if p.logger.Enabled(context.Background(), logging.LevelTrace) {
+		p.logger.Log(context.Background(), logging.LevelTrace, "all attributes in evpn update", "attributes", update.GetAllAttributeID())
 	}
-	evpn, err := nlri.GetNLRIEVPN()
+	routes, err := nlri.GetNLRIEVPN()
 	if err != nil {
 		return nil, err
 	}
@@ -30,22 +32,23 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 		return nil, fmt.Errorf("unknown operation %d", op)
 	}
 
-	for _, e := range evpn.Route {
+	for _, e := range routes.Route {
 		prfx := EVPNPrefix{
 			Action:         operation,
+			IsWithdraw:     op == DelPrefix,
 			PeerType:       uint8(ph.PeerType),
+			PeerRD:         ph.GetPeerDistinguisherString(),
 			RouterHash:     p.speakerHash,
 			RouterIP:       p.speakerIP,
 			PeerHash:       ph.GetPeerHash(),
 			PeerASN:        ph.PeerAS,
+			PeerBGPID:      ph.GetPeerBGPIDString(),
 			Timestamp:      ph.GetPeerTimestamp(),
 			Nexthop:        nlri.GetNextHop(),
 			BaseAttributes: update.BaseAttributes,
 		}
-		if ases := update.BaseAttributes.ASPath; len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = int32(ases[len(ases)-1])
-		}
+		// Last element in AS_PATH outside of confederation segments would be the AS of the origin
+		prfx.OriginAS = int32(update.BaseAttributes.OriginAS())
 
 		prfx.PeerIP = ph.GetPeerAddrString()
 		prfx.RemoteBGPID = ph.GetPeerBGPIDString()
@@ -58,16 +61,17 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 			prfx.RouteType = e.GetEVPNRouteType()
 			esi := e.GetEVPNESI()
 			if esi != nil {
-				// TODO Change 10 for a const for ESI length
-				for i := 0; i < 10; i++ {
-					prfx.ESI += fmt.Sprintf("%02d", esi[i])
-					// TODO same here ESI length -1
-					if i < 9 {
+				for i := 0; i < len(esi); i++ {
+					prfx.ESI += fmt.Sprintf("%02x", esi[i])
+					if i < len(esi)-1 {
 						prfx.ESI += ":"
 					}
 				}
 			}
 			prfx.EthTag = e.GetEVPNTAG()
+			if ad, ok := e.RouteTypeSpec.(*evpn.EthAutoDiscovery); ok {
+				prfx.IsPerES = ad.IsPerES()
+			}
 			if ip := e.GetEVPNIPLength(); ip != nil {
 				prfx.IPLength = *ip
 				gw := e.GetEVPNGWAddr()
@@ -109,12 +113,17 @@ func (p *producer) evpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *
 			if f, err := ph.IsAdjRIBInPost(); err == nil {
 				prfx.IsAdjRIBInPost = f
 			}
+			if f, err := ph.IsAdjRIBOut(); err == nil {
+				prfx.IsAdjRIBOut = f
+			}
 			if f, err := ph.IsAdjRIBOutPost(); err == nil {
 				prfx.IsAdjRIBOutPost = f
 			}
 			if f, err := ph.IsLocRIBFiltered(); err == nil {
 				prfx.IsLocRIBFiltered = f
 			}
+			prfx.IsLocRIB = ph.IsLocRIB()
+			prfx.TableName = p.tableName(ph)
 		}
 		prfxs = append(prfxs, prfx)
 	}
@@ -0,0 +1,147 @@
+package message
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// evpnRouteMonitor returns Route Monitoring message carrying MP_REACH_NLRI with EVPN route
+func evpnRouteMonitor(t *testing.T, route []byte) *bmp.RouteMonitor {
+	mpReach := []byte{
+		0x00, 0x19, 0x46, // AFI 25 SAFI 70
+		0x04, 0x0A, 0x00, 0x00, 0x01, // Next Hop 10.0.0.1
+		0x00, // Reserved
+	}
+	mpReach = append(mpReach, route...)
+	attrs := []byte{
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x80, 0x0E, byte(len(mpReach)), // MP_REACH_NLRI
+	}
+	attrs = append(attrs, mpReach...)
+	length := 19 + 2 + 2 + len(attrs)
+	update := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		byte(length >> 8), byte(length), 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		byte(len(attrs) >> 8), byte(len(attrs)), // Total Path Attribute Length
+	}
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage(append(update, attrs...), 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+
+	return rm
+}
+
+func TestProduceEVPNEthAutoDiscovery(t *testing.T) {
+	tests := []struct {
+		name   string
+		ethTag []byte
+		label  []byte
+		expect *EVPNPrefix
+	}{
+		{
+			name:   "per evi",
+			ethTag: []byte{0x00, 0x00, 0x00, 0x64},
+			label:  []byte{0x18, 0xa9, 0xb1},
+			expect: &EVPNPrefix{
+				VPNRD:     "200:50",
+				RouteType: 1,
+				ESI:       "00:11:11:11:11:11:11:11:11:a1",
+				EthTag:    []byte{0x00, 0x00, 0x00, 0x64},
+				Labels:    []uint32{101019},
+			},
+		},
+		{
+			name:   "per ethernet segment",
+			ethTag: []byte{0xff, 0xff, 0xff, 0xff},
+			label:  []byte{0x00, 0x00, 0x01},
+			expect: &EVPNPrefix{
+				VPNRD:     "200:50",
+				RouteType: 1,
+				ESI:       "00:11:11:11:11:11:11:11:11:a1",
+				EthTag:    []byte{0xff, 0xff, 0xff, 0xff},
+				IsPerES:   true,
+				Labels:    []uint32{0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := []byte{
+				0x01, 0x19, // Route Type 1, Length 25
+				0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, // RD 200:50
+				0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0xa1, // ESI
+			}
+			route = append(append(route, tt.ethTag...), tt.label...)
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false).(*producer)
+			rm := evpnRouteMonitor(t, route)
+			got := &EVPNPrefix{}
+			published := produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, got)
+			if published.msgType != bmp.EVPNMsg {
+				t.Fatalf("expected message type %d, got %d", bmp.EVPNMsg, published.msgType)
+			}
+			if got.VPNRD != tt.expect.VPNRD || got.RouteType != tt.expect.RouteType || got.ESI != tt.expect.ESI ||
+				got.IsPerES != tt.expect.IsPerES || !bytes.Equal(got.EthTag, tt.expect.EthTag) {
+				t.Errorf("expected evpn prefix %+v, got %+v", tt.expect, got)
+			}
+			if diff := deep.Equal(tt.expect.Labels, got.Labels); diff != nil {
+				t.Errorf("Diffs: %+v", diff)
+			}
+		})
+	}
+}
+
+func TestProduceEVPNEthernetSegment(t *testing.T) {
+	tests := []struct {
+		name     string
+		originIP []byte
+		expect   *EVPNPrefix
+	}{
+		{
+			name:     "ipv4 originating router",
+			originIP: []byte{0x20, 0xac, 0x1f, 0x65, 0x06},
+			expect: &EVPNPrefix{
+				VPNRD:     "172.31.101.6:0",
+				RouteType: 4,
+				ESI:       "00:11:11:11:11:11:11:11:11:11",
+				IPAddress: "172.31.101.6",
+				IPLength:  32,
+			},
+		},
+		{
+			name:     "ipv6 originating router",
+			originIP: []byte{0x80, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01},
+			expect: &EVPNPrefix{
+				VPNRD:     "172.31.101.6:0",
+				RouteType: 4,
+				ESI:       "00:11:11:11:11:11:11:11:11:11",
+				IPAddress: "2001:db8::1",
+				IPLength:  128,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := []byte{
+				0x04, byte(18 + len(tt.originIP)), // Route Type 4
+				0x00, 0x01, 0xac, 0x1f, 0x65, 0x06, 0x00, 0x00, // RD 172.31.101.6:0
+				0x00, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, // ESI
+			}
+			route = append(route, tt.originIP...)
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false).(*producer)
+			got := &EVPNPrefix{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: evpnRouteMonitor(t, route)}, got)
+			if got.VPNRD != tt.expect.VPNRD || got.RouteType != tt.expect.RouteType || got.ESI != tt.expect.ESI ||
+				got.IPAddress != tt.expect.IPAddress || got.IPLength != tt.expect.IPLength {
+				t.Errorf("expected evpn prefix %+v, got %+v", tt.expect, got)
+			}
+		})
+	}
+}
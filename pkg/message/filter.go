@@ -0,0 +1,126 @@
+package message
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// Filter defines a predicate evaluated for every BMP message before it is produced,
+// the message is dropped when the predicate returns false.
+type Filter func(bmp.Message) bool
+
+// WithFilter sets filters evaluated for every BMP message, the message is produced only when
+// all filters accept it.
+func WithFilter(filters ...Filter) Option {
+	return func(p *producer) {
+		p.filters = append(p.filters, filters...)
+	}
+}
+
+// WithMessageTypes limits produced messages to BMP message types, for example bmp.RouteMonitorMsg and
+// bmp.PeerUpMsg, messages of other types are dropped before they are produced and serialized. The check
+// is coarser and cheaper than filters and it is done first. The state of the session, like Add-Path capabilities
+// of peers, is learned from Peer Up and Initiation messages before they are dropped. Parse errors are not
+// affected, see WithParseErrors. By default messages of all types are produced.
+func WithMessageTypes(types ...byte) Option {
+	return func(p *producer) {
+		if p.messageTypes == nil {
+			p.messageTypes = make(map[byte]bool, len(types))
+		}
+		for _, t := range types {
+			p.messageTypes[t] = true
+		}
+	}
+}
+
+// messageType returns BMP message type of msg's Payload, false is returned for payloads
+// not carrying a BMP message, like bmp.ParseError.
+func messageType(msg bmp.Message) (byte, bool) {
+	switch msg.Payload.(type) {
+	case *bmp.RouteMonitor:
+		return bmp.RouteMonitorMsg, true
+	case *bmp.StatsReport:
+		return bmp.StatsReportMsg, true
+	case *bmp.PeerDownMessage:
+		return bmp.PeerDownMsg, true
+	case *bmp.PeerUpMessage:
+		return bmp.PeerUpMsg, true
+	case *bmp.InitiationMessage:
+		return bmp.InitiationMsg, true
+	case *bmp.TerminationMessage:
+		return bmp.TerminationMsg, true
+	case *bmp.RouteMirrorMessage:
+		return bmp.RouteMirrorMsg, true
+	}
+
+	return 0, false
+}
+
+// PeerAddressFilter returns Filter accepting messages of the peers with the addresses.
+// Messages without Per-Peer Header, like Initiation or Termination, are accepted.
+func PeerAddressFilter(addrs ...string) Filter {
+	peers := make(map[string]bool, len(addrs))
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil {
+			addr = ip.String()
+		}
+		peers[addr] = true
+	}
+	return func(msg bmp.Message) bool {
+		if msg.PeerHeader == nil {
+			return true
+		}
+		return peers[msg.PeerHeader.GetPeerAddrString()]
+	}
+}
+
+// PeerASFilter returns Filter accepting messages of the peers with the AS numbers.
+// Messages without Per-Peer Header, like Initiation or Termination, are accepted.
+func PeerASFilter(asns ...uint32) Filter {
+	peers := make(map[uint32]bool, len(asns))
+	for _, asn := range asns {
+		peers[asn] = true
+	}
+	return func(msg bmp.Message) bool {
+		if msg.PeerHeader == nil {
+			return true
+		}
+		return peers[msg.PeerHeader.PeerAS]
+	}
+}
+
+// AFISAFIFilter returns Filter accepting Route Monitoring messages carrying NLRI types, as returned
+// by bgp.NLRIMessageType. BGP Update without MP_REACH_NLRI or MP_UNREACH_NLRI attributes carries IPv4 unicast.
+// Messages other than Route Monitoring are accepted.
+func AFISAFIFilter(nlriTypes ...int) Filter {
+	types := make(map[int]bool, len(nlriTypes))
+	for _, t := range nlriTypes {
+		types[t] = true
+	}
+	return func(msg bmp.Message) bool {
+		rm, ok := msg.Payload.(*bmp.RouteMonitor)
+		if !ok || rm == nil || rm.Update == nil {
+			return true
+		}
+		return types[updateNLRIType(rm.Update)]
+	}
+}
+
+// updateNLRIType returns NLRI type of the update's AFI/SAFI
+func updateNLRIType(update *bgp.Update) int {
+	for _, attr := range update.PathAttributes {
+		if attr.AttributeType != bgp.MP_REACH_NLRI && attr.AttributeType != bgp.MP_UNREACH_NLRI {
+			continue
+		}
+		// Both MP_REACH_NLRI and MP_UNREACH_NLRI start with AFI 2 bytes and SAFI 1 byte
+		if len(attr.Attribute) < 3 {
+			return 0
+		}
+		return bgp.NLRIMessageType(binary.BigEndian.Uint16(attr.Attribute[:2]), attr.Attribute[2])
+	}
+
+	return bgp.NLRIMessageType(1, 1)
+}
@@ -0,0 +1,121 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProducerFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		filters  []Filter
+		expected int
+	}{
+		{
+			name:     "no filters",
+			expected: 1,
+		},
+		{
+			name:     "matching peer address",
+			filters:  []Filter{PeerAddressFilter("10.0.0.2")},
+			expected: 1,
+		},
+		{
+			name:    "not matching peer address",
+			filters: []Filter{PeerAddressFilter("10.0.0.3", "2001:db8::1")},
+		},
+		{
+			name:     "matching peer as",
+			filters:  []Filter{PeerASFilter(65000)},
+			expected: 1,
+		},
+		{
+			name:    "not matching peer as",
+			filters: []Filter{PeerASFilter(65001)},
+		},
+		{
+			name:     "matching afi safi",
+			filters:  []Filter{AFISAFIFilter(bgp.NLRIMessageType(1, 1))},
+			expected: 1,
+		},
+		{
+			name:    "not matching afi safi",
+			filters: []Filter{AFISAFIFilter(bgp.NLRIMessageType(2, 1))},
+		},
+		{
+			name:    "all filters must accept",
+			filters: []Filter{PeerASFilter(65000), AFISAFIFilter(bgp.NLRIMessageType(2, 1))},
+		},
+		{
+			name: "custom predicate",
+			filters: []Filter{func(msg bmp.Message) bool {
+				return msg.PeerHeader != nil && msg.PeerHeader.PeerType == bmp.PeerType3
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, WithFilter(tt.filters...)).(*producer)
+			p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: routeMonitor(t)})
+			if len(publisher.msgs) != tt.expected {
+				t.Fatalf("expected %d published messages, got %d", tt.expected, len(publisher.msgs))
+			}
+		})
+	}
+}
+
+func TestAFISAFIFilterMPReach(t *testing.T) {
+	filter := AFISAFIFilter(bgp.NLRIMessageType(2, 1))
+	update := &bgp.Update{
+		PathAttributes: []bgp.PathAttribute{
+			{AttributeType: bgp.MP_UNREACH_NLRI, Attribute: []byte{0x00, 0x02, 0x01, 0x40, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x01, 0x00, 0x00}},
+		},
+	}
+	if !filter(bmp.Message{Payload: &bmp.RouteMonitor{Update: update}}) {
+		t.Fatalf("expected IPv6 unicast withdraw to be accepted")
+	}
+	update.PathAttributes[0].Attribute = []byte{0x00, 0x01, 0x04}
+	if filter(bmp.Message{Payload: &bmp.RouteMonitor{Update: update}}) {
+		t.Fatalf("expected IPv4 labeled unicast withdraw to be dropped")
+	}
+	if !filter(bmp.Message{Payload: &bmp.TerminationMessage{}}) {
+		t.Fatalf("expected Termination message to be accepted")
+	}
+}
+
+func TestProduceMessageTypes(t *testing.T) {
+	// Stats Report of 5 prefixes rejected by inbound policy
+	sr, err := bmp.UnmarshalBMPStatsReportMessage([]byte{0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Stats Report message with error: %+v", err)
+	}
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithMessageTypes(bmp.RouteMonitorMsg)).(*producer)
+	p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: sr})
+	if len(publisher.msgs) != 0 {
+		t.Fatalf("expected stats report to be dropped, got %d published messages", len(publisher.msgs))
+	}
+	p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: routeMonitor(t)})
+	if len(publisher.msgs) != 1 || publisher.msgs[0].msgType != bmp.UnicastPrefixMsg {
+		t.Fatalf("expected 1 published message of type %d, got %+v", bmp.UnicastPrefixMsg, publisher.msgs)
+	}
+}
+
+func TestProduceMessageTypesAddPath(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithMessageTypes(bmp.RouteMonitorMsg)).(*producer)
+	ph := perPeerHeader(t, byte(bmp.PeerType0), 0x00)
+	// Peer Up is not published, Add-Path capability is learned from it
+	p.producingWorker(bmp.Message{PeerHeader: ph, Payload: addPathPeerUp(t)})
+	if len(publisher.msgs) != 0 {
+		t.Fatalf("expected peer up to be dropped, got %d published messages", len(publisher.msgs))
+	}
+	prefix := &UnicastPrefix{}
+	produceOne(t, p, publisher, bmp.Message{PeerHeader: ph, Payload: addPathRouteMonitor(t)}, prefix)
+	if prefix.Prefix != "10.0.0.0" || prefix.PrefixLen != 8 || prefix.PathID != 16 {
+		t.Fatalf("expected prefix 10.0.0.0/8 path id 16, got %s/%d path id %d", prefix.Prefix, prefix.PrefixLen, prefix.PathID)
+	}
+}
@@ -1,6 +1,7 @@
 package message
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -28,18 +29,18 @@ func (p *producer) flowspec(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, upda
 	}
 	fs := &Flowspec{
 		Action:         operation,
+		IsWithdraw:     op == DelPrefix,
 		RouterIP:       p.speakerIP,
 		PeerType:       uint8(ph.PeerType),
 		PeerASN:        ph.PeerAS,
+		PeerBGPID:      ph.GetPeerBGPIDString(),
 		Timestamp:      ph.GetPeerTimestamp(),
 		BaseAttributes: update.BaseAttributes,
 		SpecHash:       fsnlri.GetSpecHash(),
 	}
 
-	if ases := update.BaseAttributes.ASPath; len(ases) != 0 {
-		// Last element in AS_PATH would be the AS of the origin
-		fs.OriginAS = int32(ases[len(ases)-1])
-	}
+	// Last element in AS_PATH outside of confederation segments would be the AS of the origin
+	fs.OriginAS = int32(update.BaseAttributes.OriginAS())
 
 	fs.Nexthop = nlri.GetNextHop()
 	fs.Spec = fsnlri.Spec
@@ -49,12 +50,17 @@ func (p *producer) flowspec(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, upda
 	if f, err := ph.IsAdjRIBInPost(); err == nil {
 		fs.IsAdjRIBInPost = f
 	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		fs.IsAdjRIBOut = f
+	}
 	if f, err := ph.IsAdjRIBOutPost(); err == nil {
 		fs.IsAdjRIBOutPost = f
 	}
 	if f, err := ph.IsLocRIBFiltered(); err == nil {
 		fs.IsLocRIBFiltered = f
 	}
+	fs.IsLocRIB = ph.IsLocRIB()
+	fs.TableName = p.tableName(ph)
 
 	return []*Flowspec{fs}, nil
 }
@@ -69,12 +75,20 @@ func (fs *Flowspec) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(objmap["action"], &o.Action); err != nil {
 		return err
 	}
+	if v, ok := objmap["is_withdraw"]; ok {
+		if err := json.Unmarshal(v, &o.IsWithdraw); err != nil {
+			return err
+		}
+	}
 	// spec_has is mandatory because it serves as a key
 	if err := json.Unmarshal(objmap["spec_hash"], &o.SpecHash); err != nil {
 		return err
 	}
-	if err := json.Unmarshal(objmap["base_attrs"], &o.BaseAttributes); err != nil {
-		return err
+	// Fields marshaled with omitempty are optional
+	if v, ok := objmap["base_attrs"]; ok {
+		if err := json.Unmarshal(v, &o.BaseAttributes); err != nil {
+			return err
+		}
 	}
 	if err := json.Unmarshal(objmap["is_ipv4"], &o.IsIPv4); err != nil {
 		return err
@@ -82,17 +96,45 @@ func (fs *Flowspec) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(objmap["is_nexthop_ipv4"], &o.IsNexthopIPv4); err != nil {
 		return err
 	}
-	if err := json.Unmarshal(objmap["nexthop"], &o.Nexthop); err != nil {
-		return err
+	if v, ok := objmap["nexthop"]; ok {
+		if err := json.Unmarshal(v, &o.Nexthop); err != nil {
+			return err
+		}
 	}
-	if err := json.Unmarshal(objmap["peer_asn"], &o.PeerASN); err != nil {
-		return err
+	if v, ok := objmap["peer_asn"]; ok {
+		if err := json.Unmarshal(v, &o.PeerASN); err != nil {
+			return err
+		}
 	}
-	if err := json.Unmarshal(objmap["router_ip"], &o.RouterIP); err != nil {
-		return err
+	if v, ok := objmap["peer_bgp_id"]; ok {
+		if err := json.Unmarshal(v, &o.PeerBGPID); err != nil {
+			return err
+		}
 	}
-	if err := json.Unmarshal(objmap["timestamp"], &o.Timestamp); err != nil {
-		return err
+	if v, ok := objmap["router_ip"]; ok {
+		if err := json.Unmarshal(v, &o.RouterIP); err != nil {
+			return err
+		}
+	}
+	if v, ok := objmap["timestamp"]; ok {
+		if err := json.Unmarshal(v, &o.Timestamp); err != nil {
+			return err
+		}
+	}
+	if v, ok := objmap["collector_timestamp"]; ok {
+		if err := json.Unmarshal(v, &o.CollectorTimestamp); err != nil {
+			return err
+		}
+	}
+	if v, ok := objmap["message_id"]; ok {
+		if err := json.Unmarshal(v, &o.MessageID); err != nil {
+			return err
+		}
+	}
+	if v, ok := objmap["table_name"]; ok {
+		if err := json.Unmarshal(v, &o.TableName); err != nil {
+			return err
+		}
 	}
 	if s, ok := objmap["spec"]; ok {
 		var specs []map[string]interface{}
@@ -101,7 +143,11 @@ func (fs *Flowspec) UnmarshalJSON(b []byte) error {
 		}
 		o.Spec = make([]flowspec.Spec, 0)
 		for _, spec := range specs {
-			switch flowspec.SpecType(spec["type"].(float64)) {
+			t, ok := spec["type"].(float64)
+			if !ok {
+				return fmt.Errorf("invalid flowspec spec type %+v", spec["type"])
+			}
+			switch flowspec.SpecType(t) {
 			case flowspec.Type1:
 				fallthrough
 			case flowspec.Type2:
@@ -110,14 +156,15 @@ func (fs *Flowspec) UnmarshalJSON(b []byte) error {
 					return err
 				}
 				o.Spec = append(o.Spec, s)
-			case flowspec.Type3:
+			case flowspec.Type3, flowspec.Type4, flowspec.Type5, flowspec.Type6, flowspec.Type7, flowspec.Type8,
+				flowspec.Type9, flowspec.Type10, flowspec.Type11, flowspec.Type12, flowspec.Type13:
 				s, err := makeGenericSpec(spec)
 				if err != nil {
 					return err
 				}
 				o.Spec = append(o.Spec, s)
 			default:
-				glog.Errorf("Unknown type: %+v", spec["type"].(flowspec.SpecType))
+				glog.Errorf("Unknown type: %d", int(t))
 			}
 		}
 	}
@@ -134,9 +181,16 @@ func makePrefixSpec(spec map[string]interface{}) (flowspec.Spec, error) {
 	if p, ok := spec["prefix_len"]; ok {
 		s.PrefixLength = uint8(p.(float64))
 	}
-	if p, ok := spec["prefix"]; ok {
-		s.Prefix = make([]byte, len(p.(string)))
-		copy(s.Prefix, []byte(p.(string)))
+	if p, ok := spec["offset"]; ok {
+		s.Offset = uint8(p.(float64))
+	}
+	if p, ok := spec["prefix"].(string); ok {
+		// Slices of bytes are marshaled as base64 encoded strings
+		b, err := base64.StdEncoding.DecodeString(p)
+		if err != nil {
+			return nil, err
+		}
+		s.Prefix = b
 	}
 
 	return s, nil
@@ -159,9 +213,12 @@ func makeOpValPair(src []interface{}) ([]*flowspec.OpVal, error) {
 	ovp := make([]*flowspec.OpVal, len(src))
 	for i, s := range src {
 		o := &flowspec.OpVal{}
-		if p, ok := s.(map[string]interface{})["value"]; ok {
-			o.Val = make([]byte, len(p.(string)))
-			copy(o.Val, []byte(p.(string)))
+		if p, ok := s.(map[string]interface{})["value"].(string); ok {
+			b, err := base64.StdEncoding.DecodeString(p)
+			if err != nil {
+				return nil, err
+			}
+			o.Val = b
 		}
 		if p, ok := s.(map[string]interface{})["operator"]; ok {
 			op := &flowspec.Operator{}
@@ -183,6 +240,12 @@ func makeOpValPair(src []interface{}) ([]*flowspec.OpVal, error) {
 			if e, ok := p.(map[string]interface{})["equal"]; ok {
 				op.EQBit = e.(bool)
 			}
+			if e, ok := p.(map[string]interface{})["not"]; ok {
+				op.NOTBit = e.(bool)
+			}
+			if e, ok := p.(map[string]interface{})["match"]; ok {
+				op.MatchBit = e.(bool)
+			}
 			o.Op = op
 		}
 		ovp[i] = o
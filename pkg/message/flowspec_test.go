@@ -0,0 +1,66 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/flowspec"
+)
+
+func TestProduceFlowspec(t *testing.T) {
+	update := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x3A, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x23, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x00, // AS_PATH empty
+		0xC0, 0x10, 0x08, 0x80, 0x06, 0xFD, 0xE8, 0x47, 0x43, 0x50, 0x00, // EXTENDED_COMMUNITIES traffic-rate 400000 bps
+		0x80, 0x0E, 0x0E, // MP_REACH_NLRI
+		0x00, 0x01, 0x85, // AFI 1 SAFI 133
+		0x00, 0x00, // Next Hop Length 0 and Reserved
+		0x08, 0x01, 0x18, 0x0A, 0x00, 0x00, 0x05, 0x81, 0x50, // Destination 10.0.0.0/24 and Destination Port 80
+	}
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage(update, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false).(*producer)
+	got := &Flowspec{}
+	published := produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, got)
+	if published.msgType != bmp.FlowspecMsg {
+		t.Fatalf("expected message type %d, got %d", bmp.FlowspecMsg, published.msgType)
+	}
+	expect := []flowspec.Spec{
+		&flowspec.PrefixSpec{
+			SpecType:     1,
+			PrefixLength: 24,
+			Prefix:       []byte{0x0A, 0x00, 0x00},
+		},
+		&flowspec.GenericSpec{
+			SpecType: 5,
+			OpVal: []*flowspec.OpVal{
+				{
+					Op: &flowspec.Operator{
+						EOLBit: true,
+						Length: 1,
+						EQBit:  true,
+					},
+					Val: []byte{0x50},
+				},
+			},
+		},
+	}
+	if diff := deep.Equal(expect, got.Spec); diff != nil {
+		t.Errorf("Diffs: %+v", diff)
+	}
+	if got.SpecHash != "bcc00529da530dd7874a33d6f6c0fd36" {
+		t.Errorf("expected spec hash bcc00529da530dd7874a33d6f6c0fd36, got %s", got.SpecHash)
+	}
+	if got.BaseAttributes == nil || len(got.BaseAttributes.ExtCommunityList) != 1 ||
+		got.BaseAttributes.ExtCommunityList[0] != "flowspec-traffic-rate=AS: 65000 Rate: 400000 bps" {
+		t.Errorf("expected traffic rate extended community, got %+v", got.BaseAttributes)
+	}
+}
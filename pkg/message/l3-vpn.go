@@ -29,11 +29,14 @@ func (p *producer) l3vpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update
 	for _, e := range nlril3vpn.NLRI {
 		prfx := L3VPNPrefix{
 			Action:         operation,
+			IsWithdraw:     op == DelPrefix,
 			RouterHash:     p.speakerHash,
 			RouterIP:       p.speakerIP,
 			PeerType:       uint8(ph.PeerType),
+			PeerRD:         ph.GetPeerDistinguisherString(),
 			PeerHash:       ph.GetPeerHash(),
 			PeerASN:        ph.PeerAS,
+			PeerBGPID:      ph.GetPeerBGPIDString(),
 			Timestamp:      ph.GetPeerTimestamp(),
 			Nexthop:        nlri.GetNextHop(),
 			PrefixLen:      int32(e.Length),
@@ -41,10 +44,8 @@ func (p *producer) l3vpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update
 			BaseAttributes: update.BaseAttributes,
 		}
 
-		if ases := update.BaseAttributes.ASPath; len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = int32(ases[len(ases)-1])
-		}
+		// Last element in AS_PATH outside of confederation segments would be the AS of the origin
+		prfx.OriginAS = int32(update.BaseAttributes.OriginAS())
 		if nlri.IsIPv6NLRI() {
 			// IPv6 specific conversions
 			prfx.IsIPv4 = false
@@ -63,15 +64,21 @@ func (p *producer) l3vpn(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update
 		if f, err := ph.IsAdjRIBInPost(); err == nil {
 			prfx.IsAdjRIBInPost = f
 		}
+		if f, err := ph.IsAdjRIBOut(); err == nil {
+			prfx.IsAdjRIBOut = f
+		}
 		if f, err := ph.IsAdjRIBOutPost(); err == nil {
 			prfx.IsAdjRIBOutPost = f
 		}
 		if f, err := ph.IsLocRIBFiltered(); err == nil {
 			prfx.IsLocRIBFiltered = f
 		}
+		prfx.IsLocRIB = ph.IsLocRIB()
+		prfx.TableName = p.tableName(ph)
 		prfx.Labels = make([]uint32, 0)
 		for _, l := range e.Label {
 			prfx.Labels = append(prfx.Labels, l.Value)
+			prfx.LabelStack = append(prfx.LabelStack, MPLSLabel{Label: l.Value, TC: l.Exp, BoS: l.BoS})
 		}
 		prfx.VPNRD = e.RD.String()
 		prfx.VPNRDType = e.RD.Type
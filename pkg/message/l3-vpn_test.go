@@ -0,0 +1,40 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceL3VPNLabelStack(t *testing.T) {
+	// VPNv4 prefix 10.1.1.0/24 RD 65000:100 with label stack 100, 200
+	vpnv4 := []byte{
+		0x88,             // Length 136 bits
+		0x00, 0x06, 0x40, // Label 100
+		0x00, 0x0c, 0x81, // Label 200 with BoS
+		0x00, 0x00, 0xFD, 0xE8, 0x00, 0x00, 0x00, 0x64, // RD 65000:100
+		0x0A, 0x01, 0x01, // Prefix
+	}
+	vpnv4NextHop := []byte{
+		0x0C,                                           // Next Hop Length 12
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // RD
+		0x0A, 0x00, 0x00, 0x01,
+		0x00, // Reserved
+	}
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false).(*producer)
+	rm := mpRouteMonitor(t, mpAttribute(14, []byte{0x00, 0x01, 0x80}, vpnv4NextHop, vpnv4))
+	got := &L3VPNPrefix{}
+	produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, got)
+	if got.Prefix != "10.1.1.0" || got.PrefixLen != 24 || got.VPNRD != "65000:100" {
+		t.Errorf("expected vpnv4 prefix 65000:100:10.1.1.0/24, got %s:%s/%d", got.VPNRD, got.Prefix, got.PrefixLen)
+	}
+	expect := []MPLSLabel{{Label: 100}, {Label: 200, BoS: true}}
+	if diff := deep.Equal(got.LabelStack, expect); diff != nil {
+		t.Errorf("expected label stack %+v, got %+v", expect, got.LabelStack)
+	}
+	if diff := deep.Equal(got.Labels, []uint32{100, 200}); diff != nil {
+		t.Errorf("expected labels [100 200], got %v", got.Labels)
+	}
+}
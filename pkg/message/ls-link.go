@@ -22,23 +22,30 @@ func (p *producer) lsLink(link *base.LinkNLRI, nextHop string, op int, ph *bmp.P
 	}
 	msg := LSLink{
 		Action:     operation,
+		IsWithdraw: op == DelPrefix,
 		RouterHash: p.speakerHash,
 		RouterIP:   p.speakerIP,
 		PeerType:   uint8(ph.PeerType),
 		PeerHash:   ph.GetPeerHash(),
 		PeerASN:    ph.PeerAS,
+		PeerBGPID:  ph.GetPeerBGPIDString(),
 		Timestamp:  ph.GetPeerTimestamp(),
 		DomainID:   link.GetIdentifier(),
 	}
 	if f, err := ph.IsAdjRIBInPost(); err == nil {
 		msg.IsAdjRIBInPost = f
 	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		msg.IsAdjRIBOut = f
+	}
 	if f, err := ph.IsAdjRIBOutPost(); err == nil {
 		msg.IsAdjRIBOutPost = f
 	}
 	if f, err := ph.IsLocRIBFiltered(); err == nil {
 		msg.IsLocRIBFiltered = f
 	}
+	msg.IsLocRIB = ph.IsLocRIB()
+	msg.TableName = p.tableName(ph)
 	msg.Nexthop = nextHop
 	msg.PeerIP = ph.GetPeerAddrString()
 	msg.Protocol = link.GetLinkProtocolID()
@@ -105,6 +112,7 @@ func (p *producer) lsLink(link *base.LinkNLRI, nextHop string, op int, ph *bmp.P
 		msg.IGPMetric = lslink.GetIGPMetric()
 		msg.TEDefaultMetric = lslink.GetTEDefaultMetric()
 		msg.AdminGroup = lslink.GetAdminGroup()
+		msg.AdminGroups = lslink.GetAdminGroups()
 		msg.MaxLinkBW = lslink.GetMaxLinkBandwidth()
 		msg.MaxResvBW = lslink.GetMaxReservableLinkBandwidth()
 		msg.UnResvBW = lslink.GetUnreservedLinkBandwidth()
@@ -113,6 +121,7 @@ func (p *producer) lsLink(link *base.LinkNLRI, nextHop string, op int, ph *bmp.P
 		msg.UnResvBWKbps = lslink.GetUnreservedLinkBandwidthKbps()
 		msg.TEDefaultMetric = lslink.GetTEDefaultMetric()
 		msg.LinkProtection = lslink.GetLinkProtectionType()
+		msg.LinkProtectionType = lslink.GetLinkProtection()
 		msg.MPLSProtoMask = lslink.GetLinkMPLSProtocolMask()
 		msg.SRLG = lslink.GetSRLG()
 		msg.LinkName = lslink.GetLinkName()
@@ -124,12 +133,15 @@ func (p *producer) lsLink(link *base.LinkNLRI, nextHop string, op int, ph *bmp.P
 			msg.AppSpecLinkAttr = aslas
 		}
 		msg.UnidirAvailableBW = lslink.GetUnidirAvailableBandwidth()
+		msg.UnidirAvailableBWKbps = lslink.GetUnidirAvailableBandwidthKbps()
 		msg.UnidirBWUtilization = lslink.GetUnidirUtilizedBandwidth()
+		msg.UnidirUtilizedBWKbps = lslink.GetUnidirUtilizedBandwidthKbps()
 		msg.UnidirDelayVariation = lslink.GetUnidirDelayVariation()
-		msg.UnidirLinkDelay = lslink.GetUnidirLinkDelay()
-		msg.UnidirLinkDelayMinMax = lslink.GetUnidirLinkDelayMinMax()
-		msg.UnidirPacketLoss = lslink.GetUnidirLinkLoss()
+		msg.UnidirLinkDelay, msg.UnidirDelayAnomalous = lslink.GetUnidirLinkDelay()
+		msg.UnidirLinkDelayMinMax, msg.UnidirMinMaxAnomalous = lslink.GetUnidirLinkDelayMinMax()
+		msg.UnidirPacketLoss, msg.UnidirLossAnomalous = lslink.GetUnidirLinkLoss()
 		msg.UnidirResidualBW = lslink.GetUnidirResidualBandwidth()
+		msg.UnidirResidualBWKbps = lslink.GetUnidirResidualBandwidthKbps()
 		if adj, err := lslink.GetSRAdjacencySID(msg.ProtocolID); err == nil {
 			msg.LSAdjacencySID = adj
 		}
@@ -144,6 +156,7 @@ func (p *producer) lsLink(link *base.LinkNLRI, nextHop string, op int, ph *bmp.P
 				msg.PeerSetSID = sid
 			}
 		}
+		msg.Opaque = lslink.GetLinkOpaque()
 	}
 
 	return &msg, nil
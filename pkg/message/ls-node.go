@@ -20,23 +20,30 @@ func (p *producer) lsNode(node *base.NodeNLRI, nextHop string, op int, ph *bmp.P
 	}
 	msg := LSNode{
 		Action:     operation,
+		IsWithdraw: op == DelPrefix,
 		RouterHash: p.speakerHash,
 		RouterIP:   p.speakerIP,
 		PeerType:   uint8(ph.PeerType),
 		PeerHash:   ph.GetPeerHash(),
 		PeerASN:    ph.PeerAS,
+		PeerBGPID:  ph.GetPeerBGPIDString(),
 		Timestamp:  ph.GetPeerTimestamp(),
 		DomainID:   node.GetIdentifier(),
 	}
 	if f, err := ph.IsAdjRIBInPost(); err == nil {
 		msg.IsAdjRIBInPost = f
 	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		msg.IsAdjRIBOut = f
+	}
 	if f, err := ph.IsAdjRIBOutPost(); err == nil {
 		msg.IsAdjRIBOutPost = f
 	}
 	if f, err := ph.IsLocRIBFiltered(); err == nil {
 		msg.IsLocRIBFiltered = f
 	}
+	msg.IsLocRIB = ph.IsLocRIB()
+	msg.TableName = p.tableName(ph)
 	msg.PeerIP = ph.GetPeerAddrString()
 	msg.Protocol = node.GetNodeProtocolID()
 	msg.ProtocolID = node.ProtocolID
@@ -73,15 +80,18 @@ func (p *producer) lsNode(node *base.NodeNLRI, nextHop string, op int, ph *bmp.P
 		}
 		if cap, err := lsnode.GetNodeSRCapabilities(msg.ProtocolID); err == nil {
 			msg.SRCapabilities = cap
+			msg.SRGB = cap.SRGB()
 		}
 		msg.SRAlgorithm = lsnode.GetSRAlgorithm()
 		msg.SRLocalBlock = lsnode.GetNodeSRLocalBlock()
+		msg.SRLB = msg.SRLocalBlock.SRLB()
 		if cap, err := lsnode.GetNodeSRv6CapabilitiesTLV(); err == nil {
 			msg.SRv6CapabilitiesTLV = cap
 		}
 		if fad, err := lsnode.GetFlexAlgoDefinition(); err == nil {
 			msg.FlexAlgoDefinition = fad
 		}
+		msg.Opaque = lsnode.GetNodeOpaque()
 	}
 
 	return &msg, nil
@@ -0,0 +1,111 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/base"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bgpls"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/sr"
+)
+
+func TestProduceLSNodeSRCapabilities(t *testing.T) {
+	node, err := base.UnmarshalNodeNLRI([]byte{
+		0x02,                                           // Protocol ID IS-IS Level 2
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Identifier
+		0x01, 0x00, 0x00, 0x12, // Local Node Descriptors
+		0x02, 0x00, 0x00, 0x04, 0x00, 0x00, 0xfd, 0xe8, // ASN 65000
+		0x02, 0x03, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // IGP Router ID
+	})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Node NLRI with error: %+v", err)
+	}
+	tests := []struct {
+		name      string
+		attr      []byte
+		srgb      []sr.LabelRange
+		srlb      []sr.LabelRange
+		algorithm []int
+	}{
+		{
+			name: "single srgb range and two algorithms",
+			attr: []byte{
+				0x04, 0x0a, 0x00, 0x0c, 0x80, 0x00, // SR Capabilities, flags I
+				0x00, 0x1f, 0x40, 0x04, 0x89, 0x00, 0x03, 0x00, 0x3e, 0x80, // Range 8000 Label 16000
+				0x04, 0x0b, 0x00, 0x02, 0x00, 0x01, // SR Algorithm SPF and Strict SPF
+			},
+			srgb:      []sr.LabelRange{{BaseLabel: 16000, RangeSize: 8000}},
+			algorithm: []int{0, 1},
+		},
+		{
+			name: "srgb and srlb",
+			attr: []byte{
+				0x04, 0x0a, 0x00, 0x0c, 0x80, 0x00, // SR Capabilities, flags I
+				0x00, 0x1f, 0x40, 0x04, 0x89, 0x00, 0x03, 0x00, 0x3e, 0x80, // Range 8000 Label 16000
+				0x04, 0x0c, 0x00, 0x0c, 0x00, 0x00, // SR Local Block
+				0x00, 0x03, 0xe8, 0x04, 0x89, 0x00, 0x03, 0x00, 0x3a, 0x98, // Range 1000 Label 15000
+			},
+			srgb:      []sr.LabelRange{{BaseLabel: 16000, RangeSize: 8000}},
+			srlb:      []sr.LabelRange{{BaseLabel: 15000, RangeSize: 1000}},
+			algorithm: []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProducer(&testPublisher{}, false).(*producer)
+			update := &bgp.Update{
+				PathAttributes: []bgp.PathAttribute{{AttributeType: 29, Attribute: tt.attr}},
+			}
+			msg, err := p.lsNode(node, "", 0, perPeerHeader(t, byte(bmp.PeerType0), 0x00), update, false)
+			if err != nil {
+				t.Fatalf("failed to produce ls_node message with error: %+v", err)
+			}
+			if diff := deep.Equal(tt.srgb, msg.SRGB); diff != nil {
+				t.Errorf("srgb diffs: %+v", diff)
+			}
+			if diff := deep.Equal(tt.srlb, msg.SRLB); diff != nil {
+				t.Errorf("srlb diffs: %+v", diff)
+			}
+			if diff := deep.Equal(tt.algorithm, msg.SRAlgorithm); diff != nil {
+				t.Errorf("sr algorithm diffs: %+v", diff)
+			}
+		})
+	}
+}
+
+func TestProduceLSNodeOpaque(t *testing.T) {
+	node, err := base.UnmarshalNodeNLRI([]byte{
+		0x02,                                           // Protocol ID IS-IS Level 2
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Identifier
+		0x01, 0x00, 0x00, 0x12, // Local Node Descriptors
+		0x02, 0x00, 0x00, 0x04, 0x00, 0x00, 0xfd, 0xe8, // ASN 65000
+		0x02, 0x03, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // IGP Router ID
+	})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Node NLRI with error: %+v", err)
+	}
+	p := NewProducer(&testPublisher{}, false).(*producer)
+	update := &bgp.Update{
+		PathAttributes: []bgp.PathAttribute{{AttributeType: 29, Attribute: []byte{
+			0x04, 0x02, 0x00, 0x02, 'r', '1', // Node Name
+			0x04, 0x01, 0x00, 0x02, 0xde, 0xad, // Opaque Node Attribute
+			0x04, 0xb0, 0x00, 0x01, 0x01, // Unknown TLV 1200
+		}}},
+	}
+	msg, err := p.lsNode(node, "", 0, perPeerHeader(t, byte(bmp.PeerType0), 0x00), update, false)
+	if err != nil {
+		t.Fatalf("failed to produce ls_node message with error: %+v", err)
+	}
+	if msg.Name != "r1" {
+		t.Errorf("expected node name r1, got %q", msg.Name)
+	}
+	expect := []*bgpls.OpaqueTLV{
+		{Type: bgpls.OpaqueNodeAttrTLV, Value: []byte{0xde, 0xad}},
+		{Type: 1200, Value: []byte{0x01}},
+	}
+	if diff := deep.Equal(expect, msg.Opaque); diff != nil {
+		t.Errorf("opaque diffs: %+v", diff)
+	}
+}
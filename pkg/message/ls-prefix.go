@@ -21,23 +21,30 @@ func (p *producer) lsPrefix(prfx *base.PrefixNLRI, nextHop string, op int, ph *b
 	}
 	msg := LSPrefix{
 		Action:     operation,
+		IsWithdraw: op == DelPrefix,
 		RouterHash: p.speakerHash,
 		RouterIP:   p.speakerIP,
 		PeerType:   uint8(ph.PeerType),
 		PeerHash:   ph.GetPeerHash(),
 		PeerASN:    ph.PeerAS,
+		PeerBGPID:  ph.GetPeerBGPIDString(),
 		Timestamp:  ph.GetPeerTimestamp(),
 		DomainID:   prfx.GetIdentifier(),
 	}
 	if f, err := ph.IsAdjRIBInPost(); err == nil {
 		msg.IsAdjRIBInPost = f
 	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		msg.IsAdjRIBOut = f
+	}
 	if f, err := ph.IsAdjRIBOutPost(); err == nil {
 		msg.IsAdjRIBOutPost = f
 	}
 	if f, err := ph.IsLocRIBFiltered(); err == nil {
 		msg.IsLocRIBFiltered = f
 	}
+	msg.IsLocRIB = ph.IsLocRIB()
+	msg.TableName = p.tableName(ph)
 	msg.Nexthop = nextHop
 	msg.PeerIP = ph.GetPeerAddrString()
 	msg.ProtocolID = prfx.ProtocolID
@@ -93,6 +100,7 @@ func (p *producer) lsPrefix(prfx *base.PrefixNLRI, nextHop string, op int, ph *b
 		if loc, err := lsprefix.GetLSSRv6Locator(); err == nil {
 			msg.SRv6Locator = loc
 		}
+		msg.Opaque = lsprefix.GetPrefixOpaque()
 	}
 
 	return &msg, nil
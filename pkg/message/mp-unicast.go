@@ -10,7 +10,7 @@ import (
 )
 
 // unicast process nlri 14 afi 1/2 safi 1 messages and generates UnicastPrefix messages
-func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *bgp.Update, label bool) ([]UnicastPrefix, error) {
+func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, update *bgp.Update, safi uint8) ([]UnicastPrefix, error) {
 	var err error
 	var operation string
 	switch op {
@@ -24,43 +24,53 @@ func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, updat
 
 	prfxs := make([]UnicastPrefix, 0)
 	var u *base.MPNLRI
-	if label {
+	switch safi {
+	case 2:
+		u, err = nlri.GetNLRIMulticast()
+	case 4:
 		u, err = nlri.GetNLRILU()
-		if err != nil {
-			return nil, err
-		}
-	} else {
+	default:
 		u, err = nlri.GetNLRIUnicast()
-		if err != nil {
-			return nil, err
-		}
 	}
+	if err != nil {
+		return nil, err
+	}
+	psid := prefixSID(update)
+	afi, _ := nlri.GetAFISAFI()
 	for _, e := range u.NLRI {
 		prfx := UnicastPrefix{
 			Action:         operation,
+			IsWithdraw:     op == DelPrefix,
 			RouterHash:     p.speakerHash,
 			RouterIP:       p.speakerIP,
 			PeerType:       uint8(ph.PeerType),
+			PeerRD:         ph.GetPeerDistinguisherString(),
 			PeerHash:       ph.GetPeerHash(),
 			PeerASN:        ph.PeerAS,
+			PeerBGPID:      ph.GetPeerBGPIDString(),
 			Timestamp:      ph.GetPeerTimestamp(),
 			PrefixLen:      int32(e.Length),
 			PathID:         int32(e.PathID),
+			AFI:            afi,
+			SAFI:           safi,
 			BaseAttributes: update.BaseAttributes,
 		}
 		if f, err := ph.IsAdjRIBInPost(); err == nil {
 			prfx.IsAdjRIBInPost = f
 		}
+		if f, err := ph.IsAdjRIBOut(); err == nil {
+			prfx.IsAdjRIBOut = f
+		}
 		if f, err := ph.IsAdjRIBOutPost(); err == nil {
 			prfx.IsAdjRIBOutPost = f
 		}
 		if f, err := ph.IsLocRIBFiltered(); err == nil {
 			prfx.IsLocRIBFiltered = f
 		}
-		if ases := update.BaseAttributes.ASPath; len(ases) != 0 {
-			// Last element in AS_PATH would be the AS of the origin
-			prfx.OriginAS = int32(ases[len(ases)-1])
-		}
+		prfx.IsLocRIB = ph.IsLocRIB()
+		prfx.TableName = p.tableName(ph)
+		// Last element in AS_PATH outside of confederation segments would be the AS of the origin
+		prfx.OriginAS = int32(update.BaseAttributes.OriginAS())
 		prfx.PeerIP = ph.GetPeerAddrString()
 		prfx.Nexthop = nlri.GetNextHop()
 		if nlri.IsIPv6NLRI() {
@@ -78,15 +88,12 @@ func (p *producer) unicast(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, updat
 			copy(a, e.Prefix)
 			prfx.Prefix = net.IP(a).To4().String()
 		}
-		if label {
+		if safi == 4 {
 			for _, l := range e.Label {
 				prfx.Labels = append(prfx.Labels, l.Value)
 			}
-			// Some Label Unicast may carry BGP Attribute 40 (Prefix SID)
-			if psid, err := update.GetAttrPrefixSID(); err == nil {
-				prfx.PrefixSID = psid
-			}
 		}
+		prfx.PrefixSID = psid
 		prfxs = append(prfxs, prfx)
 	}
 
@@ -0,0 +1,91 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceMulticast(t *testing.T) {
+	ipv4Multicast, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x32, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x1B, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x80, 0x0E, 0x0B, // MP_REACH_NLRI
+		0x00, 0x01, 0x02, // AFI 1 SAFI 2
+		0x04, 0x0A, 0x00, 0x00, 0x01, // Next Hop 10.0.0.1
+		0x00,       // Reserved
+		0x08, 0x0A, // NLRI 10.0.0.0/8
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	ipv6Multicast, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x41, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x2A, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x80, 0x0E, 0x1A, // MP_REACH_NLRI
+		0x00, 0x02, 0x02, // AFI 2 SAFI 2
+		0x10, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Next Hop 2001:db8::1
+		0x00,                         // Reserved
+		0x20, 0x20, 0x01, 0x0D, 0xB8, // NLRI 2001:db8::/32
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	tests := []struct {
+		name    string
+		splitAF bool
+		expect  []int
+	}{
+		{
+			name:   "no splitting",
+			expect: []int{bmp.UnicastPrefixMsg, bmp.MulticastPrefixMsg, bmp.MulticastPrefixMsg},
+		},
+		{
+			name:    "all afi/safi split",
+			splitAF: true,
+			expect:  []int{bmp.UnicastPrefixV4Msg, bmp.MulticastPrefixV4Msg, bmp.MulticastPrefixV6Msg},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, tt.splitAF).(*producer)
+			for _, rm := range []*bmp.RouteMonitor{routeMonitor(t), ipv4Multicast, ipv6Multicast} {
+				p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm})
+			}
+			got := make([]int, 0, len(publisher.msgs))
+			for _, m := range publisher.msgs {
+				got = append(got, m.msgType)
+			}
+			if diff := deep.Equal(tt.expect, got); diff != nil {
+				t.Fatalf("Diffs: %+v", diff)
+			}
+			expect := []struct {
+				prefix  string
+				nexthop string
+				safi    uint8
+			}{
+				{prefix: "10.0.0.0", nexthop: "10.0.0.1", safi: 1},
+				{prefix: "10.0.0.0", nexthop: "10.0.0.1", safi: 2},
+				{prefix: "2001:db8::", nexthop: "2001:db8::1", safi: 2},
+			}
+			for i, e := range expect {
+				m := &UnicastPrefix{}
+				decodePublished(t, publisher.msgs[i], m)
+				if m.Prefix != e.prefix || m.Nexthop != e.nexthop || m.SAFI != e.safi {
+					t.Errorf("expected prefix %s next hop %s safi %d, got prefix %s next hop %s safi %d",
+						e.prefix, e.nexthop, e.safi, m.Prefix, m.Nexthop, m.SAFI)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// maxParseErrorMessageLength defines how many bytes of the failed BMP message are published
+const maxParseErrorMessageLength = 1024
+
+// WithParseErrors makes the producer publish BMP messages failed to be parsed as ParseError messages,
+// the message carries the error and up to 1024 bytes of the failed BMP message.
+func WithParseErrors() Option {
+	return func(p *producer) {
+		p.parseErrors = true
+	}
+}
+
+// produceParseErrorMessage produces message from BMP message failed to be parsed
+func (p *producer) produceParseErrorMessage(msg bmp.Message) {
+	pe, ok := msg.Payload.(*bmp.ParseError)
+	if !ok {
+		p.logger.Error("got invalid Payload type in bmp.ParseError", "payload", msg.Payload)
+		return
+	}
+	m := ParseError{
+		RouterHash:    p.speakerHash,
+		RouterIP:      p.speakerIP,
+		MessageLength: len(pe.Message),
+		Message:       pe.Message,
+	}
+	if pe.Err != nil {
+		m.Error = pe.Err.Error()
+	}
+	if len(pe.Message) >= bmp.CommonHeaderLength {
+		t := pe.Message[5]
+		m.BMPMessageType = &t
+	}
+	if len(m.Message) > maxParseErrorMessageLength {
+		m.Message = m.Message[:maxParseErrorMessageLength]
+		m.Truncated = true
+	}
+	if err := p.marshalAndPublish(&m, bmp.ParseErrorMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process parse error message", "error", err)
+		return
+	}
+}
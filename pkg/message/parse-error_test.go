@@ -0,0 +1,79 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceParseErrorMessage(t *testing.T) {
+	longMsg := make([]byte, 2000)
+	copy(longMsg, []byte{3, 0, 0, 7, 208, bmp.PeerUpMsg})
+	peerUp := uint8(bmp.PeerUpMsg)
+	tests := []struct {
+		name        string
+		parseErrors bool
+		msg         []byte
+		expect      *ParseError
+	}{
+		{
+			name: "disabled",
+			msg:  []byte{3, 0, 0, 0, 6, 0},
+		},
+		{
+			name:        "invalid header",
+			parseErrors: true,
+			msg:         []byte{3, 0},
+			expect: &ParseError{
+				Error:         "invalid",
+				MessageLength: 2,
+				Message:       []byte{3, 0},
+			},
+		},
+		{
+			name:        "truncated message",
+			parseErrors: true,
+			msg:         longMsg,
+			expect: &ParseError{
+				BMPMessageType: &peerUp,
+				Error:          "invalid",
+				MessageLength:  2000,
+				Message:        longMsg[:maxParseErrorMessageLength],
+				Truncated:      true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			opts := []Option{WithRouterAddress("192.168.80.103")}
+			if tt.parseErrors {
+				opts = append(opts, WithParseErrors())
+			}
+			p := NewProducer(publisher, false, opts...).(*producer)
+			msg := bmp.Message{Payload: &bmp.ParseError{Message: tt.msg, Err: fmt.Errorf("invalid")}}
+			if tt.expect == nil {
+				p.producingWorker(msg)
+				if len(publisher.msgs) != 0 {
+					t.Fatalf("expected no published message, got %d", len(publisher.msgs))
+				}
+				return
+			}
+			tt.expect.RouterHash = p.speakerHash
+			tt.expect.RouterIP = "192.168.80.103"
+			got := &ParseError{}
+			published := produceOne(t, p, publisher, msg, got)
+			if published.msgType != bmp.ParseErrorMsg {
+				t.Fatalf("expected message type %d, got %d", bmp.ParseErrorMsg, published.msgType)
+			}
+			if string(published.key) != p.speakerHash {
+				t.Errorf("expected message key %s, got %s", p.speakerHash, string(published.key))
+			}
+			if diff := deep.Equal(tt.expect, got); diff != nil {
+				t.Errorf("Diffs: %+v", diff)
+			}
+		})
+	}
+}
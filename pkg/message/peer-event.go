@@ -0,0 +1,56 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// producePeerEvent produces peer event message from PeerStateChange message m produced from Peer Up or Peer Down
+// message of the peer
+func (p *producer) producePeerEvent(op int, m *PeerStateChange, msg bmp.Message) {
+	ph := msg.PeerHeader
+	e := PeerEvent{
+		Action:          "up",
+		RouterHash:      m.RouterHash,
+		RouterIP:        m.RouterIP,
+		PeerHash:        ph.GetPeerHash(),
+		PeerIP:          m.RemoteIP,
+		PeerBGPID:       m.RemoteBGPID,
+		PeerType:        m.PeerType,
+		PeerRD:          m.PeerRD,
+		PeerASN:         m.RemoteASN,
+		PeerPort:        m.RemotePort,
+		IsIPv4:          m.IsIPv4,
+		LocalIP:         m.LocalIP,
+		LocalASN:        m.LocalASN,
+		LocalBGPID:      m.LocalBGPID,
+		LocalPort:       m.LocalPort,
+		Timestamp:       m.Timestamp,
+		IsLocRIB:        m.IsLocRIB,
+		TableName:       m.TableName,
+		BMPReason:       m.BMPReason,
+		BMPReasonString: m.BMPReasonString,
+		BMPErrorCode:    m.BMPErrorCode,
+		BMPErrorSubCode: m.BMPErrorSubCode,
+		ErrorText:       m.ErrorText,
+		FSMEvent:        m.FSMEvent,
+	}
+	if op == peerDown {
+		e.Action = "down"
+	}
+	if f, err := ph.IsAdjRIBInPost(); err == nil {
+		e.IsAdjRIBInPost = f
+	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		e.IsAdjRIBOut = f
+	}
+	if f, err := ph.IsAdjRIBOutPost(); err == nil {
+		e.IsAdjRIBOutPost = f
+	}
+	if f, err := ph.IsLocRIBFiltered(); err == nil {
+		e.IsLocRIBFiltered = f
+	}
+	if err := p.marshalAndPublish(&e, bmp.PeerEventMsg, []byte(e.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process peer event message", "error", err)
+		return
+	}
+}
@@ -0,0 +1,73 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProducePeerEvents(t *testing.T) {
+	pd, err := bmp.UnmarshalPeerDownMessage([]byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x06, 0x04})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Peer Down message with error: %+v", err)
+	}
+	tests := []struct {
+		name    string
+		payload interface{}
+		expect  PeerEvent
+	}{
+		{
+			name:    "peer up",
+			payload: locRIBPeerUp(t, ""),
+			expect:  PeerEvent{Action: "up"},
+		},
+		{
+			name:    "peer down",
+			payload: pd,
+			expect: PeerEvent{
+				Action:          "down",
+				BMPReason:       1,
+				BMPReasonString: "Local system closed session with notification",
+				BMPErrorCode:    6,
+				BMPErrorSubCode: 4,
+				ErrorText:       "Cease: Administrative Reset",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, WithPeerEvents()).(*producer)
+			p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: tt.payload})
+			if len(publisher.msgs) != 2 {
+				t.Fatalf("expected 2 published messages, got %d", len(publisher.msgs))
+			}
+			if publisher.msgs[0].msgType != bmp.PeerStateChangeMsg {
+				t.Fatalf("expected message type %d, got %d", bmp.PeerStateChangeMsg, publisher.msgs[0].msgType)
+			}
+			if publisher.msgs[1].msgType != bmp.PeerEventMsg {
+				t.Fatalf("expected message type %d, got %d", bmp.PeerEventMsg, publisher.msgs[1].msgType)
+			}
+			got := &PeerEvent{}
+			decodePublished(t, publisher.msgs[1], got)
+			if got.Action != tt.expect.Action {
+				t.Fatalf("expected action %s, got %s", tt.expect.Action, got.Action)
+			}
+			if got.BMPReason != tt.expect.BMPReason || got.BMPReasonString != tt.expect.BMPReasonString {
+				t.Errorf("expected reason %d %q, got %d %q", tt.expect.BMPReason, tt.expect.BMPReasonString, got.BMPReason, got.BMPReasonString)
+			}
+			if got.BMPErrorCode != tt.expect.BMPErrorCode || got.BMPErrorSubCode != tt.expect.BMPErrorSubCode || got.ErrorText != tt.expect.ErrorText {
+				t.Errorf("expected notification %d/%d %q, got %d/%d %q", tt.expect.BMPErrorCode, tt.expect.BMPErrorSubCode, tt.expect.ErrorText,
+					got.BMPErrorCode, got.BMPErrorSubCode, got.ErrorText)
+			}
+			if got.PeerIP != "10.0.0.2" || got.PeerASN != 65000 || got.PeerBGPID != "10.0.0.2" {
+				t.Errorf("expected peer 10.0.0.2 as 65000 bgp id 10.0.0.2, got %s as %d bgp id %s", got.PeerIP, got.PeerASN, got.PeerBGPID)
+			}
+			peer := &PeerStateChange{}
+			decodePublished(t, publisher.msgs[0], peer)
+			if got.RouterIP != peer.RouterIP || got.RouterHash != peer.RouterHash {
+				t.Errorf("expected router %s hash %s, got %s hash %s", peer.RouterIP, peer.RouterHash, got.RouterIP, got.RouterHash)
+			}
+		})
+	}
+}
@@ -1,17 +1,17 @@
 package message
 
 import (
-	"crypto/md5"
-	"fmt"
+	"context"
 	"net"
 
-	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/logging"
 )
 
 func (p *producer) producePeerMessage(op int, msg bmp.Message) {
 	if msg.PeerHeader == nil {
-		glog.Errorf("perPeerHeader is missing, cannot construct PeerStateChange message")
+		p.logger.Error("perPeerHeader is missing, cannot construct PeerStateChange message")
 		return
 	}
 	action := "add"
@@ -23,7 +23,7 @@ func (p *producer) producePeerMessage(op int, msg bmp.Message) {
 	if op == peerUP {
 		peerUpMsg, ok := msg.Payload.(*bmp.PeerUpMessage)
 		if !ok {
-			glog.Errorf("got invalid Payload type in bmp.Message %+v", msg.Payload)
+			p.logger.Error("got invalid Payload type in bmp.Message", "payload", msg.Payload)
 			return
 		}
 		m = PeerStateChange{
@@ -40,20 +40,26 @@ func (p *producer) producePeerMessage(op int, msg bmp.Message) {
 		if f, err := msg.PeerHeader.IsAdjRIBInPost(); err == nil {
 			m.IsAdjRIBInPost = f
 		}
+		if f, err := msg.PeerHeader.IsAdjRIBOut(); err == nil {
+			m.IsAdjRIBOut = f
+		}
 		if f, err := msg.PeerHeader.IsAdjRIBOutPost(); err == nil {
 			m.IsAdjRIBOutPost = f
 		}
 		if f, err := msg.PeerHeader.IsLocRIBFiltered(); err == nil {
 			m.IsLocRIBFiltered = f
 		}
+		m.IsLocRIB = msg.PeerHeader.IsLocRIB()
+		m.TableName = peerUpMsg.GetTableName()
+		if m.IsLocRIB {
+			p.setTableName(msg.PeerHeader, m.TableName)
+		}
 		m.RemoteIP = msg.PeerHeader.GetPeerAddrString()
 		m.RemoteBGPID = msg.PeerHeader.GetPeerBGPIDString()
 		m.LocalBGPID = net.IP(peerUpMsg.SentOpen.BGPID).To4().String()
 		m.IsIPv4 = !msg.PeerHeader.IsRemotePeerIPv6()
 		m.LocalIP = peerUpMsg.GetLocalAddressString()
-		// Saving local bgp speaker identities.
-		p.speakerIP = m.LocalIP
-		p.speakerHash = fmt.Sprintf("%x", md5.Sum([]byte(p.speakerIP)))
+		// Local bgp speaker identities are learned from Peer Up message before it is produced, see learnSession
 		m.RouterIP = p.speakerIP
 		m.RouterHash = p.speakerHash
 
@@ -62,30 +68,17 @@ func (p *producer) producePeerMessage(op int, msg bmp.Message) {
 			// Local BGP speaker is 4 bytes AS capable
 			m.LocalASN = lasn
 		}
-		// Check if local router advertises AddPath Send/Receive for any AFI/SAFI,
-		// if map comes back empty no further AddPath Capability is needed
-		if lAddPath := peerUpMsg.SentOpen.AddPathCapability(); len(lAddPath) != 0 {
-			// Check if remote router advertises AddPath Send/Receive for any AFI/SAFI,
-			// if map comes back empty no further AddPath Capability is needed
-			if rAddPath := peerUpMsg.ReceivedOpen.AddPathCapability(); len(rAddPath) != 0 {
-				for k := range lAddPath {
-					// Enable AddPath only for AFI/SAFI types existing in both local and remote maps
-					if _, ok := rAddPath[k]; ok {
-						// AFI/SAFI type exists in both maps, which means both peers support Send/Receive of AddPath
-						p.addPathCapable[k] = true
-					}
-				}
-			}
-		}
 		m.AdvCapabilities = peerUpMsg.SentOpen.GetCapabilities()
 		m.RcvCapabilities = peerUpMsg.ReceivedOpen.GetCapabilities()
-		if glog.V(6) {
-			glog.Infof("producer for speaker ip: %s add path: %+v", p.speakerIP, p.addPathCapable)
+		m.AdvOpenCapabilities = bgp.DecodeCapabilities(m.AdvCapabilities)
+		m.RcvOpenCapabilities = bgp.DecodeCapabilities(m.RcvCapabilities)
+		if p.logger.Enabled(context.Background(), logging.LevelTrace) {
+			p.logger.Log(context.Background(), logging.LevelTrace, "producer for speaker", "speaker_ip", p.speakerIP, "add_path", p.addPathCapable)
 		}
 	} else {
 		peerDownMsg, ok := msg.Payload.(*bmp.PeerDownMessage)
 		if !ok {
-			glog.Errorf("got invalid Payload type in bmp.Message")
+			p.logger.Error("got invalid Payload type in bmp.Message")
 			return
 		}
 		m = PeerStateChange{
@@ -101,12 +94,52 @@ func (p *producer) producePeerMessage(op int, msg bmp.Message) {
 		m.RemoteIP = msg.PeerHeader.GetPeerAddrString()
 		m.RemoteBGPID = msg.PeerHeader.GetPeerBGPIDString()
 		m.IsIPv4 = !msg.PeerHeader.IsRemotePeerIPv6()
+		m.IsLocRIB = msg.PeerHeader.IsLocRIB()
+		if m.IsLocRIB {
+			m.TableName = p.tableName(msg.PeerHeader)
+			p.setTableName(msg.PeerHeader, "")
+		}
 		m.InfoData = make([]byte, len(peerDownMsg.Data))
 		copy(m.InfoData, peerDownMsg.Data)
+		m.BMPReasonString = peerDownMsg.ReasonString()
+		if n := peerDownMsg.Notification; n != nil {
+			m.BMPErrorCode = int(n.Code)
+			m.BMPErrorSubCode = int(n.Subcode)
+			m.ErrorText = n.Error
+		}
+		m.FSMEvent = peerDownMsg.FSMEvent
 
 	}
-	if err := p.marshalAndPublish(&m, bmp.PeerStateChangeMsg, []byte(m.RouterHash), false); err != nil {
-		glog.Errorf("failed to process peer message with error: %+v", err)
+	if err := p.marshalAndPublish(&m, bmp.PeerStateChangeMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process peer message", "error", err)
+		return
+	}
+	if p.peerEvents {
+		p.producePeerEvent(op, &m, msg)
+	}
+}
+
+// setTableName stores VRF/Table Name of Loc-RIB peer of the Per-Peer Header, the name is carried
+// in messages of the peer until it goes down, empty name removes the stored one. Loc-RIB instances
+// are distinguished by Peer Distinguisher per RFC9069.
+func (p *producer) setTableName(ph *bmp.PerPeerHeader, name string) {
+	p.tableNamesMu.Lock()
+	defer p.tableNamesMu.Unlock()
+	if name == "" {
+		delete(p.tableNames, ph.GetPeerDistinguisherString())
 		return
 	}
+	p.tableNames[ph.GetPeerDistinguisherString()] = name
+}
+
+// tableName returns VRF/Table Name of Loc-RIB peer of the Per-Peer Header learned from its Peer Up
+// message, empty string is returned for other peers and when the name is not known.
+func (p *producer) tableName(ph *bmp.PerPeerHeader) string {
+	if !ph.IsLocRIB() {
+		return ""
+	}
+	p.tableNamesMu.RLock()
+	defer p.tableNamesMu.RUnlock()
+
+	return p.tableNames[ph.GetPeerDistinguisherString()]
 }
@@ -0,0 +1,286 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceLocRIBMessages(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	routerHash := p.speakerHash
+	// Loc-RIB Peer Up carries zero-filled local address and ports and VRF/Table Name TLV
+	pu, err := bmp.UnmarshalPeerUpMessage([]byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+		0x00, 0x03, 0x00, 0x06, 'g', 'l', 'o', 'b', 'a', 'l',
+	}, false)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Peer Up message with error: %+v", err)
+	}
+	p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType3), 0x80), Payload: pu})
+	p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType3), 0x80), Payload: routeMonitor(t)})
+	if len(publisher.msgs) != 2 {
+		t.Fatalf("expected 2 published messages, got %d", len(publisher.msgs))
+	}
+	peer := &PeerStateChange{}
+	decodePublished(t, publisher.msgs[0], peer)
+	if !peer.IsLocRIB || !peer.IsLocRIBFiltered || peer.PeerType != uint8(bmp.PeerType3) {
+		t.Fatalf("expected Loc-RIB filtered peer, got peer type %d is_loc_rib %t is_loc_rib_filtered %t", peer.PeerType, peer.IsLocRIB, peer.IsLocRIBFiltered)
+	}
+	if peer.TableName != "global" {
+		t.Fatalf("expected table name global, got %q", peer.TableName)
+	}
+	if peer.RouterHash != routerHash {
+		t.Fatalf("Loc-RIB Peer Up is not expected to change router hash %s, got %s", routerHash, peer.RouterHash)
+	}
+	prefix := &UnicastPrefix{}
+	decodePublished(t, publisher.msgs[1], prefix)
+	if !prefix.IsLocRIB || !prefix.IsLocRIBFiltered || prefix.IsAdjRIBInPost || prefix.IsAdjRIBOutPost {
+		t.Fatalf("expected Loc-RIB filtered prefix, got %+v", prefix)
+	}
+	if prefix.Prefix != "10.0.0.0" || prefix.PrefixLen != 8 || prefix.PeerIP != "0.0.0.0" {
+		t.Fatalf("unexpected prefix %s/%d from peer %s", prefix.Prefix, prefix.PrefixLen, prefix.PeerIP)
+	}
+	if prefix.TableName != "global" {
+		t.Fatalf("expected prefix table name global, got %q", prefix.TableName)
+	}
+}
+
+// locRIBPeerUp returns Loc-RIB Peer Up message carrying VRF/Table Name TLV with the name
+func locRIBPeerUp(t *testing.T, name string) *bmp.PeerUpMessage {
+	b := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+		0x00, 0x03, 0x00, byte(len(name)),
+	}
+	pu, err := bmp.UnmarshalPeerUpMessage(append(b, name...), false)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Peer Up message with error: %+v", err)
+	}
+
+	return pu
+}
+
+func TestProduceLocRIBTableName(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	// vrf returns Per-Peer Header of Loc-RIB instance with Peer Distinguisher 65000:rd
+	vrf := func(rd byte) *bmp.PerPeerHeader {
+		ph := perPeerHeader(t, byte(bmp.PeerType3), 0x00)
+		ph.PeerDistinguisher = []byte{0x00, 0x00, 0xFD, 0xE8, 0x00, 0x00, 0x00, rd}
+		return ph
+	}
+	p.producingWorker(bmp.Message{PeerHeader: vrf(1), Payload: locRIBPeerUp(t, "red")})
+	p.producingWorker(bmp.Message{PeerHeader: vrf(2), Payload: locRIBPeerUp(t, "blue")})
+	publisher.msgs = nil
+	tests := []struct {
+		name   string
+		ph     *bmp.PerPeerHeader
+		expect string
+	}{
+		{
+			name:   "first vrf",
+			ph:     vrf(1),
+			expect: "red",
+		},
+		{
+			name:   "second vrf",
+			ph:     vrf(2),
+			expect: "blue",
+		},
+		{
+			name: "unknown vrf",
+			ph:   vrf(3),
+		},
+		{
+			name: "adj-rib-in peer",
+			ph:   perPeerHeader(t, byte(bmp.PeerType0), 0x00),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher.msgs = nil
+			prefix := &UnicastPrefix{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: tt.ph, Payload: routeMonitor(t)}, prefix)
+			if prefix.TableName != tt.expect {
+				t.Errorf("expected table name %q, got %q", tt.expect, prefix.TableName)
+			}
+		})
+	}
+	// Peer Down carries the name, subsequent messages of the instance do not
+	publisher.msgs = nil
+	p.producingWorker(bmp.Message{PeerHeader: vrf(1), Payload: &bmp.PeerDownMessage{Reason: bmp.PeerDownDeconfigured, Data: []byte{}}})
+	p.producingWorker(bmp.Message{PeerHeader: vrf(1), Payload: routeMonitor(t)})
+	if len(publisher.msgs) != 2 {
+		t.Fatalf("expected 2 published messages, got %d", len(publisher.msgs))
+	}
+	peer := &PeerStateChange{}
+	decodePublished(t, publisher.msgs[0], peer)
+	if peer.TableName != "red" {
+		t.Errorf("expected Peer Down table name red, got %q", peer.TableName)
+	}
+	prefix := &UnicastPrefix{}
+	decodePublished(t, publisher.msgs[1], prefix)
+	if prefix.TableName != "" {
+		t.Errorf("expected no table name after Peer Down, got %q", prefix.TableName)
+	}
+}
+
+func TestProducePeerUpLocalAddress(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	// Peer Up of IPv6 session from local address 2001:db8::2 port 179 to remote port 50000
+	pu, err := bmp.UnmarshalPeerUpMessage([]byte{
+		0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+		0x00, 0xB3, 0xC3, 0x50,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x01, 0x00,
+	}, true)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Peer Up message with error: %+v", err)
+	}
+	peer := &PeerStateChange{}
+	// V flag is set in Per-Peer Header
+	produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x80), Payload: pu}, peer)
+	if peer.LocalIP != "2001:db8::2" || peer.LocalPort != 179 || peer.RemotePort != 50000 {
+		t.Fatalf("expected local address [2001:db8::2]:179 and remote port 50000, got [%s]:%d and %d", peer.LocalIP, peer.LocalPort, peer.RemotePort)
+	}
+	if peer.IsIPv4 {
+		t.Errorf("expected IPv6 peer")
+	}
+	if peer.RouterIP != "2001:db8::2" {
+		t.Errorf("expected router ip 2001:db8::2, got %s", peer.RouterIP)
+	}
+}
+
+func TestProducePeerIdentity(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	// Peer Up of IPv4 session from local address 10.0.0.1 port 179 to remote port 50000
+	pu, err := bmp.UnmarshalPeerUpMessage([]byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x01,
+		0x00, 0xB3, 0xC3, 0x50,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x01, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x1D, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, 0x02, 0x00,
+	}, false)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Peer Up message with error: %+v", err)
+	}
+	// Per-Peer Header carries peer AS 65000 and peer BGP ID 10.0.0.2
+	p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: pu})
+	p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: routeMonitor(t)})
+	if len(publisher.msgs) != 2 {
+		t.Fatalf("expected 2 published messages, got %d", len(publisher.msgs))
+	}
+	peer := &PeerStateChange{}
+	decodePublished(t, publisher.msgs[0], peer)
+	if peer.RemoteASN != 65000 || peer.RemoteBGPID != "10.0.0.2" {
+		t.Fatalf("expected peer AS 65000 and BGP ID 10.0.0.2, got %d and %s", peer.RemoteASN, peer.RemoteBGPID)
+	}
+	prefix := &UnicastPrefix{}
+	decodePublished(t, publisher.msgs[1], prefix)
+	if prefix.PeerASN != peer.RemoteASN {
+		t.Errorf("expected prefix peer AS %d, got %d", peer.RemoteASN, prefix.PeerASN)
+	}
+	if prefix.PeerBGPID != peer.RemoteBGPID {
+		t.Errorf("expected prefix peer BGP ID %s, got %s", peer.RemoteBGPID, prefix.PeerBGPID)
+	}
+}
+
+func TestProducePeerDownReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		expect PeerStateChange
+	}{
+		{
+			name:  "local notification",
+			input: []byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x15, 0x03, 0x06, 0x04},
+			expect: PeerStateChange{
+				BMPReason:       1,
+				BMPReasonString: "Local system closed session with notification",
+				BMPErrorCode:    6,
+				BMPErrorSubCode: 4,
+				ErrorText:       "Cease: Administrative Reset",
+			},
+		},
+		{
+			name:  "local close without notification",
+			input: []byte{0x02, 0x00, 0x0A},
+			expect: PeerStateChange{
+				BMPReason:       2,
+				BMPReasonString: "Local system closed session without notification",
+				FSMEvent:        10,
+			},
+		},
+		{
+			name:  "peer de-configured",
+			input: []byte{0x05},
+			expect: PeerStateChange{
+				BMPReason:       5,
+				BMPReasonString: "Peer de-configured",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false).(*producer)
+			pd, err := bmp.UnmarshalPeerDownMessage(tt.input)
+			if err != nil {
+				t.Fatalf("failed to unmarshal Peer Down message with error: %+v", err)
+			}
+			peer := &PeerStateChange{}
+			produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: pd}, peer)
+			if peer.Action != "down" {
+				t.Fatalf("expected action down, got %s", peer.Action)
+			}
+			if peer.BMPReason != tt.expect.BMPReason || peer.BMPReasonString != tt.expect.BMPReasonString {
+				t.Errorf("expected reason %d %q, got %d %q", tt.expect.BMPReason, tt.expect.BMPReasonString, peer.BMPReason, peer.BMPReasonString)
+			}
+			if peer.BMPErrorCode != tt.expect.BMPErrorCode || peer.BMPErrorSubCode != tt.expect.BMPErrorSubCode || peer.ErrorText != tt.expect.ErrorText {
+				t.Errorf("expected notification %d/%d %q, got %d/%d %q", tt.expect.BMPErrorCode, tt.expect.BMPErrorSubCode, tt.expect.ErrorText,
+					peer.BMPErrorCode, peer.BMPErrorSubCode, peer.ErrorText)
+			}
+			if peer.FSMEvent != tt.expect.FSMEvent {
+				t.Errorf("expected FSM event %d, got %d", tt.expect.FSMEvent, peer.FSMEvent)
+			}
+		})
+	}
+}
+
+// addPathPeerUp returns Peer Up message of the session with Add-Path Send/Receive negotiated for IPv4 unicast
+func addPathPeerUp(t *testing.T) *bmp.PeerUpMessage {
+	open := func(bgpID byte) []byte {
+		return []byte{
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0x00, 0x25, 0x01, 0x04, 0xFD, 0xE8, 0x00, 0xB4, 0x0A, 0x00, 0x00, bgpID,
+			0x08, 0x02, 0x06, 0x45, 0x04, 0x00, 0x01, 0x01, 0x03, // Add-Path AFI 1 SAFI 1 Send/Receive
+		}
+	}
+	b := []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x01,
+		0x00, 0xB3, 0xC3, 0x50,
+	}
+	b = append(append(b, open(1)...), open(2)...)
+	pu, err := bmp.UnmarshalPeerUpMessage(b, false)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Peer Up message with error: %+v", err)
+	}
+
+	return pu
+}
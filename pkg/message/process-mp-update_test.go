@@ -0,0 +1,201 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceSplitAF(t *testing.T) {
+	ipv6Unicast, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x45, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x2E, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x80, 0x0E, 0x1E, // MP_REACH_NLRI
+		0x00, 0x02, 0x01, // AFI 2 SAFI 1
+		0x10, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Next Hop 2001:db8::1
+		0x00,                                                 // Reserved
+		0x40, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x01, 0x00, 0x00, // NLRI 2001:db8:1::/64
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	ipv4Labeled, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x35, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x1E, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x80, 0x0E, 0x0E, // MP_REACH_NLRI
+		0x00, 0x01, 0x04, // AFI 1 SAFI 4
+		0x04, 0x0A, 0x00, 0x00, 0x01, // Next Hop 10.0.0.1
+		0x00,                         // Reserved
+		0x20, 0x00, 0x01, 0x01, 0x0A, // NLRI label 16 10.0.0.0/8
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	tests := []struct {
+		name    string
+		splitAF bool
+		opts    []Option
+		expect  []int
+	}{
+		{
+			name:   "no splitting",
+			expect: []int{bmp.UnicastPrefixMsg, bmp.UnicastPrefixMsg, bmp.UnicastPrefixMsg},
+		},
+		{
+			name:   "no splitting with split afi/safi",
+			opts:   []Option{WithSplitAF(bgp.NLRIMessageType(1, 1))},
+			expect: []int{bmp.UnicastPrefixMsg, bmp.UnicastPrefixMsg, bmp.UnicastPrefixMsg},
+		},
+		{
+			name:    "all afi/safi split",
+			splitAF: true,
+			expect:  []int{bmp.UnicastPrefixV4Msg, bmp.UnicastPrefixV6Msg, bmp.UnicastPrefixV4Msg},
+		},
+		{
+			name:    "ipv4 unicast split",
+			splitAF: true,
+			opts:    []Option{WithSplitAF(bgp.NLRIMessageType(1, 1))},
+			expect:  []int{bmp.UnicastPrefixV4Msg, bmp.UnicastPrefixMsg, bmp.UnicastPrefixMsg},
+		},
+		{
+			name:    "unicast split",
+			splitAF: true,
+			opts:    []Option{WithSplitAF(bgp.NLRIMessageType(1, 1), bgp.NLRIMessageType(2, 1))},
+			expect:  []int{bmp.UnicastPrefixV4Msg, bmp.UnicastPrefixV6Msg, bmp.UnicastPrefixMsg},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, tt.splitAF, tt.opts...).(*producer)
+			for _, rm := range []*bmp.RouteMonitor{routeMonitor(t), ipv6Unicast, ipv4Labeled} {
+				p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm})
+			}
+			got := make([]int, 0, len(publisher.msgs))
+			for _, m := range publisher.msgs {
+				got = append(got, m.msgType)
+			}
+			if diff := deep.Equal(tt.expect, got); diff != nil {
+				t.Fatalf("Diffs: %+v", diff)
+			}
+			// AFI/SAFI tag does not depend on splitting
+			expect := []struct {
+				afi  uint16
+				safi uint8
+			}{
+				{afi: 1, safi: 1},
+				{afi: 2, safi: 1},
+				{afi: 1, safi: 4},
+			}
+			for i, e := range expect {
+				m := &UnicastPrefix{}
+				decodePublished(t, publisher.msgs[i], m)
+				if m.AFI != e.afi || m.SAFI != e.safi {
+					t.Errorf("prefix %s: expected afi %d safi %d, got afi %d safi %d", m.Prefix, e.afi, e.safi, m.AFI, m.SAFI)
+				}
+			}
+		})
+	}
+}
+
+func TestProduceMPUnreach(t *testing.T) {
+	// IPv6 VPN prefix 2001:db8:1:2::/64 RD 65000:100 with label 24001
+	vpnv6 := []byte{
+		0x98,             // Length 152 bits
+		0x05, 0xdc, 0x11, // Label 24001
+		0x00, 0x00, 0xFD, 0xE8, 0x00, 0x00, 0x00, 0x64, // RD 65000:100
+		0x20, 0x01, 0x0D, 0xB8, 0x00, 0x01, 0x00, 0x02, // Prefix
+	}
+	// Withdrawn IPv6 VPN prefix carries Compatibility field instead of the label
+	vpnv6Withdrawn := append([]byte{0x98, 0x80, 0x00, 0x00}, vpnv6[4:]...)
+	// EVPN MAC/IP Advertisement route RD 200:50 MAC 00:11:22:33:44:55 IP 10.0.0.5 label 100
+	macIP := []byte{
+		0x02, 0x25, // Route Type 2, Length 37
+		0x00, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x00, 0x32, // RD 200:50
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // ESI
+		0x00, 0x00, 0x00, 0x00, // Ethernet Tag
+		0x30, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, // MAC
+		0x20, 0x0A, 0x00, 0x00, 0x05, // IP
+		0x00, 0x06, 0x41, // Label 100
+	}
+	vpnv6NextHop := []byte{
+		0x18,                                           // Next Hop Length 24
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // RD
+		0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x00, // Reserved
+	}
+	tests := []struct {
+		name     string
+		rm       *bmp.RouteMonitor
+		msgType  int
+		withdraw []bool
+	}{
+		{
+			name:     "ipv6 vpn withdraw",
+			rm:       mpRouteMonitor(t, mpAttribute(15, []byte{0x00, 0x02, 0x80}, vpnv6Withdrawn)),
+			msgType:  bmp.L3VPNMsg,
+			withdraw: []bool{true},
+		},
+		{
+			name:     "ipv6 vpn withdraw and advertise",
+			rm:       mpRouteMonitor(t, mpAttribute(15, []byte{0x00, 0x02, 0x80}, vpnv6Withdrawn), mpAttribute(14, []byte{0x00, 0x02, 0x80}, vpnv6NextHop, vpnv6)),
+			msgType:  bmp.L3VPNMsg,
+			withdraw: []bool{true, false},
+		},
+		{
+			name:     "evpn mac/ip advertisement withdraw",
+			rm:       mpRouteMonitor(t, mpAttribute(15, []byte{0x00, 0x19, 0x46}, macIP)),
+			msgType:  bmp.EVPNMsg,
+			withdraw: []bool{true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false).(*producer)
+			p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: tt.rm})
+			if len(publisher.msgs) != len(tt.withdraw) {
+				t.Fatalf("expected %d published messages, got %d", len(tt.withdraw), len(publisher.msgs))
+			}
+			for i, m := range publisher.msgs {
+				if m.msgType != tt.msgType {
+					t.Fatalf("expected message type %d, got %d", tt.msgType, m.msgType)
+				}
+				action := "add"
+				if tt.withdraw[i] {
+					action = "del"
+				}
+				switch tt.msgType {
+				case bmp.L3VPNMsg:
+					got := &L3VPNPrefix{}
+					decodePublished(t, m, got)
+					if got.Action != action || got.IsWithdraw != tt.withdraw[i] {
+						t.Errorf("expected action %s and withdraw %t, got %s and %t", action, tt.withdraw[i], got.Action, got.IsWithdraw)
+					}
+					if got.Prefix != "2001:db8:1:2::" || got.PrefixLen != 64 || got.VPNRD != "65000:100" || got.IsIPv4 {
+						t.Errorf("expected ipv6 vpn prefix 65000:100:2001:db8:1:2::/64, got %s:%s/%d", got.VPNRD, got.Prefix, got.PrefixLen)
+					}
+				case bmp.EVPNMsg:
+					got := &EVPNPrefix{}
+					decodePublished(t, m, got)
+					if got.Action != action || got.IsWithdraw != tt.withdraw[i] {
+						t.Errorf("expected action %s and withdraw %t, got %s and %t", action, tt.withdraw[i], got.Action, got.IsWithdraw)
+					}
+					if got.RouteType != 2 || got.VPNRD != "200:50" || got.MAC != "00:11:22:33:44:55" || got.IPAddress != "10.0.0.5" {
+						t.Errorf("expected evpn route type 2 200:50 mac 00:11:22:33:44:55 ip 10.0.0.5, got %+v", got)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,14 @@
 package message
 
 import (
-	"github.com/golang/glog"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/logging"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/gobmp/pkg/pub"
 )
 
@@ -18,27 +24,260 @@ type Producer interface {
 
 type producer struct {
 	publisher      pub.Publisher
+	logger         *slog.Logger
+	router         pub.Router
 	speakerIP      string
 	speakerHash    string
 	addPathCapable map[int]bool
+	// identity is known identity of the monitored router, routerHashFunc derives speakerHash from it
+	identity       RouterIdentity
+	routerHashFunc RouterHashFunc
 	// If splitAF is set to true, ipv4 and ipv6 messages will go into separate topics
 	splitAF bool
+	metrics *metrics.Metrics
+	filters []Filter
+	// If messageTypes is not nil, only messages of these BMP message types are produced
+	messageTypes map[byte]bool
+	// transforms are applied to every BMP message accepted by filters before it is produced
+	transforms []Transform
+	// If splitNLRITypes is not empty, only messages of these NLRI types are split by splitAF
+	splitNLRITypes map[int]bool
+	// If rawMessage is set to true, the original BMP message is attached to every produced message
+	rawMessage bool
+	// If messageID is set to true, every produced message carries an identifier derived from its content
+	messageID bool
+	// If attrFlags is set to true, Attribute Flags of path attributes are attached to base attributes
+	attrFlags bool
+	// If unknownAttrs is set to true, path attributes not decoded by gobmp are attached to base attributes
+	unknownAttrs bool
+	// If endOfRIB is set to true, End-of-RIB markers received from peers are published
+	endOfRIB bool
+	// If peerEvents is set to true, peer events are published along with PeerStateChange messages
+	peerEvents bool
+	// If parseErrors is set to true, BMP messages failed to be parsed are published
+	parseErrors bool
+	// If publishTimeout is not 0, a publish not completed within the timeout fails
+	publishTimeout time.Duration
+	// If queueCapacity is not 0, received messages wait in the queue and are handled according to dropPolicy
+	// when the queue is full
+	queueCapacity int
+	dropPolicy    DropPolicy
+	// Adding a message to the full queue blocking longer than queueBlockThreshold is counted by metrics
+	queueBlockThreshold time.Duration
+	// tableNames are VRF/Table Names of Loc-RIB peers learned from Peer Up messages by Peer Distinguisher
+	tableNames   map[string]string
+	tableNamesMu sync.RWMutex
+}
+
+// Option defines a function which modifies optional parameters of the producer
+type Option func(*producer)
+
+// WithLogger sets the logger of the producer, by default and when logger is nil records are written to glog
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *producer) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+// WithMetrics sets metrics collectors used to count messages failed to be published
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(p *producer) {
+		p.metrics = m
+	}
+}
+
+// WithRouterAddress sets the address of the monitored router used as RouterIP and to compute RouterHash
+// until the router identity is learned from Peer Up message. The address identifies the router to publishers
+// implementing pub.RouterPublisher for all produced messages.
+func WithRouterAddress(addr string) Option {
+	return func(p *producer) {
+		p.router = pub.NewRouter(addr)
+		p.identity.Address = addr
+		p.identity.RouterIP = addr
+	}
+}
+
+// WithRouterHashFunc sets the function deriving RouterHash of produced messages from the identity of
+// the monitored router, for example from sysName of Initiation message when the address of BMP session
+// is not stable. By default RouterHash is md5 hash of RouterIP, see DefaultRouterHash.
+func WithRouterHashFunc(f RouterHashFunc) Option {
+	return func(p *producer) {
+		p.routerHashFunc = f
+	}
+}
+
+// WithAddPath enables BGP Add-Path for the NLRI types, as returned by bgp.NLRIMessageType, regardless
+// of Add-Path capability advertised in Peer Up message. It is useful when BMP session is established
+// after BGP sessions are up and Peer Up messages do not reflect the negotiated capabilities.
+func WithAddPath(nlriTypes ...int) Option {
+	return func(p *producer) {
+		for _, t := range nlriTypes {
+			p.addPathCapable[t] = true
+		}
+	}
+}
+
+// WithSplitAF limits splitting ipv4 and ipv6 messages into separate topics to the NLRI types, as returned
+// by bgp.NLRIMessageType, messages of other NLRI types go into topics combining both address families.
+// The option has effect only when the producer is instantiated with splitAF set to true, by default
+// messages of all NLRI types are split. IPv4 and IPv6 Flowspec share NLRI type and are split together.
+func WithSplitAF(nlriTypes ...int) Option {
+	return func(p *producer) {
+		if p.splitNLRITypes == nil {
+			p.splitNLRITypes = make(map[int]bool, len(nlriTypes))
+		}
+		for _, t := range nlriTypes {
+			p.splitNLRITypes[t] = true
+		}
+	}
+}
+
+// split returns true when ipv4 and ipv6 messages of the NLRI type go into separate topics
+func (p *producer) split(nlriType int) bool {
+	if !p.splitAF {
+		return false
+	}
+	if len(p.splitNLRITypes) == 0 {
+		return true
+	}
+
+	return p.splitNLRITypes[nlriType]
+}
+
+// WithRawMessage attaches the original BMP message, including the Common Header, as base64 encoded
+// raw_bmp_message field to every produced message. Base64 encoding grows the raw message by a third and
+// a BMP message carrying multiple prefixes is repeated in every message produced from it, so the option
+// considerably increases the size of published messages and should be used for troubleshooting.
+func WithRawMessage() Option {
+	return func(p *producer) {
+		p.rawMessage = true
+	}
 }
 
-// Producer dispatches kafka workers upon request received from the channel
+// WithMessageID attaches message_id field to every produced message, the identifier is derived from
+// the sequence number of the BMP message in the session, the type and the content of the produced message,
+// including the router, the peer, Per-Peer Header timestamp and NLRI, but not the time the collector
+// received the BMP message. A message published more than once, for example when a publish is retried,
+// carries the same identifier, so consumers can use it to drop duplicates. Messages of the same content
+// produced from different BMP messages carry different identifiers, the sequence restarts with every BMP
+// session, so duplicates across sessions are not detected.
+func WithMessageID() Option {
+	return func(p *producer) {
+		p.messageID = true
+	}
+}
+
+// WithEndOfRIB publishes a message when End-of-RIB marker, rfc4724, is received from a peer, the message
+// carries the peer and AFI/SAFI of the marker. End-of-RIB marker signals the peer has sent its initial
+// routing table for AFI/SAFI, so consumers can tell when the routing table of the peer is synchronized.
+func WithEndOfRIB() Option {
+	return func(p *producer) {
+		p.endOfRIB = true
+	}
+}
+
+// WithPeerEvents publishes a peer event message along with every PeerStateChange message. Peer events of
+// peers going up and down carry the same peer identity and Action "up" or "down", so consumers track the
+// state of BGP sessions from a single message type. PeerStateChange messages are published as before.
+func WithPeerEvents() Option {
+	return func(p *producer) {
+		p.peerEvents = true
+	}
+}
+
+// WithUnknownAttributes attaches path attributes of BGP Update not decoded by gobmp, the type code, Attribute
+// Flags and the raw value, as unknown_attrs of base attributes of produced messages. Unknown attributes are
+// counted by metrics regardless of the option, the option helps to discover attributes routers send.
+func WithUnknownAttributes() Option {
+	return func(p *producer) {
+		p.unknownAttrs = true
+	}
+}
+
+// WithAttributeFlags attaches Attribute Flags of all path attributes of BGP Update, Optional, Transitive,
+// Partial and Extended Length bits by attribute type code, as attr_flags of base attributes of produced
+// messages. It is useful for troubleshooting interoperability issues, for example a transitive flag set on
+// a non-transitive attribute.
+func WithAttributeFlags() Option {
+	return func(p *producer) {
+		p.attrFlags = true
+	}
+}
+
+// WithPublishTimeout makes a publish of a produced message fail when it does not complete within
+// the timeout, so a stalled backend does not block the producer. Publishers implementing
+// pub.ContextPublisher abandon the publish, the publish to other publishers keeps running in
+// the background, see pub.PublishMessageContext. By default there is no timeout.
+func WithPublishTimeout(timeout time.Duration) Option {
+	return func(p *producer) {
+		p.publishTimeout = timeout
+	}
+}
+
+// Producer dispatches kafka workers upon request received from the channel,
+// when stopped, Producer returns once all dispatched workers are done.
 func (p *producer) Producer(queue chan bmp.Message, stop chan struct{}) {
+	if p.queueCapacity > 0 {
+		p.queuedProducer(queue, stop)
+		return
+	}
+	var wg sync.WaitGroup
 	for {
 		select {
 		case msg := <-queue:
-			go p.producingWorker(msg)
+			if updatesSession(msg) {
+				// Workers of messages received before must not see the updated session state
+				wg.Wait()
+				p.learnSession(msg)
+			}
+			msg, ok := p.accept(msg)
+			if !ok {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				p.produce(msg)
+			}()
 		case <-stop:
-			glog.Infof("received interrupt, stopping.")
+			p.logger.Info("received interrupt, stopping.")
+			wg.Wait()
 			return
 		}
 	}
 }
 
+// producingWorker produces the BMP message, it must not run concurrently with other workers of the producer
 func (p *producer) producingWorker(msg bmp.Message) {
+	p.learnSession(msg)
+	msg, ok := p.accept(msg)
+	if !ok {
+		return
+	}
+	p.produce(msg)
+}
+
+// accept applies message types, filters and transforms to the BMP message, false is returned when
+// the message is dropped
+func (p *producer) accept(msg bmp.Message) (bmp.Message, bool) {
+	if p.messageTypes != nil {
+		if t, ok := messageType(msg); ok && !p.messageTypes[t] {
+			return msg, false
+		}
+	}
+	for _, filter := range p.filters {
+		if !filter(msg) {
+			return msg, false
+		}
+	}
+
+	return p.transform(msg)
+}
+
+// produce produces messages from the BMP message accepted by the producer and publishes them
+func (p *producer) produce(msg bmp.Message) {
 	switch obj := msg.Payload.(type) {
 	case *bmp.PeerUpMessage:
 		p.producePeerMessage(peerUP, msg)
@@ -48,16 +287,36 @@ func (p *producer) producingWorker(msg bmp.Message) {
 		p.produceRouteMonitorMessage(msg)
 	case *bmp.StatsReport:
 		p.produceStatsMessage(msg)
+	case *bmp.RouteMirrorMessage:
+		p.produceRouteMirrorMessage(msg)
+	case *bmp.InitiationMessage:
+		p.produceRouterInfoMessage(msg)
+	case *bmp.TerminationMessage:
+		p.produceTerminationMessage(msg)
+	case *bmp.ParseError:
+		if p.parseErrors {
+			p.produceParseErrorMessage(msg)
+		}
 	default:
-		glog.Warningf("got Unknown message %T to push to the producer, ignoring it...", obj)
+		p.logger.Warn("got unknown message to push to the producer, ignoring it", "type", fmt.Sprintf("%T", obj))
 	}
 }
 
 // NewProducer instantiates a new instance of a producer with Publisher interface
-func NewProducer(publisher pub.Publisher, splitAF bool) Producer {
-	return &producer{
-		publisher:      publisher,
-		splitAF:        splitAF,
-		addPathCapable: make(map[int]bool),
+func NewProducer(publisher pub.Publisher, splitAF bool, opts ...Option) Producer {
+	p := &producer{
+		publisher:           publisher,
+		logger:              logging.Default(),
+		splitAF:             splitAF,
+		addPathCapable:      make(map[int]bool),
+		tableNames:          make(map[string]string),
+		routerHashFunc:      DefaultRouterHash,
+		queueBlockThreshold: defaultQueueBlockThreshold,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.updateRouterHash()
+
+	return p
 }
@@ -0,0 +1,202 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+type publishedMsg struct {
+	msgType int
+	key     []byte
+	msg     []byte
+}
+
+// testPublisher stores published messages to be validated by tests
+type testPublisher struct {
+	msgs []publishedMsg
+}
+
+func (p *testPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	p.msgs = append(p.msgs, publishedMsg{msgType: msgType, key: msgHash, msg: msg})
+	return nil
+}
+
+func (p *testPublisher) Stop() {}
+
+// decodePublished unmarshals published message m into v
+func decodePublished(t *testing.T, m publishedMsg, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(m.msg, v); err != nil {
+		t.Fatalf("failed to unmarshal published message with error: %+v", err)
+	}
+}
+
+// produceOne produces msg, checks that it is published as a single message and unmarshals it into v
+func produceOne(t *testing.T, p *producer, publisher *testPublisher, msg bmp.Message, v interface{}) publishedMsg {
+	t.Helper()
+	p.producingWorker(msg)
+	if len(publisher.msgs) != 1 {
+		t.Fatalf("expected 1 published message, got %d", len(publisher.msgs))
+	}
+	decodePublished(t, publisher.msgs[0], v)
+
+	return publisher.msgs[0]
+}
+
+// routerPublisher stores routers of published messages
+type routerPublisher struct {
+	testPublisher
+	routers []pub.Router
+}
+
+func (p *routerPublisher) PublishRouterMessage(router pub.Router, msgType int, msgHash []byte, msg []byte) error {
+	p.routers = append(p.routers, router)
+	return p.PublishMessage(msgType, msgHash, msg)
+}
+
+func TestProduceRouterPublisher(t *testing.T) {
+	tm, err := bmp.UnmarshalTerminationMessage([]byte{0, 1, 0, 2, 0, 1})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Termination message with error: %+v", err)
+	}
+	// Two BMP sessions produce messages of their routers
+	publisher := &routerPublisher{}
+	for _, addr := range []string{"192.168.80.103", "192.168.80.104"} {
+		p := NewProducer(publisher, false, WithRouterAddress(addr)).(*producer)
+		p.producingWorker(bmp.Message{Payload: tm})
+	}
+	expect := []pub.Router{
+		{Address: "192.168.80.103", Hash: "1f101e2bd3415b5b65f51fe19222e561"},
+		pub.NewRouter("192.168.80.104"),
+	}
+	if diff := deep.Equal(expect, publisher.routers); diff != nil {
+		t.Fatalf("Diffs: %+v", diff)
+	}
+	if expect[0].Hash == expect[1].Hash {
+		t.Errorf("expected distinct routing keys of routers, got %s", expect[0].Hash)
+	}
+	for i, m := range publisher.msgs {
+		if string(m.key) != expect[i].Hash {
+			t.Errorf("expected message key %s, got %s", expect[i].Hash, string(m.key))
+		}
+	}
+}
+
+// blockingPublisher blocks every publish until release is closed
+type blockingPublisher struct {
+	testPublisher
+	release chan struct{}
+}
+
+func (p *blockingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	<-p.release
+	return nil
+}
+
+func TestProducePublishTimeout(t *testing.T) {
+	publisher := &blockingPublisher{release: make(chan struct{})}
+	defer close(publisher.release)
+	p := NewProducer(publisher, false, WithPublishTimeout(10*time.Millisecond)).(*producer)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: routeMonitor(t)})
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer is blocked by stalled publisher")
+	}
+	err := p.marshalAndPublish(&UnicastPrefix{}, bmp.UnicastPrefixMsg, nil, bmp.Message{}, false)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected publish to fail with %v, got %+v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestProducerNilLogger(t *testing.T) {
+	p := NewProducer(&testPublisher{}, false, WithLogger(nil)).(*producer)
+	// Unknown payload is logged and ignored
+	p.producingWorker(bmp.Message{Payload: struct{}{}})
+}
+
+// perPeerHeader returns Per Peer Header of peer 10.0.0.2 AS 65000 of the given type and flags
+func perPeerHeader(t *testing.T, peerType, flags byte) *bmp.PerPeerHeader {
+	b := []byte{
+		peerType, flags,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x02,
+		0x00, 0x00, 0xFD, 0xE8,
+		0x0A, 0x00, 0x00, 0x02,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	if peerType == byte(bmp.PeerType3) {
+		// Loc-RIB Peer Address is zero-filled
+		copy(b[10:26], make([]byte, 16))
+	}
+	ph, err := bmp.UnmarshalPerPeerHeader(b)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Per Peer Header with error: %+v", err)
+	}
+
+	return ph
+}
+
+// routeMonitor returns Route Monitoring message advertising 10.0.0.0/8 with next hop 10.0.0.1
+func routeMonitor(t *testing.T) *bmp.RouteMonitor {
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage([]byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x2D, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x14, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+		0x40, 0x03, 0x04, 0x0A, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+		0x08, 0x0A, // NLRI 10.0.0.0/8
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+
+	return rm
+}
+
+// mpRouteMonitor returns Route Monitoring message carrying attrs after ORIGIN and AS_PATH attributes
+func mpRouteMonitor(t *testing.T, attrs ...[]byte) *bmp.RouteMonitor {
+	b := []byte{
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xFD, 0xE8, // AS_PATH 65000
+	}
+	for _, attr := range attrs {
+		b = append(b, attr...)
+	}
+	length := 19 + 2 + 2 + len(b)
+	update := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		byte(length >> 8), byte(length), 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		byte(len(b) >> 8), byte(len(b)), // Total Path Attribute Length
+	}
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage(append(update, b...), 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+
+	return rm
+}
+
+// mpAttribute returns path attribute of type attrType carrying value
+func mpAttribute(attrType byte, value ...[]byte) []byte {
+	v := make([]byte, 0)
+	for _, b := range value {
+		v = append(v, b...)
+	}
+
+	return append([]byte{0x80, attrType, byte(len(v))}, v...)
+}
@@ -0,0 +1,123 @@
+package message
+
+import (
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// defaultQueueBlockThreshold is the time adding a BMP message to the full queue may block before it is
+// counted as backpressure
+const defaultQueueBlockThreshold = 100 * time.Millisecond
+
+// DropPolicy defines what the producer does with a BMP message when its queue is full
+type DropPolicy int
+
+const (
+	// QueueBlock stops receiving BMP messages until there is room in the queue, so a slow publisher
+	// slows down parsing and reading from the BMP session.
+	QueueBlock DropPolicy = iota
+	// QueueDropOldest drops the oldest BMP message waiting in the queue to make room for the received one
+	QueueDropOldest
+	// QueueDropNewest drops the received BMP message
+	QueueDropNewest
+)
+
+func (d DropPolicy) String() string {
+	switch d {
+	case QueueBlock:
+		return "block"
+	case QueueDropOldest:
+		return "drop-oldest"
+	case QueueDropNewest:
+		return "drop-newest"
+	}
+
+	return "unknown"
+}
+
+// WithQueue makes the producer keep up to capacity received BMP messages waiting to be published and
+// publish them one at a time in the order they are received. When the queue is full, the BMP message
+// is handled according to policy, dropped messages are counted by metrics. By default the producer
+// publishes every received BMP message right away in its own goroutine.
+func WithQueue(capacity int, policy DropPolicy) Option {
+	return func(p *producer) {
+		p.queueCapacity = capacity
+		p.dropPolicy = policy
+	}
+}
+
+// WithQueueBlockThreshold sets the time adding a BMP message to the full queue with QueueBlock policy may
+// block before it is counted by metrics as backpressure from the publisher, 100ms by default.
+func WithQueueBlockThreshold(threshold time.Duration) Option {
+	return func(p *producer) {
+		p.queueBlockThreshold = threshold
+	}
+}
+
+// queuedProducer receives BMP messages into the queue of queueCapacity messages and publishes them
+// one at a time, when stopped, it returns once all queued messages are published.
+func (p *producer) queuedProducer(queue chan bmp.Message, stop chan struct{}) {
+	pending := make(chan bmp.Message, p.queueCapacity)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range pending {
+			p.metrics.MessageDequeued()
+			p.producingWorker(msg)
+		}
+	}()
+	for {
+		select {
+		case msg := <-queue:
+			p.enqueue(pending, msg)
+		case <-stop:
+			p.logger.Info("received interrupt, stopping.")
+			close(pending)
+			<-done
+			return
+		}
+	}
+}
+
+// enqueue adds the BMP message to the pending queue according to the drop policy, the message is counted
+// as queued before it is added, so the queue depth does not go negative when it is taken right away.
+func (p *producer) enqueue(pending chan bmp.Message, msg bmp.Message) {
+	p.metrics.MessageQueued()
+	switch p.dropPolicy {
+	case QueueDropNewest:
+		select {
+		case pending <- msg:
+		default:
+			p.metrics.MessageDequeued()
+			p.metrics.MessageDropped()
+		}
+	case QueueDropOldest:
+		for {
+			select {
+			case pending <- msg:
+				return
+			default:
+			}
+			// The queue is full, dropping the oldest message unless it has just been taken for publishing
+			select {
+			case <-pending:
+				p.metrics.MessageDequeued()
+				p.metrics.MessageDropped()
+			default:
+			}
+		}
+	default:
+		select {
+		case pending <- msg:
+			return
+		default:
+		}
+		// The queue is full, the publisher does not keep up with received messages
+		start := time.Now()
+		pending <- msg
+		if time.Since(start) >= p.queueBlockThreshold {
+			p.metrics.QueueBlocked()
+		}
+	}
+}
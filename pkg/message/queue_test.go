@@ -0,0 +1,191 @@
+package message
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/metrics"
+)
+
+// queuedMsg returns BMP message identified by i in its parsing error
+func queuedMsg(i int) bmp.Message {
+	return bmp.Message{Payload: &bmp.ParseError{Err: fmt.Errorf("message %d", i)}}
+}
+
+// droppedMessages returns the value of dropped messages counter registered with reg
+func droppedMessages(t *testing.T, reg *prometheus.Registry) float64 {
+	return metricValue(t, reg, "gobmp_dropped_messages_total")
+}
+
+// metricValue returns the value of the counter or the gauge of name registered with reg
+func metricValue(t *testing.T, reg *prometheus.Registry, name string) float64 {
+	t.Helper()
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics with error: %+v", err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		if g := mf.GetMetric()[0].GetGauge(); g != nil {
+			return g.GetValue()
+		}
+		return mf.GetMetric()[0].GetCounter().GetValue()
+	}
+	t.Fatalf("metric %s is not registered", name)
+	return 0
+}
+
+func TestProducerQueueDropPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  DropPolicy
+		expect  []int
+		dropped float64
+	}{
+		{
+			name:    "drop oldest",
+			policy:  QueueDropOldest,
+			expect:  []int{1, 2},
+			dropped: 1,
+		},
+		{
+			name:    "drop newest",
+			policy:  QueueDropNewest,
+			expect:  []int{0, 1},
+			dropped: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+			m, err := metrics.NewMetrics(reg)
+			if err != nil {
+				t.Fatalf("failed to instantiate metrics with error: %+v", err)
+			}
+			p := NewProducer(&testPublisher{}, false, WithQueue(2, tt.policy), WithMetrics(m)).(*producer)
+			// Nobody takes messages from the pending queue, the third message finds it full
+			pending := make(chan bmp.Message, p.queueCapacity)
+			for i := 0; i < 3; i++ {
+				p.enqueue(pending, queuedMsg(i))
+			}
+			close(pending)
+			got := make([]int, 0)
+			for msg := range pending {
+				var i int
+				fmt.Sscanf(msg.Payload.(*bmp.ParseError).Err.Error(), "message %d", &i)
+				got = append(got, i)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.expect) {
+				t.Fatalf("expected queued messages %v, got %v", tt.expect, got)
+			}
+			if d := droppedMessages(t, reg); d != tt.dropped {
+				t.Fatalf("expected %f dropped messages, got %f", tt.dropped, d)
+			}
+		})
+	}
+}
+
+func TestProducerQueueBlock(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("failed to instantiate metrics with error: %+v", err)
+	}
+	p := NewProducer(&testPublisher{}, false, WithQueue(2, QueueBlock), WithMetrics(m)).(*producer)
+	pending := make(chan bmp.Message, p.queueCapacity)
+	p.enqueue(pending, queuedMsg(0))
+	p.enqueue(pending, queuedMsg(1))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.enqueue(pending, queuedMsg(2))
+	}()
+	select {
+	case <-done:
+		t.Fatalf("expected enqueue to block while the queue is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-pending
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("enqueue is still blocked after the queue got room")
+	}
+	if d := droppedMessages(t, reg); d != 0 {
+		t.Fatalf("expected no dropped messages, got %f", d)
+	}
+}
+
+func TestQueuedProducer(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithQueue(10, QueueBlock), WithParseErrors())
+	queue := make(chan bmp.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Producer(queue, stop)
+		close(done)
+	}()
+	for i := 0; i < 3; i++ {
+		queue <- queuedMsg(i)
+	}
+	close(stop)
+	<-done
+	// All queued messages are published in order before the producer returns
+	if len(publisher.msgs) != 3 {
+		t.Fatalf("expected 3 published messages, got %d", len(publisher.msgs))
+	}
+	for i, msg := range publisher.msgs {
+		var pe ParseError
+		decodePublished(t, msg, &pe)
+		if pe.Error != fmt.Sprintf("message %d", i) {
+			t.Fatalf("expected message %d to be published, got %q", i, pe.Error)
+		}
+	}
+}
+
+func TestProducerQueueDepth(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.NewMetrics(reg)
+	if err != nil {
+		t.Fatalf("failed to instantiate metrics with error: %+v", err)
+	}
+	publisher := &blockingPublisher{release: make(chan struct{})}
+	p := NewProducer(publisher, false, WithQueue(3, QueueBlock), WithQueueBlockThreshold(10*time.Millisecond),
+		WithMetrics(m), WithParseErrors())
+	queue := make(chan bmp.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		p.Producer(queue, stop)
+		close(done)
+	}()
+	// The first message is taken for publishing and blocks the publisher, the following ones fill the queue
+	for i := 0; i < 4; i++ {
+		queue <- queuedMsg(i)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for metricValue(t, reg, "gobmp_producer_queue_depth") != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected queue depth 3, got %f", metricValue(t, reg, "gobmp_producer_queue_depth"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// The queue is full, the next message blocks the producer until the publisher is released
+	queue <- queuedMsg(4)
+	time.Sleep(50 * time.Millisecond)
+	close(publisher.release)
+	close(stop)
+	<-done
+	if d := metricValue(t, reg, "gobmp_producer_queue_depth"); d != 0 {
+		t.Fatalf("expected empty queue after stop, got depth %f", d)
+	}
+	if b := metricValue(t, reg, "gobmp_producer_queue_blocked_total"); b != 1 {
+		t.Fatalf("expected 1 blocked send, got %f", b)
+	}
+}
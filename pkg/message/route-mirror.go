@@ -0,0 +1,50 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// produceRouteMirrorMessage produces message from BMP Route Mirroring Message
+func (p *producer) produceRouteMirrorMessage(msg bmp.Message) {
+	if msg.PeerHeader == nil {
+		p.logger.Error("perPeerHeader is missing, cannot construct Route Mirroring message")
+		return
+	}
+	rm, ok := msg.Payload.(*bmp.RouteMirrorMessage)
+	if !ok {
+		p.logger.Error("got invalid Payload type in bmp.RouteMirrorMessage", "payload", msg.Payload)
+		return
+	}
+	m := RouteMirror{
+		RouterHash:   p.speakerHash,
+		RouterIP:     p.speakerIP,
+		PeerType:     uint8(msg.PeerHeader.PeerType),
+		PeerHash:     msg.PeerHeader.GetPeerHash(),
+		RemoteBGPID:  msg.PeerHeader.GetPeerBGPIDString(),
+		RemoteASN:    msg.PeerHeader.PeerAS,
+		RemoteIP:     msg.PeerHeader.GetPeerAddrString(),
+		PeerRD:       msg.PeerHeader.GetPeerDistinguisherString(),
+		Timestamp:    msg.PeerHeader.GetPeerTimestamp(),
+		BGPMessages:  rm.BGPMessages(),
+		ErroredPDU:   rm.IsErroredPDU(),
+		MessagesLost: rm.IsMessagesLost(),
+	}
+	for _, b := range m.BGPMessages {
+		// BGP message header is 16 bytes marker, 2 bytes length and 1 byte type
+		if len(b) < 19 || b[18] != bgp.BGPNotificationMessageType {
+			continue
+		}
+		n, err := bgp.UnmarshalBGPNotificationMessage(b[16:])
+		if err != nil {
+			// Malformed Notification is published as raw BGP message only
+			p.logger.Warn("failed to decode mirrored BGP Notification message", "error", err)
+			continue
+		}
+		m.Notifications = append(m.Notifications, n)
+	}
+	if err := p.marshalAndPublish(&m, bmp.RouteMirrorMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process Route Mirroring message", "error", err)
+		return
+	}
+}
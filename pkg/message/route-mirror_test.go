@@ -0,0 +1,49 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceRouteMirrorNotification(t *testing.T) {
+	notification := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x15, 0x03, // Notification
+		0x04, 0x00, // Hold Timer Expired
+	}
+	// Malformed Notification is published without being decoded
+	malformed := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x13, 0x03,
+	}
+	b := append([]byte{0x00, 0x00, 0x00, 0x15}, notification...)
+	b = append(b, 0x00, 0x00, 0x00, 0x13)
+	b = append(b, malformed...)
+	rm, err := bmp.UnmarshalBMPRouteMirrorMessage(b)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Mirroring message with error: %+v", err)
+	}
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false).(*producer)
+	got := &RouteMirror{}
+	published := produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, got)
+	if published.msgType != bmp.RouteMirrorMsg {
+		t.Fatalf("expected message type %d, got %d", bmp.RouteMirrorMsg, published.msgType)
+	}
+	if len(got.BGPMessages) != 2 {
+		t.Errorf("expected 2 mirrored BGP messages, got %d", len(got.BGPMessages))
+	}
+	expect := []*bgp.NotificationMessage{
+		{
+			Code:    4,
+			Subcode: 0,
+			Error:   "Hold Timer Expired",
+		},
+	}
+	if diff := deep.Equal(expect, got.Notifications); diff != nil {
+		t.Errorf("Diffs: %+v", diff)
+	}
+}
@@ -1,12 +1,17 @@
 package message
 
 import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"time"
 
-	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/bgp"
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/pub"
 )
 
 const (
@@ -18,21 +23,38 @@ const (
 
 func (p *producer) produceRouteMonitorMessage(msg bmp.Message) {
 	if msg.PeerHeader == nil {
-		glog.Errorf("perPeerHeader is missing, cannot construct PeerStateChange message")
+		p.logger.Error("perPeerHeader is missing, cannot construct PeerStateChange message")
 		return
 	}
 	routeMonitorMsg, ok := msg.Payload.(*bmp.RouteMonitor)
 	if !ok {
-		glog.Errorf("got invalid Payload type in bmp.Message")
+		p.logger.Error("got invalid Payload type in bmp.Message")
 		return
 	}
 	if routeMonitorMsg == nil {
-		glog.Errorf("route monitor message is nil")
+		p.logger.Error("route monitor message is nil")
 		return
 	}
 	if routeMonitorMsg.Update == nil {
 		return
 	}
+	if p.attrFlags && routeMonitorMsg.Update.BaseAttributes != nil {
+		routeMonitorMsg.Update.BaseAttributes.AttrFlags = bgp.AttributeFlags(routeMonitorMsg.Update.PathAttributes)
+	}
+	if unknown := bgp.UnknownAttributes(routeMonitorMsg.Update.PathAttributes); len(unknown) != 0 {
+		for _, attr := range unknown {
+			p.metrics.UnknownAttribute(attr.Type)
+		}
+		if p.unknownAttrs && routeMonitorMsg.Update.BaseAttributes != nil {
+			routeMonitorMsg.Update.BaseAttributes.UnknownAttrs = unknown
+		}
+	}
+	if p.endOfRIB {
+		if afi, safi, ok := routeMonitorMsg.Update.EndOfRIB(); ok {
+			p.produceEndOfRIBMessage(msg, afi, safi)
+			return
+		}
+	}
 	attrType := uint8(0)
 	index := 0
 	if len(routeMonitorMsg.Update.PathAttributes) != 0 {
@@ -41,22 +63,33 @@ func (p *producer) produceRouteMonitorMessage(msg bmp.Message) {
 	}
 	// Using first attribute type to select which nlri processor to call
 	switch attrType {
-	case 14:
-		nlri, err := bgp.UnmarshalMPReachNLRI(routeMonitorMsg.Update.PathAttributes[index].Attribute, routeMonitorMsg.Update.HasPrefixSID(), p.addPathCapable)
-		if err != nil {
-			glog.Errorf("failed to process MP_REACH_NLRI with error: %+v", err)
-		}
-		p.processMPUpdate(nlri, AddPrefix, msg.PeerHeader, routeMonitorMsg.Update)
-	case 15:
-		// MP_UNREACH_NLRI
-		nlri, err := bgp.UnmarshalMPUnReachNLRI(routeMonitorMsg.Update.PathAttributes[index].Attribute, p.addPathCapable)
-		if err != nil {
-			glog.Errorf("failed to process MP_UNREACH_NLRI with error: %+v", err)
+	case 14, 15:
+		// Update can carry both MP_REACH_NLRI and MP_UNREACH_NLRI, prefixes of both are processed
+		// so withdrawn prefixes do not stay active
+		for _, attr := range routeMonitorMsg.Update.PathAttributes[index:] {
+			switch attr.AttributeType {
+			case 14:
+				nlri, err := bgp.UnmarshalMPReachNLRI(attr.Attribute, routeMonitorMsg.Update.HasPrefixSID(), p.addPathCapable)
+				if err != nil {
+					p.logger.Error("failed to process MP_REACH_NLRI", "error", err)
+					continue
+				}
+				p.processMPUpdate(nlri, AddPrefix, msg.PeerHeader, routeMonitorMsg.Update, msg)
+			case 15:
+				// MP_UNREACH_NLRI
+				nlri, err := bgp.UnmarshalMPUnReachNLRI(attr.Attribute, p.addPathCapable)
+				if err != nil {
+					p.logger.Error("failed to process MP_UNREACH_NLRI", "error", err)
+					continue
+				}
+				p.processMPUpdate(nlri, DelPrefix, msg.PeerHeader, routeMonitorMsg.Update, msg)
+			}
 		}
-		p.processMPUpdate(nlri, DelPrefix, msg.PeerHeader, routeMonitorMsg.Update)
 	default:
+		src := msg
 		t := bmp.UnicastPrefixMsg
-		if p.splitAF {
+		// Original BGP's NLRI carries AFI 1 SAFI 1 prefixes
+		if p.split(bgp.NLRIMessageType(1, 1)) {
 			t = bmp.UnicastPrefixV4Msg
 		}
 		// Original BGP's NLRI messages processing
@@ -64,37 +97,118 @@ func (p *producer) produceRouteMonitorMessage(msg bmp.Message) {
 		if routeMonitorMsg.Update.WithdrawnRoutesLength != 0 {
 			msg, err := p.nlri(DelPrefix, msg.PeerHeader, routeMonitorMsg.Update)
 			if err != nil {
-				glog.Errorf("failed to produce original NLRI Withdraw message with error: %+v", err)
+				p.logger.Error("failed to produce original NLRI Withdraw message", "error", err)
 				return
 			}
 			msgs = append(msgs, msg...)
 		}
 		msg, err := p.nlri(AddPrefix, msg.PeerHeader, routeMonitorMsg.Update)
 		if err != nil {
-			glog.Errorf("failed to produce original NLRI Withdraw message with error: %+v", err)
+			p.logger.Error("failed to produce original NLRI Withdraw message", "error", err)
 			return
 		}
 		msgs = append(msgs, msg...)
 		// Loop through and publish all collected messages
 		for _, m := range msgs {
-			if err := p.marshalAndPublish(&m, t, []byte(m.RouterHash), false); err != nil {
-				glog.Errorf("failed to process Unicast Prefix message with error: %+v", err)
+			if err := p.marshalAndPublish(&m, t, []byte(m.RouterHash), src, false); err != nil {
+				p.logger.Error("failed to process Unicast Prefix message", "error", err)
 				return
 			}
 		}
 	}
 }
 
-func (p *producer) marshalAndPublish(msg interface{}, msgType int, hash []byte, debug bool) error {
+// collectorTimestamp returns the time the collector received msg in RFC3339 format,
+// empty string is returned when the time is unknown.
+func collectorTimestamp(msg bmp.Message) string {
+	if msg.ReceivedAt.IsZero() {
+		return ""
+	}
+
+	return msg.ReceivedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// messageID returns the identifier of the message of msgType marshaled to j produced from BMP message
+// with the sequence number seq, the identifier is md5 hash of the sequence number, the type and
+// the content of the message.
+func messageID(seq uint64, msgType int, j []byte) string {
+	b := make([]byte, 0, len(j)+16)
+	b = binary.BigEndian.AppendUint64(b, seq)
+	b = binary.BigEndian.AppendUint32(b, uint32(msgType))
+	b = append(b, j...)
+
+	return fmt.Sprintf("%x", md5.Sum(b))
+}
+
+// marshalAndPublish marshals msg produced from BMP message src to JSON and publishes it. The time
+// the collector received src is attached to the JSON object as collector_timestamp field, Metadata
+// of src is attached as metadata field, when
+// the producer is configured to generate message IDs, message_id field is attached and when
+// the producer is configured to preserve raw BMP messages, the raw message is attached as base64
+// encoded raw_bmp_message field.
+func (p *producer) marshalAndPublish(msg interface{}, msgType int, hash []byte, src bmp.Message, debug bool) error {
 	j, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal a message of type %d with error: %+v", msgType, err)
 	}
-	if err := p.publisher.PublishMessage(msgType, hash, j); err != nil {
-		return fmt.Errorf("failed to push a message of type %d to kafka with error: %+v", msgType, err)
+	// The identifier is computed before fields specific to the collector are attached
+	if p.messageID {
+		j = appendField(j, "message_id", messageID(src.Sequence, msgType, j))
+	}
+	if ts := collectorTimestamp(src); ts != "" {
+		j = appendField(j, "collector_timestamp", ts)
+	}
+	if len(src.Metadata) != 0 {
+		if m, err := json.Marshal(src.Metadata); err == nil {
+			j = appendJSONField(j, "metadata", m)
+		}
+	}
+	if p.rawMessage && len(src.RawMessage) != 0 {
+		j = appendRawMessage(j, src.RawMessage)
+	}
+	ctx := context.Background()
+	if p.publishTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.publishTimeout)
+		defer cancel()
+	}
+	if err := pub.PublishMessageContext(ctx, p.publisher, p.router, msgType, hash, j); err != nil {
+		p.metrics.PublishFailed()
+		return fmt.Errorf("failed to push a message of type %d to kafka with error: %w", msgType, err)
 	}
 	if debug {
-		glog.Infof("message of type: %+v json: %s", msgType, string(j))
+		p.logger.Info("produced message", "type", msgType, "json", string(j))
 	}
 	return nil
 }
+
+// appendRawMessage adds raw_bmp_message field with base64 encoded raw to the marshaled JSON object j.
+func appendRawMessage(j []byte, raw []byte) []byte {
+	return appendField(j, "raw_bmp_message", base64.StdEncoding.EncodeToString(raw))
+}
+
+// appendField adds the field name with the string value to the marshaled JSON object j, value is not
+// escaped and must not carry characters requiring escaping.
+func appendField(j []byte, name string, value string) []byte {
+	return appendJSONField(j, name, []byte(`"`+value+`"`))
+}
+
+// appendJSONField adds the field name with the marshaled JSON value to the marshaled JSON object j.
+func appendJSONField(j []byte, name string, value []byte) []byte {
+	if len(j) < 2 || j[len(j)-1] != '}' {
+		return j
+	}
+	field := make([]byte, 0, len(name)+len(value)+5)
+	if len(j) > 2 {
+		field = append(field, ',')
+	}
+	field = append(field, '"')
+	field = append(field, name...)
+	field = append(field, `":`...)
+	field = append(field, value...)
+	field = append(field, '}')
+	r := make([]byte, 0, len(j)-1+len(field))
+	r = append(r, j[:len(j)-1]...)
+
+	return append(r, field...)
+}
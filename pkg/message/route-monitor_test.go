@@ -0,0 +1,198 @@
+package message
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceRawMessage(t *testing.T) {
+	// Termination message with Common Header, Reason TLV carrying reason 1
+	raw := []byte{3, 0, 0, 0, 12, 5, 0, 1, 0, 2, 0, 1}
+	tests := []struct {
+		name   string
+		opts   []Option
+		expect []byte
+	}{
+		{
+			name:   "disabled by default",
+			expect: nil,
+		},
+		{
+			name:   "enabled",
+			opts:   []Option{WithRawMessage()},
+			expect: raw,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, tt.opts...).(*producer)
+			tm, err := bmp.UnmarshalTerminationMessage(raw[bmp.CommonHeaderLength:])
+			if err != nil {
+				t.Fatalf("failed to unmarshal Termination message with error: %+v", err)
+			}
+			got := struct {
+				Termination
+				RawBMPMessage string `json:"raw_bmp_message"`
+			}{}
+			produceOne(t, p, publisher, bmp.Message{Payload: tm, RawMessage: raw}, &got)
+			if got.ReasonString != "Unspecified reason" {
+				t.Errorf("expected reason string %q, got %q", "Unspecified reason", got.ReasonString)
+			}
+			if tt.expect == nil {
+				if got.RawBMPMessage != "" {
+					t.Fatalf("expected no raw message, got %s", got.RawBMPMessage)
+				}
+				return
+			}
+			b, err := base64.StdEncoding.DecodeString(got.RawBMPMessage)
+			if err != nil {
+				t.Fatalf("failed to decode raw message with error: %+v", err)
+			}
+			if !bytes.Equal(b, tt.expect) {
+				t.Errorf("expected raw message %v, got %v", tt.expect, b)
+			}
+		})
+	}
+}
+
+func TestAppendRawMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		j      string
+		expect string
+	}{
+		{
+			name:   "empty object",
+			j:      `{}`,
+			expect: `{"raw_bmp_message":"AwAAAAY="}`,
+		},
+		{
+			name:   "object with fields",
+			j:      `{"action":"add"}`,
+			expect: `{"action":"add","raw_bmp_message":"AwAAAAY="}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(appendRawMessage([]byte(tt.j), []byte{3, 0, 0, 0, 6})); got != tt.expect {
+				t.Errorf("expected %s, got %s", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestProduceTimestamps(t *testing.T) {
+	received := time.Date(2023, time.November, 14, 22, 13, 21, 250000000, time.UTC)
+	tests := []struct {
+		name      string
+		timestamp []byte
+		expect    string
+	}{
+		{
+			name:      "router reports time",
+			timestamp: []byte{0x65, 0x53, 0xf1, 0x00, 0x00, 0x07, 0xa1, 0x20},
+			expect:    "2023-11-14T22:13:20.5Z",
+		},
+		{
+			name:      "router does not report time",
+			timestamp: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false).(*producer)
+			ph := perPeerHeader(t, byte(bmp.PeerType0), 0x00)
+			copy(ph.PeerTimestamp, tt.timestamp)
+			prefix := &UnicastPrefix{}
+			published := produceOne(t, p, publisher, bmp.Message{PeerHeader: ph, Payload: routeMonitor(t), ReceivedAt: received}, prefix)
+			if prefix.Timestamp != tt.expect {
+				t.Errorf("expected router timestamp %q, got %q", tt.expect, prefix.Timestamp)
+			}
+			if prefix.CollectorTimestamp != "2023-11-14T22:13:21.25Z" {
+				t.Errorf("expected collector timestamp 2023-11-14T22:13:21.25Z, got %q", prefix.CollectorTimestamp)
+			}
+			if tt.expect == "" && bytes.Contains(published.msg, []byte(`"timestamp"`)) {
+				t.Errorf("expected timestamp to be absent, got %s", published.msg)
+			}
+		})
+	}
+}
+
+func TestProduceMessageID(t *testing.T) {
+	// produce returns the message produced from Route Monitor message with the sequence number and
+	// Per-Peer Header timestamp, received by the collector at the time
+	produce := func(opts []Option, seq uint64, timestamp byte, received time.Time) *UnicastPrefix {
+		publisher := &testPublisher{}
+		p := NewProducer(publisher, false, opts...).(*producer)
+		ph := perPeerHeader(t, byte(bmp.PeerType0), 0x00)
+		ph.PeerTimestamp[3] = timestamp
+		prefix := &UnicastPrefix{}
+		produceOne(t, p, publisher, bmp.Message{PeerHeader: ph, Payload: routeMonitor(t), ReceivedAt: received, Sequence: seq}, prefix)
+		return prefix
+	}
+	opts := []Option{WithMessageID()}
+	received := time.Date(2023, time.November, 14, 22, 13, 21, 0, time.UTC)
+	id := produce(opts, 1, 1, received).MessageID
+	if len(id) != 32 {
+		t.Fatalf("expected md5 hash message id, got %q", id)
+	}
+	tests := []struct {
+		name      string
+		opts      []Option
+		seq       uint64
+		timestamp byte
+		received  time.Time
+		same      bool
+	}{
+		{
+			name:      "identical message",
+			opts:      opts,
+			seq:       1,
+			timestamp: 1,
+			received:  received,
+			same:      true,
+		},
+		{
+			name:      "identical message received later",
+			opts:      opts,
+			seq:       1,
+			timestamp: 1,
+			received:  received.Add(time.Minute),
+			same:      true,
+		},
+		{
+			name:      "different sequence",
+			opts:      opts,
+			seq:       2,
+			timestamp: 1,
+			received:  received,
+		},
+		{
+			name:      "different timestamp",
+			opts:      opts,
+			seq:       1,
+			timestamp: 2,
+			received:  received,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := produce(tt.opts, tt.seq, tt.timestamp, tt.received).MessageID
+			if tt.same && m != id {
+				t.Errorf("expected message id %q, got %q", id, m)
+			}
+			if !tt.same && (m == id || m == "") {
+				t.Errorf("expected message id different from %q, got %q", id, m)
+			}
+		})
+	}
+	if m := produce(nil, 1, 1, received).MessageID; m != "" {
+		t.Errorf("expected no message id without the option, got %q", m)
+	}
+}
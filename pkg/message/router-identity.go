@@ -0,0 +1,40 @@
+package message
+
+import (
+	"crypto/md5"
+	"fmt"
+)
+
+// RouterIdentity describes what the producer knows about the monitored router, it grows as BMP messages
+// of the session are received.
+type RouterIdentity struct {
+	// Address is the remote address of the BMP session, it is empty when the producer is instantiated
+	// without WithRouterAddress option.
+	Address string
+	// RouterIP is the local address of BGP sessions learned from Peer Up message, until Peer Up message
+	// is received it is the same as Address.
+	RouterIP string
+	// BGPID is the local BGP Identifier learned from Peer Up message
+	BGPID string
+	// SysName is sysName of Initiation message
+	SysName string
+}
+
+// RouterHashFunc derives RouterHash, used as router_hash of published messages and as their key,
+// from the identity of the monitored router. It is called every time the identity changes.
+type RouterHashFunc func(id RouterIdentity) string
+
+// DefaultRouterHash returns md5 hash of RouterIP, or an empty string when RouterIP is not known.
+func DefaultRouterHash(id RouterIdentity) string {
+	if id.RouterIP == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%x", md5.Sum([]byte(id.RouterIP)))
+}
+
+// updateRouterHash recomputes RouterHash after the router identity has changed
+func (p *producer) updateRouterHash() {
+	p.speakerIP = p.identity.RouterIP
+	p.speakerHash = p.routerHashFunc(p.identity)
+}
@@ -0,0 +1,110 @@
+package message
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// lockedPublisher stores messages published by concurrent producing workers
+type lockedPublisher struct {
+	sync.Mutex
+	testPublisher
+}
+
+func (p *lockedPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	p.Lock()
+	defer p.Unlock()
+	return p.testPublisher.PublishMessage(msgType, msgHash, msg)
+}
+
+func TestProducerRouterIdentityOrder(t *testing.T) {
+	publisher := &lockedPublisher{}
+	hashFunc := func(id RouterIdentity) string {
+		if id.SysName != "" {
+			return "sysname-" + id.SysName
+		}
+		return "address-" + id.Address
+	}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103"), WithRouterHashFunc(hashFunc))
+	im, err := bmp.UnmarshalInitiationMessage([]byte{0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Initiation message with error: %+v", err)
+	}
+	queue := make(chan bmp.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Producer(queue, stop)
+	}()
+	ph := perPeerHeader(t, byte(bmp.PeerType0), 0x00)
+	const count = 50
+	for i := 0; i < count; i++ {
+		queue <- bmp.Message{PeerHeader: ph, Payload: routeMonitor(t)}
+	}
+	queue <- bmp.Message{Payload: im}
+	for i := 0; i < count; i++ {
+		queue <- bmp.Message{PeerHeader: ph, Payload: routeMonitor(t)}
+	}
+	close(stop)
+	<-done
+	keys := make(map[string]int)
+	for _, m := range publisher.msgs {
+		keys[string(m.key)]++
+	}
+	// Messages received before Initiation message carry the hash of the address, the following ones
+	// the hash of sysName regardless of the order workers run
+	if keys["address-192.168.80.103"] != count || keys["sysname-xrv9k-r1"] != count+1 {
+		t.Fatalf("expected %d messages of address hash and %d of sysName hash, got %v", count, count+1, keys)
+	}
+}
+
+func TestProduceRouterHashFunc(t *testing.T) {
+	publisher := &testPublisher{}
+	hashFunc := func(id RouterIdentity) string {
+		if id.SysName != "" {
+			return "sysname-" + id.SysName
+		}
+		return "address-" + id.Address
+	}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103"), WithRouterHashFunc(hashFunc)).(*producer)
+	im, err := bmp.UnmarshalInitiationMessage([]byte{0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Initiation message with error: %+v", err)
+	}
+	ph := perPeerHeader(t, byte(bmp.PeerType0), 0x00)
+	p.producingWorker(bmp.Message{PeerHeader: ph, Payload: routeMonitor(t)})
+	p.producingWorker(bmp.Message{Payload: im})
+	p.producingWorker(bmp.Message{PeerHeader: ph, Payload: routeMonitor(t)})
+	expect := []struct {
+		msgType    int
+		routerHash string
+	}{
+		{msgType: bmp.UnicastPrefixMsg, routerHash: "address-192.168.80.103"},
+		{msgType: bmp.InitiationMsg, routerHash: "sysname-xrv9k-r1"},
+		{msgType: bmp.UnicastPrefixMsg, routerHash: "sysname-xrv9k-r1"},
+	}
+	if len(publisher.msgs) != len(expect) {
+		t.Fatalf("expected %d published messages, got %d", len(expect), len(publisher.msgs))
+	}
+	for i, e := range expect {
+		published := publisher.msgs[i]
+		if published.msgType != e.msgType {
+			t.Fatalf("expected message type %d, got %d", e.msgType, published.msgType)
+		}
+		if string(published.key) != e.routerHash {
+			t.Errorf("expected message key %s, got %s", e.routerHash, string(published.key))
+		}
+		var got struct {
+			RouterHash string `json:"router_hash"`
+			RouterIP   string `json:"router_ip"`
+		}
+		decodePublished(t, published, &got)
+		if got.RouterHash != e.routerHash || got.RouterIP != "192.168.80.103" {
+			t.Errorf("expected router_hash %s router_ip 192.168.80.103, got router_hash %s router_ip %s",
+				e.routerHash, got.RouterHash, got.RouterIP)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// produceRouterInfoMessage produces message from BMP Initiation Message
+func (p *producer) produceRouterInfoMessage(msg bmp.Message) {
+	im, ok := msg.Payload.(*bmp.InitiationMessage)
+	if !ok {
+		p.logger.Error("got invalid Payload type in bmp.InitiationMessage", "payload", msg.Payload)
+		return
+	}
+	m := RouterInfo{
+		RouterHash: p.speakerHash,
+		RouterIP:   p.speakerIP,
+		SysName:    im.SysName(),
+		SysDescr:   im.SysDescr(),
+		Strings:    im.Strings(),
+	}
+	if err := p.marshalAndPublish(&m, bmp.InitiationMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process Initiation message", "error", err)
+		return
+	}
+}
@@ -0,0 +1,39 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceRouterInfoMessage(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	im, err := bmp.UnmarshalInitiationMessage([]byte{
+		0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73,
+		0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49,
+		0, 0, 0, 3, 'l', 'a', 'b',
+	})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Initiation message with error: %+v", err)
+	}
+	expect := &RouterInfo{
+		RouterHash: "1f101e2bd3415b5b65f51fe19222e561",
+		RouterIP:   "192.168.80.103",
+		SysName:    "xrv9k-r1",
+		SysDescr:   " 7.2.1.23I",
+		Strings:    []string{"lab"},
+	}
+	got := &RouterInfo{}
+	published := produceOne(t, p, publisher, bmp.Message{Payload: im}, got)
+	if published.msgType != bmp.InitiationMsg {
+		t.Fatalf("expected message type %d, got %d", bmp.InitiationMsg, published.msgType)
+	}
+	if string(published.key) != expect.RouterHash {
+		t.Fatalf("expected message key %s, got %s", expect.RouterHash, string(published.key))
+	}
+	if diff := deep.Equal(expect, got); diff != nil {
+		t.Errorf("Diffs: %+v", diff)
+	}
+}
@@ -0,0 +1,58 @@
+package message
+
+import (
+	"net"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// updatesSession returns true when the BMP message carries the state of the BMP session learned by
+// learnSession, the identity of the monitored router or Add-Path capability of peers.
+func updatesSession(msg bmp.Message) bool {
+	switch msg.Payload.(type) {
+	case *bmp.InitiationMessage, *bmp.PeerUpMessage:
+		return true
+	}
+
+	return false
+}
+
+// learnSession updates the state of the BMP session from Initiation and Peer Up messages as they are received,
+// before message types, filters and transforms are applied, so dropping these messages does not lose the state.
+// The state is updated in the order BMP messages are received and only while no producing worker runs, so
+// workers read it without locking and every message is produced with the state as of the time it was received.
+func (p *producer) learnSession(msg bmp.Message) {
+	switch obj := msg.Payload.(type) {
+	case *bmp.InitiationMessage:
+		if sysName := obj.SysName(); sysName != "" && sysName != p.identity.SysName {
+			p.identity.SysName = sysName
+			p.updateRouterHash()
+		}
+	case *bmp.PeerUpMessage:
+		if msg.PeerHeader == nil {
+			return
+		}
+		// Saving local bgp speaker identities, Loc-RIB peer's local address is zero-filled,
+		// it does not identify the speaker.
+		if !msg.PeerHeader.IsLocRIB() {
+			p.identity.RouterIP = obj.GetLocalAddressString()
+			p.identity.BGPID = net.IP(obj.SentOpen.BGPID).To4().String()
+			p.updateRouterHash()
+		}
+		// Check if local router advertises AddPath Send/Receive for any AFI/SAFI,
+		// if map comes back empty no further AddPath Capability is needed
+		if lAddPath := obj.SentOpen.AddPathCapability(); len(lAddPath) != 0 {
+			// Check if remote router advertises AddPath Send/Receive for any AFI/SAFI,
+			// if map comes back empty no further AddPath Capability is needed
+			if rAddPath := obj.ReceivedOpen.AddPathCapability(); len(rAddPath) != 0 {
+				for k := range lAddPath {
+					// Enable AddPath only for AFI/SAFI types existing in both local and remote maps
+					if _, ok := rAddPath[k]; ok {
+						// AFI/SAFI type exists in both maps, which means both peers support Send/Receive of AddPath
+						p.addPathCapable[k] = true
+					}
+				}
+			}
+		}
+	}
+}
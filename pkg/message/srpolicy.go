@@ -26,11 +26,13 @@ func (p *producer) srpolicy(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, upda
 	}
 	prfx := SRPolicy{
 		Action:         operation,
+		IsWithdraw:     op == DelPrefix,
 		RouterHash:     p.speakerHash,
 		RouterIP:       p.speakerIP,
 		PeerType:       uint8(ph.PeerType),
 		PeerHash:       ph.GetPeerHash(),
 		PeerASN:        ph.PeerAS,
+		PeerBGPID:      ph.GetPeerBGPIDString(),
 		Timestamp:      ph.GetPeerTimestamp(),
 		Nexthop:        nlri.GetNextHop(),
 		BaseAttributes: update.BaseAttributes,
@@ -38,16 +40,19 @@ func (p *producer) srpolicy(nlri bgp.MPNLRI, op int, ph *bmp.PerPeerHeader, upda
 	if f, err := ph.IsAdjRIBInPost(); err == nil {
 		prfx.IsAdjRIBInPost = f
 	}
+	if f, err := ph.IsAdjRIBOut(); err == nil {
+		prfx.IsAdjRIBOut = f
+	}
 	if f, err := ph.IsAdjRIBOutPost(); err == nil {
 		prfx.IsAdjRIBOutPost = f
 	}
 	if f, err := ph.IsLocRIBFiltered(); err == nil {
 		prfx.IsLocRIBFiltered = f
 	}
-	if ases := update.BaseAttributes.ASPath; len(ases) != 0 {
-		// Last element in AS_PATH would be the AS of the origin
-		prfx.OriginAS = int32(ases[len(ases)-1])
-	}
+	prfx.IsLocRIB = ph.IsLocRIB()
+	prfx.TableName = p.tableName(ph)
+	// Last element in AS_PATH outside of confederation segments would be the AS of the origin
+	prfx.OriginAS = int32(update.BaseAttributes.OriginAS())
 	prfx.PeerIP = ph.GetPeerAddrString()
 	prfx.IsIPv4 = true
 	prfx.IsNexthopIPv4 = true
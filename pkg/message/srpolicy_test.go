@@ -0,0 +1,85 @@
+package message
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/srpolicy"
+)
+
+func TestProduceSRPolicy(t *testing.T) {
+	update := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x6A, 0x02,
+		0x00, 0x00, // Withdrawn Routes Length
+		0x00, 0x53, // Total Path Attribute Length
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x00, // AS_PATH empty
+		0xC0, 0x17, 0x30, // TUNNEL_ENCAPSULATION
+		0x00, 0x0F, 0x00, 0x2C, // Tunnel Type SR Policy
+		0x0C, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, // Preference 100
+		0x0D, 0x06, 0x00, 0x00, 0x00, 0x3E, 0x80, 0x00, // Binding SID label 1000
+		0x80, 0x00, 0x19, 0x00, // Segment List
+		0x09, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, // Weight 1
+		0x01, 0x06, 0x00, 0x00, 0x18, 0x6A, 0xA0, 0x00, // Type A Segment label 100010
+		0x01, 0x06, 0x00, 0x00, 0x05, 0xDC, 0x11, 0x00, // Type A Segment label 24001 with S bit
+		0x80, 0x0E, 0x16, // MP_REACH_NLRI
+		0x00, 0x01, 0x49, // AFI 1 SAFI 73
+		0x04, 0x0A, 0x00, 0x00, 0x01, 0x00, // Next Hop 10.0.0.1 and Reserved
+		0x60, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x63, 0x0A, 0x00, 0x00, 0x0D, // Distinguisher 2 Color 99 Endpoint 10.0.0.13
+	}
+	rm, err := bmp.UnmarshalBMPRouteMonitorMessage(update, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal Route Monitoring message with error: %+v", err)
+	}
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false).(*producer)
+	got := &SRPolicy{}
+	published := produceOne(t, p, publisher, bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: rm}, got)
+	if published.msgType != bmp.SRPolicyMsg {
+		t.Fatalf("expected message type %d, got %d", bmp.SRPolicyMsg, published.msgType)
+	}
+	if got.Action != "add" || got.Distinguisher != 2 || got.Color != 99 || !net.IP(got.Endpoint).Equal(net.IPv4(10, 0, 0, 13)) {
+		t.Errorf("expected SR Policy distinguisher 2 color 99 endpoint 10.0.0.13, got %+v", got)
+	}
+	if got.Nexthop != "10.0.0.1" {
+		t.Errorf("expected next hop 10.0.0.1, got %s", got.Nexthop)
+	}
+	if got.Preference == nil || got.Preference.Preference != 100 {
+		t.Errorf("expected preference 100, got %+v", got.Preference)
+	}
+	if got.BSID == nil || got.BSID.BSID == nil || got.BSID.BSID.GetType() != srpolicy.LABELBSID {
+		t.Fatalf("expected label Binding SID, got %+v", got.BSID)
+	}
+	if bsid := got.BSID.BSID.GetBSID(); len(bsid) != 4 || binary.BigEndian.Uint32(bsid) != 1000 {
+		t.Errorf("expected Binding SID label 1000, got %v", bsid)
+	}
+	if len(got.SegmentList) != 1 {
+		t.Fatalf("expected 1 segment list, got %d", len(got.SegmentList))
+	}
+	sl := got.SegmentList[0]
+	if sl.Weight == nil || sl.Weight.Weight != 1 {
+		t.Errorf("expected segment list weight 1, got %+v", sl.Weight)
+	}
+	expect := []struct {
+		label uint32
+		s     bool
+	}{
+		{label: 100010},
+		{label: 24001, s: true},
+	}
+	if len(sl.Segment) != len(expect) {
+		t.Fatalf("expected %d segments, got %d", len(expect), len(sl.Segment))
+	}
+	for i, e := range expect {
+		if sl.Segment[i].GetType() != srpolicy.TypeA {
+			t.Fatalf("expected segment %d of type A, got %d", i, sl.Segment[i].GetType())
+		}
+		s := sl.Segment[i].(srpolicy.TypeASegment)
+		if s.GetLabel() != e.label || s.GetS() != e.s {
+			t.Errorf("expected segment %d label %d S bit %t, got label %d S bit %t", i, e.label, e.s, s.GetLabel(), s.GetS())
+		}
+	}
+}
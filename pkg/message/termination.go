@@ -0,0 +1,27 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// produceTerminationMessage produces message from BMP Termination Message
+func (p *producer) produceTerminationMessage(msg bmp.Message) {
+	tm, ok := msg.Payload.(*bmp.TerminationMessage)
+	if !ok {
+		p.logger.Error("got invalid Payload type in bmp.TerminationMessage", "payload", msg.Payload)
+		return
+	}
+	m := Termination{
+		RouterHash:   p.speakerHash,
+		RouterIP:     p.speakerIP,
+		ReasonString: tm.ReasonString(),
+		Strings:      tm.Strings(),
+	}
+	if reason, ok := tm.Reason(); ok {
+		m.Reason = &reason
+	}
+	if err := p.marshalAndPublish(&m, bmp.TerminationMsg, []byte(m.RouterHash), msg, false); err != nil {
+		p.logger.Error("failed to process Termination message", "error", err)
+		return
+	}
+}
@@ -0,0 +1,32 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceTerminationMessage(t *testing.T) {
+	publisher := &testPublisher{}
+	p := NewProducer(publisher, false, WithRouterAddress("192.168.80.103")).(*producer)
+	tm, err := bmp.UnmarshalTerminationMessage([]byte{0, 1, 0, 2, 0, 1})
+	if err != nil {
+		t.Fatalf("failed to unmarshal Termination message with error: %+v", err)
+	}
+	reason := uint16(1)
+	expect := &Termination{
+		RouterHash:   p.speakerHash,
+		RouterIP:     "192.168.80.103",
+		Reason:       &reason,
+		ReasonString: "Unspecified reason",
+	}
+	got := &Termination{}
+	published := produceOne(t, p, publisher, bmp.Message{Payload: tm}, got)
+	if published.msgType != bmp.TerminationMsg {
+		t.Fatalf("expected message type %d, got %d", bmp.TerminationMsg, published.msgType)
+	}
+	if diff := deep.Equal(expect, got); diff != nil {
+		t.Errorf("Diffs: %+v", diff)
+	}
+}
@@ -0,0 +1,32 @@
+package message
+
+import (
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// Transform defines a function applied to every BMP message accepted by filters before it is produced,
+// the returned message is produced instead, for example with Metadata attached. The message is dropped
+// when the function returns an error.
+type Transform func(msg bmp.Message) (bmp.Message, error)
+
+// WithTransform sets transforms applied to every BMP message in the order they are set, a transform
+// receives the message returned by the previous one.
+func WithTransform(transforms ...Transform) Option {
+	return func(p *producer) {
+		p.transforms = append(p.transforms, transforms...)
+	}
+}
+
+// transform applies transforms to msg, false is returned when a transform drops the message
+func (p *producer) transform(msg bmp.Message) (bmp.Message, bool) {
+	for _, t := range p.transforms {
+		var err error
+		if msg, err = t(msg); err != nil {
+			p.logger.Warn("message dropped by transform", "error", err)
+			p.metrics.TransformDropped()
+			return msg, false
+		}
+	}
+
+	return msg, true
+}
@@ -0,0 +1,136 @@
+package message
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestProduceTransform(t *testing.T) {
+	// Metadata of routers by the peer address, as an inventory lookup would return
+	inventory := map[string]map[string]string{
+		"10.0.0.2": {"site": "lab", "role": "edge"},
+	}
+	tests := []struct {
+		name       string
+		transforms []Transform
+		metadata   map[string]string
+		published  int
+	}{
+		{
+			name: "inject metadata",
+			transforms: []Transform{func(msg bmp.Message) (bmp.Message, error) {
+				msg.Metadata = inventory[msg.PeerHeader.GetPeerAddrString()]
+				return msg, nil
+			}},
+			metadata:  map[string]string{"site": "lab", "role": "edge"},
+			published: 1,
+		},
+		{
+			name: "drop message",
+			transforms: []Transform{func(msg bmp.Message) (bmp.Message, error) {
+				return msg, errors.New("unknown router")
+			}},
+		},
+		{
+			name: "transforms applied in order",
+			transforms: []Transform{
+				func(msg bmp.Message) (bmp.Message, error) {
+					msg.Metadata = map[string]string{"site": "lab"}
+					return msg, nil
+				},
+				func(msg bmp.Message) (bmp.Message, error) {
+					msg.Metadata["role"] = "core"
+					return msg, nil
+				},
+			},
+			metadata:  map[string]string{"site": "lab", "role": "core"},
+			published: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, WithTransform(tt.transforms...)).(*producer)
+			p.producingWorker(bmp.Message{PeerHeader: perPeerHeader(t, byte(bmp.PeerType0), 0x00), Payload: routeMonitor(t)})
+			if len(publisher.msgs) != tt.published {
+				t.Fatalf("expected %d published messages, got %d", tt.published, len(publisher.msgs))
+			}
+			if tt.published == 0 {
+				return
+			}
+			got := struct {
+				Prefix   string            `json:"prefix"`
+				Metadata map[string]string `json:"metadata"`
+			}{}
+			decodePublished(t, publisher.msgs[0], &got)
+			if got.Prefix != "10.0.0.0" {
+				t.Errorf("expected prefix 10.0.0.0, got %s", got.Prefix)
+			}
+			if diff := deep.Equal(got.Metadata, tt.metadata); diff != nil {
+				t.Errorf("expected metadata %v, got %v", tt.metadata, got.Metadata)
+			}
+		})
+	}
+}
+
+func TestProduceTransformDropsPeerUp(t *testing.T) {
+	dropPeerUp := func(msg bmp.Message) (bmp.Message, error) {
+		if _, ok := msg.Payload.(*bmp.PeerUpMessage); ok {
+			return msg, errors.New("peer up is not published")
+		}
+		return msg, nil
+	}
+	tests := []struct {
+		name    string
+		produce func(p *producer, msgs ...bmp.Message)
+	}{
+		{
+			name: "producing worker",
+			produce: func(p *producer, msgs ...bmp.Message) {
+				for _, msg := range msgs {
+					p.producingWorker(msg)
+				}
+			},
+		},
+		{
+			name: "producer",
+			produce: func(p *producer, msgs ...bmp.Message) {
+				queue := make(chan bmp.Message)
+				stop := make(chan struct{})
+				done := make(chan struct{})
+				go func() {
+					p.Producer(queue, stop)
+					close(done)
+				}()
+				for _, msg := range msgs {
+					queue <- msg
+				}
+				close(stop)
+				<-done
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &testPublisher{}
+			p := NewProducer(publisher, false, WithTransform(dropPeerUp)).(*producer)
+			ph := perPeerHeader(t, byte(bmp.PeerType0), 0x00)
+			// Add-Path capability is learned from the dropped Peer Up
+			tt.produce(p, bmp.Message{PeerHeader: ph, Payload: addPathPeerUp(t)}, bmp.Message{PeerHeader: ph, Payload: addPathRouteMonitor(t)})
+			if len(publisher.msgs) != 1 {
+				t.Fatalf("expected 1 published message, got %d", len(publisher.msgs))
+			}
+			prefix := &UnicastPrefix{}
+			decodePublished(t, publisher.msgs[0], prefix)
+			if prefix.Prefix != "10.0.0.0" || prefix.PrefixLen != 8 || prefix.PathID != 16 {
+				t.Fatalf("expected prefix 10.0.0.0/8 path id 16, got %s/%d path id %d", prefix.Prefix, prefix.PrefixLen, prefix.PathID)
+			}
+			if prefix.RouterIP != "10.0.0.1" {
+				t.Errorf("expected router ip 10.0.0.1 learned from the dropped Peer Up, got %s", prefix.RouterIP)
+			}
+		})
+	}
+}
@@ -39,17 +39,30 @@ type PeerStateChange struct {
 	RemoteHolddown  int            `json:"remote_holddown,omitempty"`
 	AdvHolddown     int            `json:"adv_holddown,omitempty"`
 	BMPReason       int            `json:"bmp_reason,omitempty"`
-	BMPErrorCode    int            `json:"bmp_error_code,omitempty"`
-	BMPErrorSubCode int            `json:"bmp_error_sub_code,omitempty"`
-	ErrorText       string         `json:"error_text,omitempty"`
-	IsL3VPN         bool           `json:"is_l"`
-	IsPrepolicy     bool           `json:"is_prepolicy"`
-	IsIPv4          bool           `json:"is_ipv4"`
-	TableName       string         `json:"table_name,omitempty"`
+	BMPReasonString string         `json:"bmp_reason_string,omitempty"`
+	// Code, subcode and description of BGP Notification carried by Peer Down message
+	BMPErrorCode    int    `json:"bmp_error_code,omitempty"`
+	BMPErrorSubCode int    `json:"bmp_error_sub_code,omitempty"`
+	ErrorText       string `json:"error_text,omitempty"`
+	// FSMEvent is the code of FSM Event which closed the session without Notification
+	FSMEvent    uint16 `json:"fsm_event,omitempty"`
+	IsL3VPN     bool   `json:"is_l"`
+	IsPrepolicy bool   `json:"is_prepolicy"`
+	IsIPv4      bool   `json:"is_ipv4"`
+	TableName   string `json:"table_name,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// Capabilities of sent and received Open messages decoded into structured fields
+	AdvOpenCapabilities *bgp.OpenCapabilities `json:"adv_capabilities,omitempty"`
+	RcvOpenCapabilities *bgp.OpenCapabilities `json:"recv_capabilities,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
 }
 
 // UnicastPrefix defines a message format sent as a result of BMP Route Monitor message
@@ -59,6 +72,7 @@ type UnicastPrefix struct {
 	ID             string              `json:"_id,omitempty"`
 	Rev            string              `json:"_rev,omitempty"`
 	Action         string              `json:"action,omitempty"` // Action can be "add" or "del"
+	IsWithdraw     bool                `json:"is_withdraw,omitempty"`
 	Sequence       int                 `json:"sequence,omitempty"`
 	Hash           string              `json:"hash,omitempty"`
 	RouterHash     string              `json:"router_hash,omitempty"`
@@ -66,7 +80,9 @@ type UnicastPrefix struct {
 	BaseAttributes *bgp.BaseAttributes `json:"base_attrs,omitempty"`
 	PeerHash       string              `json:"peer_hash,omitempty"`
 	PeerIP         string              `json:"peer_ip,omitempty"`
+	PeerBGPID      string              `json:"peer_bgp_id,omitempty"`
 	PeerType       uint8               `json:"peer_type"`
+	PeerRD         string              `json:"peer_rd,omitempty"`
 	PeerASN        uint32              `json:"peer_asn,omitempty"`
 	Timestamp      string              `json:"timestamp,omitempty"`
 	Prefix         string              `json:"prefix,omitempty"`
@@ -78,10 +94,22 @@ type UnicastPrefix struct {
 	PathID         int32               `json:"path_id,omitempty"`
 	Labels         []uint32            `json:"labels,omitempty"`
 	PrefixSID      *prefixsid.PSid     `json:"prefix_sid,omitempty"`
+	// AFI is 1 for IPv4 and 2 for IPv6 prefixes
+	AFI uint16 `json:"afi"`
+	// SAFI is 1 for unicast, 2 for multicast and 4 for labeled unicast prefixes
+	SAFI uint8 `json:"safi"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // LSNode defines a structure of LS Node message
@@ -90,6 +118,7 @@ type LSNode struct {
 	ID                  string                          `json:"_id,omitempty"`
 	Rev                 string                          `json:"_rev,omitempty"`
 	Action              string                          `json:"action,omitempty"` // Action can be "add" or "del"
+	IsWithdraw          bool                            `json:"is_withdraw,omitempty"`
 	Sequence            int                             `json:"sequence,omitempty"`
 	Hash                string                          `json:"hash,omitempty"`
 	RouterHash          string                          `json:"router_hash,omitempty"`
@@ -97,9 +126,12 @@ type LSNode struct {
 	RouterIP            string                          `json:"router_ip,omitempty"`
 	PeerHash            string                          `json:"peer_hash,omitempty"`
 	PeerIP              string                          `json:"peer_ip,omitempty"`
+	PeerBGPID           string                          `json:"peer_bgp_id,omitempty"`
 	PeerType            uint8                           `json:"peer_type"`
 	PeerASN             uint32                          `json:"peer_asn,omitempty"`
 	Timestamp           string                          `json:"timestamp,omitempty"`
+	AFI                 uint16                          `json:"afi"`
+	SAFI                uint8                           `json:"safi"`
 	IGPRouterID         string                          `json:"igp_router_id,omitempty"`
 	RouterID            string                          `json:"router_id,omitempty"`
 	ASN                 uint32                          `json:"asn,omitempty"`
@@ -113,13 +145,24 @@ type LSNode struct {
 	SRCapabilities      *sr.Capability                  `json:"ls_sr_capabilities,omitempty"`
 	SRAlgorithm         []int                           `json:"sr_algorithm,omitempty"`
 	SRLocalBlock        *sr.LocalBlock                  `json:"sr_local_block,omitempty"`
+	SRGB                []sr.LabelRange                 `json:"srgb,omitempty"`
+	SRLB                []sr.LabelRange                 `json:"srlb,omitempty"`
 	SRv6CapabilitiesTLV *srv6.CapabilityTLV             `json:"srv6_capabilities_tlv,omitempty"`
 	NodeMSD             []*base.MSDTV                   `json:"node_msd,omitempty"`
 	FlexAlgoDefinition  []*bgpls.FlexAlgoDefinition     `json:"flex_algo_definition,omitempty"`
+	Opaque              []*bgpls.OpaqueTLV              `json:"opaque,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // LSLink defines a structure of LS link message
@@ -128,6 +171,7 @@ type LSLink struct {
 	ID                    string                        `json:"_id,omitempty"`
 	Rev                   string                        `json:"_rev,omitempty"`
 	Action                string                        `json:"action,omitempty"`
+	IsWithdraw            bool                          `json:"is_withdraw,omitempty"`
 	Sequence              int                           `json:"sequence,omitempty"`
 	Hash                  string                        `json:"hash,omitempty"`
 	RouterHash            string                        `json:"router_hash,omitempty"`
@@ -135,9 +179,12 @@ type LSLink struct {
 	DomainID              int64                         `json:"domain_id"`
 	PeerHash              string                        `json:"peer_hash,omitempty"`
 	PeerIP                string                        `json:"peer_ip,omitempty"`
+	PeerBGPID             string                        `json:"peer_bgp_id,omitempty"`
 	PeerType              uint8                         `json:"peer_type"`
 	PeerASN               uint32                        `json:"peer_asn,omitempty"`
 	Timestamp             string                        `json:"timestamp,omitempty"`
+	AFI                   uint16                        `json:"afi"`
+	SAFI                  uint8                         `json:"safi"`
 	IGPRouterID           string                        `json:"igp_router_id,omitempty"`
 	RouterID              string                        `json:"router_id,omitempty"`
 	LSID                  uint32                        `json:"ls_id,omitempty"`
@@ -181,16 +228,35 @@ type LSLink struct {
 	LinkMSD               []*base.MSDTV                 `json:"link_msd,omitempty"`
 	AppSpecLinkAttr       []*bgpls.AppSpecLinkAttr      `json:"app_spec_link_attr,omitempty"`
 	UnidirLinkDelay       uint32                        `json:"unidir_link_delay,omitempty"`
+	UnidirDelayAnomalous  bool                          `json:"unidir_link_delay_anomalous,omitempty"`
 	UnidirLinkDelayMinMax []uint32                      `json:"unidir_link_delay_min_max,omitempty"`
+	UnidirMinMaxAnomalous bool                          `json:"unidir_link_delay_min_max_anomalous,omitempty"`
 	UnidirDelayVariation  uint32                        `json:"unidir_delay_variation,omitempty"`
 	UnidirPacketLoss      uint32                        `json:"unidir_packet_loss,omitempty"`
+	UnidirLossAnomalous   bool                          `json:"unidir_packet_loss_anomalous,omitempty"`
 	UnidirResidualBW      uint32                        `json:"unidir_residual_bw,omitempty"`
 	UnidirAvailableBW     uint32                        `json:"unidir_available_bw,omitempty"`
 	UnidirBWUtilization   uint32                        `json:"unidir_bw_utilization,omitempty"`
+	UnidirResidualBWKbps  uint64                        `json:"unidir_residual_bw_kbps,omitempty"`
+	UnidirAvailableBWKbps uint64                        `json:"unidir_available_bw_kbps,omitempty"`
+	UnidirUtilizedBWKbps  uint64                        `json:"unidir_bw_utilization_kbps,omitempty"`
+	Opaque                []*bgpls.OpaqueTLV            `json:"opaque,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
+	// AdminGroups lists administrative groups set in AdminGroup bitmask
+	AdminGroups []uint32 `json:"admin_groups,omitempty"`
+	// LinkProtectionType carries decoded Protection Cap flags of LinkProtection
+	LinkProtectionType *bgpls.LinkProtectionType `json:"link_protection_type,omitempty"`
 }
 
 // L3VPNPrefix defines the structure of Layer 3 VPN message
@@ -199,6 +265,7 @@ type L3VPNPrefix struct {
 	ID             string              `json:"_id,omitempty"`
 	Rev            string              `json:"_rev,omitempty"`
 	Action         string              `json:"action,omitempty"` // Action can be "add" or "del"
+	IsWithdraw     bool                `json:"is_withdraw,omitempty"`
 	Sequence       int                 `json:"sequence,omitempty"`
 	Hash           string              `json:"hash,omitempty"`
 	RouterHash     string              `json:"router_hash,omitempty"`
@@ -206,25 +273,38 @@ type L3VPNPrefix struct {
 	BaseAttributes *bgp.BaseAttributes `json:"base_attrs,omitempty"`
 	PeerHash       string              `json:"peer_hash,omitempty"`
 	PeerIP         string              `json:"peer_ip,omitempty"`
+	PeerBGPID      string              `json:"peer_bgp_id,omitempty"`
 	PeerType       uint8               `json:"peer_type"`
+	PeerRD         string              `json:"peer_rd,omitempty"`
 	PeerASN        uint32              `json:"peer_asn,omitempty"`
 	Timestamp      string              `json:"timestamp,omitempty"`
 	Prefix         string              `json:"prefix,omitempty"`
 	PrefixLen      int32               `json:"prefix_len,omitempty"`
 	IsIPv4         bool                `json:"is_ipv4"`
+	AFI            uint16              `json:"afi"`
+	SAFI           uint8               `json:"safi"`
 	OriginAS       int32               `json:"origin_as,omitempty"`
 	Nexthop        string              `json:"nexthop,omitempty"`
 	ClusterList    string              `json:"cluster_list,omitempty"`
 	IsNexthopIPv4  bool                `json:"is_nexthop_ipv4"`
 	PathID         int32               `json:"path_id,omitempty"`
 	Labels         []uint32            `json:"labels,omitempty"`
+	LabelStack     []MPLSLabel         `json:"label_stack,omitempty"`
 	VPNRD          string              `json:"vpn_rd,omitempty"`
 	VPNRDType      uint16              `json:"vpn_rd_type"`
 	PrefixSID      *prefixsid.PSid     `json:"prefix_sid,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // LSPrefix defines a structure of LS Prefix message
@@ -233,6 +313,7 @@ type LSPrefix struct {
 	ID                   string                        `json:"_id,omitempty"`
 	Rev                  string                        `json:"_rev,omitempty"`
 	Action               string                        `json:"action,omitempty"`
+	IsWithdraw           bool                          `json:"is_withdraw,omitempty"`
 	Sequence             int                           `json:"sequence,omitempty"`
 	Hash                 string                        `json:"hash,omitempty"`
 	RouterHash           string                        `json:"router_hash,omitempty"`
@@ -240,9 +321,12 @@ type LSPrefix struct {
 	DomainID             int64                         `json:"domain_id"`
 	PeerHash             string                        `json:"peer_hash,omitempty"`
 	PeerIP               string                        `json:"peer_ip,omitempty"`
+	PeerBGPID            string                        `json:"peer_bgp_id,omitempty"`
 	PeerType             uint8                         `json:"peer_type"`
 	PeerASN              uint32                        `json:"peer_asn,omitempty"`
 	Timestamp            string                        `json:"timestamp,omitempty"`
+	AFI                  uint16                        `json:"afi"`
+	SAFI                 uint8                         `json:"safi"`
 	IGPRouterID          string                        `json:"igp_router_id,omitempty"`
 	RouterID             string                        `json:"router_id,omitempty"`
 	LSID                 uint32                        `json:"ls_id,omitempty"`
@@ -263,10 +347,19 @@ type LSPrefix struct {
 	PrefixAttrTLVs       *bgpls.PrefixAttrTLVs         `json:"prefix_attr_tlvs,omitempty"`
 	FlexAlgoPrefixMetric []*bgpls.FlexAlgoPrefixMetric `json:"flex_algo_prefix_metric,omitempty"`
 	SRv6Locator          *srv6.LocatorTLV              `json:"srv6_locator,omitempty"`
+	Opaque               []*bgpls.OpaqueTLV            `json:"opaque,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // LSSRv6SID defines a structure of LS SRv6 SID message
@@ -275,6 +368,7 @@ type LSSRv6SID struct {
 	ID                   string                        `json:"_id,omitempty"`
 	Rev                  string                        `json:"_rev,omitempty"`
 	Action               string                        `json:"action,omitempty"`
+	IsWithdraw           bool                          `json:"is_withdraw,omitempty"`
 	Sequence             int                           `json:"sequence,omitempty"`
 	Hash                 string                        `json:"hash,omitempty"`
 	RouterHash           string                        `json:"router_hash,omitempty"`
@@ -282,9 +376,12 @@ type LSSRv6SID struct {
 	DomainID             int64                         `json:"domain_id"`
 	PeerHash             string                        `json:"peer_hash,omitempty"`
 	PeerIP               string                        `json:"peer_ip,omitempty"`
+	PeerBGPID            string                        `json:"peer_bgp_id,omitempty"`
 	PeerType             uint8                         `json:"peer_type"`
 	PeerASN              uint32                        `json:"peer_asn,omitempty"`
 	Timestamp            string                        `json:"timestamp,omitempty"`
+	AFI                  uint16                        `json:"afi"`
+	SAFI                 uint8                         `json:"safi"`
 	IGPRouterID          string                        `json:"igp_router_id,omitempty"`
 	LocalNodeASN         uint32                        `json:"local_node_asn,omitempty"`
 	RouterID             string                        `json:"router_id,omitempty"`
@@ -308,8 +405,16 @@ type LSSRv6SID struct {
 	SRv6SIDStructure     *srv6.SIDStructure            `json:"srv6_sid_structure,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // EVPNPrefix defines the structure of EVPN message
@@ -318,6 +423,7 @@ type EVPNPrefix struct {
 	ID             string              `json:"_id,omitempty"`
 	Rev            string              `json:"_rev,omitempty"`
 	Action         string              `json:"action,omitempty"` // Action can be "add" or "del"
+	IsWithdraw     bool                `json:"is_withdraw,omitempty"`
 	Sequence       int                 `json:"sequence,omitempty"`
 	Hash           string              `json:"hash,omitempty"`
 	RouterHash     string              `json:"router_hash,omitempty"`
@@ -326,10 +432,14 @@ type EVPNPrefix struct {
 	PeerHash       string              `json:"peer_hash,omitempty"`
 	RemoteBGPID    string              `json:"remote_bgp_id,omitempty"`
 	PeerIP         string              `json:"peer_ip,omitempty"`
+	PeerBGPID      string              `json:"peer_bgp_id,omitempty"`
 	PeerType       uint8               `json:"peer_type"`
+	PeerRD         string              `json:"peer_rd,omitempty"`
 	PeerASN        uint32              `json:"peer_asn,omitempty"`
 	Timestamp      string              `json:"timestamp,omitempty"`
 	IsIPv4         bool                `json:"is_ipv4"`
+	AFI            uint16              `json:"afi"`
+	SAFI           uint8               `json:"safi"`
 	OriginAS       int32               `json:"origin_as,omitempty"`
 	Nexthop        string              `json:"nexthop,omitempty"`
 	ClusterList    string              `json:"cluster_list,omitempty"`
@@ -341,6 +451,7 @@ type EVPNPrefix struct {
 	VPNRDType      uint16              `json:"vpn_rd_type"`
 	ESI            string              `json:"eth_segment_id,omitempty"`
 	EthTag         []byte              `json:"eth_tag,omitempty"`
+	IsPerES        bool                `json:"is_per_es,omitempty"`
 	IPAddress      string              `json:"ip_address,omitempty"`
 	IPLength       uint8               `json:"ip_len,omitempty"`
 	GWAddress      string              `json:"gw_address,omitempty"`
@@ -352,8 +463,16 @@ type EVPNPrefix struct {
 	// Add to the message
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // SRPolicy defines the structure of SR Policy message
@@ -362,6 +481,7 @@ type SRPolicy struct {
 	ID             string                  `json:"_id,omitempty"`
 	Rev            string                  `json:"_rev,omitempty"`
 	Action         string                  `json:"action,omitempty"` // Action can be "add" or "del"
+	IsWithdraw     bool                    `json:"is_withdraw,omitempty"`
 	Sequence       int                     `json:"sequence,omitempty"`
 	Hash           string                  `json:"hash,omitempty"`
 	RouterHash     string                  `json:"router_hash,omitempty"`
@@ -369,10 +489,13 @@ type SRPolicy struct {
 	BaseAttributes *bgp.BaseAttributes     `json:"base_attrs,omitempty"`
 	PeerHash       string                  `json:"peer_hash,omitempty"`
 	PeerIP         string                  `json:"peer_ip,omitempty"`
+	PeerBGPID      string                  `json:"peer_bgp_id,omitempty"`
 	PeerType       uint8                   `json:"peer_type"`
 	PeerASN        uint32                  `json:"peer_asn,omitempty"`
 	Timestamp      string                  `json:"timestamp,omitempty"`
 	IsIPv4         bool                    `json:"is_ipv4"`
+	AFI            uint16                  `json:"afi"`
+	SAFI           uint8                   `json:"safi"`
 	OriginAS       int32                   `json:"origin_as,omitempty"`
 	Nexthop        string                  `json:"nexthop,omitempty"`
 	ClusterList    string                  `json:"cluster_list,omitempty"`
@@ -391,8 +514,16 @@ type SRPolicy struct {
 	SegmentList    []*srpolicy.SegmentList `json:"segment_list_subtlv,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // Flowspec defines the structure of SR Policy message
@@ -401,14 +532,18 @@ type Flowspec struct {
 	ID             string              `json:"_id,omitempty"`
 	Rev            string              `json:"_rev,omitempty"`
 	Action         string              `json:"action,omitempty"` // Action can be "add" or "del"
+	IsWithdraw     bool                `json:"is_withdraw,omitempty"`
 	Sequence       int                 `json:"sequence,omitempty"`
 	RouterIP       string              `json:"router_ip,omitempty"`
 	BaseAttributes *bgp.BaseAttributes `json:"base_attrs,omitempty"`
 	PeerIP         string              `json:"peer_ip,omitempty"`
+	PeerBGPID      string              `json:"peer_bgp_id,omitempty"`
 	PeerType       uint8               `json:"peer_type"`
 	PeerASN        uint32              `json:"peer_asn,omitempty"`
 	Timestamp      string              `json:"timestamp,omitempty"`
 	IsIPv4         bool                `json:"is_ipv4"`
+	AFI            uint16              `json:"afi"`
+	SAFI           uint8               `json:"safi"`
 	OriginAS       int32               `json:"origin_as,omitempty"`
 	Nexthop        string              `json:"nexthop,omitempty"`
 	IsNexthopIPv4  bool                `json:"is_nexthop_ipv4"`
@@ -417,32 +552,218 @@ type Flowspec struct {
 	Spec           []flowspec.Spec     `json:"spec,omitempty"`
 	// Values are assigned based on PerPeerHeader flas
 	IsAdjRIBInPost   bool `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool `json:"is_adj_rib_out"`
 	IsAdjRIBOutPost  bool `json:"is_adj_rib_out_post_policy"`
 	IsLocRIBFiltered bool `json:"is_loc_rib_filtered"`
+	IsLocRIB         bool `json:"is_loc_rib"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+	// TableName is VRF/Table Name of Loc-RIB peer learned from its Peer Up message
+	TableName string `json:"table_name,omitempty"`
 }
 
 // Stats defines a message format sent to as a result of BMP Stats Message
 type Stats struct {
-	Key                        string `json:"_key,omitempty"`
-	ID                         string `json:"_id,omitempty"`
-	Rev                        string `json:"_rev,omitempty"`
-	Sequence                   int    `json:"sequence,omitempty"`
-	RouterHash                 string `json:"router_hash,omitempty"`
-	RouterIP                   string `json:"router_ip,omitempty"`
-	PeerType                   uint8  `json:"peer_type"`
-	RemoteBGPID                string `json:"remote_bgp_id,omitempty"`
-	RemoteASN                  uint32 `json:"remote_asn,omitempty"`
-	RemoteIP                   string `json:"remote_ip,omitempty"`
-	PeerRD                     string `json:"peer_rd,omitempty"`
-	Timestamp                  string `json:"timestamp,omitempty"`
-	DuplicatePrefixs           uint32 `json:"duplicate_prefix,omitempty"`
-	DuplicateWithDraws         uint32 `json:"duplicate_withdraws,omitempty"`
-	InvalidatedDueCluster      uint32 `json:"invalidated_due_cluster,omitempty"`
-	InvalidatedDueAspath       uint32 `json:"invalidated_due_aspath,omitempty"`
-	InvalidatedDueOriginatorId uint32 `json:"invalidated_due_originator_id,omitempty"`
-	InvalidatedAsConfed        uint32 `json:"invalidated_due_asconfed,omitempty"`
-	AdjRIBsIn                  uint64 `json:"ads_rib_in,omitempty"`
-	LocalRib                   uint64 `json:"local_rib,omitempty"`
-	UpdatesAsWithdraw          uint32 `json:"updates_as_withdraw,omitempty"`
-	PrefixesAsWithdraw         uint32 `json:"prefixes_as_withdraw,omitempty"`
+	Key                        string        `json:"_key,omitempty"`
+	ID                         string        `json:"_id,omitempty"`
+	Rev                        string        `json:"_rev,omitempty"`
+	Sequence                   int           `json:"sequence,omitempty"`
+	RouterHash                 string        `json:"router_hash,omitempty"`
+	RouterIP                   string        `json:"router_ip,omitempty"`
+	PeerType                   uint8         `json:"peer_type"`
+	RemoteBGPID                string        `json:"remote_bgp_id,omitempty"`
+	RemoteASN                  uint32        `json:"remote_asn,omitempty"`
+	RemoteIP                   string        `json:"remote_ip,omitempty"`
+	PeerRD                     string        `json:"peer_rd,omitempty"`
+	Timestamp                  string        `json:"timestamp,omitempty"`
+	DuplicatePrefixs           uint32        `json:"duplicate_prefix,omitempty"`
+	DuplicateWithDraws         uint32        `json:"duplicate_withdraws,omitempty"`
+	InvalidatedDueCluster      uint32        `json:"invalidated_due_cluster,omitempty"`
+	InvalidatedDueAspath       uint32        `json:"invalidated_due_aspath,omitempty"`
+	InvalidatedDueOriginatorId uint32        `json:"invalidated_due_originator_id,omitempty"`
+	InvalidatedAsConfed        uint32        `json:"invalidated_due_asconfed,omitempty"`
+	AdjRIBsIn                  uint64        `json:"ads_rib_in,omitempty"`
+	LocalRib                   uint64        `json:"local_rib,omitempty"`
+	UpdatesAsWithdraw          uint32        `json:"updates_as_withdraw,omitempty"`
+	PrefixesAsWithdraw         uint32        `json:"prefixes_as_withdraw,omitempty"`
+	PrefixesRejected           uint32        `json:"prefixes_rejected,omitempty"`
+	DuplicateUpdates           uint32        `json:"duplicate_updates,omitempty"`
+	AdjRIBsOutPrePolicy        uint64        `json:"adj_rib_out_pre_policy,omitempty"`
+	AdjRIBsOutPostPolicy       uint64        `json:"adj_rib_out_post_policy,omitempty"`
+	PerAFISAFIAdjRIBIn         []AFISAFIStat `json:"per_afi_safi_adj_rib_in,omitempty"`
+	PerAFISAFILocRIB           []AFISAFIStat `json:"per_afi_safi_local_rib,omitempty"`
+	PerAFISAFIAdjRIBOutPre     []AFISAFIStat `json:"per_afi_safi_adj_rib_out_pre_policy,omitempty"`
+	PerAFISAFIAdjRIBOutPost    []AFISAFIStat `json:"per_afi_safi_adj_rib_out_post_policy,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// AFISAFIStat defines the number of routes of AFI/SAFI reported in BMP Stats Message
+type AFISAFIStat struct {
+	AFI    uint16 `json:"afi"`
+	SAFI   uint8  `json:"safi"`
+	Routes uint64 `json:"routes"`
+}
+
+// RouteMirror defines a message format sent as a result of BMP Route Mirroring Message
+type RouteMirror struct {
+	Key          string   `json:"_key,omitempty"`
+	ID           string   `json:"_id,omitempty"`
+	Rev          string   `json:"_rev,omitempty"`
+	Sequence     int      `json:"sequence,omitempty"`
+	RouterHash   string   `json:"router_hash,omitempty"`
+	RouterIP     string   `json:"router_ip,omitempty"`
+	PeerType     uint8    `json:"peer_type"`
+	PeerHash     string   `json:"peer_hash,omitempty"`
+	RemoteBGPID  string   `json:"remote_bgp_id,omitempty"`
+	RemoteASN    uint32   `json:"remote_asn,omitempty"`
+	RemoteIP     string   `json:"remote_ip,omitempty"`
+	PeerRD       string   `json:"peer_rd,omitempty"`
+	Timestamp    string   `json:"timestamp,omitempty"`
+	BGPMessages  [][]byte `json:"bgp_messages,omitempty"`
+	ErroredPDU   bool     `json:"errored_pdu"`
+	MessagesLost bool     `json:"messages_lost"`
+	// Notifications carries mirrored BGP Notification messages decoded into error code and subcode
+	Notifications []*bgp.NotificationMessage `json:"notifications,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// RouterInfo defines a message format sent as a result of BMP Initiation Message
+type RouterInfo struct {
+	Key        string   `json:"_key,omitempty"`
+	ID         string   `json:"_id,omitempty"`
+	Rev        string   `json:"_rev,omitempty"`
+	Sequence   int      `json:"sequence,omitempty"`
+	RouterHash string   `json:"router_hash,omitempty"`
+	RouterIP   string   `json:"router_ip,omitempty"`
+	SysName    string   `json:"sys_name,omitempty"`
+	SysDescr   string   `json:"sys_descr,omitempty"`
+	Strings    []string `json:"strings,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// Termination defines a message format sent as a result of BMP Termination Message
+type Termination struct {
+	Key          string   `json:"_key,omitempty"`
+	ID           string   `json:"_id,omitempty"`
+	Rev          string   `json:"_rev,omitempty"`
+	Sequence     int      `json:"sequence,omitempty"`
+	RouterHash   string   `json:"router_hash,omitempty"`
+	RouterIP     string   `json:"router_ip,omitempty"`
+	Reason       *uint16  `json:"reason,omitempty"`
+	ReasonString string   `json:"reason_string,omitempty"`
+	Strings      []string `json:"strings,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// ParseError defines a message format sent when a BMP message fails to be parsed
+type ParseError struct {
+	Key            string `json:"_key,omitempty"`
+	ID             string `json:"_id,omitempty"`
+	Rev            string `json:"_rev,omitempty"`
+	Sequence       int    `json:"sequence,omitempty"`
+	RouterHash     string `json:"router_hash,omitempty"`
+	RouterIP       string `json:"router_ip,omitempty"`
+	BMPMessageType *uint8 `json:"bmp_message_type,omitempty"`
+	Error          string `json:"error"`
+	MessageLength  int    `json:"message_length"`
+	Message        []byte `json:"message,omitempty"`
+	Truncated      bool   `json:"truncated,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// EndOfRIB defines a message format sent when BGP End-of-RIB marker is received from a peer,
+// it signals the peer has sent its initial routing table for AFI/SAFI
+type EndOfRIB struct {
+	Key              string `json:"_key,omitempty"`
+	ID               string `json:"_id,omitempty"`
+	Rev              string `json:"_rev,omitempty"`
+	Sequence         int    `json:"sequence,omitempty"`
+	RouterHash       string `json:"router_hash,omitempty"`
+	RouterIP         string `json:"router_ip,omitempty"`
+	PeerHash         string `json:"peer_hash,omitempty"`
+	PeerIP           string `json:"peer_ip,omitempty"`
+	PeerBGPID        string `json:"peer_bgp_id,omitempty"`
+	PeerType         uint8  `json:"peer_type"`
+	PeerRD           string `json:"peer_rd,omitempty"`
+	PeerASN          uint32 `json:"peer_asn,omitempty"`
+	Timestamp        string `json:"timestamp,omitempty"`
+	AFI              uint16 `json:"afi"`
+	SAFI             uint8  `json:"safi"`
+	IsAdjRIBInPost   bool   `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool   `json:"is_adj_rib_out"`
+	IsAdjRIBOutPost  bool   `json:"is_adj_rib_out_post_policy"`
+	IsLocRIB         bool   `json:"is_loc_rib"`
+	IsLocRIBFiltered bool   `json:"is_loc_rib_filtered"`
+	TableName        string `json:"table_name,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// PeerEvent defines a message format sent when a peer goes up or down, unlike PeerStateChange it carries
+// the same peer identity for both and Action is "up" or "down". The reason and BGP Notification are set
+// only when the peer goes down, the local address of the session only when it goes up.
+type PeerEvent struct {
+	Key              string `json:"_key,omitempty"`
+	ID               string `json:"_id,omitempty"`
+	Rev              string `json:"_rev,omitempty"`
+	Sequence         int    `json:"sequence,omitempty"`
+	Action           string `json:"action"`
+	RouterHash       string `json:"router_hash,omitempty"`
+	RouterIP         string `json:"router_ip,omitempty"`
+	PeerHash         string `json:"peer_hash,omitempty"`
+	PeerIP           string `json:"peer_ip,omitempty"`
+	PeerBGPID        string `json:"peer_bgp_id,omitempty"`
+	PeerType         uint8  `json:"peer_type"`
+	PeerRD           string `json:"peer_rd,omitempty"`
+	PeerASN          uint32 `json:"peer_asn,omitempty"`
+	PeerPort         int    `json:"peer_port,omitempty"`
+	IsIPv4           bool   `json:"is_ipv4"`
+	LocalIP          string `json:"local_ip,omitempty"`
+	LocalASN         uint32 `json:"local_asn,omitempty"`
+	LocalBGPID       string `json:"local_bgp_id,omitempty"`
+	LocalPort        int    `json:"local_port,omitempty"`
+	Timestamp        string `json:"timestamp,omitempty"`
+	IsAdjRIBInPost   bool   `json:"is_adj_rib_in_post_policy"`
+	IsAdjRIBOut      bool   `json:"is_adj_rib_out"`
+	IsAdjRIBOutPost  bool   `json:"is_adj_rib_out_post_policy"`
+	IsLocRIB         bool   `json:"is_loc_rib"`
+	IsLocRIBFiltered bool   `json:"is_loc_rib_filtered"`
+	TableName        string `json:"table_name,omitempty"`
+	BMPReason        int    `json:"bmp_reason,omitempty"`
+	BMPReasonString  string `json:"bmp_reason_string,omitempty"`
+	BMPErrorCode     int    `json:"bmp_error_code,omitempty"`
+	BMPErrorSubCode  int    `json:"bmp_error_sub_code,omitempty"`
+	ErrorText        string `json:"error_text,omitempty"`
+	FSMEvent         uint16 `json:"fsm_event,omitempty"`
+	// CollectorTimestamp is the time the collector received the BMP message
+	CollectorTimestamp string `json:"collector_timestamp,omitempty"`
+	// MessageID identifies the message for deduplication, see WithMessageID
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// MPLSLabel defines an entry of MPLS label stack carried by labeled NLRI, rfc3032. TC is Traffic Class,
+// formerly Experimental bits, BoS is set in the bottom entry of the stack.
+type MPLSLabel struct {
+	Label uint32 `json:"label"`
+	TC    uint8  `json:"tc"`
+	BoS   bool   `json:"bos"`
 }
@@ -0,0 +1,168 @@
+package message
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/bgp"
+	"github.com/sbezverk/gobmp/pkg/prefixsid"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	attrs, err := bgp.UnmarshalBGPBaseAttributes([]byte{
+		0x40, 0x01, 0x01, 0x00, // ORIGIN IGP
+		0x40, 0x02, 0x06, 0x02, 0x01, 0x00, 0x00, 0xfd, 0xe8, // AS_PATH 65000
+		0x40, 0x03, 0x04, 0x0a, 0x00, 0x00, 0x01, // NEXT_HOP 10.0.0.1
+		0x80, 0x04, 0x04, 0x00, 0x00, 0x00, 0x00, // MED 0
+		0x40, 0x05, 0x04, 0x00, 0x00, 0x00, 0x64, // LOCAL_PREF 100
+		0xc0, 0x07, 0x08, 0x00, 0x00, 0xfd, 0xe8, 0x0a, 0x00, 0x00, 0x01, // AGGREGATOR 65000 10.0.0.1
+		0xc0, 0x08, 0x04, 0xfd, 0xe8, 0x00, 0x01, // COMMUNITIES 65000:1
+		0xc0, 0x10, 0x08, 0x00, 0x02, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, // EXTENDED COMMUNITIES rt=65000:1
+		0xc0, 0x20, 0x0c, 0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, // LARGE_COMMUNITY 65000:1:2
+	}, 4)
+	if err != nil {
+		t.Fatalf("failed to unmarshal base attributes with error: %+v", err)
+	}
+	psid, err := prefixsid.UnmarshalBGPAttrPrefixSID([]byte{
+		0x01, 0x00, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, // Label Index 100
+		0x03, 0x00, 0x08, 0x00, 0x00, 0x00, 0x3e, 0x80, 0x00, 0x1f, 0x40, // Originator SRGB 16000 range 8000
+	})
+	if err != nil {
+		t.Fatalf("failed to unmarshal prefix sid with error: %+v", err)
+	}
+	srv6PSid, err := prefixsid.UnmarshalBGPAttrPrefixSID([]byte{
+		0x05, 0x00, 0x22, 0x00, 0x01, 0x00, 0x1e, 0x00, 0x20, 0x01, 0x00, 0x00, 0x00, 0x05, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x11, 0x00, 0x01, 0x00, 0x06, 0x28, 0x18, 0x10, 0x00, 0x10, 0x40, // SRv6 L3 Service SID 2001:0:5:3::
+	})
+	if err != nil {
+		t.Fatalf("failed to unmarshal srv6 prefix sid with error: %+v", err)
+	}
+	capabilities := bgp.Capability{
+		1:  {{Value: []byte{0x00, 0x01, 0x00, 0x01}, Description: "Multiprotocol Extensions for BGP-4 : afi=1 safi=1 IPv4 Unicast"}},
+		65: {{Value: []byte{0x00, 0x00, 0xfd, 0xe8}, Description: "Support for 4-octet AS number capability"}},
+	}
+	tests := []struct {
+		name string
+		msg  interface{}
+		new  func() interface{}
+	}{
+		{
+			name: "unicast prefix",
+			msg: &UnicastPrefix{
+				Action:         "add",
+				RouterHash:     "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6",
+				RouterIP:       "192.168.80.103",
+				BaseAttributes: attrs,
+				PeerIP:         "10.0.0.2",
+				PeerBGPID:      "10.0.0.2",
+				PeerASN:        65000,
+				Prefix:         "10.0.0.0",
+				PrefixLen:      8,
+				IsIPv4:         true,
+				OriginAS:       65000,
+				Nexthop:        "10.0.0.1",
+				IsNexthopIPv4:  true,
+				Labels:         []uint32{24000},
+				PrefixSID:      psid,
+				AFI:            1,
+				SAFI:           4,
+			},
+			new: func() interface{} { return &UnicastPrefix{} },
+		},
+		{
+			name: "unicast prefix with srv6 l3 service",
+			msg: &UnicastPrefix{
+				Action:     "add",
+				RouterHash: "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6",
+				PeerIP:     "2001:db8::2",
+				Prefix:     "2001:db8:1::",
+				PrefixLen:  64,
+				Nexthop:    "2001:db8::1",
+				PrefixSID:  srv6PSid,
+				AFI:        2,
+				SAFI:       1,
+			},
+			new: func() interface{} { return &UnicastPrefix{} },
+		},
+		{
+			name: "peer up",
+			msg: &PeerStateChange{
+				Action:              "add",
+				RouterHash:          "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6",
+				RemoteBGPID:         "10.0.0.2",
+				RouterIP:            "10.0.0.1",
+				RemoteASN:           65000,
+				RemoteIP:            "10.0.0.2",
+				RemotePort:          50000,
+				LocalASN:            65000,
+				LocalIP:             "10.0.0.1",
+				LocalPort:           179,
+				LocalBGPID:          "10.0.0.1",
+				AdvCapabilities:     capabilities,
+				RcvCapabilities:     capabilities,
+				RemoteHolddown:      180,
+				AdvHolddown:         180,
+				IsIPv4:              true,
+				AdvOpenCapabilities: bgp.DecodeCapabilities(capabilities),
+				RcvOpenCapabilities: bgp.DecodeCapabilities(capabilities),
+			},
+			new: func() interface{} { return &PeerStateChange{} },
+		},
+		{
+			name: "peer down",
+			msg: &PeerStateChange{
+				Action:      "down",
+				RouterHash:  "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6",
+				RemoteBGPID: "10.0.0.2",
+				RouterIP:    "10.0.0.1",
+				RemoteASN:   65000,
+				RemoteIP:    "10.0.0.2",
+				BMPReason:   1,
+				InfoData:    []byte{0x01, 0x06, 0x00, 0x00, 0x00, 0x00},
+				IsIPv4:      true,
+			},
+			new: func() interface{} { return &PeerStateChange{} },
+		},
+		{
+			name: "stats",
+			msg: &Stats{
+				RouterHash:       "4f4ecd3d20e7d8e9c0d4f1dd0d1ae5c6",
+				RouterIP:         "10.0.0.1",
+				RemoteBGPID:      "10.0.0.2",
+				RemoteASN:        65000,
+				RemoteIP:         "10.0.0.2",
+				PrefixesRejected: 3,
+				AdjRIBsIn:        1000,
+				PerAFISAFIAdjRIBIn: []AFISAFIStat{
+					{AFI: 1, SAFI: 1, Routes: 800},
+					{AFI: 2, SAFI: 1, Routes: 200},
+				},
+			},
+			new: func() interface{} { return &Stats{} },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.msg)
+			if err != nil {
+				t.Fatalf("failed to marshal message with error: %+v", err)
+			}
+			got := tt.new()
+			if err := json.Unmarshal(b, got); err != nil {
+				t.Fatalf("failed to unmarshal message with error: %+v", err)
+			}
+			if diff := deep.Equal(tt.msg, got); diff != nil {
+				t.Errorf("Diffs: %+v", diff)
+			}
+			rb, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("failed to marshal unmarshaled message with error: %+v", err)
+			}
+			if !bytes.Equal(b, rb) {
+				t.Errorf("expected json %s, got %s", b, rb)
+			}
+		})
+	}
+}
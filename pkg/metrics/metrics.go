@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+const namespace = "gobmp"
+
+// Metrics defines a set of gobmp collectors, all methods are safe to call
+// on nil Metrics, in this case they do nothing.
+type Metrics struct {
+	messagesReceived   *prometheus.CounterVec
+	bytesRead          *prometheus.CounterVec
+	parseErrors        prometheus.Counter
+	activeSessions     prometheus.Gauge
+	refusedConnections prometheus.Counter
+	publishFailures    prometheus.Counter
+	droppedMessages    prometheus.Counter
+	queueDepth         prometheus.Gauge
+	queueBlocked       prometheus.Counter
+	transformDropped   prometheus.Counter
+	unknownAttributes  *prometheus.CounterVec
+}
+
+// NewMetrics instantiates gobmp collectors and registers them with the registerer,
+// collected metrics can be exposed by wiring promhttp.HandlerFor the corresponding gatherer.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_received_total",
+			Help:      "Number of BMP messages received per BMP message type.",
+		}, []string{"type"}),
+		bytesRead: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bytes_read_total",
+			Help:      "Number of bytes read per BMP client.",
+		}, []string{"client"}),
+		parseErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "parse_errors_total",
+			Help:      "Number of BMP messages which failed to be parsed.",
+		}),
+		activeSessions: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "active_sessions",
+			Help:      "Number of active BMP sessions.",
+		}),
+		refusedConnections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "refused_connections_total",
+			Help:      "Number of BMP connections refused because of the connections limit.",
+		}),
+		publishFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "publish_failures_total",
+			Help:      "Number of messages the publisher failed to publish.",
+		}),
+		droppedMessages: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "dropped_messages_total",
+			Help:      "Number of BMP messages dropped because the producer queue was full.",
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "producer_queue_depth",
+			Help:      "Number of BMP messages waiting in producer queues of all sessions to be published.",
+		}),
+		queueBlocked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "producer_queue_blocked_total",
+			Help:      "Number of times adding a BMP message to a full producer queue blocked longer than the threshold.",
+		}),
+		transformDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transform_dropped_messages_total",
+			Help:      "Number of BMP messages dropped because a producer transform failed.",
+		}),
+		unknownAttributes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "unknown_path_attributes_total",
+			Help:      "Number of path attributes not decoded by gobmp per attribute type code.",
+		}, []string{"type"}),
+	}
+	for _, c := range []prometheus.Collector{
+		m.messagesReceived,
+		m.bytesRead,
+		m.parseErrors,
+		m.activeSessions,
+		m.refusedConnections,
+		m.publishFailures,
+		m.droppedMessages,
+		m.queueDepth,
+		m.queueBlocked,
+		m.transformDropped,
+		m.unknownAttributes,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// MessageReceived increments the number of received BMP messages of type t
+func (m *Metrics) MessageReceived(t byte) {
+	if m == nil {
+		return
+	}
+	m.messagesReceived.WithLabelValues(messageTypeName(t)).Inc()
+}
+
+// BytesRead adds n to the number of bytes read from the client
+func (m *Metrics) BytesRead(client string, n int) {
+	if m == nil {
+		return
+	}
+	m.bytesRead.WithLabelValues(client).Add(float64(n))
+}
+
+// ParseError increments the number of BMP messages failed to be parsed
+func (m *Metrics) ParseError() {
+	if m == nil {
+		return
+	}
+	m.parseErrors.Inc()
+}
+
+// SessionUp increments the number of active BMP sessions
+func (m *Metrics) SessionUp() {
+	if m == nil {
+		return
+	}
+	m.activeSessions.Inc()
+}
+
+// SessionDown decrements the number of active BMP sessions
+func (m *Metrics) SessionDown() {
+	if m == nil {
+		return
+	}
+	m.activeSessions.Dec()
+}
+
+// ConnectionRefused increments the number of refused BMP connections
+func (m *Metrics) ConnectionRefused() {
+	if m == nil {
+		return
+	}
+	m.refusedConnections.Inc()
+}
+
+// PublishFailed increments the number of messages failed to be published
+func (m *Metrics) PublishFailed() {
+	if m == nil {
+		return
+	}
+	m.publishFailures.Inc()
+}
+
+// MessageDropped increments the number of BMP messages dropped because the producer queue was full
+func (m *Metrics) MessageDropped() {
+	if m == nil {
+		return
+	}
+	m.droppedMessages.Inc()
+}
+
+// MessageQueued increments the number of BMP messages waiting in producer queues
+func (m *Metrics) MessageQueued() {
+	if m == nil {
+		return
+	}
+	m.queueDepth.Inc()
+}
+
+// MessageDequeued decrements the number of BMP messages waiting in producer queues
+func (m *Metrics) MessageDequeued() {
+	if m == nil {
+		return
+	}
+	m.queueDepth.Dec()
+}
+
+// QueueBlocked increments the number of times adding a BMP message to a full producer queue blocked
+// longer than the threshold
+func (m *Metrics) QueueBlocked() {
+	if m == nil {
+		return
+	}
+	m.queueBlocked.Inc()
+}
+
+// TransformDropped increments the number of BMP messages dropped because a producer transform failed
+func (m *Metrics) TransformDropped() {
+	if m == nil {
+		return
+	}
+	m.transformDropped.Inc()
+}
+
+// UnknownAttribute increments the number of path attributes of type code t not decoded by gobmp
+func (m *Metrics) UnknownAttribute(t uint8) {
+	if m == nil {
+		return
+	}
+	m.unknownAttributes.WithLabelValues(strconv.Itoa(int(t))).Inc()
+}
+
+func messageTypeName(t byte) string {
+	switch t {
+	case bmp.RouteMonitorMsg:
+		return "route_monitor"
+	case bmp.StatsReportMsg:
+		return "stats_report"
+	case bmp.PeerDownMsg:
+		return "peer_down"
+	case bmp.PeerUpMsg:
+		return "peer_up"
+	case bmp.InitiationMsg:
+		return "initiation"
+	case bmp.TerminationMsg:
+		return "termination"
+	case bmp.RouteMirrorMsg:
+		return "route_mirror"
+	default:
+		return strconv.Itoa(int(t))
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestMetrics(t *testing.T) {
+	m, err := NewMetrics(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("failed to instantiate metrics with error: %+v", err)
+	}
+	m.MessageReceived(bmp.PeerUpMsg)
+	m.MessageReceived(bmp.RouteMonitorMsg)
+	m.MessageReceived(bmp.RouteMonitorMsg)
+	m.BytesRead("192.0.2.1", 100)
+	m.BytesRead("192.0.2.1", 20)
+	m.SessionUp()
+	m.SessionUp()
+	m.SessionDown()
+	m.ParseError()
+	m.PublishFailed()
+	m.ConnectionRefused()
+	m.MessageDropped()
+	m.MessageDropped()
+	m.MessageQueued()
+	m.MessageQueued()
+	m.MessageDequeued()
+	m.QueueBlocked()
+	m.TransformDropped()
+	m.UnknownAttribute(199)
+	m.UnknownAttribute(199)
+
+	tests := []struct {
+		name   string
+		c      prometheus.Collector
+		expect float64
+	}{
+		{
+			name:   "peer up messages",
+			c:      m.messagesReceived.WithLabelValues("peer_up"),
+			expect: 1,
+		},
+		{
+			name:   "route monitor messages",
+			c:      m.messagesReceived.WithLabelValues("route_monitor"),
+			expect: 2,
+		},
+		{
+			name:   "bytes read",
+			c:      m.bytesRead.WithLabelValues("192.0.2.1"),
+			expect: 120,
+		},
+		{
+			name:   "active sessions",
+			c:      m.activeSessions,
+			expect: 1,
+		},
+		{
+			name:   "parse errors",
+			c:      m.parseErrors,
+			expect: 1,
+		},
+		{
+			name:   "publish failures",
+			c:      m.publishFailures,
+			expect: 1,
+		},
+		{
+			name:   "refused connections",
+			c:      m.refusedConnections,
+			expect: 1,
+		},
+		{
+			name:   "dropped messages",
+			c:      m.droppedMessages,
+			expect: 2,
+		},
+		{
+			name:   "queue depth",
+			c:      m.queueDepth,
+			expect: 1,
+		},
+		{
+			name:   "queue blocked",
+			c:      m.queueBlocked,
+			expect: 1,
+		},
+		{
+			name:   "transform dropped messages",
+			c:      m.transformDropped,
+			expect: 1,
+		},
+		{
+			name:   "unknown path attributes",
+			c:      m.unknownAttributes.WithLabelValues("199"),
+			expect: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v := testutil.ToFloat64(tt.c); v != tt.expect {
+				t.Fatalf("expected %f but got %f", tt.expect, v)
+			}
+		})
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.MessageReceived(bmp.PeerUpMsg)
+	m.BytesRead("192.0.2.1", 100)
+	m.SessionUp()
+	m.SessionDown()
+	m.ParseError()
+	m.PublishFailed()
+	m.ConnectionRefused()
+	m.MessageDropped()
+	m.MessageQueued()
+	m.MessageDequeued()
+	m.QueueBlocked()
+	m.UnknownAttribute(199)
+}
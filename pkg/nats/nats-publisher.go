@@ -2,6 +2,7 @@ package nats
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang/glog"
@@ -16,6 +17,9 @@ const (
 	unicastMessageTopic    = "gobmp.parsed.unicast_prefix"
 	unicastMessageV4Topic  = "gobmp.parsed.unicast_prefix_v4"
 	unicastMessageV6Topic  = "gobmp.parsed.unicast_prefix_v6"
+	multicastTopic         = "gobmp.parsed.multicast_prefix"
+	multicastV4Topic       = "gobmp.parsed.multicast_prefix_v4"
+	multicastV6Topic       = "gobmp.parsed.multicast_prefix_v6"
 	lsNodeMessageTopic     = "gobmp.parsed.ls_node"
 	lsLinkMessageTopic     = "gobmp.parsed.ls_link"
 	l3vpnMessageTopic      = "gobmp.parsed.l3vpn"
@@ -31,6 +35,21 @@ const (
 	flowspecMessageV4Topic = "gobmp.parsed.flowspec_v4"
 	flowspecMessageV6Topic = "gobmp.parsed.flowspec_v6"
 	statsMessageTopic      = "gobmp.parsed.statistics"
+	routeMirrorTopic       = "gobmp.parsed.route_mirror"
+	routerInfoTopic        = "gobmp.parsed.router_info"
+	terminationTopic       = "gobmp.parsed.termination"
+	parseErrorTopic        = "gobmp.parsed.parse_error"
+	endOfRIBTopic          = "gobmp.parsed.end_of_rib"
+	peerEventTopic         = "gobmp.parsed.peer_event"
+)
+
+const (
+	// defaultStream is the name of JetStream stream storing published messages
+	defaultStream = "GOBMP"
+	// defaultSubjectPrefix is the prefix of subjects messages are published to
+	defaultSubjectPrefix = "gobmp.parsed"
+	// defaultAckTimeout is the time to wait for JetStream to acknowledge a published message
+	defaultAckTimeout = 5 * time.Second
 )
 
 var (
@@ -39,9 +58,41 @@ var (
 	waitReconnect = time.Second
 )
 
+// Option defines a function which modifies optional parameters of NATS publisher
+type Option func(*publisher)
+
+// WithStream sets the name of JetStream stream storing published messages. An existing stream is used as is and
+// must capture the publisher's subjects. When the stream does not exist, but another stream captures subjects
+// of the publisher, for example a stream created by the operator, messages are stored in that stream, otherwise
+// the stream is created with file storage.
+func WithStream(name string) Option {
+	return func(p *publisher) {
+		p.stream = name
+	}
+}
+
+// WithSubjectPrefix replaces "gobmp.parsed" prefix of subjects messages are published to, for example
+// with prefix "bmp.r1" peer messages are published to "bmp.r1.peer".
+func WithSubjectPrefix(prefix string) Option {
+	return func(p *publisher) {
+		p.prefix = prefix
+	}
+}
+
+// WithAckTimeout sets the time to wait for JetStream to acknowledge a published message, PublishMessage returns
+// an error when the message is not acknowledged in time and the message may not be stored.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(p *publisher) {
+		p.ackTimeout = timeout
+	}
+}
+
 type publisher struct {
-	nc *nats.Conn
-	js nats.JetStreamContext
+	nc         *nats.Conn
+	js         nats.JetStreamContext
+	stream     string
+	prefix     string
+	ackTimeout time.Duration
 }
 
 func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
@@ -54,6 +105,12 @@ func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
 		return p.produceMessage(unicastMessageV4Topic, key, msg)
 	case bmp.UnicastPrefixV6Msg:
 		return p.produceMessage(unicastMessageV6Topic, key, msg)
+	case bmp.MulticastPrefixMsg:
+		return p.produceMessage(multicastTopic, key, msg)
+	case bmp.MulticastPrefixV4Msg:
+		return p.produceMessage(multicastV4Topic, key, msg)
+	case bmp.MulticastPrefixV6Msg:
+		return p.produceMessage(multicastV6Topic, key, msg)
 	case bmp.LSNodeMsg:
 		return p.produceMessage(lsNodeMessageTopic, key, msg)
 	case bmp.LSLinkMsg:
@@ -84,58 +141,117 @@ func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
 		return p.produceMessage(flowspecMessageV6Topic, key, msg)
 	case bmp.StatsReportMsg:
 		return p.produceMessage(statsMessageTopic, key, msg)
+	case bmp.RouteMirrorMsg:
+		return p.produceMessage(routeMirrorTopic, key, msg)
+	case bmp.InitiationMsg:
+		return p.produceMessage(routerInfoTopic, key, msg)
+	case bmp.TerminationMsg:
+		return p.produceMessage(terminationTopic, key, msg)
+	case bmp.ParseErrorMsg:
+		return p.produceMessage(parseErrorTopic, key, msg)
+	case bmp.EndOfRIBMsg:
+		return p.produceMessage(endOfRIBTopic, key, msg)
+	case bmp.PeerEventMsg:
+		return p.produceMessage(peerEventTopic, key, msg)
 	}
 
 	return fmt.Errorf("not implemented")
 }
 
-func (p *publisher) produceMessage(subject string, key []byte, data []byte) error {
+func (p *publisher) produceMessage(topic string, key []byte, data []byte) error {
 	// use the header to pass the hash key
 	header := nats.Header{}
 	header.Set("Hash", string(key))
 
 	msg := &nats.Msg{
-		Subject: subject,
+		Subject: p.subject(topic),
 		Header:  header,
 		Data:    data,
 	}
 
-	_, err := p.js.PublishMsg(msg)
-	if err != nil {
-		return err
+	// PublishMsg waits for JetStream acknowledgement, the message is stored when no error is returned
+	if _, err := p.js.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish message to %s with error: %w", msg.Subject, err)
 	}
 
 	return nil
 }
 
+// subject returns the subject of the topic with the publisher's subject prefix
+func (p *publisher) subject(topic string) string {
+	return p.prefix + strings.TrimPrefix(topic, defaultSubjectPrefix)
+}
+
+// ensureStream creates the publisher's stream capturing all subjects with the publisher's prefix,
+// when neither the stream nor another stream capturing the subjects exists.
+func (p *publisher) ensureStream() error {
+	_, err := p.js.StreamInfo(p.stream)
+	if err == nil {
+		return nil
+	}
+	if err != nats.ErrStreamNotFound {
+		return err
+	}
+	// Adding a stream overlapping subjects of an existing one fails, the existing stream stores messages
+	name, err := p.js.StreamNameBySubject(p.prefix + ".>")
+	if err == nil {
+		glog.Infof("JetStream stream %s does not exist, messages are stored in stream %s capturing subjects %s.>", p.stream, name, p.prefix)
+		p.stream = name
+		return nil
+	}
+	if err != nats.ErrNoMatchingStream {
+		return err
+	}
+	_, err = p.js.AddStream(&nats.StreamConfig{
+		Name:     p.stream,
+		Subjects: []string{p.prefix + ".>"},
+		Storage:  nats.FileStorage,
+	})
+
+	return err
+}
+
 func (p *publisher) Stop() {
 	p.nc.Close()
 }
 
-// NewPublisher instantiates a new instance of a NATS publisher
-func NewPublisher(natsSrv string) (pub.Publisher, error) {
+// NewPublisher instantiates a new instance of a NATS publisher, messages are published to JetStream stream
+// and each message is acknowledged by the server once it is stored.
+func NewPublisher(natsSrv string, opts ...Option) (pub.Publisher, error) {
 	glog.Infof("Initializing NATS producer client")
+	p := &publisher{
+		stream:     defaultStream,
+		prefix:     defaultSubjectPrefix,
+		ackTimeout: defaultAckTimeout,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
 
-	opts := []nats.Option{
+	natsOpts := []nats.Option{
 		nats.Name("gobmp-producer"),
 		nats.MaxReconnects(maxReconnects),
 		nats.ReconnectWait(waitReconnect),
 		nats.Timeout(natsTimeout),
 	}
 
-	nc, err := nats.Connect(natsSrv, opts...)
+	nc, err := nats.Connect(natsSrv, natsOpts...)
 	if err != nil {
 		return nil, err
 	}
+	p.nc = nc
 
 	// Create a JetStream context
-	js, err := nc.JetStream()
+	p.js, err = nc.JetStream(nats.MaxWait(p.ackTimeout))
 	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	if err := p.ensureStream(); err != nil {
+		glog.Errorf("failed to ensure JetStream stream %s with error: %+v", p.stream, err)
+		nc.Close()
 		return nil, err
 	}
 
-	return &publisher{
-		nc: nc,
-		js: js,
-	}, nil
+	return p, nil
 }
@@ -0,0 +1,153 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// runServer starts in-process JetStream enabled NATS server storing streams in storeDir
+func runServer(t *testing.T, storeDir string) *server.Server {
+	t.Helper()
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		NoSigs:    true,
+		JetStream: true,
+		StoreDir:  storeDir,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NATS server with error: %+v", err)
+	}
+	go s.Start()
+	if !s.ReadyForConnections(5 * time.Second) {
+		s.Shutdown()
+		t.Fatalf("NATS server is not ready for connections")
+	}
+
+	return s
+}
+
+func TestPublishMessageStored(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		stream  string
+		subject string
+	}{
+		{
+			name:    "default stream and subject",
+			stream:  defaultStream,
+			subject: "gobmp.parsed.peer",
+		},
+		{
+			name:    "custom stream and subject prefix",
+			opts:    []Option{WithStream("BMP_R1"), WithSubjectPrefix("bmp.r1")},
+			stream:  "BMP_R1",
+			subject: "bmp.r1.peer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeDir := t.TempDir()
+			s := runServer(t, storeDir)
+			p, err := NewPublisher(s.ClientURL(), tt.opts...)
+			if err != nil {
+				s.Shutdown()
+				t.Fatalf("failed to create NATS publisher with error: %+v", err)
+			}
+			if err := p.PublishMessage(bmp.PeerStateChangeMsg, []byte("router_hash"), []byte(`{"action":"add"}`)); err != nil {
+				t.Fatalf("failed to publish message with error: %+v", err)
+			}
+			p.Stop()
+			s.Shutdown()
+			s.WaitForShutdown()
+
+			// The acknowledged message must survive the server restart
+			s = runServer(t, storeDir)
+			defer s.Shutdown()
+			nc, err := nats.Connect(s.ClientURL())
+			if err != nil {
+				t.Fatalf("failed to connect to NATS server with error: %+v", err)
+			}
+			defer nc.Close()
+			js, err := nc.JetStream()
+			if err != nil {
+				t.Fatalf("failed to create JetStream context with error: %+v", err)
+			}
+			msg, err := js.GetLastMsg(tt.stream, tt.subject)
+			if err != nil {
+				t.Fatalf("failed to get stored message with error: %+v", err)
+			}
+			if string(msg.Data) != `{"action":"add"}` {
+				t.Errorf("expected stored message %s, got %s", `{"action":"add"}`, string(msg.Data))
+			}
+			if hash := msg.Header.Get("Hash"); hash != "router_hash" {
+				t.Errorf("expected Hash header router_hash, got %s", hash)
+			}
+		})
+	}
+}
+
+func TestPublishMessageAckTimeout(t *testing.T) {
+	s := runServer(t, t.TempDir())
+	p, err := NewPublisher(s.ClientURL(), WithAckTimeout(100*time.Millisecond))
+	if err != nil {
+		s.Shutdown()
+		t.Fatalf("failed to create NATS publisher with error: %+v", err)
+	}
+	defer p.Stop()
+	s.Shutdown()
+	s.WaitForShutdown()
+	start := time.Now()
+	if err := p.PublishMessage(bmp.PeerStateChangeMsg, []byte("router_hash"), []byte(`{"action":"add"}`)); err == nil {
+		t.Fatalf("expected to fail when the message is not acknowledged but succeeded")
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("expected to fail within ack timeout, failed after %s", d)
+	}
+}
+
+func TestPublishMessageExistingStream(t *testing.T) {
+	s := runServer(t, t.TempDir())
+	defer s.Shutdown()
+	nc, err := nats.Connect(s.ClientURL())
+	if err != nil {
+		t.Fatalf("failed to connect to NATS server with error: %+v", err)
+	}
+	defer nc.Close()
+	js, err := nc.JetStream()
+	if err != nil {
+		t.Fatalf("failed to create JetStream context with error: %+v", err)
+	}
+	// Stream created by the operator before the publisher stored messages in its own stream
+	if _, err := js.AddStream(&nats.StreamConfig{
+		Name:     "BMP",
+		Subjects: []string{"gobmp.parsed.*"},
+		Storage:  nats.MemoryStorage,
+	}); err != nil {
+		t.Fatalf("failed to add stream with error: %+v", err)
+	}
+	p, err := NewPublisher(s.ClientURL())
+	if err != nil {
+		t.Fatalf("failed to create NATS publisher with error: %+v", err)
+	}
+	defer p.Stop()
+	if err := p.PublishMessage(bmp.PeerStateChangeMsg, []byte("router_hash"), []byte(`{"action":"add"}`)); err != nil {
+		t.Fatalf("failed to publish message with error: %+v", err)
+	}
+	if _, err := js.StreamInfo(defaultStream); err != nats.ErrStreamNotFound {
+		t.Errorf("expected stream %s not to be created, got error %v", defaultStream, err)
+	}
+	msg, err := js.GetLastMsg("BMP", "gobmp.parsed.peer")
+	if err != nil {
+		t.Fatalf("failed to get stored message with error: %+v", err)
+	}
+	if string(msg.Data) != `{"action":"add"}` {
+		t.Errorf("expected stored message %s, got %s", `{"action":"add"}`, string(msg.Data))
+	}
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+// parseJob is a message received from the queue, result is closed once the message is parsed
+type parseJob struct {
+	b      []byte
+	seq    uint64
+	msgs   []bmp.Message
+	err    error
+	result chan struct{}
+}
+
+// parseOrdered parses messages received from the queue by p.concurrency workers and sends parsed messages
+// to producerQueue in the order they are received. Jobs are queued to the workers and to the sender
+// in the same order, the sender waits for every job to be parsed before sending it, so jobs parsed ahead
+// of a slow one are buffered until it is sent. When stopped, parseOrdered returns once all received
+// messages are sent.
+func (p *parser) parseOrdered(queue chan []byte, producerQueue chan bmp.Message, stop chan struct{}) {
+	jobs := make(chan *parseJob, p.concurrency)
+	pending := make(chan *parseJob, 2*p.concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < p.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.err = parseMessages(job.b, p.logger, job.seq, func(bmpMsg bmp.Message) {
+					job.msgs = append(job.msgs, bmpMsg)
+				})
+				close(job.result)
+			}
+		}()
+	}
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for job := range pending {
+			<-job.result
+			for _, bmpMsg := range job.msgs {
+				producerQueue <- bmpMsg
+			}
+			if job.err != nil {
+				p.metrics.ParseError()
+				// Producer decides whether the failure is published
+				producerQueue <- bmp.Message{Payload: &bmp.ParseError{Message: job.b, Err: job.err}, ReceivedAt: time.Now(), Sequence: job.seq}
+			}
+		}
+	}()
+	// seq numbers messages in the order they are received from the queue
+	var seq uint64
+	for {
+		select {
+		case msg := <-queue:
+			seq++
+			job := &parseJob{b: msg, seq: seq, result: make(chan struct{})}
+			// The job is queued to the sender first, so it is sent in order even when parsed right away
+			pending <- job
+			jobs <- job
+		case <-stop:
+			p.logger.Info("received interrupt, stopping.")
+			close(jobs)
+			close(pending)
+			wg.Wait()
+			<-sent
+			return
+		}
+	}
+}
@@ -1,110 +1,281 @@
 package parser
 
 import (
-	"github.com/golang/glog"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
 	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/logging"
+	"github.com/sbezverk/gobmp/pkg/metrics"
 	"github.com/sbezverk/tools"
 )
 
-// Parser dispatches workers upon request received from the channel
-func Parser(queue chan []byte, producerQueue chan bmp.Message, stop chan struct{}) {
+type parser struct {
+	metrics *metrics.Metrics
+	logger  *slog.Logger
+	// If concurrency is not 0, Parser parses messages by concurrency workers and sends them to the producer
+	// in the order they are received
+	concurrency int
+}
+
+// Option defines a function which modifies optional parameters of the parser
+type Option func(*parser)
+
+// WithMetrics sets metrics collectors used to count messages failed to be parsed
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(p *parser) {
+		p.metrics = m
+	}
+}
+
+// WithLogger sets the logger of the parser, by default and when logger is nil records are written to glog
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *parser) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+// WithConcurrency makes Parser parse messages by a pool of n workers and send parsed messages to the producer
+// in the order they are received from the queue, while messages following a slow one are parsed. At most 2*n
+// messages are parsed or wait for preceding messages to be sent, so reading from the queue is paused when
+// the producer falls behind. By default every message is parsed by a dedicated worker and parsed messages
+// are sent in the order parsing completes.
+func WithConcurrency(n int) Option {
+	return func(p *parser) {
+		p.concurrency = n
+	}
+}
+
+// Parser dispatches workers upon request received from the channel,
+// when stopped, Parser returns once all dispatched workers are done.
+func Parser(queue chan []byte, producerQueue chan bmp.Message, stop chan struct{}, opts ...Option) {
+	p := &parser{
+		logger: logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.concurrency > 0 {
+		p.parseOrdered(queue, producerQueue, stop)
+		return
+	}
+	var wg sync.WaitGroup
+	// seq numbers messages in the order they are received from the queue
+	var seq uint64
 	for {
 		select {
 		case msg := <-queue:
-			go parsingWorker(msg, producerQueue)
+			seq++
+			wg.Add(1)
+			go func(seq uint64) {
+				defer wg.Done()
+				if err := parsingWorker(msg, producerQueue, p.logger, seq); err != nil {
+					p.metrics.ParseError()
+					// Producer decides whether the failure is published
+					producerQueue <- bmp.Message{Payload: &bmp.ParseError{Message: msg, Err: err}, ReceivedAt: time.Now(), Sequence: seq}
+				}
+			}(seq)
 		case <-stop:
-			glog.Infof("received interrupt, stopping.")
+			p.logger.Info("received interrupt, stopping.")
+			wg.Wait()
 			return
 		}
 	}
 }
 
-func parsingWorker(b []byte, producerQueue chan bmp.Message) {
-	perPerHeaderLen := 0
-	var bmpMsg bmp.Message
+// Parse parses BMP messages carried in b and returns them, unlike Parser the parsing error is returned
+// to the caller, messages parsed before the error are returned with it.
+func Parse(b []byte, opts ...Option) ([]bmp.Message, error) {
+	p := &parser{
+		logger: logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	producerQueue := make(chan bmp.Message)
+	done := make(chan struct{})
+	msgs := make([]bmp.Message, 0)
+	go func() {
+		for msg := range producerQueue {
+			msgs = append(msgs, msg)
+		}
+		close(done)
+	}()
+	err := parsingWorker(b, producerQueue, p.logger, 0)
+	close(producerQueue)
+	<-done
+	if err != nil {
+		p.metrics.ParseError()
+	}
+
+	return msgs, err
+}
+
+// ParseMessage parses a single BMP message carried in raw, including the Common Header, and returns it.
+// Unlike Parse, an error is returned when raw does not carry exactly one BMP message or the type of
+// the message is not supported.
+func ParseMessage(raw []byte, opts ...Option) (bmp.Message, error) {
+	p := &parser{
+		logger: logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	bmpMsg, l, err := parseMessage(raw, p.logger)
+	if err == nil && l != len(raw) {
+		err = fmt.Errorf("BMP message of %d bytes is followed by %d bytes", l, len(raw)-l)
+	}
+	if err == nil && bmpMsg.Payload == nil {
+		err = fmt.Errorf("unsupported BMP message type %d", raw[5])
+	}
+	if err != nil {
+		p.metrics.ParseError()
+		return bmp.Message{}, err
+	}
+	bmpMsg.ReceivedAt = time.Now()
+
+	return bmpMsg, nil
+}
+
+// parsingWorker parses BMP messages carried in b and sends them to producerQueue, seq is the sequence
+// number of b in the session, 0 when it is unknown.
+func parsingWorker(b []byte, producerQueue chan bmp.Message, logger *slog.Logger, seq uint64) error {
+	return parseMessages(b, logger, seq, func(bmpMsg bmp.Message) {
+		if producerQueue != nil {
+			producerQueue <- bmpMsg
+		}
+	})
+}
+
+// parseMessages parses BMP messages carried in b and passes messages of supported types to send in the order
+// they are carried, seq is the sequence number of b in the session, 0 when it is unknown.
+func parseMessages(b []byte, logger *slog.Logger, seq uint64, send func(bmp.Message)) error {
+	// All BMP messages carried in b are received at once
+	receivedAt := time.Now()
 	// Loop through all found Common Headers in the slice and process them
 	for p := 0; p < len(b); {
-		bmpMsg.PeerHeader = nil
-		bmpMsg.Payload = nil
-		// Recovering common header first
-		ch, err := bmp.UnmarshalCommonHeader(b[p : p+bmp.CommonHeaderLength])
+		bmpMsg, l, err := parseMessage(b[p:], logger)
 		if err != nil {
-			glog.Errorf("fail to recover BMP message Common Header with error: %+v", err)
-			return
+			return err
 		}
-		p += bmp.CommonHeaderLength
-		switch ch.MessageType {
-		case bmp.RouteMonitorMsg:
-			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+bmp.PerPeerHeaderLength]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
-			}
-			perPerHeaderLen = bmp.PerPeerHeaderLength
-			rm, err := bmp.UnmarshalBMPRouteMonitorMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength])
-			if err != nil {
-				glog.Errorf("fail to recover BMP Route Monitoring with error: %+v", err)
-				if glog.V(5) {
-					glog.Infof("common header content: %+v", ch)
-					glog.Infof("per peer header content: %s", tools.MessageHex(b[p:p+bmp.PerPeerHeaderLength]))
-					glog.Infof("message content: %s", tools.MessageHex(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength]))
-				}
-				return
-			}
-			bmpMsg.Payload = rm
-			p += perPerHeaderLen
-		case bmp.StatsReportMsg:
-			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
-			}
-			perPerHeaderLen = bmp.PerPeerHeaderLength
-			if bmpMsg.Payload, err = bmp.UnmarshalBMPStatsReportMessage(b[p+perPerHeaderLen:]); err != nil {
-				glog.Errorf("fail to recover BMP Stats Reports message with error: %+v", err)
-				return
-			}
-			p += perPerHeaderLen
-		case bmp.PeerDownMsg:
-			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
-			}
-			perPerHeaderLen = bmp.PerPeerHeaderLength
-			if bmpMsg.Payload, err = bmp.UnmarshalPeerDownMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
-				glog.Errorf("fail to recover BMP Peer Down message with error: %+v", err)
-				return
-			}
-			p += perPerHeaderLen
-		case bmp.PeerUpMsg:
-			if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Per Peer Header with error: %+v", err)
-				return
-			}
-			perPerHeaderLen = bmp.PerPeerHeaderLength
-			if bmpMsg.Payload, err = bmp.UnmarshalPeerUpMessage(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength], bmpMsg.PeerHeader.IsRemotePeerIPv6()); err != nil {
-				glog.Errorf("fail to recover BMP Peer Up message with error: %+v", err)
-				return
-			}
-			p += perPerHeaderLen
-		case bmp.InitiationMsg:
-			if _, err := bmp.UnmarshalInitiationMessage(b[p : p+(int(ch.MessageLength)-bmp.CommonHeaderLength)]); err != nil {
-				glog.Errorf("fail to recover BMP Initiation message with error: %+v", err)
-				return
-			}
-		case bmp.TerminationMsg:
-			glog.V(5).Infof("Termination message")
-			if glog.V(6) {
-				glog.Infof("Content: %s", tools.MessageHex(b))
-			}
-		case bmp.RouteMirrorMsg:
-			glog.V(5).Infof("Route Mirroring message")
-			if glog.V(6) {
-				glog.Infof("Content:%s", tools.MessageHex(b))
+		p += l
+		if bmpMsg.Payload != nil {
+			bmpMsg.ReceivedAt = receivedAt
+			bmpMsg.Sequence = seq
+			send(bmpMsg)
+		}
+	}
+
+	return nil
+}
+
+// parseMessage parses the first BMP message carried in b and returns it with its length, the Payload
+// of a message of unsupported type is nil.
+func parseMessage(b []byte, logger *slog.Logger) (bmp.Message, int, error) {
+	var bmpMsg bmp.Message
+	perPerHeaderLen := 0
+	p := 0
+	// Recovering common header first
+	if len(b) < bmp.CommonHeaderLength {
+		err := fmt.Errorf("not enough bytes to unmarshal BMP message Common Header")
+		logger.Error("fail to recover BMP message Common Header", "error", err)
+		return bmpMsg, 0, err
+	}
+	ch, err := bmp.UnmarshalCommonHeader(b[p : p+bmp.CommonHeaderLength])
+	if err != nil {
+		logger.Error("fail to recover BMP message Common Header", "error", err)
+		return bmpMsg, 0, err
+	}
+	if int(ch.MessageLength) < bmp.CommonHeaderLength || int(ch.MessageLength) > len(b) {
+		err := fmt.Errorf("invalid BMP message length %d, %d bytes available", ch.MessageLength, len(b))
+		logger.Error("fail to recover BMP message", "error", err)
+		return bmpMsg, 0, err
+	}
+	switch ch.MessageType {
+	case bmp.RouteMonitorMsg, bmp.StatsReportMsg, bmp.PeerDownMsg, bmp.PeerUpMsg, bmp.RouteMirrorMsg:
+		if int(ch.MessageLength) < bmp.CommonHeaderLength+bmp.PerPeerHeaderLength {
+			err := fmt.Errorf("invalid BMP message length %d, too short for Per Peer Header", ch.MessageLength)
+			logger.Error("fail to recover BMP Per Peer Header", "error", err)
+			return bmpMsg, 0, err
+		}
+	}
+	p += bmp.CommonHeaderLength
+	switch ch.MessageType {
+	case bmp.RouteMonitorMsg:
+		if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+bmp.PerPeerHeaderLength]); err != nil {
+			logger.Error("fail to recover BMP Per Peer Header", "error", err)
+			return bmpMsg, 0, err
+		}
+		perPerHeaderLen = bmp.PerPeerHeaderLength
+		rm, err := bmp.UnmarshalBMPRouteMonitorMessage(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength], bmpMsg.PeerHeader.ASNLength())
+		if err != nil {
+			logger.Error("fail to recover BMP Route Monitoring", "error", err)
+			if logger.Enabled(context.Background(), slog.LevelDebug) {
+				logger.Debug("failed Route Monitoring message",
+					"common_header", fmt.Sprintf("%+v", ch),
+					"per_peer_header", tools.MessageHex(b[p:p+bmp.PerPeerHeaderLength]),
+					"message", tools.MessageHex(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength]))
 			}
+			return bmpMsg, 0, err
 		}
-		perPerHeaderLen = 0
-		p += (int(ch.MessageLength) - bmp.CommonHeaderLength)
-		if producerQueue != nil && bmpMsg.Payload != nil {
-			producerQueue <- bmpMsg
+		bmpMsg.Payload = rm
+	case bmp.StatsReportMsg:
+		if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
+			logger.Error("fail to recover BMP Per Peer Header", "error", err)
+			return bmpMsg, 0, err
+		}
+		perPerHeaderLen = bmp.PerPeerHeaderLength
+		if bmpMsg.Payload, err = bmp.UnmarshalBMPStatsReportMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
+			logger.Error("fail to recover BMP Stats Reports message", "error", err)
+			return bmpMsg, 0, err
+		}
+	case bmp.PeerDownMsg:
+		if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
+			logger.Error("fail to recover BMP Per Peer Header", "error", err)
+			return bmpMsg, 0, err
+		}
+		perPerHeaderLen = bmp.PerPeerHeaderLength
+		if bmpMsg.Payload, err = bmp.UnmarshalPeerDownMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
+			logger.Error("fail to recover BMP Peer Down message", "error", err)
+			return bmpMsg, 0, err
+		}
+	case bmp.PeerUpMsg:
+		if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
+			logger.Error("fail to recover BMP Per Peer Header", "error", err)
+			return bmpMsg, 0, err
+		}
+		perPerHeaderLen = bmp.PerPeerHeaderLength
+		if bmpMsg.Payload, err = bmp.UnmarshalPeerUpMessage(b[p+perPerHeaderLen:p+int(ch.MessageLength)-bmp.CommonHeaderLength], bmpMsg.PeerHeader.IsRemotePeerIPv6()); err != nil {
+			logger.Error("fail to recover BMP Peer Up message", "error", err)
+			return bmpMsg, 0, err
+		}
+	case bmp.InitiationMsg:
+		if bmpMsg.Payload, err = bmp.UnmarshalInitiationMessage(b[p : p+(int(ch.MessageLength)-bmp.CommonHeaderLength)]); err != nil {
+			logger.Error("fail to recover BMP Initiation message", "error", err)
+			return bmpMsg, 0, err
+		}
+	case bmp.TerminationMsg:
+		if bmpMsg.Payload, err = bmp.UnmarshalTerminationMessage(b[p : p+(int(ch.MessageLength)-bmp.CommonHeaderLength)]); err != nil {
+			logger.Error("fail to recover BMP Termination message", "error", err)
+			return bmpMsg, 0, err
+		}
+	case bmp.RouteMirrorMsg:
+		if bmpMsg.PeerHeader, err = bmp.UnmarshalPerPeerHeader(b[p : p+int(ch.MessageLength-bmp.CommonHeaderLength)]); err != nil {
+			logger.Error("fail to recover BMP Per Peer Header", "error", err)
+			return bmpMsg, 0, err
+		}
+		perPerHeaderLen = bmp.PerPeerHeaderLength
+		if bmpMsg.Payload, err = bmp.UnmarshalBMPRouteMirrorMessage(b[p+perPerHeaderLen : p+int(ch.MessageLength)-bmp.CommonHeaderLength]); err != nil {
+			logger.Error("fail to recover BMP Route Mirroring message", "error", err)
+			return bmpMsg, 0, err
 		}
 	}
+	bmpMsg.RawMessage = b[:ch.MessageLength]
+
+	return bmpMsg, int(ch.MessageLength), nil
 }
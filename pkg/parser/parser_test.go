@@ -1,6 +1,16 @@
 package parser
 
-import "testing"
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+	"github.com/sbezverk/gobmp/pkg/logging"
+)
 
 func TestParsingWorker(t *testing.T) {
 	tests := []struct {
@@ -14,7 +24,317 @@ func TestParsingWorker(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parsingWorker(tt.input, nil)
+			parsingWorker(tt.input, nil, logging.Default(), 0)
+		})
+	}
+}
+
+func TestParsingWorkerRouteMirror(t *testing.T) {
+	// Route Mirroring message from peer 192.168.80.103 AS 5070 with Messages Lost Information and mirrored BGP Update
+	input := []byte{
+		3, 0, 0, 0, 81, 6,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126,
+		0, 1, 0, 2, 0, 1,
+		0, 0, 0, 23, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 23, 2, 0, 0, 0, 0,
+	}
+	producerQueue := make(chan bmp.Message, 1)
+	if err := parsingWorker(input, producerQueue, logging.Default(), 7); err != nil {
+		t.Fatalf("failed to parse Route Mirroring message with error: %+v", err)
+	}
+	msg := <-producerQueue
+	rm, ok := msg.Payload.(*bmp.RouteMirrorMessage)
+	if !ok {
+		t.Fatalf("expected payload of type *bmp.RouteMirrorMessage, got %T", msg.Payload)
+	}
+	if !rm.IsMessagesLost() {
+		t.Fatalf("expected Messages Lost Information")
+	}
+	if len(rm.BGPMessages()) != 1 {
+		t.Fatalf("expected 1 mirrored BGP message, got %d", len(rm.BGPMessages()))
+	}
+	if got := msg.PeerHeader.GetPeerAddrString(); got != "192.168.80.103" {
+		t.Fatalf("expected peer address 192.168.80.103, got %s", got)
+	}
+	if !bytes.Equal(msg.RawMessage, input) {
+		t.Fatalf("expected raw message %v, got %v", input, msg.RawMessage)
+	}
+	if msg.ReceivedAt.IsZero() {
+		t.Fatalf("expected receive time of the message")
+	}
+	if msg.Sequence != 7 {
+		t.Fatalf("expected sequence number 7, got %d", msg.Sequence)
+	}
+}
+
+func TestParserParseError(t *testing.T) {
+	// Common Header with unsupported BMP version 2
+	input := []byte{2, 0, 0, 0, 6, 4}
+	queue := make(chan []byte)
+	producerQueue := make(chan bmp.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Parser(queue, producerQueue, stop)
+		close(done)
+	}()
+	queue <- input
+	msg := <-producerQueue
+	close(stop)
+	<-done
+	pe, ok := msg.Payload.(*bmp.ParseError)
+	if !ok {
+		t.Fatalf("expected payload of type *bmp.ParseError, got %T", msg.Payload)
+	}
+	if pe.Err == nil {
+		t.Errorf("expected parsing error")
+	}
+	if !bytes.Equal(pe.Message, input) {
+		t.Errorf("expected message %v, got %v", input, pe.Message)
+	}
+}
+
+func TestParseMessage(t *testing.T) {
+	// initiation, peerUp and routeMirror are messages of TestParsingWorker and TestParsingWorkerRouteMirror
+	initiation := []byte{3, 0, 0, 0, 32, 4, 0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73, 0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49}
+	peerUp := []byte{3, 0, 0, 0, 234, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 128, 0, 179, 131, 152, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 91, 1, 4, 19, 206, 0, 90, 192, 168, 8, 8, 62, 2, 6, 1, 4, 0, 1, 0, 1, 2, 6, 1, 4, 0, 1, 0, 4, 2, 6, 1, 4, 0, 1, 0, 128, 2, 2, 128, 0, 2, 2, 2, 0, 2, 6, 65, 4, 0, 0, 19, 206, 2, 20, 5, 18, 0, 1, 0, 1, 0, 2, 0, 1, 0, 2, 0, 2, 0, 1, 0, 128, 0, 2, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 75, 1, 4, 19, 206, 0, 90, 57, 112, 1, 254, 46, 2, 44, 2, 0, 1, 4, 0, 1, 0, 1, 1, 4, 0, 2, 0, 1, 1, 4, 0, 1, 0, 4, 1, 4, 0, 2, 0, 4, 1, 4, 0, 1, 0, 128, 1, 4, 0, 2, 0, 128, 65, 4, 0, 0, 19, 206}
+	routeMirror := []byte{
+		3, 0, 0, 0, 81, 6,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126,
+		0, 1, 0, 2, 0, 1,
+		0, 0, 0, 23, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 23, 2, 0, 0, 0, 0,
+	}
+	// Peer Down of peer 192.168.80.103, remote system closed the session without notification
+	peerDown := []byte{
+		3, 0, 0, 0, 49, 2,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126,
+		4,
+	}
+	// Route Monitoring of peer 192.168.80.103 carrying BGP Update withdrawing 10.0.0.0/8
+	routeMonitor := []byte{
+		3, 0, 0, 0, 73, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126,
+		255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 25, 2, 0, 2, 8, 10, 0, 0,
+	}
+	tests := []struct {
+		name    string
+		input   []byte
+		fail    bool
+		payload interface{}
+		peer    bool
+	}{
+		{
+			name:    "initiation",
+			input:   initiation,
+			payload: &bmp.InitiationMessage{},
+		},
+		{
+			name:    "peer up",
+			input:   peerUp,
+			payload: &bmp.PeerUpMessage{},
+			peer:    true,
+		},
+		{
+			name:    "peer down",
+			input:   peerDown,
+			payload: &bmp.PeerDownMessage{},
+			peer:    true,
+		},
+		{
+			name:    "route monitoring",
+			input:   routeMonitor,
+			payload: &bmp.RouteMonitor{},
+			peer:    true,
+		},
+		{
+			name:    "route mirroring",
+			input:   routeMirror,
+			payload: &bmp.RouteMirrorMessage{},
+			peer:    true,
+		},
+		{
+			name:  "two messages",
+			input: append(append([]byte{}, initiation...), peerDown...),
+			fail:  true,
+		},
+		{
+			name:  "truncated message",
+			input: peerUp[:100],
+			fail:  true,
+		},
+		{
+			name:  "too short for per peer header",
+			input: []byte{3, 0, 0, 0, 10, 2, 0, 0, 0, 0},
+			fail:  true,
+		},
+		{
+			name:  "unsupported message type",
+			input: []byte{3, 0, 0, 0, 6, 9},
+			fail:  true,
+		},
+		{
+			name:  "empty message",
+			input: []byte{},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseMessage(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("failed but supposed to succeed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if tt.fail {
+				return
+			}
+			if reflect.TypeOf(msg.Payload) != reflect.TypeOf(tt.payload) {
+				t.Fatalf("expected payload of type %T, got %T", tt.payload, msg.Payload)
+			}
+			if tt.peer && (msg.PeerHeader == nil || msg.PeerHeader.GetPeerAddrString() != "192.168.80.103") {
+				t.Fatalf("expected Per Peer Header of peer 192.168.80.103, got %+v", msg.PeerHeader)
+			}
+			if !tt.peer && msg.PeerHeader != nil {
+				t.Fatalf("expected no Per Peer Header, got %+v", msg.PeerHeader)
+			}
+			if !bytes.Equal(msg.RawMessage, tt.input) {
+				t.Fatalf("expected raw message %v, got %v", tt.input, msg.RawMessage)
+			}
+			if msg.ReceivedAt.IsZero() {
+				t.Fatalf("expected receive time of the message")
+			}
+		})
+	}
+}
+
+func TestParseMessageASNLength(t *testing.T) {
+	tests := []struct {
+		name   string
+		flags  byte
+		asPath []uint32
+	}{
+		{
+			name:   "4 bytes asn",
+			flags:  0x00,
+			asPath: []uint32{4259840513, 33684969},
+		},
+		{
+			name:   "2 bytes asn of A flag",
+			flags:  0x20,
+			asPath: []uint32{65000, 513, 65001},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Route Monitoring of peer 192.168.80.103 carrying AS_PATH which is valid with 2 and 4 bytes ASNs
+			input := []byte{
+				3, 0, 0, 0, 86, 0,
+				0, tt.flags, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126,
+				255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 38, 2, 0, 0, 0, 15,
+				0x40, 0x02, 0x0C, 0x02, 0x01, 0xFD, 0xE8, 0x02, 0x01, 0x02, 0x01, 0x02, 0x01, 0xFD, 0xE9,
+			}
+			msg, err := ParseMessage(input)
+			if err != nil {
+				t.Fatalf("failed but supposed to succeed with error: %+v", err)
+			}
+			rm, ok := msg.Payload.(*bmp.RouteMonitor)
+			if !ok {
+				t.Fatalf("expected payload of type %T, got %T", rm, msg.Payload)
+			}
+			if !reflect.DeepEqual(rm.Update.BaseAttributes.ASPath, tt.asPath) {
+				t.Fatalf("expected as path %v, got %v", tt.asPath, rm.Update.BaseAttributes.ASPath)
+			}
+		})
+	}
+}
+
+// testRouteMonitor is Route Monitoring of peer 192.168.80.103 carrying BGP Update withdrawing 10.0.0.0/8
+var testRouteMonitor = []byte{
+	3, 0, 0, 0, 73, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 192, 168, 80, 103, 0, 0, 19, 206, 57, 112, 1, 254, 94, 98, 129, 171, 0, 0, 215, 126,
+	255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 0, 25, 2, 0, 2, 8, 10, 0, 0,
+}
+
+// runParser sends inputs to Parser started with opts and returns messages sent to the producer
+func runParser(inputs [][]byte, opts ...Option) []bmp.Message {
+	queue := make(chan []byte)
+	producerQueue := make(chan bmp.Message)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Parser(queue, producerQueue, stop, opts...)
+		close(done)
+	}()
+	msgs := make([]bmp.Message, 0, len(inputs))
+	received := make(chan struct{})
+	go func() {
+		for range inputs {
+			msgs = append(msgs, <-producerQueue)
+		}
+		close(received)
+	}()
+	for _, input := range inputs {
+		queue <- input
+	}
+	<-received
+	close(stop)
+	<-done
+
+	return msgs
+}
+
+func TestParserConcurrency(t *testing.T) {
+	initiation := []byte{3, 0, 0, 0, 32, 4, 0, 1, 0, 10, 32, 55, 46, 50, 46, 49, 46, 50, 51, 73, 0, 2, 0, 8, 120, 114, 118, 57, 107, 45, 114, 49}
+	invalid := []byte{2, 0, 0, 0, 6, 4}
+	inputs := make([][]byte, 0)
+	for i := 0; i < 100; i++ {
+		switch i % 10 {
+		case 0:
+			inputs = append(inputs, initiation)
+		case 5:
+			inputs = append(inputs, invalid)
+		default:
+			inputs = append(inputs, testRouteMonitor)
+		}
+	}
+	for _, concurrency := range []int{1, 4, 16} {
+		t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+			msgs := runParser(inputs, WithConcurrency(concurrency))
+			if len(msgs) != len(inputs) {
+				t.Fatalf("expected %d messages, got %d", len(inputs), len(msgs))
+			}
+			for i, msg := range msgs {
+				if msg.Sequence != uint64(i+1) {
+					t.Fatalf("expected message %d of sequence %d, got %d", i, i+1, msg.Sequence)
+				}
+				var expect interface{}
+				switch i % 10 {
+				case 0:
+					expect = &bmp.InitiationMessage{}
+				case 5:
+					expect = &bmp.ParseError{}
+				default:
+					expect = &bmp.RouteMonitor{}
+				}
+				if reflect.TypeOf(msg.Payload) != reflect.TypeOf(expect) {
+					t.Fatalf("expected message %d of type %T, got %T", i, expect, msg.Payload)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	inputs := make([][]byte, 1000)
+	for i := range inputs {
+		inputs[i] = testRouteMonitor
+	}
+	for _, concurrency := range []int{0, 1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency %d", concurrency), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				runParser(inputs, WithConcurrency(concurrency), WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+			}
 		})
 	}
 }
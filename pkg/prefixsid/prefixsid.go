@@ -2,6 +2,8 @@ package prefixsid
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/gobmp/pkg/srv6"
@@ -14,7 +16,19 @@ type LabelIndexTLV struct {
 	Type       uint8  `json:"-"`
 	Length     uint16 `json:"-"`
 	Flags      uint16 `json:"flags,omitempty"`
-	LabelIndex uint32 `json:"last_index,omitempty"`
+	LabelIndex uint32 `json:"label_index"`
+}
+
+// UnmarshalJSON restores Type and Length of Label Index TLV which are not carried in JSON
+func (tlv *LabelIndexTLV) UnmarshalJSON(b []byte) error {
+	type labelIndexTLV LabelIndexTLV
+	if err := json.Unmarshal(b, (*labelIndexTLV)(tlv)); err != nil {
+		return err
+	}
+	tlv.Type = 1
+	tlv.Length = 7
+
+	return nil
 }
 
 // SRGB defines a structure of Segment Routing GLobal Block
@@ -33,6 +47,18 @@ type OriginatorSRGBTLV struct {
 	SRGB   []SRGB `json:"srgb,omitempty"`
 }
 
+// UnmarshalJSON restores Type and Length of Originator SRGB TLV which are not carried in JSON
+func (tlv *OriginatorSRGBTLV) UnmarshalJSON(b []byte) error {
+	type originatorSRGBTLV OriginatorSRGBTLV
+	if err := json.Unmarshal(b, (*originatorSRGBTLV)(tlv)); err != nil {
+		return err
+	}
+	tlv.Type = 3
+	tlv.Length = uint16(2 + 6*len(tlv.SRGB))
+
+	return nil
+}
+
 // PSid defines bgp prefix sid attribute 40
 // https://tools.ietf.org/html/rfc8669#section-3
 type PSid struct {
@@ -52,59 +78,61 @@ func UnmarshalBGPAttrPrefixSID(b []byte) (*PSid, error) {
 		OriginatorSRGB: nil,
 	}
 	for p := 0; p < len(b); {
-		// Determin the type, currently only type 1 and 3 are supported
-		switch b[p] {
+		// Type 1 byte and Length 2 bytes
+		if p+3 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal Prefix SID tlv")
+		}
+		t := b[p]
+		l := int(binary.BigEndian.Uint16(b[p+1 : p+3]))
+		p += 3
+		if p+l > len(b) {
+			return nil, fmt.Errorf("invalid Prefix SID tlv %d length %d", t, l)
+		}
+		v := b[p : p+l]
+		// Determin the type, currently only type 1, 3 and 5 are supported
+		switch t {
 		case 1:
-			p++
-			psid.LabelIndex = &LabelIndexTLV{}
-			psid.LabelIndex.Type = 1
-			psid.LabelIndex.Length = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			// Skip reserved byte
-			p++
-			psid.LabelIndex.Flags = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			psid.LabelIndex.LabelIndex = binary.BigEndian.Uint32(b[p : p+4])
-			p += 4
+			// Reserved 1 byte, Flags 2 bytes and Label Index 4 bytes
+			if l != 7 {
+				return nil, fmt.Errorf("invalid Label Index tlv length %d, expected 7", l)
+			}
+			psid.LabelIndex = &LabelIndexTLV{
+				Type:       t,
+				Length:     uint16(l),
+				Flags:      binary.BigEndian.Uint16(v[1:3]),
+				LabelIndex: binary.BigEndian.Uint32(v[3:7]),
+			}
 		case 3:
-			p++
-			psid.OriginatorSRGB = &OriginatorSRGBTLV{}
-			psid.OriginatorSRGB.Type = 1
-			psid.OriginatorSRGB.Length = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			psid.OriginatorSRGB.Flags = binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			// Multiple SRGB are possible, loop through, each SRGB takes 6 bytes. Subtrack 2 (length of Flags)
-			// from the total Value portion length
-			psid.OriginatorSRGB.SRGB = make([]SRGB, 0)
-			for i := 0; i < int(psid.OriginatorSRGB.Length-2)/6; i++ {
+			// Flags 2 bytes followed by one or more SRGB, each SRGB takes 6 bytes.
+			if l < 2 || (l-2)%6 != 0 {
+				return nil, fmt.Errorf("invalid Originator SRGB tlv length %d", l)
+			}
+			psid.OriginatorSRGB = &OriginatorSRGBTLV{
+				Type:   t,
+				Length: uint16(l),
+				Flags:  binary.BigEndian.Uint16(v[0:2]),
+				SRGB:   make([]SRGB, 0),
+			}
+			for i := 2; i < l; i += 6 {
 				srgb := SRGB{}
-				t := make([]byte, 4)
-				copy(t, b[p:p+3])
-				srgb.First = binary.BigEndian.Uint32(t)
-				p += 3
-				t = make([]byte, 4)
-				copy(t, b[p:p+3])
-				srgb.Number = binary.BigEndian.Uint32(t)
-				p += 3
+				// First and Number are 3 bytes long
+				buf := make([]byte, 4)
+				copy(buf[1:], v[i:i+3])
+				srgb.First = binary.BigEndian.Uint32(buf)
+				buf = make([]byte, 4)
+				copy(buf[1:], v[i+3:i+6])
+				srgb.Number = binary.BigEndian.Uint32(buf)
 				psid.OriginatorSRGB.SRGB = append(psid.OriginatorSRGB.SRGB, srgb)
 			}
 		case 5:
-			p++
-			l := binary.BigEndian.Uint16(b[p : p+2])
-			p += 2
-			l3, err := srv6.UnmarshalSRv6L3Service(b[p : p+int(l)])
+			l3, err := srv6.UnmarshalSRv6L3Service(v)
 			if err != nil {
 				return nil, err
 			}
 			psid.SRv6L3Service = l3
-			p += int(l)
-		default:
-			// Skip unknown type, length 2 bytes and the value
-			p++
-			p += int(binary.BigEndian.Uint16(b[p : p+2]))
-			p += 2
 		}
+		// Unknown types are skipped
+		p += l
 	}
 	return &psid, nil
 }
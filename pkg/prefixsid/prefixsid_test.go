@@ -27,6 +27,21 @@ func TestUnmarshalBGPAttrPrefixSID(t *testing.T) {
 				OriginatorSRGB: nil,
 			},
 		},
+		{
+			name:  "label index and originator srgb",
+			input: []byte{0x01, 0x00, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x08, 0x00, 0x00, 0x00, 0x3e, 0x80, 0x00, 0x1f, 0x40},
+			expect: &PSid{
+				LabelIndex: &LabelIndexTLV{
+					Type:   1,
+					Length: 7,
+				},
+				OriginatorSRGB: &OriginatorSRGBTLV{
+					Type:   3,
+					Length: 8,
+					SRGB:   []SRGB{{First: 16000, Number: 8000}},
+				},
+			},
+		},
 		{
 			name:  "prefix sid type 5",
 			input: []byte{0x05, 0x00, 0x22, 0x00, 0x01, 0x00, 0x1e, 0x00, 0x20, 0x01, 0x00, 0x00, 0x00, 0x05, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x11, 0x00, 0x01, 0x00, 0x06, 0x28, 0x18, 0x10, 0x00, 0x10, 0x40},
@@ -70,3 +85,38 @@ func TestUnmarshalBGPAttrPrefixSID(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalBGPAttrPrefixSIDMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "truncated tlv header",
+			input: []byte{0x01, 0x00},
+		},
+		{
+			name:  "tlv length exceeds attribute",
+			input: []byte{0x01, 0x00, 0x07, 0x00, 0x00, 0x00, 0x00},
+		},
+		{
+			name:  "invalid label index length",
+			input: []byte{0x01, 0x00, 0x03, 0x00, 0x00, 0x00},
+		},
+		{
+			name:  "invalid originator srgb length",
+			input: []byte{0x03, 0x00, 0x05, 0x00, 0x00, 0x00, 0x3e, 0x80},
+		},
+		{
+			name:  "truncated srv6 sid information",
+			input: []byte{0x05, 0x00, 0x05, 0x00, 0x01, 0x00, 0x01, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := UnmarshalBGPAttrPrefixSID(tt.input); err == nil {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+		})
+	}
+}
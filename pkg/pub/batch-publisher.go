@@ -0,0 +1,212 @@
+package pub
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/logging"
+)
+
+// batchKey identifies a batch, messages of the same router, type and with the same key are batched together,
+// so the order of messages for the same key is preserved by the backend.
+type batchKey struct {
+	router  Router
+	msgType int
+	msgHash string
+}
+
+type batch struct {
+	msgHash []byte
+	msgs    [][]byte
+}
+
+// takenBatch is a batch taken out of accumulated batches to be published once the lock is released
+type takenBatch struct {
+	key   batchKey
+	batch *batch
+	// prev is closed once the previous batch of the same key is published, nil when there is none,
+	// done is closed once this batch is published.
+	prev chan struct{}
+	done chan struct{}
+}
+
+// BatchOption defines a function which modifies optional parameters of the batch publisher
+type BatchOption func(*batchPublisher)
+
+// WithBatchLogger sets the logger of the batch publisher, by default and when logger is nil records are written
+// to glog
+func WithBatchLogger(logger *slog.Logger) BatchOption {
+	return func(p *batchPublisher) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+type batchPublisher struct {
+	sync.Mutex
+	logger        *slog.Logger
+	inner         Publisher
+	maxMessages   int
+	flushInterval time.Duration
+	batches       map[batchKey]*batch
+	// order keeps the keys of batches in the order they were created, it makes the flush of all batches
+	// deterministic.
+	order []batchKey
+	// publishing keeps for every key the channel closed once the last taken batch of the key is published,
+	// so batches of the same key are published in order without holding the lock.
+	publishing map[batchKey]chan struct{}
+	stop       chan struct{}
+	stopped    chan struct{}
+	stopOnce   sync.Once
+}
+
+func (p *batchPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishRouterMessage(Router{}, msgType, msgHash, msg)
+}
+
+func (p *batchPublisher) PublishRouterMessage(router Router, msgType int, msgHash []byte, msg []byte) error {
+	p.Lock()
+	k := batchKey{router: router, msgType: msgType, msgHash: string(msgHash)}
+	b, ok := p.batches[k]
+	if !ok {
+		b = &batch{
+			msgHash: msgHash,
+			msgs:    make([][]byte, 0, p.maxMessages),
+		}
+		p.batches[k] = b
+		p.order = append(p.order, k)
+	}
+	b.msgs = append(b.msgs, msg)
+	if len(b.msgs) < p.maxMessages {
+		p.Unlock()
+		return nil
+	}
+	tb := p.take(k)
+	p.Unlock()
+
+	return p.publish(tb)
+}
+
+// take removes the batch of key k from accumulated batches, it must be called with the lock held.
+func (p *batchPublisher) take(k batchKey) *takenBatch {
+	b, ok := p.batches[k]
+	if !ok {
+		return nil
+	}
+	delete(p.batches, k)
+	for i, o := range p.order {
+		if o == k {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+	tb := &takenBatch{
+		key:   k,
+		batch: b,
+		prev:  p.publishing[k],
+		done:  make(chan struct{}),
+	}
+	p.publishing[k] = tb.done
+
+	return tb
+}
+
+// publish publishes the taken batch as a single JSON array once the previous batch of the same key
+// is published, it must be called without the lock held, so a slow publish does not block other keys.
+func (p *batchPublisher) publish(tb *takenBatch) error {
+	if tb == nil {
+		return nil
+	}
+	defer func() {
+		close(tb.done)
+		p.Lock()
+		if p.publishing[tb.key] == tb.done {
+			delete(p.publishing, tb.key)
+		}
+		p.Unlock()
+	}()
+	if tb.prev != nil {
+		<-tb.prev
+	}
+	b := tb.batch
+	payload := make([]byte, 0, len(b.msgs)*(len(b.msgs[0])+1)+1)
+	payload = append(payload, '[')
+	payload = append(payload, bytes.Join(b.msgs, []byte{','})...)
+	payload = append(payload, ']')
+
+	return PublishRouterMessage(p.inner, tb.key.router, tb.key.msgType, b.msgHash, payload)
+}
+
+// flushAll publishes all accumulated batches in the order they were created
+func (p *batchPublisher) flushAll() {
+	p.Lock()
+	taken := make([]*takenBatch, 0, len(p.order))
+	for len(p.order) != 0 {
+		taken = append(taken, p.take(p.order[0]))
+	}
+	p.Unlock()
+	for _, tb := range taken {
+		if err := p.publish(tb); err != nil {
+			p.logger.Error("failed to publish batch of messages", "type", tb.key.msgType, "error", err)
+		}
+	}
+}
+
+func (p *batchPublisher) flusher() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.flushAll()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *batchPublisher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		<-p.stopped
+		p.flushAll()
+		p.inner.Stop()
+	})
+}
+
+// NewBatchPublisher returns a new instance of Publisher accumulating messages of the same type and key
+// and publishing them to inner as a single JSON array of messages, when maxMessages messages have been
+// accumulated or on every flushInterval, whichever comes first. When flushInterval is 0, batches are
+// published only when full or on Stop. Messages with the same type and key are never reordered.
+// A failure to publish a batch is returned by the publish which completed the batch, failures of
+// the periodic flush are logged. Stop flushes the remaining messages and stops inner publisher.
+func NewBatchPublisher(inner Publisher, maxMessages int, flushInterval time.Duration, opts ...BatchOption) Publisher {
+	if maxMessages < 1 {
+		maxMessages = 1
+	}
+	p := &batchPublisher{
+		logger:        logging.Default(),
+		inner:         inner,
+		maxMessages:   maxMessages,
+		flushInterval: flushInterval,
+		batches:       make(map[batchKey]*batch),
+		order:         make([]batchKey, 0),
+		publishing:    make(map[batchKey]chan struct{}),
+		stop:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if flushInterval > 0 {
+		go p.flusher()
+	} else {
+		close(p.stopped)
+	}
+
+	return p
+}
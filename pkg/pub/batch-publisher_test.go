@@ -0,0 +1,194 @@
+package pub
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type publishedBatch struct {
+	msgType int
+	msgHash string
+	msgs    []int
+}
+
+type recordingPublisher struct {
+	sync.Mutex
+	batches []publishedBatch
+	stopped bool
+}
+
+func (r *recordingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	r.Lock()
+	defer r.Unlock()
+	b := publishedBatch{msgType: msgType, msgHash: string(msgHash)}
+	if err := json.Unmarshal(msg, &b.msgs); err != nil {
+		return err
+	}
+	r.batches = append(r.batches, b)
+
+	return nil
+}
+
+func (r *recordingPublisher) Stop() {
+	r.Lock()
+	defer r.Unlock()
+	r.stopped = true
+}
+
+func (r *recordingPublisher) published() []publishedBatch {
+	r.Lock()
+	defer r.Unlock()
+	return append([]publishedBatch{}, r.batches...)
+}
+
+func TestBatchPublisher(t *testing.T) {
+	inner := &recordingPublisher{}
+	p := NewBatchPublisher(inner, 3, 0)
+	msgs := []struct {
+		msgType int
+		msgHash string
+		msg     int
+	}{
+		{7, "peer1", 1},
+		{7, "peer2", 2},
+		{7, "peer1", 3},
+		{8, "peer1", 4},
+		{7, "peer1", 5},
+		{7, "peer2", 6},
+	}
+	for _, m := range msgs {
+		if err := p.PublishMessage(m.msgType, []byte(m.msgHash), []byte(fmt.Sprintf("%d", m.msg))); err != nil {
+			t.Fatalf("failed to publish message with error: %+v", err)
+		}
+	}
+	// Only the batch of type 7 and key peer1 is full
+	expect := []publishedBatch{
+		{msgType: 7, msgHash: "peer1", msgs: []int{1, 3, 5}},
+	}
+	if got := inner.published(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected batches %+v, got %+v", expect, got)
+	}
+	p.Stop()
+	expect = append(expect,
+		publishedBatch{msgType: 7, msgHash: "peer2", msgs: []int{2, 6}},
+		publishedBatch{msgType: 8, msgHash: "peer1", msgs: []int{4}},
+	)
+	if got := inner.published(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected batches %+v after stop, got %+v", expect, got)
+	}
+	if !inner.stopped {
+		t.Fatalf("expected inner publisher to be stopped")
+	}
+}
+
+func TestBatchPublisherFlushInterval(t *testing.T) {
+	inner := &recordingPublisher{}
+	p := NewBatchPublisher(inner, 100, 10*time.Millisecond)
+	defer p.Stop()
+	for i := 0; i < 5; i++ {
+		if err := p.PublishMessage(7, []byte("peer1"), []byte(fmt.Sprintf("%d", i))); err != nil {
+			t.Fatalf("failed to publish message with error: %+v", err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(inner.published()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("batch was not flushed after flush interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	expect := []publishedBatch{
+		{msgType: 7, msgHash: "peer1", msgs: []int{0, 1, 2, 3, 4}},
+	}
+	if got := inner.published(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected batches %+v, got %+v", expect, got)
+	}
+}
+
+// stallingPublisher blocks publishes of the stalled key until release is closed
+type stallingPublisher struct {
+	recordingPublisher
+	stalled string
+	release chan struct{}
+}
+
+func (s *stallingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	if string(msgHash) == s.stalled {
+		<-s.release
+	}
+	return s.recordingPublisher.PublishMessage(msgType, msgHash, msg)
+}
+
+func TestBatchPublisherSlowPublish(t *testing.T) {
+	inner := &stallingPublisher{stalled: "peer1", release: make(chan struct{})}
+	p := NewBatchPublisher(inner, 1, 0)
+	defer p.Stop()
+	errs := make(chan error, 2)
+	for i := 1; i <= 2; i++ {
+		msg := []byte(fmt.Sprintf("%d", i))
+		go func() {
+			errs <- p.PublishMessage(7, []byte("peer1"), msg)
+		}()
+		// The second batch of peer1 is taken after the first one
+		time.Sleep(10 * time.Millisecond)
+	}
+	// The stalled publish of peer1 does not block publishes of other keys
+	done := make(chan error)
+	go func() {
+		done <- p.PublishMessage(7, []byte("peer2"), []byte("3"))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to publish message with error: %+v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("publish of peer2 is blocked by the stalled publish of peer1")
+	}
+	close(inner.release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("failed to publish message with error: %+v", err)
+		}
+	}
+	// Batches of peer1 are published in the order they were taken
+	expect := []publishedBatch{
+		{msgType: 7, msgHash: "peer2", msgs: []int{3}},
+		{msgType: 7, msgHash: "peer1", msgs: []int{1}},
+		{msgType: 7, msgHash: "peer1", msgs: []int{2}},
+	}
+	if got := inner.published(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected batches %+v, got %+v", expect, got)
+	}
+}
+
+// costlyPublisher simulates a backend with a fixed overhead per publish call
+type costlyPublisher struct{}
+
+func (c *costlyPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	time.Sleep(10 * time.Microsecond)
+	return nil
+}
+
+func (c *costlyPublisher) Stop() {}
+
+var benchMsg = []byte(`{"action":"add","prefix":"10.0.0.0","prefix_len":8,"is_ipv4":true}`)
+
+func BenchmarkPublisherUnbatched(b *testing.B) {
+	p := &costlyPublisher{}
+	for i := 0; i < b.N; i++ {
+		_ = p.PublishMessage(7, []byte("peer1"), benchMsg)
+	}
+}
+
+func BenchmarkBatchPublisher(b *testing.B) {
+	p := NewBatchPublisher(&costlyPublisher{}, 100, time.Second)
+	for i := 0; i < b.N; i++ {
+		_ = p.PublishMessage(7, []byte("peer1"), benchMsg)
+	}
+	p.Stop()
+}
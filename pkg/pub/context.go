@@ -0,0 +1,41 @@
+package pub
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextPublisher is implemented by publishers whose publish can be abandoned when ctx is done,
+// for example when the backend does not accept the message in time. The router is zero when
+// the session the message was produced from is unknown.
+type ContextPublisher interface {
+	Publisher
+	PublishMessageContext(ctx context.Context, router Router, msgType int, msgHash []byte, msg []byte) error
+}
+
+// PublishMessageContext publishes msg produced from router to p and returns an error once ctx is done
+// even when the publish has not returned yet. When p does not implement ContextPublisher, the publish
+// keeps running in the background after ctx is done and its result is discarded, p must be safe
+// for concurrent use in this case.
+func PublishMessageContext(ctx context.Context, p Publisher, router Router, msgType int, msgHash []byte, msg []byte) error {
+	if cp, ok := p.(ContextPublisher); ok {
+		return cp.PublishMessageContext(ctx, router, msgType, msgHash, msg)
+	}
+	if ctx.Done() == nil {
+		// The context is never done, publishing in the caller's goroutine
+		return PublishRouterMessage(p, router, msgType, msgHash, msg)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("publish of message of type %d is abandoned: %w", msgType, err)
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- PublishRouterMessage(p, router, msgType, msgHash, msg)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("publish of message of type %d is abandoned: %w", msgType, ctx.Err())
+	}
+}
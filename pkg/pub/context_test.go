@@ -0,0 +1,83 @@
+package pub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingPublisher blocks every publish until release is closed
+type blockingPublisher struct {
+	release chan struct{}
+}
+
+func (b *blockingPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	<-b.release
+	return nil
+}
+
+func (b *blockingPublisher) Stop() {}
+
+// contextRecorder records the router of the last publish and fails when ctx is done
+type contextRecorder struct {
+	router Router
+}
+
+func (c *contextRecorder) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return errors.New("PublishMessage must not be called")
+}
+
+func (c *contextRecorder) PublishMessageContext(ctx context.Context, router Router, msgType int, msgHash []byte, msg []byte) error {
+	c.router = router
+	return ctx.Err()
+}
+
+func (c *contextRecorder) Stop() {}
+
+func TestPublishMessageContext(t *testing.T) {
+	b := &blockingPublisher{release: make(chan struct{})}
+	defer close(b.release)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- PublishMessageContext(ctx, b, Router{}, 1, nil, []byte(`{}`))
+	}()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected publish to fail with %v, got %+v", context.DeadlineExceeded, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish to blocking publisher was not cancelled by context")
+	}
+	// Already cancelled context fails the publish without calling the publisher
+	if err := PublishMessageContext(ctx, &flakyPublisher{failures: 1}, Router{}, 1, nil, []byte(`{}`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected publish to fail with %v, got %+v", context.DeadlineExceeded, err)
+	}
+	// ContextPublisher gets the context and the router
+	c := &contextRecorder{}
+	router := NewRouter("192.168.80.103")
+	if err := PublishMessageContext(context.Background(), c, router, 1, nil, []byte(`{}`)); err != nil {
+		t.Errorf("expected publish to succeed, got %+v", err)
+	}
+	if c.router != router {
+		t.Errorf("expected router %+v, got %+v", router, c.router)
+	}
+}
+
+func TestRetryPublisherPublishContext(t *testing.T) {
+	f := &flakyPublisher{failures: 10}
+	p := NewRetryPublisher(f, 10, time.Hour).(ContextPublisher)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.PublishMessageContext(ctx, Router{}, 1, nil, []byte(`{}`)); err == nil {
+		t.Fatal("expected publish to fail")
+	}
+	f.Lock()
+	defer f.Unlock()
+	if f.calls != 1 {
+		t.Errorf("expected 1 publish before the context is done, got %d", f.calls)
+	}
+}
@@ -0,0 +1,96 @@
+package pub
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/sbezverk/gobmp/pkg/logging"
+)
+
+// MultiError is returned by the multi publisher when one or more backends fail to publish a message
+type MultiError []error
+
+func (e MultiError) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+
+	return strings.Join(s, "; ")
+}
+
+// MultiOption defines a function which modifies optional parameters of the multi publisher
+type MultiOption func(*multiPublisher)
+
+// WithBestEffort makes a publish succeed when at least one of the backends has published the message,
+// failures of the other backends are logged. By default a failure of any backend fails the publish.
+func WithBestEffort() MultiOption {
+	return func(p *multiPublisher) {
+		p.bestEffort = true
+	}
+}
+
+// WithMultiLogger sets the logger of the multi publisher, by default and when logger is nil records are written
+// to glog
+func WithMultiLogger(logger *slog.Logger) MultiOption {
+	return func(p *multiPublisher) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+type multiPublisher struct {
+	logger     *slog.Logger
+	pubs       []Publisher
+	bestEffort bool
+}
+
+func (p *multiPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishRouterMessage(Router{}, msgType, msgHash, msg)
+}
+
+func (p *multiPublisher) PublishRouterMessage(router Router, msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishMessageContext(context.Background(), router, msgType, msgHash, msg)
+}
+
+func (p *multiPublisher) PublishMessageContext(ctx context.Context, router Router, msgType int, msgHash []byte, msg []byte) error {
+	var errs MultiError
+	for _, pub := range p.pubs {
+		if err := PublishMessageContext(ctx, pub, router, msgType, msgHash, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	if p.bestEffort && len(errs) < len(p.pubs) {
+		p.logger.Warn("failed to publish message to some of the backends", "type", msgType, "failed", len(errs), "backends", len(p.pubs), "error", errs)
+		return nil
+	}
+
+	return errs
+}
+
+func (p *multiPublisher) Stop() {
+	for _, pub := range p.pubs {
+		pub.Stop()
+	}
+}
+
+// NewMultiPublisher returns a new instance of Publisher forwarding every message to each of pubs.
+// Messages are published to the backends sequentially in the order pubs are passed, the next backend
+// gets the message only after the previous one has returned, so a slow backend delays the others.
+// The order of messages is preserved for every backend. Stop stops all backends in the same order.
+func NewMultiPublisher(pubs []Publisher, opts ...MultiOption) Publisher {
+	p := &multiPublisher{
+		logger: logging.Default(),
+		pubs:   pubs,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
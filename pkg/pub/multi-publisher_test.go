@@ -0,0 +1,111 @@
+package pub
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type fakePublisher struct {
+	err       error
+	published int
+	stopped   bool
+}
+
+func (f *fakePublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.published++
+
+	return nil
+}
+
+func (f *fakePublisher) Stop() {
+	f.stopped = true
+}
+
+func TestMultiPublisher(t *testing.T) {
+	errFail := errors.New("backend failure")
+	tests := []struct {
+		name       string
+		errs       []error
+		bestEffort bool
+		expectErrs int
+	}{
+		{
+			name: "all succeed",
+			errs: []error{nil, nil},
+		},
+		{
+			name:       "one fails",
+			errs:       []error{nil, errFail},
+			expectErrs: 1,
+		},
+		{
+			name:       "one fails best effort",
+			errs:       []error{errFail, nil},
+			bestEffort: true,
+		},
+		{
+			name:       "all fail best effort",
+			errs:       []error{errFail, errFail},
+			bestEffort: true,
+			expectErrs: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakes := make([]*fakePublisher, len(tt.errs))
+			pubs := make([]Publisher, len(tt.errs))
+			for i, err := range tt.errs {
+				fakes[i] = &fakePublisher{err: err}
+				pubs[i] = fakes[i]
+			}
+			var opts []MultiOption
+			if tt.bestEffort {
+				opts = append(opts, WithBestEffort())
+			}
+			p := NewMultiPublisher(pubs, opts...)
+			err := p.PublishMessage(7, nil, []byte(`{}`))
+			if tt.expectErrs == 0 && err != nil {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if tt.expectErrs != 0 {
+				merr, ok := err.(MultiError)
+				if !ok {
+					t.Fatalf("expected MultiError, got %T: %+v", err, err)
+				}
+				if len(merr) != tt.expectErrs {
+					t.Fatalf("expected %d errors, got %d", tt.expectErrs, len(merr))
+				}
+			}
+			for i, f := range fakes {
+				if f.err == nil && f.published != 1 {
+					t.Fatalf("backend %d expected to publish 1 message, published %d", i, f.published)
+				}
+			}
+			p.Stop()
+			for i, f := range fakes {
+				if !f.stopped {
+					t.Fatalf("backend %d has not been stopped", i)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiPublisherLogger(t *testing.T) {
+	var log bytes.Buffer
+	pubs := []Publisher{&fakePublisher{err: errors.New("backend failure")}, &fakePublisher{}}
+	p := NewMultiPublisher(pubs, WithBestEffort(), WithMultiLogger(slog.New(slog.NewTextHandler(&log, nil))))
+	if err := p.PublishMessage(7, nil, []byte(`{}`)); err != nil {
+		t.Fatalf("supposed to succeed but failed with error: %+v", err)
+	}
+	// The failure of the backend is logged by the publisher's logger
+	if !strings.Contains(log.String(), "backend failure") {
+		t.Errorf("expected failure of the backend to be logged, got log %q", log.String())
+	}
+}
@@ -0,0 +1,15 @@
+package pub
+
+type nullPublisher struct{}
+
+func (p *nullPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return nil
+}
+
+func (p *nullPublisher) Stop() {}
+
+// NewNullPublisher returns a new instance of Publisher discarding all messages, it is useful to run
+// the parser and the producer without publishing, for example to validate BMP captures.
+func NewNullPublisher() Publisher {
+	return &nullPublisher{}
+}
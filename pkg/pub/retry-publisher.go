@@ -0,0 +1,140 @@
+package pub
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/logging"
+)
+
+// defaultMaxRetryDelay defines the default maximum delay between retries
+const defaultMaxRetryDelay = time.Minute
+
+// RetryOption defines a function which modifies optional parameters of the retry publisher
+type RetryOption func(*retryPublisher)
+
+// WithRetryContext makes the retry publisher stop retrying when ctx is done or its deadline is reached,
+// the last publish error is returned in this case.
+func WithRetryContext(ctx context.Context) RetryOption {
+	return func(p *retryPublisher) {
+		p.ctx = ctx
+	}
+}
+
+// WithMaxRetryDelay sets the maximum delay between retries, the exponentially growing delay stops growing
+// once it reaches max, by default 1 minute. 0 means the delay is not limited.
+func WithMaxRetryDelay(max time.Duration) RetryOption {
+	return func(p *retryPublisher) {
+		p.maxDelay = max
+	}
+}
+
+// WithRetryLogger sets the logger of the retry publisher, by default and when logger is nil records are written
+// to glog
+func WithRetryLogger(logger *slog.Logger) RetryOption {
+	return func(p *retryPublisher) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+type retryPublisher struct {
+	logger     *slog.Logger
+	inner      Publisher
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	ctx        context.Context
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+func (p *retryPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishRouterMessage(Router{}, msgType, msgHash, msg)
+}
+
+func (p *retryPublisher) PublishRouterMessage(router Router, msgType int, msgHash []byte, msg []byte) error {
+	return p.PublishMessageContext(context.Background(), router, msgType, msgHash, msg)
+}
+
+// PublishMessageContext stops retrying when ctx is done, the last publish error is returned in this case.
+func (p *retryPublisher) PublishMessageContext(ctx context.Context, router Router, msgType int, msgHash []byte, msg []byte) error {
+	for retry := 0; ; retry++ {
+		err := PublishMessageContext(ctx, p.inner, router, msgType, msgHash, msg)
+		if err == nil {
+			return nil
+		}
+		if retry >= p.maxRetries {
+			p.logger.Error("failed to publish message", "type", msgType, "retries", retry, "error", err)
+			return err
+		}
+		delay := p.retryDelay(retry)
+		p.logger.Warn("failed to publish message, retrying", "type", msgType, "error", err, "delay", delay)
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-p.ctx.Done():
+			t.Stop()
+			return err
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-p.stop:
+			t.Stop()
+			return err
+		}
+	}
+}
+
+// retryDelay returns the delay before the retry, the delay doubles with every retry up to the maximum delay and
+// a random jitter in the range of [delay/2, delay] spreads retries of concurrent publishes.
+func (p *retryPublisher) retryDelay(retry int) time.Duration {
+	max := p.maxDelay
+	if max <= 0 {
+		max = math.MaxInt64
+	}
+	d := p.baseDelay
+	// Doubling is stopped before the delay exceeds the maximum, so the shift never overflows
+	if retry < 63 && d <= max>>uint(retry) {
+		d <<= uint(retry)
+	} else if d > 0 {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (p *retryPublisher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	p.inner.Stop()
+}
+
+// NewRetryPublisher returns a new instance of Publisher which retries a failed publish to inner
+// up to maxRetries times with exponential backoff starting from baseDelay, see WithMaxRetryDelay. Only after all retries
+// are exhausted the last error is returned. Stop aborts waiting retries and stops inner publisher.
+func NewRetryPublisher(inner Publisher, maxRetries int, baseDelay time.Duration, opts ...RetryOption) Publisher {
+	p := &retryPublisher{
+		logger:     logging.Default(),
+		inner:      inner,
+		maxRetries: maxRetries,
+		baseDelay:  baseDelay,
+		maxDelay:   defaultMaxRetryDelay,
+		ctx:        context.Background(),
+		stop:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
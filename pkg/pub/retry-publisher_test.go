@@ -0,0 +1,206 @@
+package pub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyPublisher fails the first failures publishes
+type flakyPublisher struct {
+	sync.Mutex
+	failures int
+	calls    int
+	stopped  bool
+}
+
+func (f *flakyPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	f.Lock()
+	defer f.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("broker is not available")
+	}
+
+	return nil
+}
+
+func (f *flakyPublisher) Stop() {
+	f.Lock()
+	defer f.Unlock()
+	f.stopped = true
+}
+
+func TestRetryPublisher(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		maxRetries  int
+		fail        bool
+		expectCalls int
+	}{
+		{
+			name:        "no failures",
+			maxRetries:  3,
+			expectCalls: 1,
+		},
+		{
+			name:        "succeeds after failures",
+			failures:    2,
+			maxRetries:  3,
+			expectCalls: 3,
+		},
+		{
+			name:        "retries exhausted",
+			failures:    5,
+			maxRetries:  3,
+			fail:        true,
+			expectCalls: 4,
+		},
+		{
+			name:        "no retries",
+			failures:    1,
+			fail:        true,
+			expectCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &flakyPublisher{failures: tt.failures}
+			p := NewRetryPublisher(inner, tt.maxRetries, time.Millisecond)
+			err := p.PublishMessage(0, nil, []byte("message"))
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if inner.calls != tt.expectCalls {
+				t.Fatalf("expected %d publish calls, got %d", tt.expectCalls, inner.calls)
+			}
+			p.Stop()
+			if !inner.stopped {
+				t.Fatalf("expected inner publisher to be stopped")
+			}
+		})
+	}
+}
+
+func TestRetryPublisherContext(t *testing.T) {
+	inner := &flakyPublisher{failures: 100}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	p := NewRetryPublisher(inner, 100, time.Second, WithRetryContext(ctx))
+	start := time.Now()
+	if err := p.PublishMessage(0, nil, []byte("message")); err == nil {
+		t.Fatalf("supposed to fail but succeeded")
+	}
+	if d := time.Since(start); d > 400*time.Millisecond {
+		t.Fatalf("publish did not respect context deadline, returned after %s", d)
+	}
+}
+
+func TestRetryPublisherStop(t *testing.T) {
+	inner := &flakyPublisher{failures: 100}
+	p := NewRetryPublisher(inner, 100, time.Second)
+	errCh := make(chan error)
+	go func() {
+		errCh <- p.PublishMessage(0, nil, []byte("message"))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	p.Stop()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("supposed to fail but succeeded")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("publish was not aborted by Stop")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     time.Duration
+		opts     []RetryOption
+		retry    int
+		min, max time.Duration
+	}{
+		{
+			name:  "first retry",
+			base:  100 * time.Millisecond,
+			retry: 0,
+			min:   50 * time.Millisecond,
+			max:   100 * time.Millisecond,
+		},
+		{
+			name:  "doubled delay",
+			base:  100 * time.Millisecond,
+			retry: 3,
+			min:   400 * time.Millisecond,
+			max:   800 * time.Millisecond,
+		},
+		{
+			name:  "default maximum delay",
+			base:  100 * time.Millisecond,
+			retry: 40,
+			min:   defaultMaxRetryDelay / 2,
+			max:   defaultMaxRetryDelay,
+		},
+		{
+			name:  "shift exceeding duration bits",
+			base:  100 * time.Millisecond,
+			retry: 1000,
+			min:   defaultMaxRetryDelay / 2,
+			max:   defaultMaxRetryDelay,
+		},
+		{
+			name:  "custom maximum delay",
+			base:  100 * time.Millisecond,
+			opts:  []RetryOption{WithMaxRetryDelay(time.Second)},
+			retry: 10,
+			min:   500 * time.Millisecond,
+			max:   time.Second,
+		},
+		{
+			name:  "unlimited delay",
+			base:  100 * time.Millisecond,
+			opts:  []RetryOption{WithMaxRetryDelay(0)},
+			retry: 100,
+			min:   math.MaxInt64 / 2,
+			max:   math.MaxInt64,
+		},
+		{
+			name:  "no base delay",
+			retry: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewRetryPublisher(&flakyPublisher{}, 1, tt.base, tt.opts...).(*retryPublisher)
+			if d := p.retryDelay(tt.retry); d < tt.min || d > tt.max {
+				t.Fatalf("expected delay in [%s, %s], got %s", tt.min, tt.max, d)
+			}
+		})
+	}
+}
+
+func TestRetryPublisherLogger(t *testing.T) {
+	var log bytes.Buffer
+	inner := &flakyPublisher{failures: 2}
+	p := NewRetryPublisher(inner, 1, time.Millisecond, WithRetryLogger(slog.New(slog.NewTextHandler(&log, nil))))
+	if err := p.PublishMessage(7, nil, []byte(`{}`)); err == nil {
+		t.Fatalf("supposed to fail but succeeded")
+	}
+	// Both the retried and the last failure are logged by the publisher's logger
+	if !strings.Contains(log.String(), "level=WARN") || !strings.Contains(log.String(), "level=ERROR") {
+		t.Errorf("expected retried and last failures to be logged, got log %q", log.String())
+	}
+}
@@ -0,0 +1,41 @@
+package pub
+
+import (
+	"crypto/md5"
+	"fmt"
+)
+
+// Router identifies the monitored router, by its BMP session, a message was produced from.
+// Router is zero when the session is unknown, for example for messages replayed from a file.
+type Router struct {
+	// Address is the remote address of the BMP session
+	Address string
+	// Hash is md5 hash of Address, computed the same way as router_hash of published messages
+	Hash string
+}
+
+// NewRouter returns Router of BMP session with the remote address
+func NewRouter(addr string) Router {
+	return Router{
+		Address: addr,
+		Hash:    fmt.Sprintf("%x", md5.Sum([]byte(addr))),
+	}
+}
+
+// RouterPublisher is implemented by publishers choosing topics, subjects or keys per monitored router.
+// The router is known before the message is published, unlike router_ip and router_hash of the message
+// which change when Peer Up message is received.
+type RouterPublisher interface {
+	Publisher
+	PublishRouterMessage(router Router, msgType int, msgHash []byte, msg []byte) error
+}
+
+// PublishRouterMessage publishes msg produced from router to p, when p does not implement RouterPublisher
+// the router is ignored and msg is published by PublishMessage.
+func PublishRouterMessage(p Publisher, router Router, msgType int, msgHash []byte, msg []byte) error {
+	if rp, ok := p.(RouterPublisher); ok {
+		return rp.PublishRouterMessage(router, msgType, msgHash, msg)
+	}
+
+	return p.PublishMessage(msgType, msgHash, msg)
+}
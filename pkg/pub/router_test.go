@@ -0,0 +1,68 @@
+package pub
+
+import (
+	"testing"
+)
+
+// routerRecorder records routers of published messages
+type routerRecorder struct {
+	routers []Router
+}
+
+func (r *routerRecorder) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	return r.PublishRouterMessage(Router{}, msgType, msgHash, msg)
+}
+
+func (r *routerRecorder) PublishRouterMessage(router Router, msgType int, msgHash []byte, msg []byte) error {
+	r.routers = append(r.routers, router)
+	return nil
+}
+
+func (r *routerRecorder) Stop() {}
+
+func TestPublishRouterMessage(t *testing.T) {
+	router := NewRouter("192.168.80.103")
+	if router.Hash != "1f101e2bd3415b5b65f51fe19222e561" {
+		t.Fatalf("expected router hash 1f101e2bd3415b5b65f51fe19222e561, got %s", router.Hash)
+	}
+	tests := []struct {
+		name string
+		wrap func(Publisher) Publisher
+	}{
+		{
+			name: "router publisher",
+			wrap: func(p Publisher) Publisher { return p },
+		},
+		{
+			name: "retry publisher",
+			wrap: func(p Publisher) Publisher { return NewRetryPublisher(p, 1, 0) },
+		},
+		{
+			name: "multi publisher",
+			wrap: func(p Publisher) Publisher { return NewMultiPublisher([]Publisher{p}) },
+		},
+		{
+			name: "batch publisher",
+			wrap: func(p Publisher) Publisher { return NewBatchPublisher(p, 1, 0) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &routerRecorder{}
+			if err := PublishRouterMessage(tt.wrap(r), router, 10, []byte(router.Hash), []byte(`{}`)); err != nil {
+				t.Fatalf("failed to publish message with error: %+v", err)
+			}
+			if len(r.routers) != 1 || r.routers[0] != router {
+				t.Errorf("expected message of router %+v, got %+v", router, r.routers)
+			}
+		})
+	}
+	// Publisher not implementing RouterPublisher gets the message without the router
+	f := &fakePublisher{}
+	if err := PublishRouterMessage(f, router, 10, []byte(router.Hash), []byte(`{}`)); err != nil {
+		t.Fatalf("failed to publish message with error: %+v", err)
+	}
+	if f.published != 1 {
+		t.Errorf("expected 1 published message, got %d", f.published)
+	}
+}
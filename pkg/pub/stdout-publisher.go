@@ -0,0 +1,54 @@
+package pub
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// StdoutMsg defines the structure of the message written by the stdout publisher,
+// one JSON object per line.
+type StdoutMsg struct {
+	Type  int             `json:"type"`
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+type stdoutPublisher struct {
+	sync.Mutex
+	w *bufio.Writer
+}
+
+func (p *stdoutPublisher) PublishMessage(msgType int, msgHash []byte, msg []byte) error {
+	b, err := json.Marshal(&StdoutMsg{
+		Type:  msgType,
+		Key:   string(msgHash),
+		Value: json.RawMessage(msg),
+	})
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	p.Lock()
+	defer p.Unlock()
+	if _, err := p.w.Write(b); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p *stdoutPublisher) Stop() {
+	p.Lock()
+	defer p.Unlock()
+	p.w.Flush()
+}
+
+// NewStdoutPublisher returns a new instance of Publisher writing messages as newline delimited JSON
+// to w, writes are buffered and flushed when the publisher is stopped. It is safe for concurrent use.
+func NewStdoutPublisher(w io.Writer) Publisher {
+	return &stdoutPublisher{
+		w: bufio.NewWriter(w),
+	}
+}
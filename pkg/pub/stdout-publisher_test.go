@@ -0,0 +1,93 @@
+package pub
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestStdoutPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		msgType int
+		msgHash []byte
+		msg     []byte
+		expect  string
+		fail    bool
+	}{
+		{
+			name:    "message with key",
+			msgType: 7,
+			msgHash: []byte("c447165a4239db770f610e30dc5df7a7"),
+			msg:     []byte(`{"action":"add","prefix":"10.0.0.0","prefix_len":8}`),
+			expect:  `{"type":7,"key":"c447165a4239db770f610e30dc5df7a7","value":{"action":"add","prefix":"10.0.0.0","prefix_len":8}}` + "\n",
+		},
+		{
+			name:    "message without key",
+			msgType: 1,
+			msg:     []byte(`{"action":"up"}`),
+			expect:  `{"type":1,"value":{"action":"up"}}` + "\n",
+		},
+		{
+			name:    "invalid json message",
+			msgType: 1,
+			msg:     []byte(`{"action":`),
+			fail:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			p := NewStdoutPublisher(&buf)
+			err := p.PublishMessage(tt.msgType, tt.msgHash, tt.msg)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			p.Stop()
+			if got := buf.String(); got != tt.expect {
+				t.Fatalf("expected output %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestStdoutPublisherConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewStdoutPublisher(&buf)
+	msg := []byte(`{"action":"add","prefix":"192.168.0.0","prefix_len":16}`)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := p.PublishMessage(7, nil, msg); err != nil {
+					t.Errorf("failed to publish message with error: %+v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	p.Stop()
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var m StdoutMsg
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("failed to unmarshal line %q with error: %+v", scanner.Text(), err)
+		}
+		if !reflect.DeepEqual([]byte(m.Value), msg) {
+			t.Fatalf("expected value %s, got %s", msg, m.Value)
+		}
+		lines++
+	}
+	if lines != 1000 {
+		t.Fatalf("expected 1000 lines, got %d", lines)
+	}
+}
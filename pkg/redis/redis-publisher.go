@@ -0,0 +1,160 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sbezverk/gobmp/pkg/logging"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+const (
+	// defaultStream is the key of the stream messages are added to
+	defaultStream = "gobmp.parsed"
+	// defaultTimeout is the time to wait for Redis to add a published message to the stream
+	defaultTimeout = 5 * time.Second
+)
+
+// Names of the fields of stream entries carrying the type, the key and the message by default
+const (
+	DefaultTypeField    = "type"
+	DefaultHashField    = "hash"
+	DefaultMessageField = "message"
+)
+
+// Option defines a function which modifies optional parameters of Redis publisher
+type Option func(*publisher)
+
+// WithStream sets the key of the stream messages of all types are added to, by default "gobmp.parsed".
+func WithStream(key string) Option {
+	return func(p *publisher) {
+		p.stream = key
+	}
+}
+
+// WithMaxLen trims the stream to maxLen entries when a message is added, older entries are evicted.
+// When approx is set, the stream is trimmed only when Redis can remove a whole node, so it may keep
+// somewhat more than maxLen entries, which is much more efficient. By default the stream is not trimmed.
+func WithMaxLen(maxLen int64, approx bool) Option {
+	return func(p *publisher) {
+		p.maxLen = maxLen
+		p.approx = approx
+	}
+}
+
+// WithFields sets the names of the fields of stream entries carrying the message type, as defined in
+// pkg/bmp/consts.go, the key of the message and the message, by default "type", "hash" and "message".
+// An empty name of the type or the key field omits the field.
+func WithFields(typeField, hashField, messageField string) Option {
+	return func(p *publisher) {
+		p.typeField = typeField
+		p.hashField = hashField
+		p.messageField = messageField
+	}
+}
+
+// WithTimeout sets the time to wait for Redis to add a message to the stream, PublishMessage returns
+// an error when the message is not added in time and the message may not be stored.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *publisher) {
+		p.timeout = timeout
+	}
+}
+
+// WithLogger sets the logger of the publisher, by default and when logger is nil records are written to glog
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *publisher) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+type publisher struct {
+	logger       *slog.Logger
+	client       *redis.Client
+	stream       string
+	maxLen       int64
+	approx       bool
+	typeField    string
+	hashField    string
+	messageField string
+	timeout      time.Duration
+}
+
+func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	return p.PublishMessageContext(ctx, pub.Router{}, t, key, msg)
+}
+
+// PublishMessageContext adds msg to the stream, the command is canceled when ctx is done.
+func (p *publisher) PublishMessageContext(ctx context.Context, router pub.Router, t int, key []byte, msg []byte) error {
+	values := make([]interface{}, 0, 6)
+	if p.typeField != "" {
+		values = append(values, p.typeField, strconv.Itoa(t))
+	}
+	if p.hashField != "" && len(key) != 0 {
+		values = append(values, p.hashField, key)
+	}
+	values = append(values, p.messageField, msg)
+	if err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		MaxLen: p.maxLen,
+		Approx: p.approx,
+		Values: values,
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to add message of type %d to stream %s with error: %w", t, p.stream, err)
+	}
+
+	return nil
+}
+
+func (p *publisher) Stop() {
+	if err := p.client.Close(); err != nil {
+		p.logger.Error("failed to close Redis client", "error", err)
+	}
+}
+
+// NewPublisher instantiates a new instance of a Redis publisher, every message is added to Redis stream
+// with XADD as an entry carrying the message type, the key and the message. redisSrv is either host:port
+// of Redis server or redis:// or rediss:// URL, which may carry the credentials and the database.
+func NewPublisher(redisSrv string, opts ...Option) (pub.Publisher, error) {
+	p := &publisher{
+		logger:       logging.Default(),
+		stream:       defaultStream,
+		typeField:    DefaultTypeField,
+		hashField:    DefaultHashField,
+		messageField: DefaultMessageField,
+		timeout:      defaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.logger.Info("Initializing Redis publisher")
+	if p.messageField == "" {
+		return nil, fmt.Errorf("name of the message field must not be empty")
+	}
+	redisOpts := &redis.Options{Addr: redisSrv}
+	if strings.Contains(redisSrv, "://") {
+		var err error
+		if redisOpts, err = redis.ParseURL(redisSrv); err != nil {
+			return nil, err
+		}
+	}
+	p.client = redis.NewClient(redisOpts)
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	if err := p.client.Ping(ctx).Err(); err != nil {
+		p.client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis server %s with error: %w", redisOpts.Addr, err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+func TestPublishMessage(t *testing.T) {
+	msgs := []string{`{"action":"add","prefix":"10.0.0.0"}`, `{"action":"del","prefix":"10.0.0.0"}`}
+	msgType := strconv.Itoa(bmp.UnicastPrefixV4Msg)
+	tests := []struct {
+		name   string
+		opts   []Option
+		stream string
+		expect [][]string
+	}{
+		{
+			name:   "default stream and fields",
+			stream: defaultStream,
+			expect: [][]string{
+				{"type", msgType, "hash", "router_hash", "message", msgs[0]},
+				{"type", msgType, "hash", "router_hash", "message", msgs[1]},
+			},
+		},
+		{
+			name:   "custom stream and fields",
+			opts:   []Option{WithStream("bmp"), WithFields("msg_type", "", "data")},
+			stream: "bmp",
+			expect: [][]string{
+				{"msg_type", msgType, "data", msgs[0]},
+				{"msg_type", msgType, "data", msgs[1]},
+			},
+		},
+		{
+			name:   "stream trimmed",
+			opts:   []Option{WithMaxLen(1, false)},
+			stream: defaultStream,
+			expect: [][]string{
+				{"type", msgType, "hash", "router_hash", "message", msgs[1]},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := miniredis.RunT(t)
+			p, err := NewPublisher(srv.Addr(), tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create Redis publisher with error: %+v", err)
+			}
+			for _, msg := range msgs {
+				if err := p.PublishMessage(bmp.UnicastPrefixV4Msg, []byte("router_hash"), []byte(msg)); err != nil {
+					t.Fatalf("failed to publish message with error: %+v", err)
+				}
+			}
+			p.Stop()
+			entries, err := srv.Stream(tt.stream)
+			if err != nil {
+				t.Fatalf("failed to read stream %s with error: %+v", tt.stream, err)
+			}
+			got := make([][]string, 0, len(entries))
+			for _, entry := range entries {
+				got = append(got, entry.Values)
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Fatalf("expected entries %v, got %v", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestPublisherStop(t *testing.T) {
+	srv := miniredis.RunT(t)
+	var log bytes.Buffer
+	p, err := NewPublisher("redis://"+srv.Addr(), WithLogger(slog.New(slog.NewTextHandler(&log, nil))))
+	if err != nil {
+		t.Fatalf("failed to create Redis publisher with error: %+v", err)
+	}
+	p.Stop()
+	if err := p.PublishMessage(bmp.PeerStateChangeMsg, []byte("router_hash"), []byte(`{"action":"add"}`)); err == nil {
+		t.Fatalf("expected publish to fail after Stop but succeeded")
+	}
+	// Closing the closed client fails, the failure is logged by the publisher's logger
+	p.Stop()
+	if !strings.Contains(log.String(), "failed to close Redis client") {
+		t.Errorf("expected failure to close Redis client to be logged, got log %q", log.String())
+	}
+}
+
+func TestNewPublisherFailure(t *testing.T) {
+	srv := miniredis.RunT(t)
+	addr := srv.Addr()
+	srv.Close()
+	if _, err := NewPublisher(addr, WithTimeout(100*time.Millisecond)); err == nil {
+		t.Fatalf("expected to fail to connect to stopped Redis server but succeeded")
+	}
+	if _, err := NewPublisher(addr, WithFields("type", "hash", "")); err == nil {
+		t.Fatalf("expected to fail with empty message field but succeeded")
+	}
+}
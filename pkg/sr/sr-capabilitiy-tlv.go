@@ -22,6 +22,10 @@ func UnmarshalSRCapabilitySubTLV(b []byte) ([]CapabilitySubTLV, error) {
 	}
 	caps := make([]CapabilitySubTLV, 0)
 	for p := 0; p < len(b); {
+		// Range 3 bytes followed by SID/Label sub tlv type and length, 2 bytes each
+		if p+7 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal SR Capability tlv")
+		}
 		cap := CapabilitySubTLV{}
 		r := make([]byte, 4)
 		// Copy 3 bytes of Range into 4 byte slice to convert it into uint32
@@ -39,6 +43,9 @@ func UnmarshalSRCapabilitySubTLV(b []byte) ([]CapabilitySubTLV, error) {
 		default:
 			return nil, fmt.Errorf("unknown SR Capability tlv type %d", t)
 		}
+		if p+int(l) > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal SR Capability tlv type %d", t)
+		}
 		s := make([]byte, 4)
 		switch l {
 		case 3:
@@ -94,6 +94,9 @@ func UnmarshalSRCapability(b []byte, proto base.ProtoID) (*Capability, error) {
 	if glog.V(6) {
 		glog.Infof("SR Capability Raw: %s", tools.MessageHex(b))
 	}
+	if len(b) < 2 {
+		return nil, fmt.Errorf("not enough bytes to unmarshal SR Capability")
+	}
 	cap := Capability{}
 	p := 0
 	switch proto {
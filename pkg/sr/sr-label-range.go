@@ -0,0 +1,39 @@
+package sr
+
+// LabelRange defines a range of MPLS labels advertised in SRGB or SRLB, RangeSize labels
+// starting with BaseLabel.
+type LabelRange struct {
+	BaseLabel uint32 `json:"base_label"`
+	RangeSize uint32 `json:"range_size"`
+}
+
+// SRGB returns label ranges of Segment Routing Global Block advertised in SR Capabilities TLV,
+// SRGB is always advertised with labels, only 20 rightmost bits of SID/Label are used.
+func (c *Capability) SRGB() []LabelRange {
+	if c == nil {
+		return nil
+	}
+	srgb := make([]LabelRange, 0, len(c.SubTLV))
+	for _, tlv := range c.SubTLV {
+		srgb = append(srgb, LabelRange{BaseLabel: tlv.SID & 0x000fffff, RangeSize: tlv.Range})
+	}
+
+	return srgb
+}
+
+// SRLB returns label ranges of Segment Routing Local Block, sub-ranges advertised with
+// an index instead of a label are skipped.
+func (lb *LocalBlock) SRLB() []LabelRange {
+	if lb == nil {
+		return nil
+	}
+	srlb := make([]LabelRange, 0, len(lb.TLV))
+	for _, tlv := range lb.TLV {
+		if tlv.Label == nil {
+			continue
+		}
+		srlb = append(srlb, LabelRange{BaseLabel: *tlv.Label, RangeSize: tlv.SubRange})
+	}
+
+	return srlb
+}
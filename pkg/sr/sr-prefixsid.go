@@ -21,6 +21,9 @@ type PrefixSIDTLV struct {
 	Flags     PrefixSIDFlags `json:"flags,omitempty"`
 	Algorithm uint8          `json:"algo"`
 	SID       uint32         `json:"prefix_sid"`
+	// Label is set when V and L flags are set and SID carries a label, otherwise Index is set
+	Label *uint32 `json:"label,omitempty"`
+	Index *uint32 `json:"index,omitempty"`
 }
 
 func (p *PrefixSIDTLV) MarshalJSON() ([]byte, error) {
@@ -31,10 +34,14 @@ func (p *PrefixSIDTLV) MarshalJSON() ([]byte, error) {
 			Flags     *ISISFlags `json:"flags,omitempty"`
 			Algorithm uint8      `json:"algo"`
 			SID       uint32     `json:"prefix_sid"`
+			Label     *uint32    `json:"label,omitempty"`
+			Index     *uint32    `json:"index,omitempty"`
 		}{
 			Flags:     f,
 			Algorithm: p.Algorithm,
 			SID:       p.SID,
+			Label:     p.Label,
+			Index:     p.Index,
 		})
 	case *OSPFFlags:
 		f := p.Flags.(*OSPFFlags)
@@ -42,10 +49,14 @@ func (p *PrefixSIDTLV) MarshalJSON() ([]byte, error) {
 			Flags     *OSPFFlags `json:"flags,omitempty"`
 			Algorithm uint8      `json:"algo"`
 			SID       uint32     `json:"prefix_sid"`
+			Label     *uint32    `json:"label,omitempty"`
+			Index     *uint32    `json:"index,omitempty"`
 		}{
 			Flags:     f,
 			Algorithm: p.Algorithm,
 			SID:       p.SID,
+			Label:     p.Label,
+			Index:     p.Index,
 		})
 	default:
 		f := p.Flags.(*UnknownProtoFlags)
@@ -53,10 +64,14 @@ func (p *PrefixSIDTLV) MarshalJSON() ([]byte, error) {
 			Flags     *UnknownProtoFlags `json:"flags,omitempty"`
 			Algorithm uint8              `json:"algo"`
 			SID       uint32             `json:"prefix_sid"`
+			Label     *uint32            `json:"label,omitempty"`
+			Index     *uint32            `json:"index,omitempty"`
 		}{
 			Flags:     f,
 			Algorithm: p.Algorithm,
 			SID:       p.SID,
+			Label:     p.Label,
+			Index:     p.Index,
 		})
 	}
 }
@@ -107,6 +122,18 @@ func (p *PrefixSIDTLV) UnmarshalJSON(b []byte) error {
 			return err
 		}
 	}
+	// Label     *uint32        `json:"label,omitempty"`
+	if v, ok := objVal["label"]; ok {
+		if err := json.Unmarshal(v, &result.Label); err != nil {
+			return err
+		}
+	}
+	// Index     *uint32        `json:"index,omitempty"`
+	if v, ok := objVal["index"]; ok {
+		if err := json.Unmarshal(v, &result.Index); err != nil {
+			return err
+		}
+	}
 	*p = *result
 
 	return nil
@@ -117,6 +144,10 @@ func UnmarshalPrefixSIDTLV(b []byte, proto base.ProtoID) (*PrefixSIDTLV, error)
 	if glog.V(6) {
 		glog.Infof("Prefix SID TLV Raw: %s for proto: %+v", tools.MessageHex(b), proto)
 	}
+	// If length of Prefix SID TLV 7 bytes, then SID is 20 bits label, if 8 bytes then SID is 4 bytes index
+	if len(b) != 7 && len(b) != 8 {
+		return nil, fmt.Errorf("invalid length %d for Prefix SID TLV", len(b))
+	}
 	psid := PrefixSIDTLV{}
 	p := 0
 	switch proto {
@@ -147,18 +178,19 @@ func UnmarshalPrefixSIDTLV(b []byte, proto base.ProtoID) (*PrefixSIDTLV, error)
 	psid.Algorithm = b[p]
 	p++
 	// SID length would be Length of b - Flags 1 byte - Algorithm 1 byte - 2 bytes Reserved
-	// If length of Prefix SID TLV 7 bytes, then SID is 20 bits label, if 8 bytes then SID is 4 bytes index
 	p += 2
 	s := make([]byte, 4)
-	switch len(b) {
-	case 7:
-		copy(s[1:], b[p:p+3])
-	case 8:
-		copy(s, b[p:p+4])
-	default:
-		return nil, fmt.Errorf("invalid length %d for Prefix SID TLV", len(b))
-	}
+	copy(s[4-(len(b)-p):], b[p:])
 	psid.SID = binary.BigEndian.Uint32(s)
+	// V (Value) and L (Local) flags are at the same position for all protocols, when both are set
+	// the SID carries a label, otherwise an index.
+	if psid.Flags.GetPrefixSIDFlagByte()&0x0c == 0x0c {
+		label := psid.SID & 0x000fffff
+		psid.Label = &label
+	} else {
+		index := psid.SID
+		psid.Index = &index
+	}
 
 	return &psid, nil
 }
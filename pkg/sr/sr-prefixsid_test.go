@@ -31,6 +31,7 @@ func TestUnmarshalPrefixSIDTLV(t *testing.T) {
 				},
 				Algorithm: 129,
 				SID:       20007,
+				Index:     pUint32(20007),
 			},
 			fail: false,
 		},
@@ -49,6 +50,7 @@ func TestUnmarshalPrefixSIDTLV(t *testing.T) {
 				},
 				Algorithm: 0,
 				SID:       8,
+				Index:     pUint32(8),
 			},
 			fail: false,
 		},
@@ -66,9 +68,44 @@ func TestUnmarshalPrefixSIDTLV(t *testing.T) {
 				},
 				Algorithm: 0,
 				SID:       212,
+				Index:     pUint32(212),
 			},
 			fail: false,
 		},
+		{
+			name:  "isis label",
+			input: []byte{0x0C, 0x00, 0x00, 0x00, 0x00, 0x3E, 0x81},
+			proto: base.ISISL2,
+			prefixSIDTLV: &PrefixSIDTLV{
+				Flags: &ISISFlags{
+					VFlag: true,
+					LFlag: true,
+				},
+				Algorithm: 0,
+				SID:       16001,
+				Label:     pUint32(16001),
+			},
+		},
+		{
+			name:  "ospf label",
+			input: []byte{0x0C, 0x01, 0x00, 0x00, 0xF0, 0x3E, 0x81},
+			proto: base.OSPFv2,
+			prefixSIDTLV: &PrefixSIDTLV{
+				Flags: &OSPFFlags{
+					VFlag: true,
+					LFlag: true,
+				},
+				Algorithm: 1,
+				SID:       0xF03E81,
+				Label:     pUint32(16001),
+			},
+		},
+		{
+			name:  "invalid length",
+			input: []byte{0x0C, 0x00, 0x00, 0x00, 0x3E, 0x81},
+			proto: base.ISISL2,
+			fail:  true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -140,6 +177,20 @@ func TestRoundTripPrefixSIDTLV(t *testing.T) {
 				},
 				Algorithm: 0,
 				SID:       212,
+				Index:     pUint32(212),
+			},
+		},
+		{
+			name:  "label",
+			proto: base.ISISL2,
+			original: &PrefixSIDTLV{
+				Flags: &ISISFlags{
+					VFlag: true,
+					LFlag: true,
+				},
+				Algorithm: 0,
+				SID:       16001,
+				Label:     pUint32(16001),
 			},
 		},
 	}
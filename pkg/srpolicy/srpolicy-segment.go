@@ -4,8 +4,10 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"net"
 
 	"github.com/golang/glog"
+	"github.com/sbezverk/gobmp/pkg/srv6"
 	"github.com/sbezverk/tools"
 )
 
@@ -99,9 +101,17 @@ func (sl *SegmentList) UnmarshalJSON(b []byte) error {
 				}
 				seg = t
 			case TypeB:
-				fallthrough
+				t := &typeBSegment{}
+				if err := t.unmarshalJSONObj(s); err != nil {
+					return err
+				}
+				seg = t
 			case TypeC:
-				fallthrough
+				t := &typeCSegment{}
+				if err := t.unmarshalJSONObj(s); err != nil {
+					return err
+				}
+				seg = t
 			case TypeD:
 				fallthrough
 			case TypeE:
@@ -142,13 +152,19 @@ func UnmarshalSegmentListSTLV(b []byte) (*SegmentList, error) {
 	for p < len(b) {
 		t := int(b[p])
 		p++
+		if p >= len(b) {
+			return nil, fmt.Errorf("missing length of segment list sub tlv %d", t)
+		}
+		l := int(b[p])
+		p++
+		if p+l > len(b) {
+			return nil, fmt.Errorf("invalid length %d of segment list sub tlv %d, remaining data length %d", l, t, len(b)-p)
+		}
 		switch t {
 		case WEIGHTSTLV:
 			if sl.Weight != nil {
 				return nil, fmt.Errorf("Segment List Sub TLV can carry a single instance of Weight")
 			}
-			l := b[p]
-			p++
 			if l != 6 {
 				return nil, fmt.Errorf("invalid length %d of raw data for Weight Sub TLV", l)
 			}
@@ -157,43 +173,30 @@ func UnmarshalSegmentListSTLV(b []byte) (*SegmentList, error) {
 				Weight: binary.BigEndian.Uint32(b[p+2 : p+2+4]),
 			}
 			sl.Weight = w
-			p += int(l)
 		case int(TypeA):
-			glog.Infof("Segment of type A")
-			l := b[p]
-			p++
-			if l != 6 {
-				return nil, fmt.Errorf("invalid length %d of raw data for Type A Segment Sub TLV", l)
-			}
-			s, err := UnmarshalTypeASegment(b[p : p+int(l)])
+			s, err := UnmarshalTypeASegment(b[p : p+l])
 			if err != nil {
 				return nil, err
 			}
 			sl.Segment = append(sl.Segment, s)
-			p += int(l)
 		case int(TypeB):
-			glog.Infof("Segment of type B not implemented")
+			s, err := UnmarshalTypeBSegment(b[p : p+l])
+			if err != nil {
+				return nil, err
+			}
+			sl.Segment = append(sl.Segment, s)
 		case int(TypeC):
-			glog.Infof("Segment of type C not implemented")
-		case int(TypeD):
-			glog.Infof("Segment of type D not implemented")
-		case int(TypeE):
-			glog.Infof("Segment of type E not implemented")
-		case int(TypeF):
-			glog.Infof("Segment of type F not implemented")
-		case int(TypeG):
-			glog.Infof("Segment of type G not implemented")
-		case int(TypeH):
-			glog.Infof("Segment of type H not implemented")
-		case int(TypeI):
-			glog.Infof("Segment of type I not implemented")
-		case int(TypeJ):
-			glog.Infof("Segment of type J not implemented")
-		case int(TypeK):
-			glog.Infof("Segment of type K not implemented")
+			s, err := UnmarshalTypeCSegment(b[p : p+l])
+			if err != nil {
+				return nil, err
+			}
+			sl.Segment = append(sl.Segment, s)
+		case int(TypeD), int(TypeE), int(TypeF), int(TypeG), int(TypeH), int(TypeI), int(TypeJ), int(TypeK):
+			glog.Warningf("Segment of type %d is not supported, skipping it", t)
 		default:
 			return nil, fmt.Errorf("unknown type of segment sub tlv %d", t)
 		}
+		p += l
 	}
 	return sl, nil
 }
@@ -356,3 +359,254 @@ func UnmarshalTypeASegment(b []byte) (Segment, error) {
 
 	return s, nil
 }
+
+// TypeBSegment defines method to access Type B specifc elements
+type TypeBSegment interface {
+	GetSID() net.IP
+	GetEndpointBehavior() uint16
+	GetSIDStructure() *srv6.SIDStructure
+}
+type typeBSegment struct {
+	flags    *SegmentFlags
+	sid      net.IP
+	behavior uint16
+	// structure is nil when the segment does not carry SRv6 Endpoint Behavior and SID Structure
+	structure *srv6.SIDStructure
+}
+
+var _ Segment = &typeBSegment{}
+var _ TypeBSegment = &typeBSegment{}
+
+func (tb *typeBSegment) GetFlags() *SegmentFlags {
+	return tb.flags
+}
+func (tb *typeBSegment) GetType() SegmentType {
+	return TypeB
+}
+
+func (tb *typeBSegment) GetSID() net.IP {
+	return tb.sid
+}
+func (tb *typeBSegment) GetEndpointBehavior() uint16 {
+	return tb.behavior
+}
+func (tb *typeBSegment) GetSIDStructure() *srv6.SIDStructure {
+	return tb.structure
+}
+
+func (tb *typeBSegment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SegmentType      SegmentType        `json:"segment_type,omitempty"`
+		Flags            *SegmentFlags      `json:"flags,omitempty"`
+		SID              net.IP             `json:"sid,omitempty"`
+		EndpointBehavior uint16             `json:"endpoint_behavior,omitempty"`
+		SIDStructure     *srv6.SIDStructure `json:"sid_structure,omitempty"`
+	}{
+		SegmentType:      TypeB,
+		Flags:            tb.flags,
+		SID:              tb.sid,
+		EndpointBehavior: tb.behavior,
+		SIDStructure:     tb.structure,
+	})
+}
+
+func (tb *typeBSegment) unmarshalJSONObj(objmap map[string]json.RawMessage) error {
+	if b, ok := objmap["flags"]; ok {
+		if err := json.Unmarshal(b, &tb.flags); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["sid"]; ok {
+		if err := json.Unmarshal(b, &tb.sid); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["endpoint_behavior"]; ok {
+		if err := json.Unmarshal(b, &tb.behavior); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["sid_structure"]; ok {
+		if err := json.Unmarshal(b, &tb.structure); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalTypeBSegment instantiates an instance of Type B Segment sub tlv, SRv6 Endpoint Behavior
+// and SID Structure are optional and present when the sub tlv is 26 bytes long.
+func UnmarshalTypeBSegment(b []byte) (Segment, error) {
+	if glog.V(5) {
+		glog.Infof("SR Policy Type B Segment STLV Raw: %s", tools.MessageHex(b))
+	}
+	if len(b) != 18 && len(b) != 26 {
+		return nil, fmt.Errorf("invalid length of Type B Segment STLV")
+	}
+	s := &typeBSegment{}
+	p := 0
+	s.flags = NewSegmentFlags(b[p])
+	p++
+	// Skip reserved byte
+	p++
+	s.sid = make(net.IP, 16)
+	copy(s.sid, b[p:p+16])
+	p += 16
+	if p == len(b) {
+		return s, nil
+	}
+	s.behavior = binary.BigEndian.Uint16(b[p : p+2])
+	p += 2
+	// Skip reserved bytes
+	p += 2
+	s.structure = &srv6.SIDStructure{
+		LBLength:  b[p],
+		LNLength:  b[p+1],
+		FunLength: b[p+2],
+		ArgLength: b[p+3],
+	}
+
+	return s, nil
+}
+
+// TypeCSegment defines method to access Type C specifc elements
+type TypeCSegment interface {
+	GetSRAlgorithm() byte
+	GetNodeAddress() net.IP
+	// GetLabel returns nil when the segment does not carry SR-MPLS SID
+	GetLabel() *uint32
+	GetTC() byte
+	GetS() bool
+	GetTTL() byte
+}
+type typeCSegment struct {
+	flags     *SegmentFlags
+	algorithm byte
+	address   net.IP
+	label     *uint32
+	tc        byte
+	s         bool
+	ttl       byte
+}
+
+var _ Segment = &typeCSegment{}
+var _ TypeCSegment = &typeCSegment{}
+
+func (tc *typeCSegment) GetFlags() *SegmentFlags {
+	return tc.flags
+}
+func (tc *typeCSegment) GetType() SegmentType {
+	return TypeC
+}
+
+func (tc *typeCSegment) GetSRAlgorithm() byte {
+	return tc.algorithm
+}
+func (tc *typeCSegment) GetNodeAddress() net.IP {
+	return tc.address
+}
+func (tc *typeCSegment) GetLabel() *uint32 {
+	return tc.label
+}
+func (tc *typeCSegment) GetTC() byte {
+	return tc.tc
+}
+func (tc *typeCSegment) GetS() bool {
+	return tc.s
+}
+func (tc *typeCSegment) GetTTL() byte {
+	return tc.ttl
+}
+
+func (tc *typeCSegment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		SegmentType SegmentType   `json:"segment_type,omitempty"`
+		Flags       *SegmentFlags `json:"flags,omitempty"`
+		SRAlgorithm byte          `json:"sr_algorithm"`
+		NodeAddress net.IP        `json:"ipv4_node_address,omitempty"`
+		Label       *uint32       `json:"label,omitempty"`
+		TC          byte          `json:"tc,omitempty"`
+		S           bool          `json:"s,omitempty"`
+		TTL         byte          `json:"ttl,omitempty"`
+	}{
+		SegmentType: TypeC,
+		Flags:       tc.flags,
+		SRAlgorithm: tc.algorithm,
+		NodeAddress: tc.address,
+		Label:       tc.label,
+		TC:          tc.tc,
+		S:           tc.s,
+		TTL:         tc.ttl,
+	})
+}
+
+func (tc *typeCSegment) unmarshalJSONObj(objmap map[string]json.RawMessage) error {
+	if b, ok := objmap["flags"]; ok {
+		if err := json.Unmarshal(b, &tc.flags); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["sr_algorithm"]; ok {
+		if err := json.Unmarshal(b, &tc.algorithm); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["ipv4_node_address"]; ok {
+		if err := json.Unmarshal(b, &tc.address); err != nil {
+			return err
+		}
+		tc.address = tc.address.To4()
+	}
+	if b, ok := objmap["label"]; ok {
+		if err := json.Unmarshal(b, &tc.label); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["tc"]; ok {
+		if err := json.Unmarshal(b, &tc.tc); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["s"]; ok {
+		if err := json.Unmarshal(b, &tc.s); err != nil {
+			return err
+		}
+	}
+	if b, ok := objmap["ttl"]; ok {
+		if err := json.Unmarshal(b, &tc.ttl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalTypeCSegment instantiates an instance of Type C Segment sub tlv, SR-MPLS SID is optional
+// and present when the sub tlv is 10 bytes long.
+func UnmarshalTypeCSegment(b []byte) (Segment, error) {
+	if glog.V(5) {
+		glog.Infof("SR Policy Type C Segment STLV Raw: %s", tools.MessageHex(b))
+	}
+	if len(b) != 6 && len(b) != 10 {
+		return nil, fmt.Errorf("invalid length of Type C Segment STLV")
+	}
+	s := &typeCSegment{}
+	p := 0
+	s.flags = NewSegmentFlags(b[p])
+	p++
+	s.algorithm = b[p]
+	p++
+	s.address = net.IPv4(b[p], b[p+1], b[p+2], b[p+3]).To4()
+	p += 4
+	if p == len(b) {
+		return s, nil
+	}
+	l := binary.BigEndian.Uint32(b[p:p+4]) >> 12
+	s.label = &l
+	s.tc = (b[p+2] & 0x0e) >> 1
+	s.s = b[p+2]&0x01 == 0x01
+	s.ttl = b[p+3]
+
+	return s, nil
+}
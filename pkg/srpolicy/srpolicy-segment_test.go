@@ -0,0 +1,133 @@
+package srpolicy
+
+import (
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/srv6"
+)
+
+func TestUnmarshalSegmentListSTLV(t *testing.T) {
+	label := uint32(16001)
+	tests := []struct {
+		name   string
+		input  []byte
+		expect *SegmentList
+		fail   bool
+	}{
+		{
+			name: "type b segment with sid structure",
+			input: []byte{
+				0x09, 0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0A,
+				0x0D, 0x1A, 0x80, 0x00, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				0x00, 0x30, 0x00, 0x00, 0x20, 0x10, 0x10, 0x00,
+			},
+			expect: &SegmentList{
+				Weight: &Weight{Weight: 10},
+				Segment: []Segment{
+					&typeBSegment{
+						flags:    &SegmentFlags{Vflag: true},
+						sid:      net.ParseIP("2001:db8::1"),
+						behavior: 0x30,
+						structure: &srv6.SIDStructure{
+							LBLength:  32,
+							LNLength:  16,
+							FunLength: 16,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "type b segment without sid structure",
+			input: []byte{0x0D, 0x12, 0x00, 0x00, 0x20, 0x01, 0x0D, 0xB8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02},
+			expect: &SegmentList{
+				Segment: []Segment{
+					&typeBSegment{
+						flags: &SegmentFlags{},
+						sid:   net.ParseIP("2001:db8::2"),
+					},
+				},
+			},
+		},
+		{
+			name: "type c segments with and without sr-mpls sid",
+			input: []byte{
+				0x03, 0x0A, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x01, 0x03, 0xE8, 0x11, 0x40,
+				0x03, 0x06, 0x00, 0x80, 0x0A, 0x00, 0x00, 0x02,
+			},
+			expect: &SegmentList{
+				Segment: []Segment{
+					&typeCSegment{
+						flags:   &SegmentFlags{},
+						address: net.IPv4(10, 0, 0, 1).To4(),
+						label:   &label,
+						s:       true,
+						ttl:     64,
+					},
+					&typeCSegment{
+						flags:     &SegmentFlags{},
+						algorithm: 128,
+						address:   net.IPv4(10, 0, 0, 2).To4(),
+					},
+				},
+			},
+		},
+		{
+			name: "unsupported segment type is skipped",
+			input: []byte{
+				0x04, 0x02, 0x00, 0x00,
+				0x01, 0x06, 0x00, 0x00, 0x03, 0xE8, 0x10, 0x00,
+			},
+			expect: &SegmentList{
+				Segment: []Segment{
+					&typeASegment{
+						flags: &SegmentFlags{},
+						label: 16001,
+					},
+				},
+			},
+		},
+		{
+			name:  "truncated segment",
+			input: []byte{0x01, 0x06, 0x00, 0x00, 0x03},
+			fail:  true,
+		},
+		{
+			name:  "invalid length of type c segment",
+			input: []byte{0x03, 0x04, 0x00, 0x00, 0x0A, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnmarshalSegmentListSTLV(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("Supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("Supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tt.expect, got) {
+				t.Fatalf("Expected Segment List: %+v does not match to the processed Segment List: %+v", *tt.expect, *got)
+			}
+			// Segment List must survive JSON round trip
+			b, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("failed to marshal Segment List with error: %+v", err)
+			}
+			rt := &SegmentList{}
+			if err := json.Unmarshal(b, rt); err != nil {
+				t.Fatalf("failed to unmarshal Segment List with error: %+v", err)
+			}
+			if !reflect.DeepEqual(got, rt) {
+				t.Fatalf("Segment List: %s does not match after JSON round trip: %+v", string(b), *rt)
+			}
+		})
+	}
+}
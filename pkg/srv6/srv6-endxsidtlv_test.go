@@ -1,6 +1,7 @@
 package srv6
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 
@@ -35,6 +36,29 @@ func TestUnmarshalSRv6EndXSIDTLV(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name:  "backup end.x sid with weight",
+			input: []byte{0x00, 0x39, 0xa0, 0x00, 0x0a, 0x00, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0xE4, 0x00, 0x04, 0x20, 0x10, 0x10, 0x10},
+			expect: &EndXSIDTLV{
+				EndpointBehavior: 57,
+				Flags: &EndXSIDFlags{
+					BFlag: true,
+					SFlag: false,
+					PFlag: true,
+				},
+				Algorithm: 0,
+				Weight:    10,
+				SID:       "2001:db8:1:1::",
+				SubTLVs: []SubTLV{&SIDStructure{
+					Type:      1252,
+					Length:    8,
+					LBLength:  32,
+					LNLength:  16,
+					FunLength: 16,
+					ArgLength: 16,
+				}},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -49,3 +73,33 @@ func TestUnmarshalSRv6EndXSIDTLV(t *testing.T) {
 		})
 	}
 }
+
+func TestEndXSIDTLVJSON(t *testing.T) {
+	e := &EndXSIDTLV{
+		EndpointBehavior: 57,
+		Flags:            &EndXSIDFlags{BFlag: true},
+		Algorithm:        128,
+		Weight:           10,
+		SID:              "2001:db8:1:1::",
+		SubTLVs: []SubTLV{&SIDStructure{
+			Type:      1252,
+			Length:    8,
+			LBLength:  32,
+			LNLength:  16,
+			FunLength: 16,
+			ArgLength: 16,
+		}},
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("failed to marshal End.X SID TLV with error: %+v", err)
+	}
+	result := &EndXSIDTLV{}
+	if err := json.Unmarshal(b, result); err != nil {
+		t.Fatalf("failed to unmarshal End.X SID TLV with error: %+v", err)
+	}
+	if !reflect.DeepEqual(e, result) {
+		t.Logf("Differences: %+v", deep.Equal(e, result))
+		t.Fatalf("Expected object: %+v does not match result: %+v", *e, *result)
+	}
+}
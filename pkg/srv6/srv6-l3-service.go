@@ -24,7 +24,9 @@ type SIDStructureSubSubTLV struct {
 
 // UnmarshalSIDStructureSubSubTLV instantiates SID Structure Sub Sub TLV
 func UnmarshalSIDStructureSubSubTLV(b []byte) (*SIDStructureSubSubTLV, error) {
-	// Skip Resrved byte
+	if len(b) != 6 {
+		return nil, fmt.Errorf("invalid SID Structure Sub Sub TLV length %d, expected 6", len(b))
+	}
 	p := 0
 	tlv := &SIDStructureSubSubTLV{}
 	tlv.LocalBlockLength = b[p]
@@ -96,6 +98,10 @@ func (istlv *InformationSubTLV) UnmarshalJSON(b []byte) error {
 
 // UnmarshalInformationSubTLV instantiates Information SubT LV
 func UnmarshalInformationSubTLV(b []byte) (*InformationSubTLV, error) {
+	// Reserved 1 byte, SID 16 bytes, Flags 1 byte and Endpoint Behavior 2 bytes
+	if len(b) < 20 {
+		return nil, fmt.Errorf("invalid SRv6 SID Information Sub TLV length %d", len(b))
+	}
 	// Skip Resrved byte
 	p := 1
 	tlv := &InformationSubTLV{}
@@ -174,6 +180,9 @@ func UnmarshalSRv6L3Service(b []byte) (*L3Service, error) {
 	l3 := L3Service{
 		SubTLVs: make(map[uint8][]SvcSubTLV),
 	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("not enough bytes to unmarshal SRv6 L3 Service")
+	}
 	// Skipping reserved byte
 	stlv, err := UnmarshalSRv6L3ServiceSubTLV(b[1:])
 	if err != nil {
@@ -189,10 +198,16 @@ func UnmarshalSRv6L3ServiceSubTLV(b []byte) (map[uint8][]SvcSubTLV, error) {
 	m := make(map[uint8][]SvcSubTLV)
 	var err error
 	for p := 0; p < len(b); {
+		if p+3 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal SRv6 L3 Service Sub TLV")
+		}
 		t := b[p]
 		p++
 		l := binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if p+int(l) > len(b) {
+			return nil, fmt.Errorf("invalid SRv6 L3 Service Sub TLV %d length %d", t, l)
+		}
 		var s SvcSubTLV
 		switch t {
 		case 1:
@@ -220,10 +235,16 @@ func UnmarshalSRv6L3ServiceSubSubTLV(b []byte) (map[uint8][]SvcSubSubTLV, error)
 	var err error
 	m := make(map[uint8][]SvcSubSubTLV)
 	for p := 1; p < len(b); {
+		if p+3 > len(b) {
+			return nil, fmt.Errorf("not enough bytes to unmarshal SRv6 L3 Service Sub Sub TLV")
+		}
 		t := b[p]
 		p++
 		l := binary.BigEndian.Uint16(b[p : p+2])
 		p += 2
+		if p+int(l) > len(b) {
+			return nil, fmt.Errorf("invalid SRv6 L3 Service Sub Sub TLV %d length %d", t, l)
+		}
 		var s SvcSubSubTLV
 		switch t {
 		case 1:
@@ -39,6 +39,10 @@ func UnmarshalSRv6LocatorTLV(b []byte) (*LocatorTLV, error) {
 	if glog.V(6) {
 		glog.Infof("SRv6 Locator TLV Raw: %s", tools.MessageHex(b))
 	}
+	// Flags 1 byte, Algorithm 1 byte, Reserved 2 bytes and Metric 4 bytes
+	if len(b) < 8 {
+		return nil, fmt.Errorf("invalid length of data %d, expected minimum of 8", len(b))
+	}
 	p := 0
 	loc := LocatorTLV{}
 	f, err := UnmarshalLocatorFlags(b[p : p+1])
@@ -0,0 +1,62 @@
+package srv6
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sbezverk/gobmp/pkg/base"
+)
+
+func TestUnmarshalSRv6LocatorTLV(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  []byte
+		fail   bool
+		expect *LocatorTLV
+	}{
+		{
+			name:  "flex algo locator",
+			input: []byte{0x80, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a},
+			expect: &LocatorTLV{
+				Flag:      &LocatorFlags{DFlag: true},
+				Algorithm: 128,
+				Metric:    10,
+			},
+		},
+		{
+			name:  "locator with sub tlv",
+			input: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x04, 0x06, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01},
+			expect: &LocatorTLV{
+				Flag:   &LocatorFlags{},
+				Metric: 1,
+				SubTLV: []*base.SubTLV{
+					{Type: 1030, Length: 4, Value: []byte{0x00, 0x00, 0x00, 0x01}},
+				},
+			},
+		},
+		{
+			name:  "truncated locator",
+			input: []byte{0x80, 0x80, 0x00, 0x00},
+			fail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := UnmarshalSRv6LocatorTLV(tt.input)
+			if err != nil && !tt.fail {
+				t.Fatalf("supposed to succeed but failed with error: %+v", err)
+			}
+			if err == nil && tt.fail {
+				t.Fatalf("supposed to fail but succeeded")
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(tt.expect, result) {
+				t.Logf("Differences: %+v", deep.Equal(tt.expect, result))
+				t.Fatalf("Expected object: %+v does not match result: %+v", *tt.expect, *result)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package srv6
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/golang/glog"
 	"github.com/sbezverk/tools"
@@ -31,6 +32,9 @@ func UnmarshalSRv6SIDStructureTLV(b []byte) (*SIDStructure, error) {
 	if glog.V(6) {
 		glog.Infof("SRv6 SID Structure TLV Raw: %s", tools.MessageHex(b))
 	}
+	if len(b) != 4 {
+		return nil, fmt.Errorf("invalid length of SRv6 SID Structure TLV %d, expected 4", len(b))
+	}
 	st := SIDStructure{}
 	p := 0
 	st.LBLength = b[p]
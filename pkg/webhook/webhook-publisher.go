@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sbezverk/gobmp/pkg/logging"
+	"github.com/sbezverk/gobmp/pkg/pub"
+)
+
+const (
+	// defaultTimeout is the time to wait for the webhook to respond to a published message
+	defaultTimeout = 10 * time.Second
+	// maxIdleConns is the number of idle connections to the webhook kept open for reuse
+	maxIdleConns = 16
+)
+
+// Headers of the request carrying the type and the key of the published message
+const (
+	MessageTypeHeader = "X-Gobmp-Message-Type"
+	MessageHashHeader = "X-Gobmp-Message-Hash"
+)
+
+// Option defines a function which modifies optional parameters of webhook publisher
+type Option func(*publisher)
+
+// WithHeader adds the header to every request, for example Authorization header carrying the webhook's credentials.
+func WithHeader(name, value string) Option {
+	return func(p *publisher) {
+		p.header.Add(name, value)
+	}
+}
+
+// WithTimeout sets the time to wait for the webhook to respond, PublishMessage returns an error when the webhook
+// does not respond in time and the message may not be delivered.
+func WithTimeout(timeout time.Duration) Option {
+	return func(p *publisher) {
+		p.timeout = timeout
+	}
+}
+
+// WithBatching makes the publisher post messages of the same type and key in batches, as a JSON array of up to
+// maxMessages messages, a batch is posted when it is full or on every flushInterval, whichever comes first.
+// See pub.NewBatchPublisher.
+func WithBatching(maxMessages int, flushInterval time.Duration) Option {
+	return func(p *publisher) {
+		p.batchSize = maxMessages
+		p.batchInterval = flushInterval
+	}
+}
+
+// WithLogger sets the logger of the publisher, by default and when logger is nil records are written to glog
+func WithLogger(logger *slog.Logger) Option {
+	return func(p *publisher) {
+		if logger != nil {
+			p.logger = logger
+		}
+	}
+}
+
+type publisher struct {
+	logger        *slog.Logger
+	url           string
+	client        *http.Client
+	header        http.Header
+	timeout       time.Duration
+	batchSize     int
+	batchInterval time.Duration
+}
+
+func (p *publisher) PublishMessage(t int, key []byte, msg []byte) error {
+	return p.PublishMessageContext(context.Background(), pub.Router{}, t, key, msg)
+}
+
+// PublishMessageContext posts msg to the webhook, the request is canceled when ctx is done.
+func (p *publisher) PublishMessageContext(ctx context.Context, router pub.Router, t int, key []byte, msg []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(msg))
+	if err != nil {
+		return err
+	}
+	for name, values := range p.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(MessageTypeHeader, strconv.Itoa(t))
+	if len(key) != 0 {
+		req.Header.Set(MessageHashHeader, string(key))
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post message of type %d with error: %w", t, err)
+	}
+	// Draining the body lets the connection be reused by the following requests
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded to message of type %d with status %s", t, resp.Status)
+	}
+
+	return nil
+}
+
+func (p *publisher) Stop() {
+	p.client.CloseIdleConnections()
+}
+
+// NewPublisher instantiates a new instance of a webhook publisher, every message is posted as JSON to webhookURL
+// and an error is returned when the webhook does not respond with 2xx status. Connections to the webhook are
+// kept open and reused by the following requests.
+func NewPublisher(webhookURL string, opts ...Option) (pub.Publisher, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url %q, http or https url is expected", webhookURL)
+	}
+	p := &publisher{
+		logger:  logging.Default(),
+		url:     webhookURL,
+		header:  http.Header{},
+		timeout: defaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.logger.Info("Initializing webhook publisher")
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConns
+	p.client = &http.Client{
+		Transport: transport,
+		Timeout:   p.timeout,
+	}
+	if p.batchSize > 1 {
+		return pub.NewBatchPublisher(p, p.batchSize, p.batchInterval, pub.WithBatchLogger(p.logger)), nil
+	}
+
+	return p, nil
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/sbezverk/gobmp/pkg/bmp"
+)
+
+type request struct {
+	body   string
+	header http.Header
+}
+
+func TestPublishMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []Option
+		status int
+		msgs   []string
+		expect []string
+		fail   bool
+	}{
+		{
+			name:   "single message",
+			opts:   []Option{WithHeader("Authorization", "Bearer token")},
+			status: http.StatusOK,
+			msgs:   []string{`{"action":"add","prefix":"10.0.0.0"}`},
+			expect: []string{`{"action":"add","prefix":"10.0.0.0"}`},
+		},
+		{
+			name:   "batch of messages",
+			opts:   []Option{WithHeader("Authorization", "Bearer token"), WithBatching(2, 0)},
+			status: http.StatusAccepted,
+			msgs:   []string{`{"action":"add","prefix":"10.0.0.0"}`, `{"action":"del","prefix":"10.0.0.0"}`},
+			expect: []string{`[{"action":"add","prefix":"10.0.0.0"},{"action":"del","prefix":"10.0.0.0"}]`},
+		},
+		{
+			name:   "non 2xx status",
+			opts:   []Option{WithHeader("Authorization", "Bearer token")},
+			status: http.StatusUnauthorized,
+			msgs:   []string{`{"action":"add","prefix":"10.0.0.0"}`},
+			expect: []string{`{"action":"add","prefix":"10.0.0.0"}`},
+			fail:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(chan request, len(tt.msgs))
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				requests <- request{body: string(b), header: r.Header}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			p, err := NewPublisher(srv.URL, tt.opts...)
+			if err != nil {
+				t.Fatalf("failed to create webhook publisher with error: %+v", err)
+			}
+			for _, msg := range tt.msgs {
+				err = p.PublishMessage(bmp.UnicastPrefixV4Msg, []byte("router_hash"), []byte(msg))
+			}
+			if tt.fail && err == nil {
+				t.Fatalf("expected to fail but succeeded")
+			}
+			if !tt.fail && err != nil {
+				t.Fatalf("failed to publish message with error: %+v", err)
+			}
+			p.Stop()
+			close(requests)
+			got := make([]request, 0)
+			for r := range requests {
+				got = append(got, r)
+			}
+			if len(got) != len(tt.expect) {
+				t.Fatalf("expected %d requests, got %d", len(tt.expect), len(got))
+			}
+			for i, r := range got {
+				if r.body != tt.expect[i] {
+					t.Errorf("expected body %s, got %s", tt.expect[i], r.body)
+				}
+				if v := r.header.Get("Authorization"); v != "Bearer token" {
+					t.Errorf("expected Authorization header \"Bearer token\", got %q", v)
+				}
+				if v := r.header.Get(MessageTypeHeader); v != strconv.Itoa(bmp.UnicastPrefixV4Msg) {
+					t.Errorf("expected message type %d, got %q", bmp.UnicastPrefixV4Msg, v)
+				}
+				if v := r.header.Get(MessageHashHeader); v != "router_hash" {
+					t.Errorf("expected message hash router_hash, got %q", v)
+				}
+			}
+		})
+	}
+}
+
+func TestNewPublisherURL(t *testing.T) {
+	for _, u := range []string{"", "localhost:8080", "ftp://localhost/hook", "http://"} {
+		if _, err := NewPublisher(u); err == nil {
+			t.Errorf("expected url %q to be rejected", u)
+		}
+	}
+}